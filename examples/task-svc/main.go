@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
 	"log"
 	"net/http"
 	"sync/atomic"
@@ -45,12 +46,12 @@ func openSubscription(client *zbc.Client, stopCh chan bool, pid int32, topic str
 		case message := <-subscriptionCh:
 			processTask(lo, message)
 
-			completeTaskMsg := zbc.NewCompleteTaskMessage(message)
-			response, err := client.Responder(completeTaskMsg)
+			response, err := client.CompleteTask((*message.SbeMessage).(*sbe.SubscribedEvent), nil)
 
 			if err != nil {
 				log.Println("Completing a task went wrong.")
 				log.Println(err)
+				break
 			}
 			if (*response.Data)["state"] == "COMPLETED" {
 				atomic.AddUint64(&ProcessedEventsCount, 1)
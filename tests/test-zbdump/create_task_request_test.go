@@ -89,7 +89,7 @@ func TestCreateTaskRequest_CommandRequest(t *testing.T) {
 	}
 	executeCmdRequestI := *msg.SbeMessage
 	cmdReq := executeCmdRequestI.(*sbe.ExecuteCommandRequest)
-	size := int(cmdReq.SbeBlockLength()) + zbc.LengthFieldSize + len(cmdReq.TopicName) + zbc.LengthFieldSize + len(cmdReq.Command) + zbc.TotalHeaderSizeNoFrame
+	size := int(cmdReq.SbeBlockLengthVersion1()) + zbc.LengthFieldSize + len(cmdReq.TopicName) + zbc.LengthFieldSize + len(cmdReq.Command) + zbc.TotalHeaderSizeNoFrame
 
 	if uint32(size) != headers.FrameHeader.Length {
 		t.Fatalf("size of sbe.ExecuteCommandRequest is %d and FrameHeader.Length is %d", size, headers.FrameHeader.Length)
@@ -117,7 +117,7 @@ func TestCreateTaskRequest_CommandRequest2(t *testing.T) {
 
 	executeCmdRequestI := *msg.SbeMessage
 	cmdReq := executeCmdRequestI.(*sbe.ExecuteCommandRequest)
-	size := int(cmdReq.SbeBlockLength()) + 2 + len(cmdReq.TopicName) + 2 + len(cmdReq.Command) + zbc.TotalHeaderSizeNoFrame
+	size := int(cmdReq.SbeBlockLengthVersion1()) + 2 + len(cmdReq.TopicName) + 2 + len(cmdReq.Command) + zbc.TotalHeaderSizeNoFrame
 
 	if uint32(size) != headers.FrameHeader.Length {
 		t.Fatalf("size of sbe.ExecuteCommandRequest is %d and FrameHeader.Length is %d", size, headers.FrameHeader.Length)
@@ -131,3 +131,38 @@ func TestCreateTaskRequest_CommandRequest2(t *testing.T) {
 		t.Fatalf("Expected %+#v, received %+#v", reader, byteBuffer.Bytes())
 	}
 }
+
+func TestCreateTaskRequest_UnmarshalData(t *testing.T) {
+	reader, fsErr := ioutil.ReadFile(CreateTaskRequest)
+	if fsErr != nil {
+		t.Fatalf("fserr %+#v", fsErr)
+	}
+
+	msgReader := zbc.MessageReader{Reader: bytes.NewReader(reader)}
+	headers, message, err := msgReader.ReadHeaders()
+	if err != nil {
+		t.Fatal("Cannot read headers.")
+	}
+
+	msg, err := msgReader.ParseMessage(headers, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var task struct {
+		EventType string            `msgpack:"eventType"`
+		Type      string            `msgpack:"type"`
+		Retries   int               `msgpack:"retries"`
+		Headers   map[string]string `msgpack:"headers"`
+	}
+	if err := msg.UnmarshalData(&task); err != nil {
+		t.Fatalf("Cannot unmarshal data: %s", err)
+	}
+
+	if task.EventType != "CREATE" || task.Type != "foo" || task.Retries != 3 {
+		t.Fatalf("Wrong task decoded: %+v", task)
+	}
+	if task.Headers["k1"] != "a" || task.Headers["k2"] != "b" {
+		t.Fatalf("Wrong headers decoded: %+v", task.Headers)
+	}
+}
@@ -0,0 +1,103 @@
+package zbc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// AllPartitions as PartitionID of TaskSubscription opens the subscription on every partition of the topic.
+const AllPartitions = -1
+
+// subscribeAll will open task subscription on every partition of the topic known from topology and merge their
+// events into one channel. Every partition keeps its own Credits, so broker can push up to Credits tasks per partition.
+func (c *Client) subscribeAll(ctx context.Context, ts *TaskSubscription, held bool) (*Subscription, error) {
+	partitions := c.cachedTopology(ctx).Partitions(ts.TopicName)
+	if len(partitions) == 0 {
+		// Cached topology may be older than the topic.
+		topology, err := c.TopologyCtx(ctx)
+		if err != nil {
+			return nil, err
+		}
+		partitions = topology.Partitions(ts.TopicName)
+	}
+	if len(partitions) == 0 {
+		return nil, ErrTopicNotFound
+	}
+
+	group := &Subscription{
+		client:     c,
+		task:       ts,
+		ch:         make(chan *Message),
+		held:       held,
+		closeCh:    make(chan struct{}),
+		forwarded:  make(chan struct{}),
+		partitions: make(map[uint16]*Subscription, len(partitions)),
+	}
+	for _, partitionID := range partitions {
+		pts := *ts
+		pts.PartitionID = int32(partitionID)
+		sub, err := c.subscribe(ctx, &pts, held)
+		if err != nil {
+			// Events of the group are not merged yet, so only partitions opened so far are closed.
+			for _, opened := range group.partitions {
+				opened.CloseCtx(ctx)
+			}
+			return nil, err
+		}
+		group.partitions[partitionID] = sub
+	}
+
+	// Merging goroutines are waited for by the spawned one, so Close waits for all of them.
+	spawned := c.spawn(func() {
+		var wg sync.WaitGroup
+		for _, sub := range group.partitions {
+			wg.Add(1)
+			go group.merge(sub, &wg)
+		}
+		wg.Wait()
+		close(group.ch)
+		close(group.forwarded)
+	})
+	if !spawned {
+		for _, opened := range group.partitions {
+			opened.CloseCtx(ctx)
+		}
+		return nil, ErrClientClosed
+	}
+	return group, nil
+}
+
+// merge will pass events of the partition to the consumer until the partition or the whole group is closed.
+func (s *Subscription) merge(sub *Subscription, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for message := range sub.ch {
+		select {
+		case <-s.closeCh:
+			return
+		case s.ch <- message:
+		}
+	}
+}
+
+// closePartitions will close subscriptions of all partitions of the group. First error is returned.
+func (s *Subscription) closePartitions(ctx context.Context) error {
+	var err error
+	for _, sub := range s.partitions {
+		if closeErr := sub.CloseCtx(ctx); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if waitErr := s.stopForwarding(ctx); err == nil {
+		err = waitErr
+	}
+	return err
+}
+
+// partitionOf returns subscription of the partition which delivered the message.
+func (s *Subscription) partitionOf(message *Message) *Subscription {
+	event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+	return s.partitions[event.PartitionId]
+}
@@ -0,0 +1,74 @@
+package zbc_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_OpenTaskSubscriptionAllPartitions(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	host, portStr, _ := net.SplitHostPort(broker.Addr())
+	port, _ := strconv.Atoi(portStr)
+	addr := zbc.BrokerAddress{Host: host, Port: port}
+	broker.HandleControl(sbe.ControlMessageType.REQUEST_TOPOLOGY, func(request *zbc.Message) zbtest.Response {
+		var leaders []zbc.TopicLeader
+		for _, partitionID := range []uint16{1, 0} {
+			leaders = append(leaders, zbc.TopicLeader{BrokerAddress: addr, TopicName: "default-topic", PartitionID: partitionID})
+		}
+		return zbtest.ControlResponse(&zbc.Topology{TopicLeaders: leaders, Brokers: []zbc.BrokerAddress{addr}})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	sub, err := client.OpenTaskSubscription(&zbc.TaskSubscription{
+		TopicName:    "default-topic",
+		PartitionID:  zbc.AllPartitions,
+		TaskType:     "foo",
+		LockDuration: 1000,
+		LockOwner:    "zbc",
+		Credits:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Broker pushes the task to subscriptions of both partitions.
+	if err := broker.PushTask(1, &zbc.Task{Type: "foo", Retries: 3}); err != nil {
+		t.Fatal(err)
+	}
+	partitions := make(map[uint16]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case message := <-sub.Events():
+			partitions[(*message.SbeMessage).(*sbe.SubscribedEvent).PartitionId] = true
+		case <-time.After(time.Second):
+			t.Fatal("Expected task from every partition")
+		}
+	}
+	if !partitions[0] || !partitions[1] {
+		t.Fatalf("Expected tasks of partitions 0 and 1, received %v", partitions)
+	}
+
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("Expected channel closed with subscriptions of all partitions")
+	}
+}
@@ -0,0 +1,132 @@
+package zbc
+
+import (
+	"context"
+	"fmt"
+)
+
+// Response is the result of request sent by SendAsync. Err is set if the request failed, Message otherwise.
+type Response struct {
+	Message *Message
+	Err     error
+}
+
+// SendAsync will send the request and return without waiting for the response. Exactly one Response is put into
+// the returned channel once the response arrives or the request times out. Error is returned if sending fails.
+// Many requests can be pipelined this way over one connection. If interceptors are used, request is passed through
+// them in the background and sending errors are put into the channel too.
+func (c *Client) SendAsync(message *Message, opts ...RequestOption) (<-chan *Response, error) {
+	ctx, cancel := c.requestContext(opts...)
+	ch, err := c.sendAsync(ctx, message, cancel)
+	if err != nil {
+		cancel()
+	}
+	return ch, err
+}
+
+// SendAsyncCtx is same as SendAsync, but waiting for the response is aborted once ctx is done.
+func (c *Client) SendAsyncCtx(ctx context.Context, message *Message) (<-chan *Response, error) {
+	return c.sendAsync(ctx, message, func() {})
+}
+
+func (c *Client) sendAsync(ctx context.Context, message *Message, cancel context.CancelFunc) (<-chan *Response, error) {
+	if len(c.getInterceptors()) > 0 {
+		ch := make(chan *Response, 1)
+		spawned := c.spawn(func() {
+			defer cancel()
+			msg, err := c.ResponderCtx(ctx, message)
+			ch <- &Response{Message: msg, Err: err}
+			close(ch)
+		})
+		if !spawned {
+			return nil, ErrClientClosed
+		}
+		return ch, nil
+	}
+
+	done := c.observeRequest(message)
+	requestID, respCh, err := c.send(ctx, message)
+	if err != nil {
+		done(err)
+		return nil, err
+	}
+
+	ch := make(chan *Response, 1)
+	spawned := c.spawn(func() {
+		defer cancel()
+		msg, err := c.await(ctx, requestID, respCh)
+		done(err)
+		ch <- &Response{Message: msg, Err: err}
+		close(ch)
+	})
+	if !spawned {
+		c.removeTransaction(requestID)
+		done(ErrClientClosed)
+		return nil, ErrClientClosed
+	}
+	return ch, nil
+}
+
+// BatchError is returned by AwaitAll and SendBatch if some of the requests failed.
+// Errors has an entry for every request, nil for those which succeeded.
+type BatchError struct {
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+	failed := 0
+	var first error
+	for _, err := range e.Errors {
+		if err != nil {
+			if first == nil {
+				first = err
+			}
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d of %d requests failed, first error: %s", failed, len(e.Errors), first)
+}
+
+// AwaitAll will wait for responses of all requests sent by SendAsync. Responses are returned in order of the channels,
+// nil for failed requests. If any request failed, error is *BatchError.
+func AwaitAll(responses []<-chan *Response) ([]*Message, error) {
+	messages := make([]*Message, len(responses))
+	errs := make([]error, len(responses))
+	failed := false
+
+	for i, ch := range responses {
+		resp := <-ch
+		messages[i], errs[i] = resp.Message, resp.Err
+		if resp.Err != nil {
+			failed = true
+		}
+	}
+
+	if failed {
+		return messages, &BatchError{Errors: errs}
+	}
+	return messages, nil
+}
+
+// SendBatch will pipeline all requests over the connection and wait for their responses. Responses are returned
+// in order of the requests, nil for failed ones. If any request failed, error is *BatchError.
+func (c *Client) SendBatch(messages []*Message, opts ...RequestOption) ([]*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.SendBatchCtx(ctx, messages)
+}
+
+// SendBatchCtx is same as SendBatch, but waiting for the responses is aborted once ctx is done.
+func (c *Client) SendBatchCtx(ctx context.Context, messages []*Message) ([]*Message, error) {
+	responses := make([]<-chan *Response, len(messages))
+	for i, message := range messages {
+		ch, err := c.SendAsyncCtx(ctx, message)
+		if err != nil {
+			failed := make(chan *Response, 1)
+			failed <- &Response{Err: err}
+			ch = failed
+		}
+		responses[i] = ch
+	}
+	return AwaitAll(responses)
+}
@@ -0,0 +1,146 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SendBatch(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		if task.Type == "bad" {
+			return zbtest.ErrorResponse(sbe.ErrorCode.REQUEST_PROCESSING_FAILURE, "bad task")
+		}
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "CREATED", "type": task.Type})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	var messages []*zbc.Message
+	for _, taskType := range []string{"a", "bad", "c"} {
+		messages = append(messages, zbc.NewTaskMessage(&sbe.ExecuteCommandRequest{
+			TopicName: []uint8("default-topic"),
+			Command:   []uint8{},
+		}, &zbc.Task{State: "CREATE", Type: taskType}))
+	}
+
+	responses, err := client.SendBatch(messages, zbc.TimeoutOption(time.Second))
+	batchErr, ok := err.(*zbc.BatchError)
+	if !ok {
+		t.Fatalf("Expected BatchError, received %v", err)
+	}
+	if batchErr.Errors[0] != nil || zbc.Cause(batchErr.Errors[1]) != zbc.ErrRequestProcessingFailure || batchErr.Errors[2] != nil {
+		t.Fatalf("Expected only second request to fail, received %v", batchErr.Errors)
+	}
+	if responses[1] != nil {
+		t.Fatalf("Expected no response for failed request, received %+v", responses[1])
+	}
+	for i, expected := range map[int]string{0: "a", 2: "c"} {
+		if taskType := (*responses[i].Data)["type"]; taskType != expected {
+			t.Fatalf("Expected response to task %s, received %v", expected, taskType)
+		}
+	}
+}
+
+func newBenchmarkClient(b *testing.B) (*zbc.Client, func()) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		b.Fatal(err)
+	}
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "CREATED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		broker.Close()
+		b.Fatal(err)
+	}
+	return client, func() {
+		client.Close(context.Background())
+		broker.Close()
+	}
+}
+
+func BenchmarkClient_CreateTask(b *testing.B) {
+	client, closeAll := newBenchmarkClient(b)
+	defer closeAll()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo", Retries: 3}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkClient_CreateTaskParallel measures throughput of concurrent requests, whose frames are written in batches.
+func BenchmarkClient_CreateTaskParallel(b *testing.B) {
+	client, closeAll := newBenchmarkClient(b)
+	defer closeAll()
+
+	b.ReportAllocs()
+	b.SetParallelism(8)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo", Retries: 3}); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+func TestClient_SendAsyncClosed(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	// Broker never answers, request waits until the client is closed.
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		return nil
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := client.SendAsyncCtx(context.Background(), zbc.NewTaskMessage(&sbe.ExecuteCommandRequest{
+		TopicName: []uint8("default-topic"),
+		Command:   []uint8{},
+	}, &zbc.Task{State: "CREATE", Type: "foo"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	client.Close(ctx)
+	select {
+	case resp := <-ch:
+		if resp.Err != zbc.ErrClientClosed {
+			t.Fatalf("Expected ErrClientClosed, received %v", resp.Err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected request to end with the client")
+	}
+}
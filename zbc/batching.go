@@ -0,0 +1,109 @@
+package zbc
+
+import (
+	"time"
+)
+
+// maxWriteBatch is number of frames written to the connection at once at most.
+const maxWriteBatch = 64
+
+// pendingWrite is a frame handed over to frameWriter. Result of the write is put into done.
+type pendingWrite struct {
+	frame    []byte
+	deadline time.Time
+	done     chan error
+}
+
+// frameWriter is the only goroutine writing to the connection, so frames of concurrent requests never interleave.
+// Senders hand their frames over through unbuffered channel and wait for the result. Frames handed over while
+// the previous batch is written are written together by a single syscall. Nothing waits for more frames, so
+// requests are not delayed while the connection is idle.
+type frameWriter struct {
+	frames chan *pendingWrite
+}
+
+func newFrameWriter() *frameWriter {
+	return &frameWriter{frames: make(chan *pendingWrite)}
+}
+
+// write will hand the frame over to the writer and return once it is written. Frames are written in order
+// of the calls. ErrClientClosed is returned if stop is closed before the writer takes the frame.
+func (w *frameWriter) write(frame []byte, deadline time.Time, stop <-chan struct{}) error {
+	p := &pendingWrite{frame: frame, deadline: deadline, done: make(chan error, 1)}
+	select {
+	case w.frames <- p:
+	case <-stop:
+		return ErrClientClosed
+	}
+	// Once taken, the frame is always written or failed, so it's safe to wait for the result only.
+	return <-p.done
+}
+
+// run will write frames handed over by write until stop is closed. Batches are written by flush.
+func (w *frameWriter) run(flush func(batch []*pendingWrite) error, stop <-chan struct{}) {
+	for {
+		var batch []*pendingWrite
+		select {
+		case p := <-w.frames:
+			batch = append(batch, p)
+		case <-stop:
+			return
+		}
+
+	collect:
+		for len(batch) < maxWriteBatch {
+			select {
+			case p := <-w.frames:
+				batch = append(batch, p)
+			default:
+				break collect
+			}
+		}
+
+		err := flush(batch)
+		for _, p := range batch {
+			p.done <- err
+		}
+	}
+}
+
+// batchDeadline returns the latest deadline of the frames, zero if any of them has none.
+func batchDeadline(batch []*pendingWrite) time.Time {
+	var deadline time.Time
+	for _, p := range batch {
+		if p.deadline.IsZero() {
+			return time.Time{}
+		}
+		if p.deadline.After(deadline) {
+			deadline = p.deadline
+		}
+	}
+	return deadline
+}
+
+// writeBatch will write frames of the batch to the connection at once.
+func (c *Client) writeBatch(batch []*pendingWrite) error {
+	conn := c.connection()
+	if deadline := batchDeadline(batch); !deadline.IsZero() {
+		conn.SetWriteDeadline(deadline)
+		defer conn.SetWriteDeadline(time.Time{})
+	}
+
+	frames := make([][]byte, len(batch))
+	length := 0
+	for i, p := range batch {
+		frames[i] = p.frame
+		length += len(p.frame)
+	}
+
+	n, err := writeFrames(conn, frames)
+	if err != nil {
+		// Closing the socket will make receiver notice the broken connection and reconnect.
+		conn.Close()
+		return err
+	}
+	if n != int64(length) {
+		return errSocketWrite
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package zbc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFrameWriter_CoalescesQueuedFrames(t *testing.T) {
+	w := newFrameWriter()
+	batches := make(chan []string, 2)
+	release := make(chan struct{})
+	flush := func(batch []*pendingWrite) error {
+		frames := make([]string, len(batch))
+		for i, p := range batch {
+			frames[i] = string(p.frame)
+		}
+		batches <- frames
+		<-release
+		return nil
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		w.run(flush, stop)
+		close(stopped)
+	}()
+
+	errs := make(chan error, 3)
+	go func() { errs <- w.write([]byte("a"), time.Time{}, stop) }()
+	if first := <-batches; len(first) != 1 || first[0] != "a" {
+		t.Fatalf("Expected first frame written alone, written %v", first)
+	}
+
+	// Frames handed over while the first one is written must be written together, in order of the calls.
+	go func() { errs <- w.write([]byte("b"), time.Time{}, stop) }()
+	time.Sleep(20 * time.Millisecond)
+	go func() { errs <- w.write([]byte("c"), time.Time{}, stop) }()
+	time.Sleep(20 * time.Millisecond)
+
+	close(release)
+	if second := <-batches; len(second) != 2 || second[0] != "b" || second[1] != "c" {
+		t.Fatalf("Expected queued frames written in one batch, written %v", second)
+	}
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(stop)
+	<-stopped
+	if err := w.write([]byte("d"), time.Time{}, stop); err != ErrClientClosed {
+		t.Fatalf("Expected ErrClientClosed once writer is stopped, received %v", err)
+	}
+}
+
+func TestBatchDeadline(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Second)
+	if d := batchDeadline([]*pendingWrite{{deadline: later}, {deadline: now}}); !d.Equal(later) {
+		t.Fatalf("Expected latest deadline, got %v", d)
+	}
+	if d := batchDeadline([]*pendingWrite{{deadline: now}, {}}); !d.IsZero() {
+		t.Fatalf("Expected no deadline, got %v", d)
+	}
+}
@@ -0,0 +1,235 @@
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+var errBufferFull = errors.New("Subscription buffer is full")
+
+// OverflowPolicy decides what happens to events which arrive when buffer of the subscription is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock stops reading from the connection until the consumer takes an event. Responses and events
+	// of other subscriptions on the same connection wait as well.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDrop drops the event. Dropped events are logged and reported to Observer implementing DropObserver.
+	// Dropped tasks stay locked until lock duration passes.
+	OverflowDrop
+
+	// OverflowSpill writes events to a temporary file until the consumer catches up. Order of events is kept.
+	OverflowSpill
+)
+
+// eventBuffer is a ring buffer of events received from the broker but not yet taken by the consumer of the
+// subscription. It decouples reading from the connection from delivery to the consumer.
+type eventBuffer struct {
+	overflow OverflowPolicy
+
+	mu     sync.Mutex
+	ring   []*Message
+	head   int
+	size   int
+	spill  *spillFile // Holds events newer than those in ring, nil unless events were spilled.
+	closed bool
+
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+func newEventBuffer(size int32, overflow OverflowPolicy) *eventBuffer {
+	if size < 1 {
+		size = 1
+	}
+	return &eventBuffer{
+		overflow: overflow,
+		ring:     make([]*Message, size),
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+}
+
+// signal will wake up goroutine waiting on ch, if there is one.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// put will add the event to the buffer. If the buffer is full, overflow policy is applied. With OverflowBlock
+// it waits until there is space or done is closed. errBufferFull is returned if the event was dropped.
+func (b *eventBuffer) put(message *Message, done <-chan struct{}) error {
+	for {
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return nil
+		}
+
+		var err error
+		switch {
+		case b.spill != nil:
+			// Events already waiting on disk must be delivered first.
+			err = b.spill.write(message)
+		case b.size < len(b.ring):
+			b.ring[(b.head+b.size)%len(b.ring)] = message
+			b.size++
+		case b.overflow == OverflowDrop:
+			err = errBufferFull
+		case b.overflow == OverflowSpill:
+			if b.spill, err = newSpillFile(); err == nil {
+				err = b.spill.write(message)
+			}
+		default:
+			b.mu.Unlock()
+			select {
+			case <-b.notFull:
+				continue
+			case <-done:
+				return nil
+			}
+		}
+		b.mu.Unlock()
+
+		if err == nil {
+			signal(b.notEmpty)
+		}
+		return err
+	}
+}
+
+// take will return the oldest event, waiting for one if the buffer is empty. Nil is returned once done is closed.
+// If spilled events cannot be read back, they are dropped and the error is returned.
+func (b *eventBuffer) take(done <-chan struct{}) (*Message, error) {
+	for {
+		b.mu.Lock()
+		if b.size > 0 {
+			message := b.ring[b.head]
+			b.ring[b.head] = nil
+			b.head = (b.head + 1) % len(b.ring)
+			b.size--
+			b.mu.Unlock()
+			signal(b.notFull)
+			return message, nil
+		}
+		if b.spill != nil {
+			message, err := b.spill.read()
+			if err != nil || b.spill.count == 0 {
+				b.spill.remove()
+				b.spill = nil
+			}
+			b.mu.Unlock()
+			return message, err
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-b.notEmpty:
+		case <-done:
+			return nil, nil
+		}
+	}
+}
+
+// len returns number of events waiting in the buffer, spilled ones included.
+func (b *eventBuffer) len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := b.size
+	if b.spill != nil {
+		n += b.spill.count
+	}
+	return n
+}
+
+// close will drop buffered events and remove the spill file. Events put afterwards are ignored.
+func (b *eventBuffer) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for i := range b.ring {
+		b.ring[i] = nil
+	}
+	b.size = 0
+	if b.spill != nil {
+		b.spill.remove()
+		b.spill = nil
+	}
+}
+
+// spillFile keeps events in a temporary file as frames prefixed by their length, same frames as sent by the broker.
+type spillFile struct {
+	file   *os.File
+	in     *os.File // Second handle of the file, so reading doesn't move offset of writes.
+	reader *bufio.Reader
+	count  int // Number of events written but not read yet.
+}
+
+func newSpillFile() (*spillFile, error) {
+	file, err := ioutil.TempFile("", "zbc-spill-")
+	if err != nil {
+		return nil, err
+	}
+	in, err := os.Open(file.Name())
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+	return &spillFile{file: file, in: in, reader: bufio.NewReader(in)}, nil
+}
+
+func (s *spillFile) write(message *Message) error {
+	buffer := acquireBuffer()
+	defer releaseBuffer(buffer)
+
+	NewMessageWriter(message).Write(buffer)
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(buffer.Len()))
+	if _, err := s.file.Write(length[:]); err != nil {
+		return err
+	}
+	if _, err := s.file.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+	s.count++
+	return nil
+}
+
+func (s *spillFile) read() (*Message, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(s.reader, length[:]); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, binary.LittleEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(s.reader, frame); err != nil {
+		return nil, err
+	}
+	s.count--
+
+	// Buffer holds the whole frame, so the reader never gets a short read.
+	mr := NewMessageReader(bufio.NewReaderSize(bytes.NewReader(frame), len(frame)))
+	headers, tail, err := mr.ReadHeaders()
+	if err != nil {
+		return nil, err
+	}
+	return mr.ParseMessage(headers, tail)
+}
+
+func (s *spillFile) remove() {
+	s.in.Close()
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
@@ -0,0 +1,99 @@
+package zbc_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+type dropCounter struct {
+	dropped int32
+}
+
+func (d *dropCounter) RequestSent(string)                     {}
+func (d *dropCounter) ResponseReceived(string, time.Duration) {}
+func (d *dropCounter) RequestFailed(string, error)            {}
+func (d *dropCounter) EventReceived(string)                   {}
+func (d *dropCounter) CreditsChanged(string, int32)           {}
+func (d *dropCounter) EventDropped(string)                    { atomic.AddInt32(&d.dropped, 1) }
+
+// openBuffered will push events to a topic subscription with buffer of one event, before its consumer reads any.
+func openBuffered(t *testing.T, overflow zbc.OverflowPolicy, observer zbc.Observer) (*zbtest.MockBroker, *zbc.Subscription) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetObserver(observer)
+
+	sub, err := client.OpenTopicSubscription(&zbc.TopicSubscription{
+		TopicName:        "default-topic",
+		Name:             "buffered",
+		PrefetchCapacity: 32,
+		BufferSize:       1,
+		Overflow:         overflow,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 10; i++ {
+		event := map[string]interface{}{"state": "CREATED"}
+		if err := broker.PushTopicEvent("default-topic", uint64(i), sbe.EventType.WORKFLOW_INSTANCE_EVENT, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return broker, sub
+}
+
+func TestSubscription_OverflowSpill(t *testing.T) {
+	broker, sub := openBuffered(t, zbc.OverflowSpill, &dropCounter{})
+	defer broker.Close()
+
+	for i := 1; i <= 10; i++ {
+		select {
+		case msg := <-sub.Events():
+			if key := (*msg.SbeMessage).(*sbe.SubscribedEvent).Key; key != uint64(i) {
+				t.Fatalf("Expected event %d, received %d", i, key)
+			}
+			if (*msg.Data)["state"] != "CREATED" {
+				t.Fatalf("Unexpected event data %v", *msg.Data)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Event %d was not delivered", i)
+		}
+	}
+}
+
+func TestSubscription_OverflowDrop(t *testing.T) {
+	observer := &dropCounter{}
+	broker, sub := openBuffered(t, zbc.OverflowDrop, observer)
+	defer broker.Close()
+
+	var last uint64
+	delivered := int32(0)
+	for delivered+atomic.LoadInt32(&observer.dropped) < 10 {
+		select {
+		case msg := <-sub.Events():
+			key := (*msg.SbeMessage).(*sbe.SubscribedEvent).Key
+			if key <= last {
+				t.Fatalf("Event %d delivered after %d", key, last)
+			}
+			last = key
+			delivered++
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 10 events delivered or dropped, %d delivered and %d dropped", delivered, atomic.LoadInt32(&observer.dropped))
+		}
+	}
+	if delivered == 10 {
+		t.Fatal("Expected events to be dropped")
+	}
+}
@@ -0,0 +1,138 @@
+package zbc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CheckpointStore keeps position of the last event acknowledged by topic subscription, so the subscription resumes
+// after it once it's opened again, also by another process. Subscriptions are identified by topic, partition and name.
+// Store shared by subscriptions saves their positions as each of them acknowledges events, in no particular order.
+type CheckpointStore interface {
+	// Load returns position saved for the subscription. False is returned if there is none.
+	Load(topic string, partitionID int32, name string) (uint64, bool, error)
+	// Save will remember position of the event acknowledged by the subscription.
+	Save(topic string, partitionID int32, name string, position uint64) error
+}
+
+func checkpointKey(topic string, partitionID int32, name string) string {
+	return fmt.Sprintf("%s/%d/%s", topic, partitionID, name)
+}
+
+// MemoryCheckpointStore keeps positions in memory. Subscriptions resume after reconnect or when they are reopened
+// by the same process, but not after restart.
+type MemoryCheckpointStore struct {
+	mu        sync.Mutex
+	positions map[string]uint64
+}
+
+// NewMemoryCheckpointStore is constructor for empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{positions: make(map[string]uint64)}
+}
+
+// Load returns position saved for the subscription.
+func (s *MemoryCheckpointStore) Load(topic string, partitionID int32, name string) (uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	position, ok := s.positions[checkpointKey(topic, partitionID, name)]
+	return position, ok, nil
+}
+
+// Save will remember the position. Positions lower than the saved one are ignored, as acknowledgements may be reordered.
+func (s *MemoryCheckpointStore) Save(topic string, partitionID int32, name string, position uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := checkpointKey(topic, partitionID, name)
+	if position > s.positions[key] {
+		s.positions[key] = position
+	}
+	return nil
+}
+
+// FileCheckpointStore keeps positions in JSON file, so subscriptions resume after restart. File is rewritten on every
+// save through a temporary file, so it's never left half written.
+type FileCheckpointStore struct {
+	path   string
+	memory *MemoryCheckpointStore
+}
+
+// NewFileCheckpointStore is constructor for FileCheckpointStore. Positions saved in the file at path are loaded,
+// missing file is created on the first save.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	s := &FileCheckpointStore{path: path, memory: NewMemoryCheckpointStore()}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &s.memory.positions); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load returns position saved for the subscription.
+func (s *FileCheckpointStore) Load(topic string, partitionID int32, name string) (uint64, bool, error) {
+	return s.memory.Load(topic, partitionID, name)
+}
+
+// Save will remember the position and write all positions into the file.
+func (s *FileCheckpointStore) Save(topic string, partitionID int32, name string, position uint64) error {
+	s.memory.Save(topic, partitionID, name, position)
+
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+	content, err := json.Marshal(s.memory.positions)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// checkpoint will save position of the acknowledged event, if the subscription has CheckpointStore.
+func (s *Subscription) checkpoint(position uint64) error {
+	if s.topic == nil || s.topic.Checkpoints == nil {
+		return nil
+	}
+	return s.topic.Checkpoints.Save(s.topic.TopicName, s.topic.PartitionID, s.topic.Name, position)
+}
+
+// resumed returns copy of the topic subscription which starts after the saved position. Subscription is returned
+// unchanged if it has no CheckpointStore or no position is saved for it.
+func (s *Subscription) resumed() (*TopicSubscription, error) {
+	ts := s.topic
+	if ts.Checkpoints == nil {
+		return ts, nil
+	}
+	position, ok, err := ts.Checkpoints.Load(ts.TopicName, ts.PartitionID, ts.Name)
+	if err != nil || !ok {
+		return ts, err
+	}
+
+	resumed := *ts
+	resumed.StartPosition = int64(position) + 1
+	resumed.ForceStart = true
+	return &resumed, nil
+}
@@ -0,0 +1,85 @@
+package zbc_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestTopicSubscription_Checkpoints(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	broker.HandleCommand(sbe.EventType.SUBSCRIPTION_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.SubscriptionAcknowledged})
+	})
+
+	dir, err := ioutil.TempDir("", "zbc-checkpoints")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "positions.json")
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	open := func() *zbc.Subscription {
+		store, err := zbc.NewFileCheckpointStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sub, err := client.OpenTopicSubscription(&zbc.TopicSubscription{
+			TopicName:        "default-topic",
+			Name:             "audit",
+			PrefetchCapacity: 10,
+			Checkpoints:      store,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sub
+	}
+
+	sub := open()
+	if err := broker.PushTopicEvent("default-topic", 1, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCreated}); err != nil {
+		t.Fatal(err)
+	}
+	event := (*(<-sub.Events()).SbeMessage).(*sbe.SubscribedEvent)
+	event.Position = 41
+	if _, err := client.AcknowledgeTopicEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	sub.Close()
+
+	// Subscription opened with a new store, as after restart, starts after the acknowledged event.
+	open().Close()
+
+	var subscribers []map[string]interface{}
+	for _, request := range broker.Received() {
+		if cmd, ok := (*request.SbeMessage).(*sbe.ExecuteCommandRequest); ok && cmd.EventType == sbe.EventType.SUBSCRIBER_EVENT {
+			subscribers = append(subscribers, *request.Data)
+		}
+	}
+	if len(subscribers) != 2 {
+		t.Fatalf("Expected subscription opened twice, opened %d times", len(subscribers))
+	}
+	if forceStart, _ := subscribers[0]["forceStart"].(bool); forceStart {
+		t.Fatalf("Expected first subscription to start at position of the broker, received %v", subscribers[0])
+	}
+	if fmt.Sprint(subscribers[1]["startPosition"]) != "42" || subscribers[1]["forceStart"] != true {
+		t.Fatalf("Expected subscription resumed after acknowledged event, received %v", subscribers[1])
+	}
+}
@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+var errInvalidSize = errors.New("Size must be a number of bytes, optionally with suffix k or m. Use --payload-size 1k")
+
+// benchOptions are flags of zbctl bench.
+type benchOptions struct {
+	topic       string
+	taskType    string
+	tasks       int
+	concurrency int
+	payloadSize int
+	rate        float64       // Tasks created per second, zero means as fast as possible.
+	wait        time.Duration // Time without completed task after which the worker gives up.
+}
+
+// percentiles summarize samples of latency in milliseconds or of throughput in tasks per second.
+type percentiles struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+func newPercentiles(samples []float64) *percentiles {
+	if len(samples) == 0 {
+		return &percentiles{}
+	}
+	sort.Float64s(samples)
+	at := func(p float64) float64 {
+		return samples[int(p*float64(len(samples)-1))]
+	}
+	return &percentiles{P50: at(0.5), P90: at(0.9), P99: at(0.99), Max: samples[len(samples)-1]}
+}
+
+func (p *percentiles) row(name string) string {
+	return fmt.Sprintf("%s\t%.1f\t%.1f\t%.1f\t%.1f", name, p.P50, p.P90, p.P99, p.Max)
+}
+
+// benchRecord is result of zbctl bench as it's printed. Throughput is counted per second of the run.
+type benchRecord struct {
+	Tasks      int            `json:"tasks"`
+	Created    int            `json:"created"`
+	Completed  int            `json:"completed"`
+	Errors     map[string]int `json:"errors"` // Failed requests by error.
+	Duration   float64        `json:"durationSeconds"`
+	Rate       float64        `json:"rate"` // Tasks created per second over the whole run.
+	Throughput *percentiles   `json:"throughput"`
+	Latency    *percentiles   `json:"latencyMs"`            // Time to create a task.
+	EndToEnd   *percentiles   `json:"endToEndMs,omitempty"` // Time from creating a task until the worker received it.
+}
+
+// benchCounter collects samples of one kind of operation from concurrent goroutines.
+type benchCounter struct {
+	mu        sync.Mutex
+	start     time.Time
+	count     int
+	latencies []float64
+	perSecond []float64
+}
+
+func newBenchCounter(start time.Time) *benchCounter {
+	return &benchCounter{start: start}
+}
+
+// add will count operation which finished now and took latency.
+func (b *benchCounter) add(latency time.Duration) {
+	second := int(time.Since(b.start) / time.Second)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.count++
+	b.latencies = append(b.latencies, float64(latency)/float64(time.Millisecond))
+	for len(b.perSecond) <= second {
+		b.perSecond = append(b.perSecond, 0)
+	}
+	b.perSecond[second]++
+}
+
+func (b *benchCounter) total() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// throughput returns percentiles of operations per second. Last second is left out, as it's usually cut short.
+func (b *benchCounter) throughput() *percentiles {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	samples := b.perSecond
+	if len(samples) > 1 {
+		samples = samples[:len(samples)-1]
+	}
+	return newPercentiles(append([]float64(nil), samples...))
+}
+
+func (b *benchCounter) latency() *percentiles {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return newPercentiles(append([]float64(nil), b.latencies...))
+}
+
+// parseSize parses size like 512, 1k or 2m into bytes.
+func parseSize(s string) (int, error) {
+	s = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "b")
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier, s = 1024, strings.TrimSuffix(s, "k")
+	case strings.HasSuffix(s, "m"):
+		multiplier, s = 1024*1024, strings.TrimSuffix(s, "m")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, errInvalidSize
+	}
+	return n * multiplier, nil
+}
+
+// benchCreatedAt is field of the payload carrying the time when the task was created, so the worker can measure
+// end to end latency.
+const benchCreatedAt = "benchCreatedAt"
+
+// runBench will create tasks with the producer, optionally completing them by a worker on the consumer, and return
+// statistics of the run. Interrupt stops creating tasks, statistics of those created so far are returned.
+func runBench(producer, consumer *zbc.Client, o *benchOptions) (*benchRecord, error) {
+	start := time.Now()
+	created, completed := newBenchCounter(start), newBenchCounter(start)
+	record := &benchRecord{Tasks: o.tasks, Errors: make(map[string]int)}
+
+	var worker *zbc.Worker
+	if consumer != nil {
+		// Tasks are created on all partitions of the topic, or on partition 0 if topology doesn't know it.
+		topology, err := consumer.Topology()
+		if err != nil {
+			return nil, err
+		}
+		partitionID := int32(zbc.AllPartitions)
+		if len(topology.Partitions(o.topic)) == 0 {
+			partitionID = 0
+		}
+
+		worker = consumer.NewWorker(o.taskType, func(task *zbc.Task) (map[string]interface{}, error) {
+			if createdAt, ok := unixNano(task.PayloadJson[benchCreatedAt]); ok {
+				completed.add(time.Since(time.Unix(0, createdAt)))
+			} else {
+				completed.add(0)
+			}
+			return nil, nil
+		},
+			zbc.WithTopic(o.topic),
+			zbc.WithPartition(partitionID),
+			zbc.WithMaxConcurrentJobs(o.concurrency),
+		)
+		if err := worker.Start(); err != nil {
+			return nil, err
+		}
+	}
+
+	data := strings.Repeat("x", o.payloadSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			log.Println("Interrupted, waiting for running requests ....")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Tasks are paced here rather than by RateLimit of the client, so latency doesn't include waiting for the rate.
+	jobs := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		next := time.Now()
+		for i := 0; i < o.tasks; i++ {
+			if o.rate > 0 {
+				next = next.Add(time.Duration(float64(time.Second) / o.rate))
+				pause := time.NewTimer(next.Sub(time.Now()))
+				select {
+				case <-pause.C:
+				case <-ctx.Done():
+					pause.Stop()
+					return
+				}
+			}
+			select {
+			case jobs <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				task := &zbc.Task{
+					Type:        o.taskType,
+					Retries:     3,
+					PayloadJson: map[string]interface{}{benchCreatedAt: time.Now().UnixNano(), "data": data},
+				}
+				began := time.Now()
+				_, err := producer.CreateTask(o.topic, task)
+				if err != nil {
+					mu.Lock()
+					record.Errors[zbc.Cause(err).Error()]++
+					mu.Unlock()
+					continue
+				}
+				created.add(time.Since(began))
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	progress := time.NewTicker(time.Second)
+	defer progress.Stop()
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		case <-progress.C:
+			log.Printf("Created %d of %d tasks, %d completed\n", created.total(), o.tasks, completed.total())
+		}
+	}
+	record.Duration = time.Since(start).Seconds()
+
+	if worker != nil {
+		// Worker is given up once it doesn't complete any task for the wait duration.
+		last, idle := completed.total(), time.Now()
+		for completed.total() < created.total() && time.Since(idle) < o.wait && ctx.Err() == nil {
+			<-progress.C
+			if n := completed.total(); n > last {
+				last, idle = n, time.Now()
+			}
+			log.Printf("Completed %d of %d tasks\n", completed.total(), created.total())
+		}
+		stopCtx, stop := context.WithTimeout(context.Background(), o.wait)
+		worker.StopCtx(stopCtx)
+		stop()
+		record.EndToEnd = completed.latency()
+	}
+
+	record.Created, record.Completed = created.total(), completed.total()
+	record.Throughput = created.throughput()
+	record.Latency = created.latency()
+	if record.Duration > 0 {
+		record.Rate = float64(record.Created) / record.Duration
+	}
+	return record, nil
+}
+
+// unixNano converts time in the payload to nanoseconds, message pack decodes it as signed or unsigned integer.
+func unixNano(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// printBench will print statistics of the run followed by count of every error.
+func printBench(p *printer, record *benchRecord) error {
+	return p.print(record, []string{strconv.Itoa(record.Created)}, func(w io.Writer) {
+		fmt.Fprintf(w, "Created %d of %d tasks in %.1fs, %.1f tasks/s\n", record.Created, record.Tasks, record.Duration, record.Rate)
+		if record.EndToEnd != nil {
+			fmt.Fprintf(w, "Completed %d tasks\n", record.Completed)
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, "\tP50\tP90\tP99\tMAX")
+		fmt.Fprintln(w, record.Throughput.row("Throughput (tasks/s)"))
+		fmt.Fprintln(w, record.Latency.row("Latency (ms)"))
+		if record.EndToEnd != nil {
+			fmt.Fprintln(w, record.EndToEnd.row("End to end (ms)"))
+		}
+
+		if len(record.Errors) == 0 {
+			return
+		}
+		var messages []string
+		for message := range record.Errors {
+			messages = append(messages, message)
+		}
+		sort.Strings(messages)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "ERRORS\tCOUNT")
+		for _, message := range messages {
+			fmt.Fprintf(w, "%s\t%d\n", message, record.Errors[message])
+		}
+	})
+}
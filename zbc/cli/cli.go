@@ -0,0 +1,207 @@
+// Package cli implements commands of zbctl, so other binaries can embed them and tests can run them against a mock
+// broker. Every command is returned by a function taking Config, which LoadConfig fills in once global flags are
+// parsed:
+//
+//	var conf zbccli.Config
+//	app := cli.NewApp()
+//	app.Flags = zbccli.Flags()
+//	app.Before = func(c *cli.Context) error { return zbccli.LoadConfig(c, os.Args, &conf) }
+//	app.Commands = []cli.Command{zbccli.DeployCommand(&conf), zbccli.TopologyCommand(&conf)}
+//	app.Run(os.Args)
+//
+// Results are written to Writer of the app, stdout by default, logs go to the standard logger. Commands return errors
+// instead of exiting, exit status is up to the app.
+package cli
+
+import (
+	"errors"
+	"log"
+	"os"
+	"time"
+
+	"github.com/urfave/cli"
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+var (
+	errResourceNotFound = errors.New("Resource at the given path not found")
+	errNilResponse      = errors.New("Received nil response")
+	errKeyMissing       = errors.New("Key is missing. Use --key <key>")
+	errIncidentNotFound = errors.New("Incident with the given key not found or already resolved")
+	errExecMissing      = errors.New("Handler command is missing. Use --exec <command>")
+	errProcessIDMissing = errors.New("BPMN process ID is missing. Use zbctl workflows describe <bpmn process id>")
+	errCaptureMissing   = errors.New("Capture file is missing. Use zbctl replay <capture file>")
+	errDumpMissing      = errors.New("Dump of frames is missing. Use zbctl decode <file|->")
+	errInvalidVar       = errors.New("Variable must be given as name=value. Use --var orderId=1234")
+	errInvalidHeader    = errors.New("Header must be given as name=value. Use --header region=eu")
+	errShellMissing     = errors.New("Shell is missing. Use zbctl completion <bash|zsh>")
+	errTopicNameMissing = errors.New("Topic name is missing. Use zbctl topic create <name>")
+	errUnhealthy        = errors.New("Cluster is unhealthy, leaders of some partitions are not reachable")
+	errSettingMissing   = errors.New("Setting is missing. Use zbctl config get <topic|profile>")
+	errValueMissing     = errors.New("Value is missing. Use zbctl config set <topic|profile> <value>")
+)
+
+// Flags returns global flags of zbctl which LoadConfig reads, output flags included.
+func Flags() []cli.Flag {
+	flags := []cli.Flag{
+		cli.StringFlag{
+			Name:   "config, cfg",
+			Usage:  "Location of the configuration file. Default is the first of ~/.zeebe/config.{toml,yaml,json} and /etc/zeebe/config.toml.",
+			EnvVar: "ZBC_CONFIG",
+		},
+		cli.StringFlag{
+			Name:   "profile",
+			Usage:  "Profile of the configuration to use, e.g. prod for settings in [profiles.prod].",
+			EnvVar: "ZB_PROFILE",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Time to wait for response of the broker. Overrides request_timeout of the configuration.",
+		},
+		cli.BoolFlag{
+			Name:  "tls",
+			Usage: "Connect to the broker over TLS.",
+		},
+		cli.StringFlag{
+			Name:  "tls-ca",
+			Usage: "CA certificate used to validate the broker.",
+		},
+		cli.StringFlag{
+			Name:  "tls-cert",
+			Usage: "Client certificate for mutual TLS.",
+		},
+		cli.StringFlag{
+			Name:  "tls-key",
+			Usage: "Private key of the client certificate.",
+		},
+		cli.BoolFlag{
+			Name:  "tls-insecure",
+			Usage: "Skip validation of the broker certificate.",
+		},
+		cli.BoolFlag{
+			Name:  "verbose",
+			Usage: "Log debug messages of the client.",
+		},
+	}
+	return append(flags, outputFlags...)
+}
+
+// LoadConfig will read configuration selected by global flags into conf and override its settings with ZB_*
+// environment variables and the flags. Args are the arguments the app is run with, urfave/cli strips the shell
+// completion flag from them before Before is called. Commands which work without configuration, e.g. completion,
+// leave conf empty.
+func LoadConfig(c *cli.Context, args []string, conf *Config) error {
+	if !needsConfig(c, args) {
+		return nil
+	}
+	if err := loadConfig(c.String("config"), conf); err != nil {
+		return err
+	}
+	profile := c.String("profile")
+	if len(profile) == 0 {
+		profile = conf.Profile
+	}
+	if err := conf.useProfile(profile); err != nil {
+		return err
+	}
+	if err := applyEnv(conf); err != nil {
+		return err
+	}
+	if c.IsSet("timeout") {
+		conf.Broker.RequestTimeout = c.Duration("timeout").String()
+	}
+	if c.Bool("tls") {
+		conf.Broker.TLS.Enabled = true
+	}
+	if c.IsSet("tls-ca") {
+		conf.Broker.TLS.CAFile = c.String("tls-ca")
+	}
+	if c.IsSet("tls-cert") {
+		conf.Broker.TLS.CertFile = c.String("tls-cert")
+	}
+	if c.IsSet("tls-key") {
+		conf.Broker.TLS.KeyFile = c.String("tls-key")
+	}
+	if c.Bool("tls-insecure") {
+		conf.Broker.TLS.InsecureSkipVerify = true
+	}
+	conf.verbose = c.Bool("verbose")
+	return nil
+}
+
+// Commands returns all built-in commands of zbctl, in order in which help lists them.
+func Commands(conf *Config) []cli.Command {
+	return []cli.Command{
+		CreateTaskCommand(conf),
+		CreateWorkflowInstanceCommand(conf),
+		InstanceCommand(conf),
+		DeployCommand(conf),
+		CompleteCommand(conf),
+		FailCommand(conf),
+		TaskCommand(conf),
+		TopicCommand(conf),
+		IncidentsCommand(conf),
+		WorkflowsCommand(conf),
+		TopologyCommand(conf),
+		HealthzCommand(conf),
+		TailCommand(conf),
+		StatsCommand(conf),
+		BenchCommand(conf),
+		ProxyCommand(),
+		ReplayCommand(),
+		DecodeCommand(),
+		ConsoleCommand(conf),
+		VersionCommand(conf),
+		ConfigCommand(conf),
+		CompletionCommand(),
+		SubscribeCommand(conf),
+		OpenCommand(conf),
+	}
+}
+
+// newClient will connect to the configured broker, over TLS if it is enabled. Durations of the configuration are
+// parsed first, so invalid ones fail before anything is dialed.
+func newClient(conf *Config) (*zbc.Client, error) {
+	var interval, timeout time.Duration
+	var err error
+	if len(conf.Broker.KeepAliveInterval) > 0 {
+		if interval, err = time.ParseDuration(conf.Broker.KeepAliveInterval); err != nil {
+			return nil, err
+		}
+	}
+	if len(conf.Broker.RequestTimeout) > 0 {
+		if timeout, err = time.ParseDuration(conf.Broker.RequestTimeout); err != nil {
+			return nil, err
+		}
+	}
+
+	client, err := dialBroker(conf)
+	if err != nil {
+		return nil, err
+	}
+	client.SetLogger(zbc.NewStdLogger(log.New(os.Stderr, "", log.LstdFlags), conf.verbose))
+	if len(conf.Broker.KeepAliveInterval) > 0 {
+		client.SetKeepAliveInterval(interval)
+	}
+	if len(conf.Broker.RequestTimeout) > 0 {
+		client.SetRequestTimeout(timeout)
+	}
+	return client, nil
+}
+
+func dialBroker(conf *Config) (*zbc.Client, error) {
+	socket, err := conf.Broker.Socket.options()
+	if err != nil {
+		return nil, err
+	}
+	if !conf.Broker.TLS.Enabled {
+		return zbc.NewClusterClient(conf.seeds(), zbc.WithSocketOptions(socket))
+	}
+
+	tls := conf.Broker.TLS
+	tlsConf, err := zbc.NewTLSConfig(tls.CAFile, tls.CertFile, tls.KeyFile, tls.InsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	return zbc.NewClusterClientTLS(conf.seeds(), tlsConf, zbc.WithSocketOptions(socket))
+}
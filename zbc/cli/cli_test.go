@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+// newTestApp returns app embedding the commands, configured to connect to the broker. Results are written to out.
+func newTestApp(t *testing.T, broker *zbtest.MockBroker, out *bytes.Buffer) (*cli.App, string) {
+	dir, err := ioutil.TempDir("", "zbccli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(path, []byte(fmt.Sprintf("brokers = [%q]\n", broker.Addr())), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var conf Config
+	app := cli.NewApp()
+	app.Writer = out
+	app.Flags = Flags()
+	app.Before = func(c *cli.Context) error {
+		return LoadConfig(c, nil, &conf)
+	}
+	app.Commands = []cli.Command{TopologyCommand(&conf), CompleteCommand(&conf)}
+	return app, dir
+}
+
+func TestCommands_Topology(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	var out bytes.Buffer
+	app, dir := newTestApp(t, broker, &out)
+	defer os.RemoveAll(dir)
+
+	if err := app.Run([]string{"zbctl", "--config", filepath.Join(dir, "config.toml"), "topology", "-o", "json"}); err != nil {
+		t.Fatal(err)
+	}
+	var topology struct {
+		Brokers []struct {
+			Host string
+			Port int
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &topology); err != nil {
+		t.Fatalf("Expected topology as JSON, printed %q: %s", out.String(), err)
+	}
+	if len(topology.Brokers) != 1 || fmt.Sprintf("%s:%d", topology.Brokers[0].Host, topology.Brokers[0].Port) != broker.Addr() {
+		t.Fatalf("Expected broker %s, printed %q", broker.Addr(), out.String())
+	}
+}
+
+func TestCommands_ReturnError(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	var out bytes.Buffer
+	app, dir := newTestApp(t, broker, &out)
+	defer os.RemoveAll(dir)
+
+	// Command without --key fails instead of exiting the process.
+	err = app.Run([]string{"zbctl", "--config", filepath.Join(dir, "config.toml"), "complete", "--task-type", "foo"})
+	if err == nil || !strings.HasPrefix(err.Error(), "Key is missing") {
+		t.Fatalf("Expected missing key error, received %v", err)
+	}
+	if out.Len() > 0 {
+		t.Fatalf("Expected nothing printed, printed %q", out.String())
+	}
+}
+
+func TestParseVars(t *testing.T) {
+	tests := []struct {
+		vars     []string
+		expected map[string]interface{}
+	}{
+		{[]string{"orderId=1234"}, map[string]interface{}{"orderId": int64(1234)}},
+		{[]string{"total=12.5", "paid=true", "note=null"}, map[string]interface{}{"total": 12.5, "paid": true, "note": nil}},
+		{[]string{"name=foo", "empty="}, map[string]interface{}{"name": "foo", "empty": ""}},
+		{[]string{"expr=a=b"}, map[string]interface{}{"expr": "a=b"}},
+		{[]string{`quoted="1234"`}, map[string]interface{}{"quoted": "1234"}},
+		{[]string{"id=007", "zip=-0123", "zero=0", "fraction=0.5"}, map[string]interface{}{"id": "007", "zip": "-0123", "zero": int64(0), "fraction": 0.5}},
+		{[]string{"a=nan", "b=NaN", "c=inf", "d=-Infinity"}, map[string]interface{}{"a": "nan", "b": "NaN", "c": "inf", "d": "-Infinity"}},
+		{[]string{`items=[1,2.5,"x"]`}, map[string]interface{}{"items": []interface{}{int64(1), 2.5, "x"}}},
+		{[]string{`order={"id":1}`}, map[string]interface{}{"order": map[string]interface{}{"id": int64(1)}}},
+	}
+	for _, test := range tests {
+		payload, err := parseVars(test.vars)
+		if err != nil {
+			t.Errorf("%v: %s", test.vars, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.expected, payload) {
+			t.Errorf("%v: expected %#v, parsed %#v", test.vars, test.expected, payload)
+		}
+	}
+
+	for _, vars := range [][]string{{"orderId"}, {"=1234"}, {`order={"id":`}} {
+		if _, err := parseVars(vars); err == nil {
+			t.Errorf("%v: expected error", vars)
+		}
+	}
+}
+
+func TestEventFilter(t *testing.T) {
+	record := map[string]interface{}{
+		"key": json.Number("4"),
+		"event": map[string]interface{}{
+			"state":   "CREATED",
+			"retries": json.Number("3"),
+			"locked":  false,
+		},
+	}
+	tests := []struct {
+		expr    string
+		matches bool
+	}{
+		{".event.state", true},
+		{".event.missing", false},
+		{".event.locked", false},
+		{`.event.state == "CREATED"`, true},
+		{`.event.state=="CREATED"`, true},
+		{`.event.state != "CREATED"`, false},
+		{".event.retries == 3.0", true},
+		{".event.retries > 2", true},
+		{".event.retries >= 3", true},
+		{".event.retries < 3", false},
+		{".event.retries <= 2", false},
+		{`.event.state > 2`, false},
+		{".key == 4", true},
+		{".", true},
+	}
+	for _, test := range tests {
+		f, err := parseFilter(test.expr)
+		if err != nil {
+			t.Errorf("%s: %s", test.expr, err)
+			continue
+		}
+		if f.matches(record) != test.matches {
+			t.Errorf("%s: expected match %t", test.expr, test.matches)
+		}
+	}
+
+	for _, expr := range []string{"event.state", ".event.state ~ 1", ".event.state == CREATED"} {
+		if _, err := parseFilter(expr); err == nil {
+			t.Errorf("%s: expected error", expr)
+		}
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zbccli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		file, profile, key, value string
+	}{
+		{"config.toml", "", "topic", "orders"},
+		{"config.toml", "prod", "topic", "prod-orders"},
+		{"config.yaml", "prod", "topic", "prod-orders"},
+		{"config.json", "", "profile", "prod"},
+	}
+	for _, test := range tests {
+		path := filepath.Join(dir, test.file)
+		if err := setConfigValue(path, test.profile, test.key, test.value); err != nil {
+			t.Fatalf("%+v: %s", test, err)
+		}
+
+		var conf Config
+		if err := decodeConfig(path, &conf); err != nil {
+			t.Fatalf("%+v: %s", test, err)
+		}
+		if err := conf.useProfile(test.profile); err != nil {
+			t.Fatalf("%+v: %s", test, err)
+		}
+		if value, err := configValue(&conf, test.key); err != nil || value != test.value {
+			t.Errorf("%+v: expected %s, read %q, %v", test, test.value, value, err)
+		}
+	}
+
+	if err := setConfigValue(filepath.Join(dir, "config.toml"), "", "brokers", "localhost"); err == nil {
+		t.Error("Expected unknown setting rejected")
+	}
+	if err := setConfigValue("", "", "topic", "orders"); err != errNoConfigFile {
+		t.Errorf("Expected errNoConfigFile, received %v", err)
+	}
+}
+
+func TestConfig_UseProfile(t *testing.T) {
+	tests := []struct {
+		profile  string
+		brokers  []string
+		topic    string
+		insecure bool
+	}{
+		{"", []string{"localhost:51015"}, "orders", false},
+		{"prod", []string{"prod1:51015", "prod2:51015"}, "orders", true},
+		{"staging", []string{"staging:51016"}, "staging-orders", false},
+	}
+	for _, test := range tests {
+		conf := Config{
+			Brokers: []string{"localhost:51015"},
+			Topic:   "orders",
+			Profiles: map[string]profile{
+				"prod":    {Brokers: []string{"prod1:51015", "prod2:51015"}, Broker: contact{TLS: tlsConfig{Enabled: true, InsecureSkipVerify: true}}},
+				"staging": {Broker: contact{Address: "staging", Port: "51016"}, Topic: "staging-orders"},
+			},
+		}
+		if err := conf.useProfile(test.profile); err != nil {
+			t.Fatalf("%s: %s", test.profile, err)
+		}
+		if !reflect.DeepEqual(test.brokers, conf.seeds()) || conf.Topic != test.topic || conf.Broker.TLS.InsecureSkipVerify != test.insecure {
+			t.Errorf("%s: expected brokers %v and topic %s, configured %+v", test.profile, test.brokers, test.topic, conf)
+		}
+	}
+
+	conf := Config{Profiles: map[string]profile{"prod": {}}}
+	if err := conf.useProfile("dev"); err == nil || !strings.Contains(err.Error(), "prod") {
+		t.Errorf("Expected unknown profile rejected with known ones listed, received %v", err)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		env   map[string]string
+		check func(c *Config) bool
+	}{
+		{map[string]string{"ZB_BROKER_ADDRESS": "broker", "ZB_BROKER_PORT": "51016"}, func(c *Config) bool {
+			return reflect.DeepEqual(c.seeds(), []string{"broker:51016"})
+		}},
+		{map[string]string{"ZB_BROKERS": "node1:51015,node2:51015"}, func(c *Config) bool {
+			return reflect.DeepEqual(c.seeds(), []string{"node1:51015", "node2:51015"})
+		}},
+		{map[string]string{"ZB_REQUEST_TIMEOUT": "3s", "ZB_KEEP_ALIVE_INTERVAL": "0"}, func(c *Config) bool {
+			return c.Broker.RequestTimeout == "3s" && c.Broker.KeepAliveInterval == "0"
+		}},
+		{map[string]string{"ZB_TLS_ENABLED": "true", "ZB_TLS_CA_FILE": "ca.pem"}, func(c *Config) bool {
+			return c.Broker.TLS.Enabled && c.Broker.TLS.CAFile == "ca.pem"
+		}},
+		{map[string]string{}, func(c *Config) bool {
+			return reflect.DeepEqual(c.seeds(), []string{"localhost:51015"})
+		}},
+	}
+	for _, test := range tests {
+		for name, value := range test.env {
+			os.Setenv(name, value)
+		}
+		conf := Config{Broker: contact{Address: "localhost", Port: "51015"}}
+		err := applyEnv(&conf)
+		for name := range test.env {
+			os.Unsetenv(name)
+		}
+		if err != nil {
+			t.Fatalf("%v: %s", test.env, err)
+		}
+		if !test.check(&conf) {
+			t.Errorf("%v: unexpected configuration %+v", test.env, conf)
+		}
+	}
+
+	os.Setenv("ZB_TLS_ENABLED", "maybe")
+	defer os.Unsetenv("ZB_TLS_ENABLED")
+	if err := applyEnv(&Config{}); err == nil {
+		t.Error("Expected invalid ZB_TLS_ENABLED rejected")
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		s    string
+		size int
+	}{
+		{"0", 0},
+		{"512", 512},
+		{"512b", 512},
+		{"1k", 1024},
+		{"4KB", 4096},
+		{" 2m ", 2 * 1024 * 1024},
+	}
+	for _, test := range tests {
+		size, err := parseSize(test.s)
+		if err != nil || size != test.size {
+			t.Errorf("%q: expected %d, parsed %d, %v", test.s, test.size, size, err)
+		}
+	}
+
+	for _, s := range []string{"", "-1", "1g", "k", "1.5k"} {
+		if _, err := parseSize(s); err != errInvalidSize {
+			t.Errorf("%q: expected errInvalidSize, received %v", s, err)
+		}
+	}
+}
+
+func TestNewClient_InvalidDuration(t *testing.T) {
+	// Nothing listens on the port, invalid duration must be reported before dialing.
+	for _, broker := range []contact{{KeepAliveInterval: "10"}, {RequestTimeout: "5"}} {
+		conf := Config{Brokers: []string{"127.0.0.1:1"}, Broker: broker}
+		if _, err := newClient(&conf); err == nil || !strings.Contains(err.Error(), "missing unit") {
+			t.Errorf("%+v: expected invalid duration, received %v", broker, err)
+		}
+	}
+}
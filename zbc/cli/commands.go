@@ -0,0 +1,1653 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli"
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// topicName returns topic given by --topic or ZB_TOPIC_NAME, otherwise topic of the configuration. Default of the
+// flag is used if neither is set.
+func topicName(c *cli.Context, conf *Config) string {
+	if c.IsSet("topic") || len(conf.Topic) == 0 {
+		return c.String("topic")
+	}
+	return conf.Topic
+}
+
+// taskFlags select the locked task which zbctl complete and zbctl fail work on.
+var taskFlags = []cli.Flag{
+	cli.Uint64Flag{
+		Name:  "key, k",
+		Usage: "Key of the task.",
+	},
+	cli.StringFlag{
+		Name:   "topic, t",
+		Value:  "default-topic",
+		Usage:  "Executing command request on specific topic.",
+		EnvVar: "ZB_TOPIC_NAME",
+	},
+	cli.Int64Flag{
+		Name:   "partition-id",
+		Value:  0,
+		Usage:  "Partition of the task.",
+		EnvVar: "ZB_PARTITION_ID",
+	},
+	cli.StringFlag{
+		Name:   "lock-owner, l",
+		Value:  "zbc",
+		Usage:  "Lock owner which locked the task.",
+		EnvVar: "ZB_LOCK_OWNER",
+	},
+	cli.StringFlag{
+		Name:   "task-type, tt",
+		Value:  "foo",
+		Usage:  "Specify task type.",
+		EnvVar: "ZB_TASK_TYPE",
+	},
+}
+
+// incidentFlags select partition whose incidents zbctl incidents list and resolve replay.
+var incidentFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "topic, t",
+		Value:  "default-topic",
+		Usage:  "Executing command request on specific topic.",
+		EnvVar: "ZB_TOPIC_NAME",
+	},
+	cli.Int64Flag{
+		Name:   "partition-id",
+		Value:  0,
+		Usage:  "Partition of the incidents.",
+		EnvVar: "ZB_PARTITION_ID",
+	},
+	cli.DurationFlag{
+		Name:  "wait",
+		Value: 2 * time.Second,
+		Usage: "Time without new events after which all incidents are considered read.",
+	},
+}
+
+// CreateTaskCommand returns zbctl create-task: create a new task using the given JSON or YAML file, - reads it from
+// standard input.
+func CreateTaskCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:      "create-task",
+		Aliases:   []string{"t"},
+		Usage:     "create a new task using the given JSON or YAML file, - reads it from standard input",
+		ArgsUsage: "<file>",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Executing command request on specific topic.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.StringFlag{
+				Name:  "payload, p",
+				Usage: "Payload as inline JSON or YAML, replaces payload of the file.",
+			},
+			cli.StringFlag{
+				Name:  "payload-file",
+				Usage: "Location of JSON or YAML file with the payload, - for standard input. Replaces payload of the file.",
+			},
+			cli.StringSliceFlag{
+				Name:  "header",
+				Usage: "Custom header of the task given as name=value, e.g. --header region=eu. Overrides custom headers of the file.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			var task zbc.Task
+			if err := loadCommand(c.Args().First(), &task); err != nil {
+				return err
+			}
+
+			payload, err := payloadFlag(c)
+			if err != nil {
+				return err
+			}
+			if payload != nil {
+				task.PayloadJson = payload
+			}
+			if headers := c.StringSlice("header"); len(headers) > 0 {
+				if task.CustomHeaders == nil {
+					task.CustomHeaders = make(map[string]string, len(headers))
+				}
+				for _, h := range headers {
+					i := strings.Index(h, "=")
+					if i <= 0 {
+						return errInvalidHeader
+					}
+					task.CustomHeaders[h[:i]] = h[i+1:]
+				}
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			response, err := client.CreateTask(topicName(c, conf), &task)
+			if err != nil {
+				return err
+			}
+
+			return p.printEvent(response)
+		},
+	}
+}
+
+// CreateWorkflowInstanceCommand returns zbctl create-workflow-instance: create a new workflow instance using the given
+// JSON or YAML file, - reads it from standard input.
+func CreateWorkflowInstanceCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:      "create-workflow-instance",
+		Aliases:   []string{"wf"},
+		Usage:     "create a new workflow instance using the given JSON or YAML file, - reads it from standard input",
+		ArgsUsage: "<file>",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Executing command request on specific topic.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.StringFlag{
+				Name:  "payload, p",
+				Usage: "Payload as inline JSON or YAML, replaces payload of the file.",
+			},
+			cli.StringFlag{
+				Name:  "payload-file",
+				Usage: "Location of JSON or YAML file with the payload, - for standard input. Replaces payload of the file.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			var workflowInstance zbc.WorkflowInstance
+			if err := loadCommand(c.Args().First(), &workflowInstance); err != nil {
+				return err
+			}
+
+			payload, err := payloadFlag(c)
+			if err != nil {
+				return err
+			}
+			if payload != nil {
+				workflowInstance.PayloadJson = payload
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			response, err := sendWorkflowInstance(client, topicName(c, conf), &workflowInstance)
+			if err != nil {
+				return err
+			}
+
+			return p.printEvent(response)
+		},
+	}
+}
+
+// InstanceCommand returns zbctl instance: manage workflow instances.
+func InstanceCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "instance",
+		Usage: "manage workflow instances",
+		Subcommands: []cli.Command{
+			{
+				Name:      "create",
+				Usage:     "create a new workflow instance of the given BPMN process",
+				ArgsUsage: "<bpmnProcessId>",
+				Flags: append([]cli.Flag{
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Executing command request on specific topic.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.IntFlag{
+						Name:  "version, v",
+						Value: -1,
+						Usage: "Version of the workflow. Latest version is used by default.",
+					},
+					cli.StringFlag{
+						Name:  "payload, p",
+						Usage: "Location of JSON or YAML file with the payload.",
+					},
+					cli.StringSliceFlag{
+						Name:  "var",
+						Usage: "Variable of the payload given as name=value, e.g. --var orderId=1234 --var amount=99.5. Overrides variables of the payload file.",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					var payload map[string]interface{}
+					if path := c.String("payload"); len(path) > 0 {
+						payload, err = loadPayload(path)
+						if err != nil {
+							return err
+						}
+					}
+					if vars := c.StringSlice("var"); len(vars) > 0 {
+						values, err := parseVars(vars)
+						if err != nil {
+							return err
+						}
+						if payload == nil {
+							payload = values
+						}
+						for k, v := range values {
+							payload[k] = v
+						}
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					response, err := client.CreateWorkflowInstance(topicName(c, conf), c.Args().First(), c.Int("version"), payload)
+					if err != nil {
+						return err
+					}
+
+					return p.printEvent(response)
+				},
+			},
+			{
+				Name:  "list",
+				Usage: "print workflow instances which are neither completed nor canceled, reconstructed by replaying the topic",
+				Flags: append([]cli.Flag{
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Topic of the workflow instances.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.StringFlag{
+						Name:  "bpmn-process-id",
+						Usage: "Print only instances of the given BPMN process.",
+					},
+					cli.DurationFlag{
+						Name:  "timeout",
+						Value: snapshotTimeout,
+						Usage: "Time after which the replay of the topic is given up.",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					instances, err := client.ListWorkflowInstances(topicName(c, conf), zbc.TimeoutOption(c.Duration("timeout")))
+					if err != nil {
+						return err
+					}
+
+					var filtered []*zbc.WorkflowInstanceSnapshot
+					for _, instance := range instances {
+						if id := c.String("bpmn-process-id"); len(id) == 0 || instance.BpmnProcessId == id {
+							filtered = append(filtered, instance)
+						}
+					}
+					return printInstances(p, filtered)
+				},
+			},
+			{
+				Name:  "cancel",
+				Usage: "cancel a running workflow instance",
+				Flags: append([]cli.Flag{
+					cli.Int64Flag{
+						Name:  "key, k",
+						Usage: "Key of the workflow instance.",
+					},
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Executing command request on specific topic.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.Int64Flag{
+						Name:   "partition-id",
+						Value:  0,
+						Usage:  "Partition of the workflow instance.",
+						EnvVar: "ZB_PARTITION_ID",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if !c.IsSet("key") {
+						return errKeyMissing
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					response, err := client.CancelWorkflowInstance(topicName(c, conf), int32(c.Int64("partition-id")), c.Int64("key"))
+					if err != nil {
+						return err
+					}
+
+					return p.printEvent(response)
+				},
+			},
+			{
+				Name:  "update-payload",
+				Usage: "replace payload of an activity instance",
+				Flags: append([]cli.Flag{
+					cli.Int64Flag{
+						Name:  "key, k",
+						Usage: "Key of the activity instance.",
+					},
+					cli.Int64Flag{
+						Name:  "workflow-instance-key, w",
+						Usage: "Key of the workflow instance.",
+					},
+					cli.StringFlag{
+						Name:  "payload, p",
+						Usage: "Location of JSON or YAML file with the payload.",
+					},
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Executing command request on specific topic.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.Int64Flag{
+						Name:   "partition-id",
+						Value:  0,
+						Usage:  "Partition of the workflow instance.",
+						EnvVar: "ZB_PARTITION_ID",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if !c.IsSet("key") || !c.IsSet("workflow-instance-key") {
+						return errKeyMissing
+					}
+					payload, err := loadPayload(c.String("payload"))
+					if err != nil {
+						return err
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					response, err := client.UpdateWorkflowInstancePayload(topicName(c, conf), int32(c.Int64("partition-id")),
+						c.Int64("key"), c.Int64("workflow-instance-key"), payload)
+					if err != nil {
+						return err
+					}
+
+					return p.printEvent(response)
+				},
+			},
+		},
+	}
+}
+
+// DeployCommand returns zbctl deploy: deploy a BPMN or YAML workflow and print the deployed workflows.
+func DeployCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:    "deploy",
+		Aliases: []string{"d"},
+		Usage:   "deploy a BPMN or YAML workflow and print the deployed workflows",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Executing command request on specific topic.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			content, err := loadFile(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			// Files ending with .yaml or .yml are YAML workflows, standard input is detected by content.
+			resourceType := zbc.ResourceTypeOf(c.Args().First(), content)
+			response, err := client.DeployWorkflowResource(topicName(c, conf), resourceType, content)
+			if err != nil {
+				return err
+			}
+
+			return printDeployment(p, response)
+		},
+	}
+}
+
+// CompleteCommand returns zbctl complete: complete a locked task.
+func CompleteCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "complete",
+		Usage: "complete a locked task",
+		Flags: append(append([]cli.Flag{
+			cli.StringFlag{
+				Name:  "payload, p",
+				Usage: "Location of JSON or YAML file with the payload.",
+			},
+		}, taskFlags...), outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			var payload map[string]interface{}
+			if path := c.String("payload"); len(path) > 0 {
+				payload, err = loadPayload(path)
+				if err != nil {
+					return err
+				}
+			}
+
+			task, err := lockedTask(c, conf)
+			if err != nil {
+				return err
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			response, err := client.CompleteTask(task, payload)
+			if err != nil {
+				return err
+			}
+
+			return p.printEvent(response)
+		},
+	}
+}
+
+// FailCommand returns zbctl fail: fail a locked task.
+func FailCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "fail",
+		Usage: "fail a locked task",
+		Flags: append(append([]cli.Flag{
+			cli.IntFlag{
+				Name:  "retries, r",
+				Value: 0,
+				Usage: "Retries left for the task. Broker creates an incident when no retries are left.",
+			},
+			cli.StringFlag{
+				Name:  "message, m",
+				Usage: "Error message attached to the task.",
+			},
+		}, taskFlags...), outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			task, err := lockedTask(c, conf)
+			if err != nil {
+				return err
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			response, err := client.FailTask(task, c.Int("retries"), c.String("message"))
+			if err != nil {
+				return err
+			}
+
+			return p.printEvent(response)
+		},
+	}
+}
+
+// TaskCommand returns zbctl task: manage tasks which are not locked by this zbctl.
+func TaskCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "task",
+		Usage: "manage tasks which are not locked by this zbctl",
+		Subcommands: []cli.Command{
+			{
+				Name:  "list",
+				Usage: "print tasks which are neither completed nor canceled, reconstructed by replaying the topic",
+				Flags: append([]cli.Flag{
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Topic of the tasks.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.StringFlag{
+						Name:  "type",
+						Usage: "Print only tasks of the given type.",
+					},
+					cli.DurationFlag{
+						Name:  "timeout",
+						Value: snapshotTimeout,
+						Usage: "Time after which the replay of the topic is given up.",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					tasks, err := client.ListTasks(topicName(c, conf), zbc.TimeoutOption(c.Duration("timeout")))
+					if err != nil {
+						return err
+					}
+
+					var filtered []*zbc.TaskSnapshot
+					for _, task := range tasks {
+						if taskType := c.String("type"); len(taskType) == 0 || task.Type == taskType {
+							filtered = append(filtered, task)
+						}
+					}
+					return printTasks(p, filtered)
+				},
+			},
+			{
+				Name:  "update-retries",
+				Usage: "set retries of a failed task, so it is locked again and its incident is resolved",
+				Flags: append([]cli.Flag{
+					cli.Int64Flag{
+						Name:  "key, k",
+						Usage: "Key of the task.",
+					},
+					cli.IntFlag{
+						Name:  "retries, r",
+						Value: 3,
+						Usage: "New retries of the task.",
+					},
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Topic of the task.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.Int64Flag{
+						Name:   "partition-id",
+						Value:  0,
+						Usage:  "Partition of the task.",
+						EnvVar: "ZB_PARTITION_ID",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if !c.IsSet("key") {
+						return errKeyMissing
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					response, err := client.UpdateTaskRetries(topicName(c, conf), int32(c.Int64("partition-id")), c.Int64("key"), c.Int("retries"))
+					if err != nil {
+						return err
+					}
+
+					return p.printEvent(response)
+				},
+			},
+		},
+	}
+}
+
+// TopicCommand returns zbctl topic: manage topics.
+func TopicCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "topic",
+		Usage: "manage topics",
+		Subcommands: []cli.Command{
+			{
+				Name:      "create",
+				Usage:     "create a topic and wait until all its partitions have a leader",
+				ArgsUsage: "<name>",
+				Flags: append([]cli.Flag{
+					cli.IntFlag{
+						Name:  "partitions, p",
+						Value: 1,
+						Usage: "Number of partitions of the topic.",
+					},
+					cli.DurationFlag{
+						Name:  "wait",
+						Value: 30 * time.Second,
+						Usage: "Time to wait until the topic is created and its partitions have a leader.",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if len(c.Args().First()) == 0 {
+						return errTopicNameMissing
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					topic, err := client.CreateTopic(c.Args().First(), c.Int("partitions"), zbc.TimeoutOption(c.Duration("wait")))
+					if err != nil {
+						return err
+					}
+
+					log.Printf("Topic %s with %d partitions created.\n", topic.Name, topic.Partitions)
+					return p.print(topic, []string{topic.Name}, func(w io.Writer) {
+						fmt.Fprintln(w, "TOPIC\tPARTITIONS\tSTATE")
+						fmt.Fprintf(w, "%s\t%d\t%s\n", topic.Name, topic.Partitions, topic.State)
+					})
+				},
+			},
+		},
+	}
+}
+
+// IncidentsCommand returns zbctl incidents: list and resolve incidents.
+func IncidentsCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "incidents",
+		Usage: "list and resolve incidents",
+		Subcommands: []cli.Command{
+			{
+				Name:  "list",
+				Usage: "print incidents which are not resolved",
+				Flags: append(incidentFlags, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					incidents, err := collectIncidents(client, topicName(c, conf), int32(c.Int64("partition-id")), c.Duration("wait"))
+					if err != nil {
+						return err
+					}
+					return printIncidents(p, incidents)
+				},
+			},
+			{
+				Name:  "resolve",
+				Usage: "resolve an incident, optionally with new payload",
+				Flags: append(append([]cli.Flag{
+					cli.Uint64Flag{
+						Name:  "key, k",
+						Usage: "Key of the incident.",
+					},
+					cli.StringFlag{
+						Name:  "payload, p",
+						Usage: "Location of JSON or YAML file with the payload.",
+					},
+				}, incidentFlags...), outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if !c.IsSet("key") {
+						return errKeyMissing
+					}
+					var payload map[string]interface{}
+					if path := c.String("payload"); len(path) > 0 {
+						payload, err = loadPayload(path)
+						if err != nil {
+							return err
+						}
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					incidents, err := collectIncidents(client, topicName(c, conf), int32(c.Int64("partition-id")), c.Duration("wait"))
+					if err != nil {
+						return err
+					}
+
+					for _, incident := range incidents {
+						event := (*incident.SbeMessage).(*sbe.SubscribedEvent)
+						if event.Key != c.Uint64("key") {
+							continue
+						}
+
+						response, err := client.ResolveIncident(event, payload)
+						if err != nil {
+							return err
+						}
+
+						return p.printEvent(response)
+					}
+					return errIncidentNotFound
+				},
+			},
+		},
+	}
+}
+
+// WorkflowsCommand returns zbctl workflows: list and describe deployed workflows.
+func WorkflowsCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "workflows",
+		Usage: "list and describe deployed workflows",
+		Subcommands: []cli.Command{
+			{
+				Name:  "list",
+				Usage: "print all deployed versions of workflows",
+				Flags: append([]cli.Flag{
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Topic of the workflows.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					workflows, err := client.ListWorkflows(topicName(c, conf))
+					if err != nil {
+						return err
+					}
+					return printWorkflows(p, workflows)
+				},
+			},
+			{
+				Name:      "describe",
+				Usage:     "print one version of the workflow",
+				ArgsUsage: "<bpmn process id>",
+				Flags: append([]cli.Flag{
+					cli.StringFlag{
+						Name:   "topic, t",
+						Value:  "default-topic",
+						Usage:  "Topic of the workflow.",
+						EnvVar: "ZB_TOPIC_NAME",
+					},
+					cli.IntFlag{
+						Name:  "version, v",
+						Value: -1,
+						Usage: "Version of the workflow, -1 means latest.",
+					},
+					cli.BoolFlag{
+						Name:  "xml",
+						Usage: "Print BPMN XML of the workflow.",
+					},
+				}, outputFlags...),
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if c.NArg() == 0 {
+						return errProcessIDMissing
+					}
+
+					client, err := newClient(conf)
+					if err != nil {
+						return err
+					}
+					log.Println("Connected to Zeebe.")
+
+					workflow, err := client.GetWorkflow(topicName(c, conf), c.Args().First(), c.Int("version"))
+					if err != nil {
+						return err
+					}
+
+					var record interface{} = workflow
+					if c.Bool("xml") {
+						record = &struct {
+							*zbc.Workflow
+							BpmnXml string `json:"bpmnXml"`
+						}{workflow, string(workflow.BpmnXml)}
+					}
+					return p.print(record, []string{strconv.FormatUint(workflow.Key, 10)}, func(w io.Writer) {
+						fmt.Fprintf(w, "BPMN process ID:\t%s\n", workflow.BpmnProcessId)
+						fmt.Fprintf(w, "Version:\t%d\n", workflow.Version)
+						fmt.Fprintf(w, "Key:\t%d\n", workflow.Key)
+						fmt.Fprintf(w, "Deployment key:\t%d\n", workflow.DeploymentKey)
+						if c.Bool("xml") {
+							fmt.Fprintln(w, string(workflow.BpmnXml))
+						}
+					})
+				},
+			},
+		},
+	}
+}
+
+// TopologyCommand returns zbctl topology: print brokers of the cluster and leaders of the partitions.
+func TopologyCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:    "topology",
+		Aliases: []string{"status"},
+		Usage:   "print brokers of the cluster and leaders of the partitions",
+		Flags: append([]cli.Flag{
+			cli.BoolFlag{
+				Name:  "json",
+				Usage: "Same as --output json.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			topology, err := client.Topology()
+			if err != nil {
+				return err
+			}
+
+			return printTopology(p, topology)
+		},
+	}
+}
+
+// HealthzCommand returns zbctl healthz: check that all brokers leading partitions respond, exits with 1 otherwise, e.g.
+// for readiness probes.
+func HealthzCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "healthz",
+		Usage: "check that all brokers leading partitions respond, exits with 1 otherwise, e.g. for readiness probes",
+		Flags: append([]cli.Flag{
+			cli.BoolFlag{
+				Name:  "json",
+				Usage: "Same as --output json.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+
+			health, err := client.HealthCheck()
+			if err != nil {
+				return err
+			}
+
+			if err := printHealth(p, health); err != nil {
+				return err
+			}
+			if !health.Healthy() {
+				return errUnhealthy
+			}
+			return nil
+		},
+	}
+}
+
+// TailCommand returns zbctl tail: print events of a topic partition as they are written, one JSON object per line.
+func TailCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "tail",
+		Usage: "print events of a topic partition as they are written, one JSON object per line",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Topic of the partition.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.IntFlag{
+				Name:  "partition",
+				Usage: "ID of the partition.",
+			},
+			cli.StringFlag{
+				Name:  "from",
+				Value: "tail",
+				Usage: "Where to start: tail for new events only, head for all events or position of the first event.",
+			},
+			cli.StringSliceFlag{
+				Name:  "filter",
+				Usage: "Print only events passing the expression, e.g. --filter '.type == \"TASK_EVENT\"' --filter '.event.retries < 3'.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			from, err := startPosition(c.String("from"))
+			if err != nil {
+				return err
+			}
+
+			var filters []*eventFilter
+			for _, expr := range c.StringSlice("filter") {
+				f, err := parseFilter(expr)
+				if err != nil {
+					return err
+				}
+				filters = append(filters, f)
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+
+			return tailTopic(client, c.App.Writer, topicName(c, conf), int32(c.Int("partition")), from, filters)
+		},
+	}
+}
+
+// StatsCommand returns zbctl stats: print log positions, topic subscriptions and task backlog of the partitions.
+func StatsCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "stats",
+		Usage: "print log positions, topic subscriptions and task backlog of the partitions",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Topic of the partitions.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.DurationFlag{
+				Name:  "watch, w",
+				Usage: "Refresh statistics with the given interval until interrupted.",
+			},
+			cli.BoolFlag{
+				Name:  "json",
+				Usage: "Same as --output json.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			for {
+				stats, err := client.TopicStats(topicName(c, conf))
+				if err != nil {
+					return err
+				}
+
+				if p.format == outputTable && !p.quiet && c.Duration("watch") > 0 {
+					// Clear the terminal, so the statistics are refreshed in place.
+					fmt.Fprint(p.w, "\033[H\033[2J")
+					fmt.Fprintln(p.w, time.Now().Format(time.RFC3339))
+				}
+				if err := printStats(p, stats); err != nil {
+					return err
+				}
+
+				if c.Duration("watch") <= 0 {
+					return nil
+				}
+				time.Sleep(c.Duration("watch"))
+			}
+		},
+	}
+}
+
+// BenchCommand returns zbctl bench: create tasks as fast as possible or at a target rate and report throughput, latency
+// and errors.
+func BenchCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "bench",
+		Usage: "create tasks as fast as possible or at a target rate and report throughput, latency and errors",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Topic on which tasks are created.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.StringFlag{
+				Name:  "task-type, tt",
+				Value: "bench",
+				Usage: "Type of the created tasks.",
+			},
+			cli.IntFlag{
+				Name:  "tasks",
+				Value: 10000,
+				Usage: "Number of tasks to create.",
+			},
+			cli.IntFlag{
+				Name:  "concurrency",
+				Value: 16,
+				Usage: "Number of requests in flight, and of tasks handled in parallel by the worker.",
+			},
+			cli.StringFlag{
+				Name:  "payload-size",
+				Value: "128",
+				Usage: "Size of payload of every task, e.g. 512, 1k or 1m.",
+			},
+			cli.Float64Flag{
+				Name:  "rate",
+				Usage: "Tasks created per second. Default is as fast as possible.",
+			},
+			cli.BoolFlag{
+				Name:  "worker",
+				Usage: "Complete created tasks with a worker and report end to end latency.",
+			},
+			cli.DurationFlag{
+				Name:  "wait",
+				Value: 30 * time.Second,
+				Usage: "Time without a completed task after which the worker gives up.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			size, err := parseSize(c.String("payload-size"))
+			if err != nil {
+				return err
+			}
+			o := &benchOptions{
+				topic:       topicName(c, conf),
+				taskType:    c.String("task-type"),
+				tasks:       c.Int("tasks"),
+				concurrency: c.Int("concurrency"),
+				payloadSize: size,
+				rate:        c.Float64("rate"),
+				wait:        c.Duration("wait"),
+			}
+			if o.concurrency < 1 {
+				o.concurrency = 1
+			}
+
+			producer, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			var consumer *zbc.Client
+			if c.Bool("worker") {
+				// Worker has a connection of its own, so completing tasks doesn't slow down creating them.
+				consumer, err = newClient(conf)
+				if err != nil {
+					return err
+				}
+			}
+			log.Println("Connected to Zeebe.")
+
+			record, err := runBench(producer, consumer, o)
+			if err != nil {
+				return err
+			}
+			return printBench(p, record)
+		},
+	}
+}
+
+// ProxyCommand returns zbctl proxy: forward connections to the broker and log their frames as JSON.
+func ProxyCommand() cli.Command {
+	return cli.Command{
+		Name:  "proxy",
+		Usage: "forward connections to the broker and log their frames as JSON",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "listen",
+				Value: ":51016",
+				Usage: "Address on which clients connect to the proxy.",
+			},
+			cli.StringFlag{
+				Name:  "target",
+				Value: "127.0.0.1:51015",
+				Usage: "Address of the broker.",
+			},
+			cli.StringFlag{
+				Name:  "dump",
+				Usage: "Location of file where frames are captured, so they can be replayed.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return runProxy(c.App.Writer, c.String("listen"), c.String("target"), c.String("dump"))
+		},
+	}
+}
+
+// ReplayCommand returns zbctl replay: send requests captured by zbctl proxy to the broker and log them with the
+// responses as JSON.
+func ReplayCommand() cli.Command {
+	return cli.Command{
+		Name:      "replay",
+		Usage:     "send requests captured by zbctl proxy to the broker and log them with the responses as JSON",
+		ArgsUsage: "<capture file>",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "target",
+				Value: "127.0.0.1:51015",
+				Usage: "Address of the broker.",
+			},
+			cli.DurationFlag{
+				Name:  "wait",
+				Value: 2 * time.Second,
+				Usage: "Time without response after which replay is finished.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if len(c.Args().First()) == 0 {
+				return errCaptureMissing
+			}
+			return replay(c.App.Writer, c.Args().First(), c.String("target"), c.Duration("wait"))
+		},
+	}
+}
+
+// DecodeCommand returns zbctl decode: print headers and decoded message of every frame in a binary dump as JSON.
+func DecodeCommand() cli.Command {
+	return cli.Command{
+		Name:      "decode",
+		Usage:     "print headers and decoded message of every frame in a binary dump as JSON",
+		ArgsUsage: "<file|->",
+		Action: func(c *cli.Context) error {
+			if len(c.Args().First()) == 0 {
+				return errDumpMissing
+			}
+			return decode(c.App.Writer, c.Args().First())
+		},
+	}
+}
+
+// ConsoleCommand returns zbctl console: type commands interactively, tab completes them.
+func ConsoleCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "console",
+		Usage: "type commands interactively, tab completes them",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Topic to which commands are sent, changed in the console by use <topic>.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+
+			runConsole(client, c.App.Writer, topicName(c, conf))
+			return nil
+		},
+	}
+}
+
+// VersionCommand returns zbctl version: print version of zbctl and SBE schema it speaks, with --broker compare it with
+// the broker.
+func VersionCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "version",
+		Usage: "print version of zbctl and SBE schema it speaks, with --broker compare it with the broker",
+		Flags: append([]cli.Flag{
+			cli.BoolFlag{
+				Name:  "broker",
+				Usage: "Query protocol of the broker and warn if it differs from protocol of zbctl.",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			record := &versionRecord{Version: c.App.Version, SchemaID: zbc.SchemaID, SchemaVersion: zbc.SchemaVersion}
+			if c.Bool("broker") {
+				client, err := newClient(conf)
+				if err != nil {
+					return err
+				}
+				record.Broker, err = client.BrokerProtocol()
+				if err != nil {
+					return err
+				}
+
+				if !record.Broker.Compatible() {
+					log.Printf("WARNING: Broker speaks SBE schema %d version %d, zbctl decodes versions %v. Commands will fail.\n",
+						record.Broker.SchemaID, record.Broker.SchemaVersion, zbc.SupportedSchemaVersions())
+				}
+			}
+			return p.print(record, []string{record.Version}, func(w io.Writer) {
+				fmt.Fprintf(w, "zbctl %s\n", record.Version)
+				fmt.Fprintf(w, "SBE schema %d version %d\n", record.SchemaID, record.SchemaVersion)
+				if broker := record.Broker; broker != nil {
+					fmt.Fprintf(w, "Broker %s: SBE schema %d version %d, transport protocol %d\n",
+						broker.Address, broker.SchemaID, broker.SchemaVersion, broker.ProtocolID)
+				}
+			})
+		},
+	}
+}
+
+// ConfigCommand returns zbctl config: get or set default topic and profile in the configuration file.
+func ConfigCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:  "config",
+		Usage: "get or set default topic and profile in the configuration file",
+		Subcommands: []cli.Command{
+			{
+				Name:      "get",
+				Usage:     "print default topic or profile in use",
+				ArgsUsage: "<topic|profile>",
+				Flags:     outputFlags,
+				Action: func(c *cli.Context) error {
+					p, err := newPrinter(c)
+					if err != nil {
+						return err
+					}
+					if len(c.Args().First()) == 0 {
+						return errSettingMissing
+					}
+					value, err := configValue(conf, c.Args().First())
+					if err != nil {
+						return err
+					}
+					return p.print(map[string]string{c.Args().First(): value}, []string{value}, func(w io.Writer) {
+						fmt.Fprintln(w, value)
+					})
+				},
+			},
+			{
+				Name:      "set",
+				Usage:     "write default topic or profile into the configuration file, topic goes into the profile in use",
+				ArgsUsage: "<topic|profile> <value>",
+				Action: func(c *cli.Context) error {
+					if len(c.Args().First()) == 0 {
+						return errSettingMissing
+					}
+					if len(c.Args()) < 2 {
+						return errValueMissing
+					}
+					return setConfigValue(conf.path, conf.Profile, c.Args().First(), c.Args().Get(1))
+				},
+			},
+		},
+	}
+}
+
+// CompletionCommand returns zbctl completion: print script completing zbctl commands, load it with source <(zbctl
+// completion bash).
+func CompletionCommand() cli.Command {
+	return cli.Command{
+		Name:      "completion",
+		Usage:     "print script completing zbctl commands, load it with source <(zbctl completion bash)",
+		ArgsUsage: "<bash|zsh>",
+		Action: func(c *cli.Context) error {
+			return writeCompletion(c.App.Writer, c.Args().First())
+		},
+	}
+}
+
+// SubscribeCommand returns zbctl subscribe: work on tasks with an external command.
+func SubscribeCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:      "subscribe",
+		Usage:     "work on tasks with an external command",
+		ArgsUsage: "[arguments of the command]",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "exec, e",
+				Usage: "Command which receives payload of the task as JSON on stdin and prints new payload to stdout.",
+			},
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Executing command request on specific topic.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.Int64Flag{
+				Name:   "partition-id",
+				Value:  0,
+				Usage:  "Specify partition on which we are opening subscription.",
+				EnvVar: "ZB_PARTITION_ID",
+			},
+			cli.StringFlag{
+				Name:   "lock-owner, l",
+				Value:  "zbc",
+				Usage:  "Specify lock owner.",
+				EnvVar: "ZB_LOCK_OWNER",
+			},
+			cli.StringFlag{
+				Name:   "task-type, tt",
+				Value:  "foo",
+				Usage:  "Specify task type.",
+				EnvVar: "ZB_TASK_TYPE",
+			},
+			cli.DurationFlag{
+				Name:  "lock-duration",
+				Value: 5 * time.Minute,
+				Usage: "How long the task stays locked while the command runs.",
+			},
+			cli.IntFlag{
+				Name:  "concurrency",
+				Value: 1,
+				Usage: "Number of commands running in parallel.",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if len(c.String("exec")) == 0 {
+				return errExecMissing
+			}
+
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+
+			worker := client.NewWorker(c.String("task-type"), execHandler(c.String("exec"), c.Args()),
+				zbc.WithTopic(topicName(c, conf)),
+				zbc.WithPartition(int32(c.Int64("partition-id"))),
+				zbc.WithLockOwner(c.String("lock-owner")),
+				zbc.WithLockDuration(c.Duration("lock-duration")),
+				zbc.WithMaxConcurrentJobs(c.Int("concurrency")),
+			)
+			if err := worker.Start(); err != nil {
+				return err
+			}
+			log.Println("Waiting for tasks ....")
+
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, os.Interrupt)
+			<-signals
+
+			log.Println("Stopping, waiting for running commands ....")
+			return client.Close(context.Background())
+		},
+	}
+}
+
+// OpenCommand returns zbctl open: open a subscription.
+func OpenCommand(conf *Config) cli.Command {
+	return cli.Command{
+		Name:    "open",
+		Aliases: []string{"n"},
+		Usage:   "open a subscription",
+		Flags: append([]cli.Flag{
+			cli.StringFlag{
+				Name:   "topic, t",
+				Value:  "default-topic",
+				Usage:  "Executing command request on specific topic.",
+				EnvVar: "ZB_TOPIC_NAME",
+			},
+			cli.Int64Flag{
+				Name:   "partition-id, p",
+				Value:  0,
+				Usage:  "Specify partition on which we are opening subscription.",
+				EnvVar: "ZB_PARTITION_ID",
+			},
+			cli.StringFlag{
+				Name:   "lock-owner, l",
+				Value:  "zbc",
+				Usage:  "Specify lock owner.",
+				EnvVar: "ZB_LOCK_OWNER",
+			},
+			cli.StringFlag{
+				Name:   "task-type, tt",
+				Value:  "foo",
+				Usage:  "Specify task type.",
+				EnvVar: "ZB_TASK_TYPE",
+			},
+		}, outputFlags...),
+		Action: func(c *cli.Context) error {
+			p, err := newPrinter(c)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(conf)
+			if err != nil {
+				return err
+			}
+			log.Println("Connected to Zeebe.")
+			return openSubscription(p, client, topicName(c, conf),
+				int32(c.Int64("partition-id")),
+				c.String("lock-owner"),
+				c.String("task-type"))
+		},
+	}
+}
+
+// snapshotTimeout is default time in which zbctl instance list and zbctl task list replay the topic. Every
+// partition takes at least a second.
+const snapshotTimeout = 30 * time.Second
+
+// collectIncidents will replay incident events of the partition and return incidents which are not resolved yet.
+// Replay is considered finished once no event arrives for the wait duration.
+func collectIncidents(client *zbc.Client, topic string, partitionID int32, wait time.Duration) ([]*zbc.Message, error) {
+	sub, err := client.OpenIncidentSubscription(&zbc.TopicSubscription{
+		TopicName:        topic,
+		PartitionID:      partitionID,
+		Name:             "zbctl-incidents",
+		StartPosition:    0,
+		PrefetchCapacity: 32,
+		ForceStart:       true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	var keys []uint64
+	open := make(map[uint64]*zbc.Message)
+	for {
+		select {
+		case message, ok := <-sub.Events():
+			if !ok {
+				return nil, errNilResponse
+			}
+			key := (*message.SbeMessage).(*sbe.SubscribedEvent).Key
+			switch (*message.Data)["state"] {
+			case zbc.IncidentCreated:
+				keys = append(keys, key)
+				open[key] = message
+			case zbc.IncidentResolved, zbc.IncidentDeleted:
+				delete(open, key)
+			}
+		case <-time.After(wait):
+			var incidents []*zbc.Message
+			for _, key := range keys {
+				if message, ok := open[key]; ok {
+					incidents = append(incidents, message)
+				}
+			}
+			return incidents, nil
+		}
+	}
+}
+
+func sendWorkflowInstance(client *zbc.Client, topic string, m *zbc.WorkflowInstance) (*zbc.Message, error) {
+	return client.Responder(zbc.NewCreateWorkflowInstanceCommand(topic, 0, m))
+}
+
+func openSubscription(p *printer, client *zbc.Client, topic string, pid int32, lo string, tt string) error {
+	taskSub := &zbc.TaskSubscription{
+		TopicName:     topic,
+		PartitionID:   pid,
+		Credits:       32,
+		LockDuration:  300000,
+		LockOwner:     lo,
+		SubscriberKey: 0,
+		TaskType:      tt,
+	}
+	subscriptionCh, err := client.TaskConsumer(taskSub)
+	if err != nil {
+		return err
+	}
+
+	log.Println("Waiting for events ....")
+	for {
+		message, ok := <-subscriptionCh
+		if !ok {
+			return errSubscriptionClosed
+		}
+		if err := p.streamEvent(message); err != nil {
+			return err
+		}
+	}
+}
+
+// execHandler returns handler which pipes payload of the task as JSON to stdin of the command. Task is completed
+// with JSON printed to stdout as payload when the command exits with 0, empty output keeps the payload unchanged.
+// Otherwise task is failed with stderr of the command as error message.
+func execHandler(command string, args []string) zbc.TaskHandler {
+	return func(task *zbc.Task) (map[string]interface{}, error) {
+		input := task.PayloadJson
+		if input == nil {
+			input = map[string]interface{}{}
+		}
+		stdin, err := json.Marshal(input)
+		if err != nil {
+			return nil, err
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(command, args...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		cmd.Env = append(os.Environ(),
+			fmt.Sprintf("ZB_TASK_TYPE=%s", task.Type),
+			fmt.Sprintf("ZB_TASK_RETRIES=%d", task.Retries),
+		)
+
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+				return nil, errors.New(msg)
+			}
+			return nil, err
+		}
+
+		if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+			return nil, nil
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(stdout.Bytes(), &payload); err != nil {
+			return nil, fmt.Errorf("handler printed invalid JSON: %s", err)
+		}
+		return payload, nil
+	}
+}
+
+// lockedTask builds the task event which would be received through subscription, so the task with the given key
+// can be completed or failed from the command line. Broker accepts the command only if lock owner matches.
+func lockedTask(c *cli.Context, conf *Config) (*sbe.SubscribedEvent, error) {
+	if !c.IsSet("key") {
+		return nil, errKeyMissing
+	}
+
+	task := map[string]interface{}{
+		"type":      c.String("task-type"),
+		"lockOwner": c.String("lock-owner"),
+		"retries":   c.Int("retries"),
+		"headers":   map[string]interface{}{},
+	}
+	b, err := msgpack.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sbe.SubscribedEvent{
+		PartitionId: uint16(c.Int64("partition-id")),
+		Key:         c.Uint64("key"),
+		TopicName:   []uint8(topicName(c, conf)),
+		Event:       b,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/urfave/cli"
+)
+
+// Completion scripts ask zbctl for the candidates by running the command line typed so far with
+// --generate-bash-completion, so plugins and new commands are completed without regenerating the scripts.
+
+const bashCompletion = `# bash completion for zbctl, load it with: source <(zbctl completion bash)
+
+_zbctl_complete() {
+  local cur opts
+  COMPREPLY=()
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  opts=$("${COMP_WORDS[@]:0:$COMP_CWORD}" --generate-bash-completion 2>/dev/null)
+  COMPREPLY=($(compgen -W "${opts}" -- "${cur}"))
+  return 0
+}
+
+complete -o default -F _zbctl_complete zbctl
+`
+
+const zshCompletion = `#compdef zbctl
+# zsh completion for zbctl, load it with: source <(zbctl completion zsh)
+
+_zbctl() {
+  local -a opts
+  opts=("${(@f)$(${words[@]:0:$CURRENT-1} --generate-bash-completion 2>/dev/null)}")
+  compadd -- $opts
+}
+
+compdef _zbctl zbctl
+`
+
+// writeCompletion will write completion script for the shell.
+func writeCompletion(w io.Writer, shell string) error {
+	switch shell {
+	case "bash":
+		_, err := io.WriteString(w, bashCompletion)
+		return err
+	case "zsh":
+		_, err := io.WriteString(w, zshCompletion)
+		return err
+	case "":
+		return errShellMissing
+	default:
+		return fmt.Errorf("Completion for %s is not supported, only bash and zsh are", shell)
+	}
+}
+
+// needsConfig tells if the command reads configuration of zbctl. Completion, plugins and version without --broker
+// work before zbctl is configured.
+func needsConfig(c *cli.Context, args []string) bool {
+	if len(args) > 0 && args[len(args)-1] == "--"+cli.BashCompletionFlag.GetName() {
+		return false
+	}
+	command := c.App.Command(c.Args().First())
+	if command != nil && command.Name == "version" {
+		return contains(c.Args().Tail(), "--broker")
+	}
+	return command == nil || (command.Name != "completion" && command.Category != pluginCategory)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/zeebe-io/zbc-go/zbc"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Settings are taken from, in order of precedence: command line flags, ZB_* environment variables, configuration file.
+// Configuration file is given by --config or ZBC_CONFIG, otherwise the first of configurationPaths which exists is used.
+// Format of the file is decided by its extension: .yaml or .yml, .json, anything else is read as TOML.
+
+const (
+	defaultConfiguration = "/etc/zeebe/config.toml"
+	defaultPort          = "51015"
+)
+
+type tlsConfig struct {
+	Enabled            bool   `toml:"enabled" yaml:"enabled" json:"enabled"`
+	CAFile             string `toml:"ca_file" yaml:"ca_file" json:"ca_file"`
+	CertFile           string `toml:"cert_file" yaml:"cert_file" json:"cert_file"`
+	KeyFile            string `toml:"key_file" yaml:"key_file" json:"key_file"`
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify" yaml:"insecure_skip_verify" json:"insecure_skip_verify"`
+}
+
+// socketConfig tunes TCP connections to the brokers, Go defaults are used for settings which are not set.
+type socketConfig struct {
+	ConnectTimeout string `toml:"connect_timeout" yaml:"connect_timeout" json:"connect_timeout"` // Duration like "3s".
+	KeepAlive      string `toml:"keep_alive" yaml:"keep_alive" json:"keep_alive"`                // Period of TCP keep-alive probes, "-1s" disables them.
+	SendBuffer     int    `toml:"send_buffer" yaml:"send_buffer" json:"send_buffer"`             // SO_SNDBUF in bytes.
+	ReceiveBuffer  int    `toml:"receive_buffer" yaml:"receive_buffer" json:"receive_buffer"`    // SO_RCVBUF in bytes.
+	Nagle          bool   `toml:"nagle" yaml:"nagle" json:"nagle"`                               // Disables TCP_NODELAY.
+}
+
+// options returns socket options of the client.
+func (s *socketConfig) options() (zbc.SocketOptions, error) {
+	opts := zbc.SocketOptions{SendBuffer: s.SendBuffer, ReceiveBuffer: s.ReceiveBuffer, Nagle: s.Nagle}
+	var err error
+	if len(s.ConnectTimeout) > 0 {
+		if opts.ConnectTimeout, err = time.ParseDuration(s.ConnectTimeout); err != nil {
+			return opts, err
+		}
+	}
+	if len(s.KeepAlive) > 0 {
+		if opts.KeepAlive, err = time.ParseDuration(s.KeepAlive); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+type contact struct {
+	Address           string       `toml:"address" yaml:"address" json:"address"`
+	Port              string       `toml:"port" yaml:"port" json:"port"`
+	KeepAliveInterval string       `toml:"keep_alive_interval" yaml:"keep_alive_interval" json:"keep_alive_interval"` // Duration like "10s", "0" disables heartbeats.
+	RequestTimeout    string       `toml:"request_timeout" yaml:"request_timeout" json:"request_timeout"`             // Duration like "5s".
+	TLS               tlsConfig    `toml:"tls" yaml:"tls" json:"tls"`
+	Socket            socketConfig `toml:"socket" yaml:"socket" json:"socket"`
+}
+
+func (c *contact) String() string {
+	return fmt.Sprintf("%s:%s", c.Address, c.Port)
+}
+
+// Config is configuration of zbctl, commands connect to the broker with it. It's loaded by LoadConfig.
+type Config struct {
+	Version  string             `toml:"version" yaml:"version" json:"version"`
+	Brokers  []string           `toml:"brokers" yaml:"brokers" json:"brokers"` // Seed addresses, address and port of broker are used if empty.
+	Broker   contact            `toml:"broker" yaml:"broker" json:"broker"`
+	Topic    string             `toml:"topic" yaml:"topic" json:"topic"`       // Default of --topic, default-topic if empty.
+	Profile  string             `toml:"profile" yaml:"profile" json:"profile"` // Profile used unless --profile or ZB_PROFILE is given.
+	Profiles map[string]profile `toml:"profiles" yaml:"profiles" json:"profiles"`
+
+	path    string // File the configuration was read from, empty if it comes from environment only.
+	verbose bool   // Set by --verbose flag, client logs debug messages then.
+}
+
+// seeds returns addresses of brokers zbctl bootstraps from, in order in which they are tried.
+func (cf *Config) seeds() []string {
+	if len(cf.Brokers) > 0 {
+		return cf.Brokers
+	}
+	return []string{cf.Broker.String()}
+}
+
+func (cf *Config) String() string {
+	if len(cf.Profile) > 0 {
+		return fmt.Sprintf("version: %s\tprofile: %s\tBrokers: %s", cf.Version, cf.Profile, strings.Join(cf.seeds(), ", "))
+	}
+	return fmt.Sprintf("version: %s\tBrokers: %s", cf.Version, strings.Join(cf.seeds(), ", "))
+
+}
+
+// configurationPaths returns locations where configuration file is searched for when none is given.
+func configurationPaths() []string {
+	var paths []string
+	if home := os.Getenv("HOME"); len(home) > 0 {
+		for _, name := range []string{"config.toml", "config.yaml", "config.yml", "config.json"} {
+			paths = append(paths, filepath.Join(home, ".zeebe", name))
+		}
+	}
+	return append(paths, defaultConfiguration)
+}
+
+func findConfig() string {
+	for _, path := range configurationPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+func decodeConfig(path string, c *Config) error {
+	content, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(content, c)
+	case ".json":
+		return json.Unmarshal(content, c)
+	default:
+		_, err := toml.Decode(string(content), c)
+		return err
+	}
+}
+
+func loadConfig(path string, c *Config) error {
+	if len(path) == 0 {
+		path = findConfig()
+	}
+	if len(path) == 0 && (len(os.Getenv("ZB_BROKER_ADDRESS")) > 0 || len(os.Getenv("ZB_BROKERS")) > 0) {
+		// Everything can be configured through environment.
+		c.Broker.Port = defaultPort
+		return nil
+	}
+
+	c.path = path
+	if err := decodeConfig(path, c); err != nil {
+		log.Printf("HINT: Expecting to find configuration file at one of %v. Try setting configuration path with:", configurationPaths())
+		log.Println(" zbctl --config <path to config.toml>")
+		return fmt.Errorf("Reading configuration failed: %s", err)
+	}
+	return nil
+}
+
+// applyEnv will override settings of the configuration with ZB_* environment variables which are set.
+func applyEnv(c *Config) error {
+	settings := map[string]*string{
+		"ZB_BROKER_ADDRESS":      &c.Broker.Address,
+		"ZB_BROKER_PORT":         &c.Broker.Port,
+		"ZB_KEEP_ALIVE_INTERVAL": &c.Broker.KeepAliveInterval,
+		"ZB_REQUEST_TIMEOUT":     &c.Broker.RequestTimeout,
+		"ZB_TLS_CA_FILE":         &c.Broker.TLS.CAFile,
+		"ZB_TLS_CERT_FILE":       &c.Broker.TLS.CertFile,
+		"ZB_TLS_KEY_FILE":        &c.Broker.TLS.KeyFile,
+	}
+	for name, setting := range settings {
+		if value, ok := os.LookupEnv(name); ok {
+			*setting = value
+		}
+	}
+
+	if value, ok := os.LookupEnv("ZB_BROKERS"); ok {
+		// Comma separated list like node1:51015,node2:51015.
+		c.Brokers = strings.Split(value, ",")
+	}
+
+	switches := map[string]*bool{
+		"ZB_TLS_ENABLED":              &c.Broker.TLS.Enabled,
+		"ZB_TLS_INSECURE_SKIP_VERIFY": &c.Broker.TLS.InsecureSkipVerify,
+	}
+	for name, setting := range switches {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value of %s: %s", name, err)
+		}
+		*setting = enabled
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var (
+	errConsoleUsage = errors.New("Unknown command. Type help to list commands")
+	errTaskUnknown  = errors.New("Task is not locked by this console. Subscribe to its type first")
+)
+
+// consoleLockOwner locks tasks received by subscriptions of the console.
+const consoleLockOwner = "zbctl-console"
+
+// consoleCommand is command typed into the console. Arguments are words of the line following the command name.
+type consoleCommand struct {
+	usage string
+	help  string
+	run   func(c *console, args []string) error
+}
+
+// consoleCommands are set by init, help lists them.
+var consoleCommands map[string]consoleCommand
+
+func init() {
+	consoleCommands = map[string]consoleCommand{
+		"help":        {"help", "list commands", (*console).help},
+		"topology":    {"topology", "print brokers and leaders of partitions", (*console).topology},
+		"use":         {"use <topic>", "send following commands to the topic", (*console).use},
+		"create-task": {"create-task <type> [name=value...]", "create task with payload built from variables", (*console).createTask},
+		"subscribe":   {"subscribe <type>", "lock tasks of the type and print them as they arrive", (*console).subscribe},
+		"complete":    {"complete <key> [name=value...]", "complete locked task with payload built from variables", (*console).complete},
+		"unsubscribe": {"unsubscribe", "close all subscriptions of the console", (*console).unsubscribe},
+		"exit":        {"exit", "close subscriptions and leave the console, same as quit or Ctrl-D", nil},
+		"quit":        {"quit", "", nil},
+	}
+}
+
+// console is interactive session which executes commands typed by the user through the client.
+type console struct {
+	client *zbc.Client
+	editor *lineEditor
+	topic  string
+
+	mu            sync.Mutex // Guards tasks, which are added by subscriptions.
+	tasks         map[uint64]*sbe.SubscribedEvent
+	subscriptions []*zbc.Subscription
+}
+
+// runConsole will read commands until the user exits the console or input ends.
+func runConsole(client *zbc.Client, out io.Writer, topic string) {
+	c := &console{
+		client: client,
+		topic:  topic,
+		tasks:  make(map[uint64]*sbe.SubscribedEvent),
+	}
+	c.editor = newLineEditor(out, "zbctl> ", c.candidates)
+	defer c.editor.close()
+
+	c.editor.print("Connected to Zeebe. Type help to list commands.")
+	for {
+		line, err := c.editor.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.editor.print(err.Error())
+			break
+		}
+
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+		if words[0] == "exit" || words[0] == "quit" {
+			break
+		}
+		cmd, ok := consoleCommands[words[0]]
+		if !ok {
+			c.editor.print(errConsoleUsage.Error())
+			continue
+		}
+		if err := cmd.run(c, words[1:]); err != nil {
+			c.editor.print(err.Error())
+		}
+	}
+	c.unsubscribe(nil)
+}
+
+func (c *console) help(args []string) error {
+	names := make([]string, 0, len(consoleCommands))
+	for name, cmd := range consoleCommands {
+		if len(cmd.help) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := consoleCommands[name]
+		c.editor.print(fmt.Sprintf("  %-36s %s", cmd.usage, cmd.help))
+	}
+	c.editor.print(fmt.Sprintf("Commands are sent to topic %s.", c.topic))
+	return nil
+}
+
+func (c *console) topology(args []string) error {
+	topology, err := c.client.Topology()
+	if err != nil {
+		return err
+	}
+	return printTopology(&printer{w: c.editor.out, format: outputTable}, topology)
+}
+
+func (c *console) use(args []string) error {
+	if len(args) != 1 {
+		return errors.New("Topic name is missing. Use use <topic>")
+	}
+	c.topic = args[0]
+	return nil
+}
+
+func (c *console) createTask(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Task type is missing. Use create-task <type> [name=value...]")
+	}
+	payload, err := parseVars(args[1:])
+	if err != nil {
+		return err
+	}
+
+	task := &zbc.Task{
+		Type:        args[0],
+		Retries:     3,
+		Headers:     map[string]interface{}{},
+		PayloadJson: payload,
+	}
+	response, err := c.client.CreateTask(c.topic, task)
+	if err != nil {
+		return err
+	}
+	c.printMessage("Task created", response)
+	return nil
+}
+
+func (c *console) subscribe(args []string) error {
+	if len(args) != 1 {
+		return errors.New("Task type is missing. Use subscribe <type>")
+	}
+	sub, err := c.client.OpenTaskSubscription(&zbc.TaskSubscription{
+		TopicName:    c.topic,
+		LockOwner:    consoleLockOwner,
+		LockDuration: 300000,
+		Credits:      32,
+		TaskType:     args[0],
+	})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.subscriptions = append(c.subscriptions, sub)
+	c.mu.Unlock()
+
+	go func() {
+		for msg := range sub.Events() {
+			task, ok := (*msg.SbeMessage).(*sbe.SubscribedEvent)
+			if !ok {
+				continue
+			}
+			c.mu.Lock()
+			c.tasks[task.Key] = task
+			c.mu.Unlock()
+			c.printMessage(fmt.Sprintf("Task %d locked", task.Key), msg)
+		}
+	}()
+	c.editor.print(fmt.Sprintf("Subscribed to tasks of type %s, complete them with complete <key>.", args[0]))
+	return nil
+}
+
+func (c *console) complete(args []string) error {
+	if len(args) == 0 {
+		return errors.New("Task key is missing. Use complete <key> [name=value...]")
+	}
+	key, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("Invalid task key %s", args[0])
+	}
+	payload, err := parseVars(args[1:])
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	task, ok := c.tasks[key]
+	c.mu.Unlock()
+	if !ok {
+		return errTaskUnknown
+	}
+
+	response, err := c.client.CompleteTask(task, payload)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.tasks, key)
+	c.mu.Unlock()
+	c.printMessage("Task completed", response)
+	return nil
+}
+
+func (c *console) unsubscribe(args []string) error {
+	c.mu.Lock()
+	subscriptions := c.subscriptions
+	c.subscriptions = nil
+	c.mu.Unlock()
+
+	for _, sub := range subscriptions {
+		if err := sub.Close(); err != nil {
+			c.editor.print(err.Error())
+		}
+	}
+	return nil
+}
+
+// printMessage prints title followed by the event of the message as indented JSON.
+func (c *console) printMessage(title string, msg *zbc.Message) {
+	if msg.Data == nil {
+		c.editor.print(title)
+		return
+	}
+	b, err := json.MarshalIndent(jsonValue("", *msg.Data), "", "  ")
+	if err != nil {
+		c.editor.print(fmt.Sprintf("%s, decoding event failed: %s", title, err))
+		return
+	}
+	c.editor.print(title + ":\n" + string(b))
+}
+
+// candidates returns completions of the last word of the line: command names for the first word, keys of locked
+// tasks for complete and topics of the cluster for use.
+func (c *console) candidates(line string) []string {
+	words := strings.Fields(line)
+	if len(words) == 0 || (len(words) == 1 && !strings.HasSuffix(line, " ")) {
+		names := make([]string, 0, len(consoleCommands))
+		for name := range consoleCommands {
+			names = append(names, name)
+		}
+		return names
+	}
+	if len(words) > 2 || (len(words) == 2 && strings.HasSuffix(line, " ")) {
+		return nil
+	}
+
+	var candidates []string
+	switch words[0] {
+	case "complete":
+		c.mu.Lock()
+		for key := range c.tasks {
+			candidates = append(candidates, strconv.FormatUint(key, 10))
+		}
+		c.mu.Unlock()
+	case "use":
+		topology, err := c.client.Topology()
+		if err != nil {
+			return nil
+		}
+		seen := make(map[string]bool)
+		for _, leader := range topology.TopicLeaders {
+			if !seen[leader.TopicName] {
+				seen[leader.TopicName] = true
+				candidates = append(candidates, leader.TopicName)
+			}
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+// decode will write every frame of the binary dump at path to w as indented JSON. Dump holds raw frames as they were
+// sent over the connection, e.g. a payload of a packet captured by tcpdump.
+func decode(w io.Writer, path string) error {
+	content, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+
+	r := zbc.NewMessageReader(bufio.NewReader(bytes.NewReader(content)))
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	for {
+		headers, body, err := r.ReadHeaders()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(decodeFrame(r, headers, body)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/urfave/cli"
+)
+
+// stdinPath is given instead of path of the file to read the resource from standard input.
+const stdinPath = "-"
+
+// isJSON tells if the resource is JSON. Format is decided by the file extension, content of files without
+// known extension, standard input and inline values is JSON if it starts with '{'.
+func isJSON(path string, content []byte) bool {
+	switch filepath.Ext(path) {
+	case ".json":
+		return true
+	case ".yaml", ".yml":
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte("{"))
+}
+
+// loadCommand reads task or workflow instance from a JSON or YAML file. JSON is converted to YAML first,
+// so fields are named by yaml tags in both formats.
+func loadCommand(path string, command interface{}) error {
+	content, err := loadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if isJSON(path, content) {
+		var v interface{}
+		if err := json.Unmarshal(content, &v); err != nil {
+			return err
+		}
+		if content, err = yaml.Marshal(v); err != nil {
+			return err
+		}
+	}
+	return yaml.Unmarshal(content, command)
+}
+
+// loadPayload reads payload from a JSON or YAML file.
+func loadPayload(path string) (map[string]interface{}, error) {
+	content, err := loadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodePayload(path, content)
+}
+
+func decodePayload(path string, content []byte) (map[string]interface{}, error) {
+	var payload map[string]interface{}
+	var err error
+	if isJSON(path, content) {
+		err = json.Unmarshal(content, &payload)
+	} else {
+		err = yaml.Unmarshal(content, &payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// payloadFlag returns payload given inline by --payload or in the file given by --payload-file, nil if there is none.
+func payloadFlag(c *cli.Context) (map[string]interface{}, error) {
+	if inline := c.String("payload"); len(inline) > 0 {
+		return decodePayload("", []byte(inline))
+	}
+	if path := c.String("payload-file"); len(path) > 0 {
+		return loadPayload(path)
+	}
+	return nil, nil
+}
+
+// parseVars returns payload built from variables given by --var name=value. Value is a boolean, null or number if it
+// parses as one, JSON object, array or string if it starts with '{', '[' or '"' and a plain string otherwise. Numbers
+// with leading zeros, like 007, and NaN or infinity stay strings, they are identifiers or words rather than numbers.
+func parseVars(vars []string) (map[string]interface{}, error) {
+	payload := make(map[string]interface{}, len(vars))
+	for _, v := range vars {
+		i := strings.Index(v, "=")
+		if i <= 0 {
+			return nil, errInvalidVar
+		}
+		value, err := parseVarValue(v[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value of variable %s: %s", v[:i], err)
+		}
+		payload[v[:i]] = value
+	}
+	return payload, nil
+}
+
+func parseVarValue(value string) (interface{}, error) {
+	switch value {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if digits := strings.TrimLeft(value, "+-"); len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return value, nil
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f, nil
+	}
+	if len(value) == 0 || !strings.ContainsAny(value[:1], "{[\"") {
+		return value, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return jsonNumbers(v), nil
+}
+
+// jsonNumbers will replace numbers decoded by json.Decoder with integers where possible, floats otherwise.
+func jsonNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = jsonNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = jsonNumbers(item)
+		}
+	}
+	return v
+}
+
+func loadFile(path string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, errResourceNotFound
+	}
+	if path == stdinPath {
+		log.Println("Loading resource from standard input")
+		return ioutil.ReadAll(os.Stdin)
+	}
+
+	log.Printf("Loading resource at %s\n", path)
+	filename, _ := filepath.Abs(path)
+	return ioutil.ReadFile(filename)
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Keys handled by the line editor.
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = 9
+	keyNewline   = 10
+	keyEnter     = 13
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// lineEditor reads lines typed into the terminal and completes the last word on tab. If input is not a terminal,
+// e.g. commands are piped into zbctl, lines are read as they are, without echo and completion.
+type lineEditor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	prompt   string
+	complete func(line string) []string // Returns candidates for the last word of the line.
+	restore  func()                     // Restores mode of the terminal, nil if input is not a terminal.
+
+	mu   sync.Mutex // Guards line, which is redrawn when output is printed while the user types.
+	line []rune
+}
+
+func newLineEditor(out io.Writer, prompt string, complete func(line string) []string) *lineEditor {
+	e := &lineEditor{
+		in:       bufio.NewReader(os.Stdin),
+		out:      out,
+		prompt:   prompt,
+		complete: complete,
+	}
+	if restore, err := makeRaw(int(os.Stdin.Fd())); err == nil {
+		e.restore = restore
+	}
+	return e
+}
+
+// close will restore mode of the terminal.
+func (e *lineEditor) close() {
+	if e.restore != nil {
+		e.restore()
+	}
+}
+
+// print will write text above the line being typed and redraw the line below it.
+func (e *lineEditor) print(text string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.restore == nil {
+		io.WriteString(e.out, text+"\n")
+		return
+	}
+	io.WriteString(e.out, "\r\033[K"+text+"\n"+e.prompt+string(e.line))
+}
+
+// readLine returns the next line without the newline. Ctrl-C discards the line being typed, io.EOF is returned
+// on Ctrl-D at the start of the line or at the end of the input.
+func (e *lineEditor) readLine() (string, error) {
+	if e.restore == nil {
+		io.WriteString(e.out, e.prompt)
+		line, err := e.in.ReadString('\n')
+		if err == io.EOF && len(line) > 0 {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	e.mu.Lock()
+	e.line = e.line[:0]
+	io.WriteString(e.out, e.prompt)
+	e.mu.Unlock()
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+
+		e.mu.Lock()
+		switch r {
+		case keyEnter, keyNewline:
+			line := string(e.line)
+			e.line = e.line[:0]
+			io.WriteString(e.out, "\n")
+			e.mu.Unlock()
+			return line, nil
+		case keyCtrlD:
+			if len(e.line) == 0 {
+				io.WriteString(e.out, "\n")
+				e.mu.Unlock()
+				return "", io.EOF
+			}
+		case keyCtrlC:
+			e.line = e.line[:0]
+			io.WriteString(e.out, "^C\n"+e.prompt)
+		case keyCtrlU:
+			e.line = e.line[:0]
+			io.WriteString(e.out, "\r\033[K"+e.prompt)
+		case keyBackspace, keyDelete:
+			if len(e.line) > 0 {
+				e.line = e.line[:len(e.line)-1]
+				io.WriteString(e.out, "\b \b")
+			}
+		case keyTab:
+			e.completeLine()
+		case keyEscape:
+			// Cursor and function keys send escape sequences, they are not supported and skipped.
+			e.skipEscapeSequence()
+		default:
+			if r >= ' ' {
+				e.line = append(e.line, r)
+				io.WriteString(e.out, string(r))
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// completeLine will extend the last word by the common prefix of the candidates. If there is nothing to extend it
+// by, candidates are listed. Must be called under mu.
+func (e *lineEditor) completeLine() {
+	line := string(e.line)
+	word := line[strings.LastIndex(line, " ")+1:]
+
+	var candidates []string
+	for _, candidate := range e.complete(line) {
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, candidate)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	suffix := commonPrefix(candidates)[len(word):]
+	if len(candidates) == 1 {
+		suffix += " "
+	}
+	if len(suffix) > 0 {
+		e.line = append(e.line, []rune(suffix)...)
+		io.WriteString(e.out, suffix)
+		return
+	}
+
+	sort.Strings(candidates)
+	io.WriteString(e.out, "\n"+strings.Join(candidates, "  ")+"\n"+e.prompt+line)
+}
+
+func (e *lineEditor) skipEscapeSequence() {
+	if next, err := e.in.Peek(1); err != nil || next[0] != '[' {
+		return
+	}
+	e.in.ReadByte()
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil || (b >= 0x40 && b <= 0x7e) {
+			return
+		}
+	}
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, value := range values[1:] {
+		for !strings.HasPrefix(value, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
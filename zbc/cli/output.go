@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/urfave/cli"
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errUnknownOutput = errors.New("Unknown output format. Use --output json, yaml or table")
+
+// Formats of --output.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// outputFlags select how results are printed. They are global flags and flags of every command printing a result,
+// so they can be given before or after the command. Logs go to stderr, stdout holds the result only.
+var outputFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "output, o",
+		Value:  outputTable,
+		Usage:  "Format of the result: json, yaml or table.",
+		EnvVar: "ZB_OUTPUT",
+	},
+	cli.BoolFlag{
+		Name:  "quiet, q",
+		Usage: "Print only keys of the result, one per line.",
+	},
+}
+
+// printer writes result of a command to writer of the app, stdout by default, in format selected by --output and
+// --quiet.
+type printer struct {
+	w        io.Writer
+	format   string
+	quiet    bool
+	streamed bool
+}
+
+// newPrinter returns printer for flags of the command. Flag of the command takes precedence over the global one,
+// --json of older commands is same as --output json.
+func newPrinter(c *cli.Context) (*printer, error) {
+	format := c.GlobalString("output")
+	if c.IsSet("output") {
+		format = c.String("output")
+	}
+	if c.Bool("json") {
+		format = outputJSON
+	}
+	switch format {
+	case "":
+		format = outputTable
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return nil, errUnknownOutput
+	}
+	return &printer{w: c.App.Writer, format: format, quiet: c.Bool("quiet") || c.GlobalBool("quiet")}, nil
+}
+
+// print will write v as JSON or YAML, or call table in the table format. With --quiet only keys are written.
+func (p *printer) print(v interface{}, keys []string, table func(w io.Writer)) error {
+	if p.quiet {
+		for _, key := range keys {
+			fmt.Fprintln(p.w, key)
+		}
+		return nil
+	}
+
+	switch p.format {
+	case outputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(b))
+		return err
+	case outputYAML:
+		b, err := marshalYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(b)
+		return err
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 8, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// stream will write one of results which keep arriving, e.g. events of a subscription. JSON is written one object
+// per line and YAML as separate documents, so they can be processed as they come. Table has no alignment,
+// its header is written before the first result only.
+func (p *printer) stream(v interface{}, key string, header string, row func() string) error {
+	first := !p.streamed
+	p.streamed = true
+	if p.quiet {
+		_, err := fmt.Fprintln(p.w, key)
+		return err
+	}
+
+	switch p.format {
+	case outputJSON:
+		return json.NewEncoder(p.w).Encode(v)
+	case outputYAML:
+		b, err := marshalYAML(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(p.w, "---"); err != nil {
+			return err
+		}
+		_, err = p.w.Write(b)
+		return err
+	}
+
+	if first {
+		fmt.Fprintln(p.w, header)
+	}
+	_, err := fmt.Fprintln(p.w, row())
+	return err
+}
+
+// marshalYAML will encode v as YAML with the same names as JSON has, structs are converted through JSON for that.
+func marshalYAML(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(jsonNumbers(doc))
+}
+
+// eventRecord is an event received from the broker as it's printed, either response to a command or subscribed event.
+type eventRecord struct {
+	Key         uint64      `json:"key"`
+	Position    uint64      `json:"position"`
+	PartitionID uint16      `json:"partitionId"`
+	Topic       string      `json:"topic"`
+	Event       interface{} `json:"event"`
+}
+
+func newEventRecord(message *zbc.Message) *eventRecord {
+	record := &eventRecord{}
+	if message.SbeMessage != nil {
+		switch event := (*message.SbeMessage).(type) {
+		case *sbe.ExecuteCommandResponse:
+			record.Key, record.Position, record.PartitionID = event.Key, event.Position, event.PartitionId
+			record.Topic = string(event.TopicName)
+		case *sbe.SubscribedEvent:
+			record.Key, record.Position, record.PartitionID = event.Key, event.Position, event.PartitionId
+			record.Topic = string(event.TopicName)
+		}
+	}
+	if message.Data != nil {
+		record.Event = jsonValue("", *message.Data)
+	}
+	return record
+}
+
+// state returns state of the event, e.g. CREATED or COMPLETED.
+func (r *eventRecord) state() interface{} {
+	if event, ok := r.Event.(map[string]interface{}); ok {
+		return event["state"]
+	}
+	return ""
+}
+
+func (r *eventRecord) row() string {
+	return fmt.Sprintf("%d\t%d\t%v", r.Key, r.PartitionID, r.state())
+}
+
+const eventHeader = "KEY\tPARTITION\tSTATE"
+
+// printEvent will print response of a command.
+func (p *printer) printEvent(message *zbc.Message) error {
+	record := newEventRecord(message)
+	return p.print(record, []string{strconv.FormatUint(record.Key, 10)}, func(w io.Writer) {
+		fmt.Fprintln(w, eventHeader)
+		fmt.Fprintln(w, record.row())
+	})
+}
+
+// streamEvent will print event of a subscription.
+func (p *printer) streamEvent(message *zbc.Message) error {
+	record := newEventRecord(message)
+	return p.stream(record, strconv.FormatUint(record.Key, 10), eventHeader, record.row)
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli"
+)
+
+// Executables named zbctl-<name> on PATH are plugins, zbctl runs them as its own subcommands, e.g. zbctl-backup as
+// zbctl backup. Plugin receives arguments following its name and environment of zbctl, ZB_* variables included,
+// --config is passed to it as ZBC_CONFIG. Built-in commands can't be replaced, the first plugin with the name on PATH wins.
+
+const (
+	pluginPrefix   = "zbctl-"
+	pluginCategory = "plugins"
+)
+
+// Plugins returns commands running plugins found on PATH, which don't clash with given commands.
+func Plugins(commands []cli.Command) []cli.Command {
+	taken := make(map[string]bool)
+	for _, command := range commands {
+		for _, name := range command.Names() {
+			taken[name] = true
+		}
+	}
+
+	var plugins []cli.Command
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name, ok := pluginName(file)
+			if !ok || taken[name] {
+				continue
+			}
+			taken[name] = true
+			plugins = append(plugins, pluginCommand(name, filepath.Join(dir, file.Name())))
+		}
+	}
+	return plugins
+}
+
+// pluginName returns name of the subcommand if file is an executable plugin.
+func pluginName(file os.FileInfo) (string, bool) {
+	name := file.Name()
+	if !strings.HasPrefix(name, pluginPrefix) || !file.Mode().IsRegular() {
+		return "", false
+	}
+	if runtime.GOOS == "windows" {
+		if !strings.EqualFold(filepath.Ext(name), ".exe") {
+			return "", false
+		}
+		name = name[:len(name)-len(".exe")]
+	} else if file.Mode()&0111 == 0 {
+		return "", false
+	}
+
+	name = strings.TrimPrefix(name, pluginPrefix)
+	return name, len(name) > 0
+}
+
+func pluginCommand(name, path string) cli.Command {
+	return cli.Command{
+		Name:            name,
+		Usage:           "run plugin " + path,
+		ArgsUsage:       "[arguments of the plugin]",
+		Category:        pluginCategory,
+		SkipFlagParsing: true,
+		HideHelp:        true,
+		Action: func(c *cli.Context) error {
+			return runPlugin(c.App.Writer, path, c.Args(), c.GlobalString("config"))
+		},
+	}
+}
+
+// runPlugin will run the plugin with standard input and error of zbctl, its output goes to w. Zbctl exits with status
+// of the plugin if it fails.
+func runPlugin(w io.Writer, path string, args []string, config string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if len(config) > 0 {
+		cmd.Env = append(cmd.Env, "ZBC_CONFIG="+config)
+	}
+
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return cli.NewExitError("", status.ExitStatus())
+		}
+	}
+	return err
+}
@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// versionRecord is version of zbctl as it's printed, Broker is set with --broker.
+type versionRecord struct {
+	Version       string              `json:"version"`
+	SchemaID      uint16              `json:"schemaId"`
+	SchemaVersion uint16              `json:"schemaVersion"`
+	Broker        *zbc.BrokerProtocol `json:"broker,omitempty"`
+}
+
+// deploymentRecord is response to zbctl deploy as it's printed.
+type deploymentRecord struct {
+	Key         uint64            `json:"key"`
+	PartitionID uint16            `json:"partitionId"`
+	Topic       string            `json:"topic"`
+	State       string            `json:"state"`
+	Workflows   []workflowVersion `json:"workflows"`
+}
+
+type workflowVersion struct {
+	BpmnProcessId string `json:"bpmnProcessId"`
+	Version       int    `json:"version"`
+}
+
+func printDeployment(p *printer, response *zbc.Message) error {
+	deployment, err := response.DeploymentResponse()
+	if err != nil {
+		return err
+	}
+
+	log.Println(deployment.State)
+	if !deployment.Created() {
+		return errors.New(deployment.ErrorMessage)
+	}
+	record := &deploymentRecord{
+		Key:         deployment.DeploymentKey,
+		PartitionID: deployment.Partition,
+		Topic:       deployment.TopicName,
+		State:       deployment.State,
+		Workflows:   []workflowVersion{},
+	}
+	for _, workflow := range deployment.DeployedWorkflows {
+		record.Workflows = append(record.Workflows, workflowVersion{workflow.BpmnProcessId, workflow.Version})
+	}
+	return p.print(record, []string{strconv.FormatUint(record.Key, 10)}, func(w io.Writer) {
+		fmt.Fprintln(w, "BPMN PROCESS ID\tVERSION")
+		for _, workflow := range record.Workflows {
+			fmt.Fprintf(w, "%s\t%d\n", workflow.BpmnProcessId, workflow.Version)
+		}
+	})
+}
+
+// printTopology prints brokers of the cluster and leaders of all partitions.
+func printTopology(p *printer, topology *zbc.Topology) error {
+	var addrs []string
+	for _, broker := range topology.Brokers {
+		addrs = append(addrs, broker.String())
+	}
+	return p.print(topology, addrs, func(w io.Writer) {
+		fmt.Fprintln(w, "BROKER")
+		for _, addr := range addrs {
+			fmt.Fprintln(w, addr)
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, "TOPIC\tPARTITION\tLEADER")
+		for _, leader := range topology.TopicLeaders {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", leader.TopicName, leader.PartitionID, leader.BrokerAddress.String())
+		}
+	})
+}
+
+// printHealth prints every broker with its reachability followed by number of partitions with reachable leader.
+// Health has no keys, with --quiet only the exit code tells it.
+func printHealth(p *printer, health *zbc.Health) error {
+	return p.print(health, nil, func(w io.Writer) {
+		fmt.Fprintln(w, "BROKER\tREACHABLE\tERROR")
+		for _, broker := range health.Brokers {
+			fmt.Fprintf(w, "%s\t%t\t%s\n", broker.Address, broker.Reachable, broker.Error)
+		}
+		fmt.Fprintf(w, "\n%d of %d partitions have a reachable leader\n", health.ReachableLeaders, health.Partitions)
+	})
+}
+
+// printStats will print statistics of the partitions followed by backlog of every task type.
+func printStats(p *printer, stats []*zbc.PartitionStats) error {
+	var ids []string
+	for _, partition := range stats {
+		ids = append(ids, strconv.Itoa(int(partition.PartitionID)))
+	}
+	return p.print(stats, ids, func(w io.Writer) {
+		fmt.Fprintln(w, "PARTITION\tPOSITION\tEVENTS\tSUBSCRIBERS")
+		for _, partition := range stats {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%d\n", partition.PartitionID, partition.Position, partition.Events, partition.Subscribers)
+		}
+		fmt.Fprintln(w)
+
+		fmt.Fprintln(w, "TASK TYPE\tPARTITION\tWAITING\tLOCKED\tFAILED")
+		for _, partition := range stats {
+			var types []string
+			for taskType := range partition.Tasks {
+				types = append(types, taskType)
+			}
+			sort.Strings(types)
+
+			for _, taskType := range types {
+				backlog := partition.Tasks[taskType]
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", taskType, partition.PartitionID, backlog.Waiting, backlog.Locked, backlog.Failed)
+			}
+		}
+	})
+}
+
+func printInstances(p *printer, instances []*zbc.WorkflowInstanceSnapshot) error {
+	if instances == nil {
+		instances = []*zbc.WorkflowInstanceSnapshot{}
+	}
+	var keys []string
+	for _, instance := range instances {
+		keys = append(keys, strconv.FormatUint(instance.Key, 10))
+	}
+	return p.print(instances, keys, func(w io.Writer) {
+		fmt.Fprintln(w, "KEY\tPARTITION\tBPMN PROCESS ID\tVERSION\tACTIVITY\tSTATE")
+		for _, instance := range instances {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%d\t%s\t%s\n", instance.Key, instance.PartitionID, instance.BpmnProcessId, instance.Version, instance.ActivityID, instance.State)
+		}
+	})
+}
+
+func printTasks(p *printer, tasks []*zbc.TaskSnapshot) error {
+	if tasks == nil {
+		tasks = []*zbc.TaskSnapshot{}
+	}
+	var keys []string
+	for _, task := range tasks {
+		keys = append(keys, strconv.FormatUint(task.Key, 10))
+	}
+	return p.print(tasks, keys, func(w io.Writer) {
+		fmt.Fprintln(w, "KEY\tPARTITION\tTYPE\tSTATE\tRETRIES\tLOCK OWNER\tWORKFLOW INSTANCE")
+		for _, task := range tasks {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%d\t%s\t%d\n", task.Key, task.PartitionID, task.Type, task.State, task.Retries, task.LockOwner, task.WorkflowInstanceKey)
+		}
+	})
+}
+
+// incidentRecord is incident as it's printed by zbctl incidents list.
+type incidentRecord struct {
+	Key                 uint64 `json:"key"`
+	WorkflowInstanceKey int64  `json:"workflowInstanceKey"`
+	ActivityId          string `json:"activityId"`
+	ErrorType           string `json:"errorType"`
+	ErrorMessage        string `json:"errorMessage"`
+}
+
+func printIncidents(p *printer, incidents []*zbc.Message) error {
+	records := []*incidentRecord{}
+	var keys []string
+	for _, message := range incidents {
+		var incident zbc.Incident
+		if err := message.UnmarshalData(&incident); err != nil {
+			return err
+		}
+		key := (*message.SbeMessage).(*sbe.SubscribedEvent).Key
+		records = append(records, &incidentRecord{key, incident.WorkflowInstanceKey, incident.ActivityId, incident.ErrorType, incident.ErrorMessage})
+		keys = append(keys, strconv.FormatUint(key, 10))
+	}
+	return p.print(records, keys, func(w io.Writer) {
+		fmt.Fprintln(w, "KEY\tWORKFLOW INSTANCE\tACTIVITY\tERROR TYPE\tERROR MESSAGE")
+		for _, r := range records {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\n", r.Key, r.WorkflowInstanceKey, r.ActivityId, r.ErrorType, r.ErrorMessage)
+		}
+	})
+}
+
+func printWorkflows(p *printer, workflows []*zbc.Workflow) error {
+	var keys []string
+	for _, workflow := range workflows {
+		keys = append(keys, strconv.FormatUint(workflow.Key, 10))
+	}
+	return p.print(workflows, keys, func(w io.Writer) {
+		fmt.Fprintln(w, "BPMN PROCESS ID\tVERSION\tKEY\tDEPLOYMENT KEY")
+		for _, workflow := range workflows {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", workflow.BpmnProcessId, workflow.Version, workflow.Key, workflow.DeploymentKey)
+		}
+	})
+}
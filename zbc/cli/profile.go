@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+var errNoConfigFile = errors.New("Configuration comes from environment only, pass --config with the file to change")
+
+// profile is named set of settings in [profiles.<name>] of the configuration, e.g. brokers of one environment.
+// Settings which are set replace those of the configuration once the profile is selected.
+type profile struct {
+	Brokers []string `toml:"brokers" yaml:"brokers" json:"brokers"`
+	Broker  contact  `toml:"broker" yaml:"broker" json:"broker"`
+	Topic   string   `toml:"topic" yaml:"topic" json:"topic"`
+}
+
+// useProfile will apply settings of the profile to the configuration. Empty name keeps the configuration unchanged.
+func (cf *Config) useProfile(name string) error {
+	if len(name) == 0 {
+		return nil
+	}
+	p, ok := cf.Profiles[name]
+	if !ok {
+		return fmt.Errorf("Profile %s not found in configuration, known profiles are: %s", name, strings.Join(cf.profileNames(), ", "))
+	}
+	cf.Profile = name
+
+	// Brokers of the profile replace all brokers, whichever way they are given.
+	if len(p.Brokers) > 0 || len(p.Broker.Address) > 0 {
+		cf.Brokers = p.Brokers
+	}
+	b := p.Broker
+	if len(b.Address) > 0 {
+		cf.Broker.Address = b.Address
+	}
+	if len(b.Port) > 0 {
+		cf.Broker.Port = b.Port
+	}
+	if len(b.KeepAliveInterval) > 0 {
+		cf.Broker.KeepAliveInterval = b.KeepAliveInterval
+	}
+	if len(b.RequestTimeout) > 0 {
+		cf.Broker.RequestTimeout = b.RequestTimeout
+	}
+	if b.TLS != (tlsConfig{}) {
+		cf.Broker.TLS = b.TLS
+	}
+	if b.Socket != (socketConfig{}) {
+		cf.Broker.Socket = b.Socket
+	}
+	if len(p.Topic) > 0 {
+		cf.Topic = p.Topic
+	}
+	return nil
+}
+
+func (cf *Config) profileNames() []string {
+	var names []string
+	for name := range cf.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// configValue returns setting of zbctl config get: topic or profile.
+func configValue(cf *Config, key string) (string, error) {
+	switch key {
+	case "topic":
+		if len(cf.Topic) == 0 {
+			return "default-topic", nil
+		}
+		return cf.Topic, nil
+	case "profile":
+		return cf.Profile, nil
+	}
+	return "", fmt.Errorf("Unknown setting %s, use topic or profile", key)
+}
+
+// setConfigValue will write setting of zbctl config set into the configuration file. Topic is written into the profile,
+// if one is given. File is rewritten in its format, comments are not kept.
+func setConfigValue(path, profileName, key, value string) error {
+	if len(path) == 0 {
+		return errNoConfigFile
+	}
+
+	var keys []string
+	switch key {
+	case "topic":
+		if len(profileName) > 0 {
+			keys = []string{"profiles", profileName}
+		}
+		keys = append(keys, "topic")
+	case "profile":
+		keys = []string{"profile"}
+	default:
+		return fmt.Errorf("Unknown setting %s, use topic or profile", key)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var out []byte
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		m := make(map[interface{}]interface{})
+		if err := yaml.Unmarshal(content, &m); err != nil {
+			return err
+		}
+		node := m
+		for _, k := range keys[:len(keys)-1] {
+			child, ok := node[k].(map[interface{}]interface{})
+			if !ok {
+				child = make(map[interface{}]interface{})
+				node[k] = child
+			}
+			node = child
+		}
+		node[keys[len(keys)-1]] = value
+		out, err = yaml.Marshal(m)
+	case ".json":
+		m := make(map[string]interface{})
+		if len(content) > 0 {
+			if err := json.Unmarshal(content, &m); err != nil {
+				return err
+			}
+		}
+		setKey(m, keys, value)
+		out, err = json.MarshalIndent(m, "", "  ")
+	default:
+		m := make(map[string]interface{})
+		if _, err := toml.Decode(string(content), &m); err != nil {
+			return err
+		}
+		setKey(m, keys, value)
+		var buf bytes.Buffer
+		err = toml.NewEncoder(&buf).Encode(m)
+		out = buf.Bytes()
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, out, 0644)
+}
+
+// setKey will set value under path of nested keys, tables on the way are created if they are missing.
+func setKey(m map[string]interface{}, keys []string, value string) {
+	node := m
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := node[k].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			node[k] = child
+		}
+		node = child
+	}
+	node[keys[len(keys)-1]] = value
+}
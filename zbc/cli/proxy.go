@@ -0,0 +1,353 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// Direction of the frame in the capture.
+const (
+	toBroker byte = iota
+	toClient
+)
+
+var directionNames = map[byte]string{
+	toBroker: "client->broker",
+	toClient: "broker->client",
+}
+
+// msgpackFields are fields of SBE messages which hold message pack, they are logged decoded instead.
+var msgpackFields = map[string]bool{"Command": true, "Event": true, "Data": true}
+
+// record is one frame in the capture file. Capture file is a sequence of records, each of them is direction,
+// time in Unix nanoseconds and length of the frame followed by the frame as it was sent over the connection.
+type record struct {
+	direction byte
+	time      time.Time
+	frame     []byte
+}
+
+func writeRecord(w io.Writer, r *record) error {
+	var header [13]byte
+	header[0] = r.direction
+	binary.LittleEndian.PutUint64(header[1:], uint64(r.time.UnixNano()))
+	binary.LittleEndian.PutUint32(header[9:], uint32(len(r.frame)))
+	if _, err := w.Write(header[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(r.frame)
+	return err
+}
+
+func readRecord(rd io.Reader) (*record, error) {
+	var header [13]byte
+	if _, err := io.ReadFull(rd, header[:]); err != nil {
+		return nil, err
+	}
+	r := &record{
+		direction: header[0],
+		time:      time.Unix(0, int64(binary.LittleEndian.Uint64(header[1:]))),
+		frame:     make([]byte, binary.LittleEndian.Uint32(header[9:])),
+	}
+	if _, err := io.ReadFull(rd, r.frame); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// frameLog is a frame logged as JSON.
+type frameLog struct {
+	Time      time.Time `json:"time"`
+	Direction string    `json:"direction"`
+	decodedFrame
+}
+
+// decodedFrame holds headers and decoded message of a frame.
+type decodedFrame struct {
+	Frame           *protocol.FrameHeader           `json:"frame"`
+	Transport       *protocol.TransportHeader       `json:"transport,omitempty"`
+	RequestResponse *protocol.RequestResponseHeader `json:"requestResponse,omitempty"`
+	Sbe             *sbe.MessageHeader              `json:"sbe,omitempty"`
+	Message         map[string]interface{}          `json:"message,omitempty"`
+	Data            interface{}                     `json:"data,omitempty"`
+	Error           string                          `json:"error,omitempty"`
+}
+
+// capture logs frames of all connections as JSON lines to its output and writes them into the capture file, if there
+// is one.
+type capture struct {
+	mu   sync.Mutex
+	log  *json.Encoder
+	dump io.Writer
+}
+
+func newCapture(out, dump io.Writer) *capture {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	return &capture{log: encoder, dump: dump}
+}
+
+func (c *capture) frame(direction byte, r *zbc.MessageReader, headers *zbc.Headers, body *[]byte) {
+	entry := &frameLog{
+		Time:         time.Now(),
+		Direction:    directionNames[direction],
+		decodedFrame: decodeFrame(r, headers, body),
+	}
+
+	var frame []byte
+	if !headers.IsControlFrame() {
+		frame = encodeFrame(headers, *body)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.log.Encode(entry)
+	if c.dump != nil && frame != nil {
+		if err := writeRecord(c.dump, &record{direction: direction, time: entry.Time, frame: frame}); err != nil {
+			log.Printf("Writing capture failed: %s\n", err)
+		}
+	}
+}
+
+// decodeFrame returns headers of the frame and its message parsed by r.
+func decodeFrame(r *zbc.MessageReader, headers *zbc.Headers, body *[]byte) decodedFrame {
+	decoded := decodedFrame{
+		Frame:           headers.FrameHeader,
+		Transport:       headers.TransportHeader,
+		RequestResponse: headers.RequestResponseHeader,
+		Sbe:             headers.SbeMessageHeader,
+	}
+	if headers.IsControlFrame() {
+		return decoded
+	}
+
+	if msg, err := r.ParseMessage(headers, body); err != nil {
+		decoded.Error = err.Error()
+	} else if msg.SbeMessage != nil {
+		decoded.Message = messageFields(*msg.SbeMessage)
+		if msg.Data != nil {
+			decoded.Data = jsonValue("", *msg.Data)
+		}
+	}
+	return decoded
+}
+
+// encodeFrame returns the frame as it was sent, fragmented messages are encoded as one frame.
+func encodeFrame(headers *zbc.Headers, body []byte) []byte {
+	var b bytes.Buffer
+	fh := *headers.FrameHeader
+	fh.Flags &^= protocol.FrameFlagBegin | protocol.FrameFlagEnd
+	fh.Encode(&b)
+	headers.TransportHeader.Encode(&b)
+	if !headers.IsSingleMessage() {
+		headers.RequestResponseHeader.Encode(&b)
+	}
+	headers.SbeMessageHeader.Encode(&b, protocol.ByteOrder)
+	b.Write(body)
+	for b.Len()%8 != 0 {
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+// messageFields returns fields of the SBE message. Bytes are logged as text, except message pack which is logged decoded.
+func messageFields(message zbc.SBE) map[string]interface{} {
+	v := reflect.Indirect(reflect.ValueOf(message))
+	fields := make(map[string]interface{})
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		value := v.Field(i).Interface()
+		if b, ok := value.([]uint8); ok {
+			if msgpackFields[name] {
+				continue
+			}
+			value = string(b)
+		}
+		fields[name] = value
+	}
+	return fields
+}
+
+// jsonValue converts message pack data, so it can be encoded as JSON. Payloads are message pack too, they are decoded.
+func jsonValue(key string, v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[k] = jsonValue(k, item)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			name, _ := k.(string)
+			m[name] = jsonValue(name, item)
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = jsonValue("", item)
+		}
+		return items
+	case []byte:
+		var payload interface{}
+		if key == "payload" && msgpack.Unmarshal(value, &payload) == nil {
+			return jsonValue("", payload)
+		}
+		return value
+	}
+	return v
+}
+
+// pipe forwards everything read from src to dst and logs a copy of it frame by frame. If the frames cannot be decoded,
+// the rest of the connection is forwarded without logging.
+func pipe(src, dst net.Conn, direction byte, c *capture) {
+	r := zbc.NewMessageReader(bufio.NewReaderSize(io.TeeReader(src, dst), 20000))
+	for {
+		headers, body, err := r.ReadHeaders()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			if _, ok := err.(net.Error); ok {
+				return
+			}
+			log.Printf("Decoding %s frames failed, forwarding without logging: %s\n", directionNames[direction], err)
+			io.Copy(dst, src)
+			return
+		}
+		c.frame(direction, r, headers, body)
+	}
+}
+
+func proxyConnection(conn net.Conn, target string, c *capture) {
+	defer conn.Close()
+
+	broker, err := net.Dial("tcp", target)
+	if err != nil {
+		log.Printf("Connecting to %s failed: %s\n", target, err)
+		return
+	}
+	defer broker.Close()
+	log.Printf("Proxying %s to %s\n", conn.RemoteAddr(), target)
+
+	// Once one side closes, closing both connections stops the other direction too.
+	done := make(chan struct{}, 2)
+	go func() {
+		pipe(conn, broker, toBroker, c)
+		done <- struct{}{}
+	}()
+	go func() {
+		pipe(broker, conn, toClient, c)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// runProxy will forward connections accepted on listen to target and log their frames to w. Frames are written into
+// the dump file too, unless it is empty.
+func runProxy(w io.Writer, listen, target, dump string) error {
+	var out io.Writer
+	if len(dump) > 0 {
+		f, err := os.Create(dump)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	c := newCapture(w, out)
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+	log.Printf("Listening on %s\n", listener.Addr())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go proxyConnection(conn, target, c)
+	}
+}
+
+// replay will send frames which client sent in the capture to target and log them together with the responses to w.
+// Connection is closed once no response arrives for wait.
+func replay(w io.Writer, path, target string, wait time.Duration) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	conn, err := net.Dial("tcp", target)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	c := newCapture(w, nil)
+	received := make(chan struct{}, 1)
+	go func() {
+		r := zbc.NewMessageReader(bufio.NewReaderSize(conn, 20000))
+		for {
+			headers, body, err := r.ReadHeaders()
+			if err != nil {
+				return
+			}
+			c.frame(toClient, r, headers, body)
+			select {
+			case received <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	rd := bufio.NewReader(f)
+	for {
+		rec, err := readRecord(rd)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if rec.direction != toBroker {
+			continue
+		}
+
+		if _, err := conn.Write(rec.frame); err != nil {
+			return err
+		}
+		r := zbc.NewMessageReader(bufio.NewReaderSize(bytes.NewReader(rec.frame), len(rec.frame)))
+		if headers, body, err := r.ReadHeaders(); err == nil {
+			c.frame(toBroker, r, headers, body)
+		}
+	}
+
+	for {
+		select {
+		case <-received:
+		case <-time.After(wait):
+			return nil
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var (
+	errSubscriptionClosed = errors.New("Subscription was closed, no more events are received")
+	errInvalidFilter      = errors.New("Filter must be a path like .event.state, optionally compared to JSON value, e.g. .event.state == \"CREATED\"")
+)
+
+// tailRecord is one line printed by zbctl tail.
+type tailRecord struct {
+	Key         uint64      `json:"key"`
+	Position    uint64      `json:"position"`
+	PartitionID uint16      `json:"partitionId"`
+	Topic       string      `json:"topic"`
+	Type        string      `json:"type"`
+	Event       interface{} `json:"event"`
+}
+
+// eventFilter is --filter expression of zbctl tail. It is a path into the printed record, e.g. .event.headers.bpmnProcessId,
+// optionally followed by comparison with JSON value. Path alone passes records where the value is set and isn't false.
+type eventFilter struct {
+	path  []string
+	op    string
+	value interface{}
+}
+
+var filterOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseFilter(expr string) (*eventFilter, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, ".") {
+		return nil, errInvalidFilter
+	}
+
+	end := strings.IndexAny(expr, " =!<>")
+	if end < 0 {
+		end = len(expr)
+	}
+	f := &eventFilter{}
+	if path := expr[1:end]; len(path) > 0 {
+		f.path = strings.Split(path, ".")
+	}
+
+	rest := strings.TrimSpace(expr[end:])
+	if len(rest) == 0 {
+		return f, nil
+	}
+	for _, op := range filterOperators {
+		if strings.HasPrefix(rest, op) {
+			f.op = op
+			break
+		}
+	}
+	if len(f.op) == 0 {
+		return nil, errInvalidFilter
+	}
+
+	value, err := decodeJSON([]byte(strings.TrimSpace(rest[len(f.op):])))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid value in filter %s: %s", expr, err)
+	}
+	f.value = value
+	return f, nil
+}
+
+// matches tells if the record, decoded from JSON, passes the filter.
+func (f *eventFilter) matches(record interface{}) bool {
+	v := record
+	for _, name := range f.path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			v = nil
+			break
+		}
+		v = m[name]
+	}
+
+	switch f.op {
+	case "":
+		return v != nil && v != false
+	case "==":
+		return equalJSON(v, f.value)
+	case "!=":
+		return !equalJSON(v, f.value)
+	}
+
+	a, ok := v.(json.Number)
+	b, ok2 := f.value.(json.Number)
+	if !ok || !ok2 {
+		return false
+	}
+	x, err := a.Float64()
+	y, err2 := b.Float64()
+	if err != nil || err2 != nil {
+		return false
+	}
+	switch f.op {
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	default:
+		return x >= y
+	}
+}
+
+// equalJSON compares decoded JSON values, numbers are equal if they have the same value.
+func equalJSON(a, b interface{}) bool {
+	if x, ok := a.(json.Number); ok {
+		if y, ok := b.(json.Number); ok && x != y {
+			fx, err := x.Float64()
+			fy, err2 := y.Float64()
+			return err == nil && err2 == nil && fx == fy
+		}
+	}
+	ja, err := json.Marshal(a)
+	jb, err2 := json.Marshal(b)
+	return err == nil && err2 == nil && bytes.Equal(ja, jb)
+}
+
+// decodeJSON decodes value keeping numbers as json.Number, so large keys don't lose precision.
+func decodeJSON(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// startPosition parses --from of zbctl tail: tail, head or position of the first event.
+func startPosition(from string) (int64, error) {
+	switch from {
+	case "tail":
+		return zbc.TailPosition, nil
+	case "head":
+		return 0, nil
+	}
+	position, err := strconv.ParseInt(from, 10, 64)
+	if err != nil || position < 0 {
+		return 0, fmt.Errorf("Invalid start position %s, use tail, head or position of the event", from)
+	}
+	return position, nil
+}
+
+// tailTopic will print every event of the partition passing all filters as one line of JSON to w. It returns
+// errSubscriptionClosed once the subscription is closed, e.g. connection to the broker is lost. Events are
+// acknowledged in batches, so the broker keeps pushing them.
+func tailTopic(client *zbc.Client, w io.Writer, topic string, partitionID int32, from int64, filters []*eventFilter) error {
+	const prefetch = 32
+	sub, err := client.OpenTopicSubscription(&zbc.TopicSubscription{
+		TopicName:        topic,
+		PartitionID:      partitionID,
+		Name:             fmt.Sprintf("zbctl-tail-%d", time.Now().UnixNano()),
+		StartPosition:    from,
+		PrefetchCapacity: prefetch,
+		ForceStart:       true,
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	encoder := json.NewEncoder(w)
+	unacknowledged := 0
+	for message := range sub.Events() {
+		event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+		record := &tailRecord{
+			Key:         event.Key,
+			Position:    event.Position,
+			PartitionID: event.PartitionId,
+			Topic:       string(event.TopicName),
+			Type:        zbc.EventTypeName(event.EventType),
+		}
+		if message.Data != nil {
+			record.Event = jsonValue("", *message.Data)
+		}
+
+		if passes, err := passesFilters(record, filters); err != nil {
+			return err
+		} else if passes {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+
+		if unacknowledged++; unacknowledged >= prefetch/2 {
+			if _, err := client.AcknowledgeTopicEvent(event); err != nil {
+				return err
+			}
+			unacknowledged = 0
+		}
+	}
+	return errSubscriptionClosed
+}
+
+func passesFilters(record *tailRecord, filters []*eventFilter) (bool, error) {
+	if len(filters) == 0 {
+		return true, nil
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	v, err := decodeJSON(b)
+	if err != nil {
+		return false, err
+	}
+	for _, f := range filters {
+		if !f.matches(v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
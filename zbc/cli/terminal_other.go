@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package cli
+
+import "errors"
+
+// makeRaw is not supported on this platform, console reads whole lines without completion.
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("Raw mode of the terminal is not supported")
+}
@@ -2,140 +2,539 @@ package zbc
 
 import (
 	"bufio"
-	"bytes"
+	"context"
+	"crypto/tls"
 	"errors"
-	"log"
 	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zeebe-io/zbc-go/zbc/sbe"
 )
 
-// RequestTimeout specifies default timeout for Responder.
+// DefaultMaxFrameLength specifies length of the longest frame sent by Client. Longer messages, e.g. big deployments, are fragmented.
+const DefaultMaxFrameLength = 1024 * 1024
+
+// DefaultMaxMessageLength specifies length of the longest message received by Client. Longer frames are discarded.
+const DefaultMaxMessageLength = 64 * 1024 * 1024
+
+// DefaultBatchWindow specifies number of requests CreateTasks keeps in flight at once.
+const DefaultBatchWindow = 64
+
+// RequestTimeout specifies default timeout for Responder in seconds. It can be changed with Client.SetRequestTimeout.
 const RequestTimeout = 5
 
 var (
-	errTimeout     = errors.New("Request timeout")
-	errSocketWrite = errors.New("Tried to write more bytes to socket")
+	errSocketWrite  = errors.New("Tried to write more bytes to socket")
+	errMessageBuild = errors.New("Cannot construct message")
+	errNoSeeds      = errors.New("No broker address given")
 )
 
+// RequestOption changes how a single request is executed.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	timeout    time.Duration
+	codec      Codec
+	requestKey string
+}
+
+// TimeoutOption will override request timeout of the Client for one request. ErrRequestTimeout is returned once it passes.
+func TimeoutOption(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// requestContext returns context which expires after request timeout of the client or the one given by TimeoutOption.
+// Codec given by CodecOption and request key given by RequestKeyOption are carried by the context.
+func (c *Client) requestContext(opts ...RequestOption) (context.Context, context.CancelFunc) {
+	options := requestOptions{timeout: c.RequestTimeout()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	ctx := context.Background()
+	if options.codec != nil {
+		ctx = ContextWithCodec(ctx, options.codec)
+	}
+	if len(options.requestKey) > 0 {
+		ctx = ContextWithRequestKey(ctx, options.requestKey)
+	}
+	return context.WithTimeout(ctx, options.timeout)
+}
+
+// RequestTimeout is a getter for time after which requests without deadline are aborted.
+func (c *Client) RequestTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.requestTimeout))
+}
+
+// SetRequestTimeout is a setter for time after which requests without deadline are aborted.
+func (c *Client) SetRequestTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&c.requestTimeout, int64(timeout))
+}
+
 // Client for one Zeebe broker
 type Client struct {
-	conn          net.Conn
-	transactions  map[uint64]chan *Message
-	subscriptions map[uint64]chan *Message
+	requestID      uint64 // Last assigned request ID. Accessed atomically, kept first for 64-bit alignment.
+	lastReceived   int64  // Unix time in nanoseconds when last frame was received. Accessed atomically.
+	requestTimeout int64  // Default request timeout as time.Duration. Accessed atomically.
+	maxFrameLength int64  // Messages longer than this are fragmented. Accessed atomically.
+	maxMessageLen  int64  // Received messages longer than this are discarded. Accessed atomically.
+	dedupWindow    int64  // Deduplication window of commands with request key as time.Duration. Accessed atomically.
+	frameTimeout   int64  // Time in which started frame must be received as time.Duration. Accessed atomically.
+	batchWindow    int64  // Requests of CreateTasks in flight at once. Accessed atomically.
+	shutdown       int32  // Set once Close is called. Accessed atomically.
+	closing        int32  // Set once Close stops accepting new requests. Accessed atomically.
+
+	addr            string
+	tlsConfig       *tls.Config
+	socketOptions   SocketOptions
+	dialer          DialerFunc
+	readBufferSize  int
+	conn            net.Conn
+	reconnectPolicy *ReconnectPolicy
+	keepAlive       int64         // Keep alive interval as time.Duration. Accessed atomically.
+	done            chan struct{} // Closed once receiver gives up.
+	observer        atomic.Value  // Holds observerHolder set by SetObserver.
+	logger          atomic.Value  // Holds loggerHolder set by SetLogger.
+	interceptors    atomic.Value  // Holds []Interceptor added by Use. Written under mu.
+
+	writes       *frameWriter             // Writes frames to conn, started by newConnection.
+	txMu         sync.Mutex               // Guards transactions.
+	transactions map[uint64]chan *Message // Pending requests by request ID.
+	sent         sentCommands             // Commands with request key in deduplication window.
+
+	lifetime   context.Context    // Done once Close starts tearing the connection down.
+	stop       context.CancelFunc // Ends lifetime.
+	lifetimeMu sync.Mutex         // Orders spawn with stop, so no goroutine is started once Close waits for them.
+	goroutines sync.WaitGroup     // Goroutines started by spawn, waited for by Close.
+
+	mu                  sync.Mutex // Guards conn, reconnectPolicy, subscriptions, topology and workers.
+	subscriptions       map[uint64]*Subscription
+	openedSubscriptions []*Subscription
+	topology            *Topology
+	seeds               []string  // Addresses of known brokers, updated from topology.
+	workers             []stopper // Started workers and topic workers, stopped by Close.
+	partitionSelector   PartitionSelector
+	tracer              Tracer
+	codec               Codec
+	retryPolicy         *RetryPolicy
+	rateLimiter         *rateLimiter
+	taskQueue           *TaskQueue
+	validator           Validator
+	credentials         *credentials // Token injected into requests or preamble of connections, nil if there is none.
+
+	pool *BrokerPool // Connections to brokers in the cluster. Nil for connections owned by the pool.
 }
 
-func (c *Client) sender(message *Message) error {
-	writer := NewMessageWriter(message)
-	byteBuff := &bytes.Buffer{}
-	writer.Write(byteBuff)
+func (c *Client) connection() net.Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn
+}
 
-	n, err := c.conn.Write(byteBuff.Bytes())
-	if err != nil {
-		return err
+// replaceConnection will set the new connection unless lifetime of the client has ended, then the connection is closed.
+// Close ends lifetime before it closes the current connection, so no connection is left open either way.
+func (c *Client) replaceConnection(conn net.Conn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lifetime.Err() != nil {
+		conn.Close()
+		return false
+	}
+	c.conn = conn
+	return true
+}
+
+// Pool returns BrokerPool with connections to brokers of the cluster.
+func (c *Client) Pool() *BrokerPool {
+	return c.pool
+}
+
+// MaxFrameLength is a getter for length of the longest frame sent to the broker. Longer messages are fragmented.
+func (c *Client) MaxFrameLength() int {
+	return int(atomic.LoadInt64(&c.maxFrameLength))
+}
+
+// MaxMessageLength is a getter for length of the longest message received from the broker.
+func (c *Client) MaxMessageLength() int {
+	return int(atomic.LoadInt64(&c.maxMessageLen))
+}
+
+// SetMaxMessageLength is a setter for length of the longest message received from the broker. Longer messages are
+// discarded, requests waiting for them time out. Zero disables the check.
+func (c *Client) SetMaxMessageLength(length int) {
+	atomic.StoreInt64(&c.maxMessageLen, int64(length))
+}
+
+// FrameTimeout is a getter for time in which rest of the frame must be received once its header arrives.
+func (c *Client) FrameTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.frameTimeout))
+}
+
+// SetFrameTimeout is a setter for time in which rest of the frame must be received once its header arrives. Frame
+// which stalls longer breaks the connection, which is then reconnected. Zero disables it, which is the default.
+func (c *Client) SetFrameTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&c.frameTimeout, int64(timeout))
+}
+
+// BatchWindow is a getter for number of requests CreateTasks keeps in flight at once.
+func (c *Client) BatchWindow() int {
+	return int(atomic.LoadInt64(&c.batchWindow))
+}
+
+// SetBatchWindow is a setter for number of requests CreateTasks keeps in flight at once. Values below 1 are ignored.
+func (c *Client) SetBatchWindow(window int) {
+	if window > 0 {
+		atomic.StoreInt64(&c.batchWindow, int64(window))
 	}
+}
+
+// SetMaxFrameLength is a setter for length of the longest frame sent to the broker. Zero disables fragmentation.
+func (c *Client) SetMaxFrameLength(length int) {
+	atomic.StoreInt64(&c.maxFrameLength, int64(length))
+}
 
-	if n != len(byteBuff.Bytes()) {
-		return errSocketWrite
+// SetReconnectPolicy is a setter for ReconnectPolicy. Setting it to nil will disable reconnecting.
+func (c *Client) SetReconnectPolicy(policy *ReconnectPolicy) {
+	c.mu.Lock()
+	c.reconnectPolicy = policy
+	c.mu.Unlock()
+}
+
+func (c *Client) getReconnectPolicy() *ReconnectPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reconnectPolicy
+}
+
+// nextRequestID will return ID which is unique among requests sent by this client.
+func (c *Client) nextRequestID() uint64 {
+	return atomic.AddUint64(&c.requestID, 1)
+}
+
+// addTransaction will register request waiting for response. Closing flag is checked under the lock, so Close
+// either sees the request as pending or the request is rejected.
+func (c *Client) addTransaction(requestID uint64) (chan *Message, error) {
+	respCh := make(chan *Message, 1)
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if atomic.LoadInt32(&c.closing) == 1 {
+		return nil, ErrClientClosed
 	}
-	return nil
+	c.transactions[requestID] = respCh
+	return respCh, nil
+}
+
+func (c *Client) removeTransaction(requestID uint64) {
+	c.txMu.Lock()
+	delete(c.transactions, requestID)
+	c.txMu.Unlock()
+}
+
+// dispatch will hand over the response to the request waiting for it. Responses nobody waits for anymore are dropped.
+func (c *Client) dispatch(requestID uint64, message *Message) {
+	c.txMu.Lock()
+	respCh, ok := c.transactions[requestID]
+	delete(c.transactions, requestID)
+	c.txMu.Unlock()
+
+	if ok {
+		respCh <- message
+	}
+}
+
+func (c *Client) sender(message *Message) error {
+	return c.senderCtx(context.Background(), message)
 }
 
+func (c *Client) senderCtx(ctx context.Context, message *Message) error {
+	writer := NewMessageWriter(message)
+	byteBuff := acquireBuffer()
+	defer releaseBuffer(byteBuff)
+	writer.WriteFragments(byteBuff, c.MaxFrameLength())
+
+	deadline, _ := ctx.Deadline()
+	return c.writes.write(byteBuff.Bytes(), deadline, c.lifetime.Done())
+}
+
+// receiver is the only goroutine reading from the connection. Responses are dispatched to the requests waiting for
+// them and events to their subscriptions. Broken connection is reconnected here, so frameWriter keeps writing to
+// whatever connection is current.
 func (c *Client) receiver() {
+	defer close(c.done)
 	for {
-		buffer := bufio.NewReaderSize(c.conn, 20000)
-		r := NewMessageReader(buffer)
-		headers, tail, err := r.ReadHeaders()
+		err := c.receive(c.connection())
+		if atomic.LoadInt32(&c.closing) == 1 {
+			return
+		}
+		c.log().Warn("Connection broken", F("addr", c.addr), F("error", err))
 
-		if err != nil {
-			log.Printf("[R] Error %+#v\n", err)
-			continue
+		if err := c.reconnect(); err == ErrClientClosed {
+			return
+		} else if err != nil {
+			c.log().Error("Giving up on connection", F("addr", c.addr), F("error", err))
+			return
 		}
-		message, err := r.ParseMessage(headers, tail)
+	}
+}
 
-		if err != nil && !headers.IsSingleMessage() {
-			// TODO: Maybe we should panic here?
-			delete(c.transactions, headers.RequestResponseHeader.RequestID)
-			continue
+// receive will read messages from the connection until socket breaks.
+func (c *Client) receive(conn net.Conn) error {
+	buffer := bufio.NewReaderSize(conn, c.readBufferSize)
+	r := NewMessageReader(buffer)
+	r.Conn = conn
+
+	for {
+		// Heartbeat makes broker respond at least once per interval, so silence means the connection is dead.
+		r.IdleTimeout = keepAliveTimeoutFactor * c.KeepAliveInterval()
+		r.FrameTimeout = c.FrameTimeout()
+		r.MaxMessageLength = uint32(c.MaxMessageLength())
+		message, err := r.ReadMessage()
+
+		if isConnectionError(err) {
+			conn.Close()
+			return err
 		}
+		atomic.StoreInt64(&c.lastReceived, time.Now().UnixNano())
 
-		if !headers.IsSingleMessage() && message != nil {
-			c.transactions[headers.RequestResponseHeader.RequestID] <- message
+		if err != nil {
+			c.log().Warn("Reading message failed", F("addr", c.addr), F("error", err))
+			if message != nil && !message.Headers.IsSingleMessage() {
+				if Cause(err) == ErrUnsupportedSchemaVersion {
+					// Request fails with the schema of the response rather than waiting until it times out.
+					c.dispatch(message.Headers.RequestResponseHeader.RequestID, message)
+				} else {
+					c.removeTransaction(message.Headers.RequestResponseHeader.RequestID)
+				}
+			}
 			continue
 		}
 
-		if err != nil && headers.IsSingleMessage() {
-			// TODO: close and delete subscription
+		headers := message.Headers
+		if headers.IsControlFrame() {
 			continue
 		}
 
-		if headers.IsSingleMessage() && message != nil {
-			subscriberKey := (*message.SbeMessage).(*sbe.SubscribedEvent).SubscriberKey
-			c.subscriptions[subscriberKey] <- message
+		if !headers.IsSingleMessage() {
+			c.dispatch(headers.RequestResponseHeader.RequestID, message)
 			continue
 		}
 
+		// Subscriptions expect subscribed events only, anything else pushed by the broker is dropped.
+		if message.SbeMessage != nil {
+			event, ok := (*message.SbeMessage).(*sbe.SubscribedEvent)
+			if !ok {
+				c.log().Warn("Received unexpected message", F("addr", c.addr), F("headers", headers))
+				continue
+			}
+			c.mu.Lock()
+			sub, ok := c.subscriptions[event.SubscriberKey]
+			c.mu.Unlock()
+
+			if ok {
+				c.observeEvent(event)
+				c.interceptEvent(message, sub.deliver)
+			}
+		}
 	}
 }
 
 // Responder implements synchronous way of sending ExecuteCommandRequest and waiting for ExecuteCommandResponse.
-func (c *Client) Responder(message *Message) (*Message, error) {
-	respCh := make(chan *Message)
-	c.transactions[message.Headers.RequestResponseHeader.RequestID] = respCh
+func (c *Client) Responder(message *Message, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.ResponderCtx(ctx, message)
+}
 
-	if err := c.sender(message); err != nil {
-		return nil, err
-	}
+// ResponderCtx is same as Responder, but it gives up waiting for the response once ctx is done.
+// Deadline of ctx is also used as write deadline of the socket. It is safe to call it from many goroutines,
+// every request gets its own request ID and responses are matched by it.
+func (c *Client) ResponderCtx(ctx context.Context, message *Message) (*Message, error) {
+	return c.intercept(ctx, message, c.observedRespond)
+}
 
-	select {
-	case resp := <-c.transactions[message.Headers.RequestResponseHeader.RequestID]:
-		delete(c.transactions, message.Headers.RequestResponseHeader.RequestID)
-		return resp, nil
-	case <-time.After(time.Second * RequestTimeout):
-		delete(c.transactions, message.Headers.RequestResponseHeader.RequestID)
-		return nil, errTimeout
+func (c *Client) observedRespond(ctx context.Context, message *Message) (*Message, error) {
+	done := c.observeRequest(message)
+	resp, err := c.respond(ctx, message)
+	done(err)
+	return resp, err
+}
+
+func (c *Client) respond(ctx context.Context, message *Message) (*Message, error) {
+	requestID, respCh, err := c.send(ctx, message)
+	if err != nil {
+		return nil, err
 	}
+	return c.await(ctx, requestID, respCh)
 }
 
-// TaskConsumer opens a subscription on task and returns a channel where all the SubscribedEvents will arrive.
-func (c *Client) TaskConsumer(ts *TaskSubscription) (chan *Message, error) {
-	subscriptionCh := make(chan *Message, ts.Credits)
-	msg := NewTaskSubscriptionMessage(ts)
+// send will write the request to the socket and return channel where its response will arrive.
+func (c *Client) send(ctx context.Context, message *Message) (uint64, chan *Message, error) {
+	message, err := c.authorize(ctx, message)
+	if err != nil {
+		return 0, nil, err
+	}
 
-	response, err := c.Responder(msg)
+	requestID := c.nextRequestID()
+	message.Headers.RequestResponseHeader.RequestID = requestID
+	respCh, err := c.addTransaction(requestID)
 	if err != nil {
-		log.Println(err)
-		return nil, err
+		return 0, nil, err
+	}
+
+	if err := c.senderCtx(ctx, message); err != nil {
+		c.removeTransaction(requestID)
+		return 0, nil, err
 	}
-	c.subscriptions[(*response.Data)["subscriberKey"].(uint64)] = subscriptionCh
+	return requestID, respCh, nil
+}
 
-	return subscriptionCh, nil
+// await will wait for the response of the request sent by send.
+func (c *Client) await(ctx context.Context, requestID uint64, respCh chan *Message) (*Message, error) {
+	select {
+	case resp := <-respCh:
+		if resp.SbeMessage == nil {
+			if header := resp.Headers.SbeMessageHeader; header != nil {
+				if err := checkSchema(header); err != nil {
+					return nil, err
+				}
+			}
+			return nil, errUnexpectedResponse
+		}
+		if errResp, ok := (*resp.SbeMessage).(*sbe.ErrorResponse); ok {
+			return nil, newBrokerError(errResp)
+		}
+		return resp, nil
+	case <-ctx.Done():
+		c.removeTransaction(requestID)
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, ErrRequestTimeout
+		}
+		return nil, ctx.Err()
+	case <-c.lifetime.Done():
+		// Close waits for pending requests before it ends lifetime, so only requests without response are left.
+		c.removeTransaction(requestID)
+		return nil, ErrClientClosed
+	}
 }
 
 // Connect will spinoff receiver in goroutine, which will make client effectively ready to communicate with the broker.
 func (c *Client) Connect() {
-	go c.receiver()
+	c.spawn(c.receiver)
+}
+
+// spawn will run f in a goroutine which Close waits for, unless lifetime of the client has ended already. Functions
+// which block must return once lifetime is done.
+func (c *Client) spawn(f func()) bool {
+	c.lifetimeMu.Lock()
+	defer c.lifetimeMu.Unlock()
+	if c.lifetime.Err() != nil {
+		return false
+	}
+
+	c.goroutines.Add(1)
+	go func() {
+		defer c.goroutines.Done()
+		f()
+	}()
+	return true
 }
 
 // NewClient is constructor for Client structure. It will resolve IP address and dial the provided tcp address.
-func NewClient(addr string) (*Client, error) {
-	tcpAddr, wrongAddr := net.ResolveTCPAddr("tcp4", addr) // TODO: support IPv6 and TLS
-	if wrongAddr != nil {
-		return nil, wrongAddr
+// Options are applied before dialing, see ClientOption.
+func NewClient(addr string, opts ...ClientOption) (*Client, error) {
+	return newClient(addr, opts)
+}
+
+// NewClientTLS is constructor for Client structure which communicates with the broker over TLS.
+// It's same as NewClient with WithTLS option.
+func NewClientTLS(addr string, tlsConfig *tls.Config, opts ...ClientOption) (*Client, error) {
+	return newClient(addr, append(opts[:len(opts):len(opts)], WithTLS(tlsConfig)))
+}
+
+// NewClusterClient is constructor for Client which connects to the first of the seed brokers it can reach. Other brokers
+// of the cluster are discovered through topology. Client keeps connection to the seed it bootstrapped from, reconnects
+// go to the same broker.
+func NewClusterClient(seeds []string, opts ...ClientOption) (*Client, error) {
+	return newClusterClient(seeds, opts)
+}
+
+// NewClusterClientTLS is same as NewClusterClient, but communicates with the brokers over TLS.
+func NewClusterClientTLS(seeds []string, tlsConfig *tls.Config, opts ...ClientOption) (*Client, error) {
+	return newClusterClient(seeds, append(opts[:len(opts):len(opts)], WithTLS(tlsConfig)))
+}
+
+func newClusterClient(seeds []string, opts []ClientOption) (*Client, error) {
+	err := errNoSeeds
+	for _, addr := range seeds {
+		var c *Client
+		if c, err = newClient(addr, opts); err == nil {
+			c.mu.Lock()
+			c.seeds = append([]string(nil), seeds...)
+			c.mu.Unlock()
+			return c, nil
+		}
 	}
+	return nil, err
+}
+
+// Seeds returns addresses of brokers known to the client. They are the seeds given to the constructor until topology
+// is received, then addresses of brokers in the topology. They can be used to bootstrap another Client.
+func (c *Client) Seeds() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.seeds...)
+}
 
-	conn, err := net.DialTCP("tcp", nil, tcpAddr)
+func newClient(addr string, opts []ClientOption) (*Client, error) {
+	c, err := newConnection(addr, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	c.pool = newBrokerPool(c)
+	c.spawn(c.pool.healthCheck)
+	return c, nil
+}
+
+// newConnection will apply the options, dial the broker and start receiving on the connection.
+func newConnection(addr string, opts []ClientOption) (*Client, error) {
+	policy := DefaultReconnectPolicy
 	c := &Client{
-		conn,
-		make(map[uint64]chan *Message),
-		make(map[uint64]chan *Message),
+		addr:              addr,
+		reconnectPolicy:   &policy,
+		readBufferSize:    DefaultReadBufferSize,
+		keepAlive:         int64(DefaultKeepAliveInterval),
+		requestTimeout:    int64(time.Second * RequestTimeout),
+		maxFrameLength:    DefaultMaxFrameLength,
+		maxMessageLen:     DefaultMaxMessageLength,
+		dedupWindow:       int64(DefaultDeduplicationWindow),
+		batchWindow:       DefaultBatchWindow,
+		done:              make(chan struct{}),
+		writes:            newFrameWriter(),
+		transactions:      make(map[uint64]chan *Message),
+		subscriptions:     make(map[uint64]*Subscription),
+		partitionSelector: NewRoundRobinSelector(),
+		seeds:             []string{addr},
+	}
+	c.lifetime, c.stop = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	conn, err := c.dialBroker()
+	if err != nil {
+		c.stop()
+		return nil, err
 	}
+	c.conn = conn
+	c.spawn(func() {
+		c.writes.run(c.writeBatch, c.lifetime.Done())
+	})
 	c.Connect()
+	c.spawn(c.heartbeat)
 
 	return c, nil
 }
@@ -0,0 +1,61 @@
+package zbc
+
+import (
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// Template is SBE message which knows fields of its message header. All messages of the sbe package implement it.
+type Template interface {
+	SBE
+	SbeBlockLength() uint16
+	SbeTemplateId() uint16
+	SbeSchemaId() uint16
+	SbeSchemaVersion() uint16
+}
+
+// NewResponseMessage is constructor for Message which answers request with given ID, e.g. by *sbe.ExecuteCommandResponse,
+// *sbe.ControlMessageResponse or *sbe.ErrorResponse. It is what broker sends, so mock brokers and proxies can be built
+// on the same codec as the Client.
+func NewResponseMessage(requestID uint64, response Template) (*Message, error) {
+	requestResponse := protocol.NewRequestResponseHeader()
+	requestResponse.RequestID = requestID
+	return newMessage(response, requestResponse)
+}
+
+// NewEventMessage is constructor for Message which pushes the event to subscription with its SubscriberKey.
+func NewEventMessage(event *sbe.SubscribedEvent) (*Message, error) {
+	return newMessage(event, nil)
+}
+
+// newMessage will set headers of the message for the body. Message without requestResponse is single message.
+func newMessage(body Template, requestResponse *protocol.RequestResponseHeader) (*Message, error) {
+	buffer := acquireBuffer()
+	defer releaseBuffer(buffer)
+	if err := body.Encode(buffer, protocol.ByteOrder, false); err != nil {
+		return nil, err
+	}
+
+	var headers Headers
+	headers.SetSbeMessageHeader(&sbe.MessageHeader{
+		BlockLength: body.SbeBlockLength(),
+		TemplateId:  body.SbeTemplateId(),
+		SchemaId:    body.SbeSchemaId(),
+		Version:     body.SbeSchemaVersion(),
+	})
+
+	length := uint32(TransportHeaderSize + SBEMessageHeaderSize + buffer.Len())
+	if requestResponse != nil {
+		headers.SetRequestResponseHeader(requestResponse)
+		headers.SetTransportHeader(protocol.NewTransportHeader(protocol.RequestResponse))
+		length += RequestResponseHeaderSize
+	} else {
+		headers.SetTransportHeader(protocol.NewTransportHeader(protocol.FullDuplexSingleMessage))
+	}
+	headers.SetFrameHeader(protocol.NewFrameHeader(length, 0, 0, 0, 2))
+
+	var msg Message
+	msg.SetHeaders(&headers)
+	msg.SetSbeMessage(body)
+	return &msg, nil
+}
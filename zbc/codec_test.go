@@ -0,0 +1,97 @@
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func roundTrip(t *testing.T, msg *Message) *Message {
+	buffer := &bytes.Buffer{}
+	NewMessageWriter(msg).Write(buffer)
+
+	r := NewMessageReader(bufio.NewReader(buffer))
+	headers, tail, err := r.ReadHeaders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := r.ParseMessage(headers, tail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(*msg.SbeMessage, *decoded.SbeMessage) {
+		t.Fatalf("Expected %+v, decoded %+v", *msg.SbeMessage, *decoded.SbeMessage)
+	}
+	if !reflect.DeepEqual(msg.Headers.SbeMessageHeader, decoded.Headers.SbeMessageHeader) {
+		t.Fatalf("Expected header %+v, decoded %+v", msg.Headers.SbeMessageHeader, decoded.Headers.SbeMessageHeader)
+	}
+	if msg.Headers.IsSingleMessage() != decoded.Headers.IsSingleMessage() {
+		t.Fatal("Single message decoded as request response or vice versa")
+	}
+	if !msg.Headers.IsSingleMessage() && msg.Headers.RequestResponseHeader.RequestID != decoded.Headers.RequestResponseHeader.RequestID {
+		t.Fatalf("Expected request ID %d, decoded %d", msg.Headers.RequestResponseHeader.RequestID, decoded.Headers.RequestResponseHeader.RequestID)
+	}
+	return decoded
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	event, err := msgpack.Marshal(map[string]interface{}{"state": "CREATED", "retries": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic := []uint8("default-topic")
+
+	messages := map[string]func() (*Message, error){
+		"ExecuteCommandRequest": func() (*Message, error) {
+			return newTestCommandMessage(), nil
+		},
+		"ControlMessageRequest": func() (*Message, error) {
+			return NewTaskSubscriptionMessage(&TaskSubscription{TopicName: "default-topic", TaskType: "foo", Credits: 32}), nil
+		},
+		"ExecuteCommandResponse": func() (*Message, error) {
+			return NewResponseMessage(7, &sbe.ExecuteCommandResponse{PartitionId: 1, Key: 2, TopicName: topic, Event: event})
+		},
+		"ControlMessageResponse": func() (*Message, error) {
+			return NewResponseMessage(7, &sbe.ControlMessageResponse{Data: event})
+		},
+		"ErrorResponse": func() (*Message, error) {
+			return NewResponseMessage(7, &sbe.ErrorResponse{
+				ErrorCode:     sbe.ErrorCode.TOPIC_NOT_FOUND,
+				ErrorData:     []uint8("Cannot execute command. Topic not found."),
+				FailedRequest: []uint8{},
+			})
+		},
+		"SubscribedEvent": func() (*Message, error) {
+			return NewEventMessage(&sbe.SubscribedEvent{
+				PartitionId:      1,
+				Position:         3,
+				Key:              4,
+				SubscriberKey:    5,
+				SubscriptionType: sbe.SubscriptionType.TOPIC_SUBSCRIPTION,
+				EventType:        sbe.EventType.TASK_EVENT,
+				TopicName:        topic,
+				Event:            event,
+			})
+		},
+	}
+
+	for name, newMessage := range messages {
+		msg, err := newMessage()
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		decoded := roundTrip(t, msg)
+
+		if name == "ErrorResponse" {
+			continue
+		}
+		if len(*decoded.Data) == 0 {
+			t.Fatalf("%s: message pack data not decoded, received %v", name, *decoded.Data)
+		}
+	}
+}
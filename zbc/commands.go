@@ -0,0 +1,310 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errTaskNotFound = errors.New("Task not found")
+
+// CreateTask will create new task on the given topic. Partition is chosen by PartitionSelector of the client.
+// Response is decoded by Message.CreateTaskResponse.
+func (c *Client) CreateTask(topic string, task *Task, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.CreateTaskCtx(ctx, topic, task)
+}
+
+// CreateTaskCtx is same as CreateTask, but request is aborted once ctx is done.
+func (c *Client) CreateTaskCtx(ctx context.Context, topic string, task *Task) (*Message, error) {
+	prepared := *task
+	if prepared.Codec == nil {
+		prepared.Codec = c.payloadCodec(ctx)
+	}
+	if c.getTracer() != nil {
+		prepared.Headers = c.injectTrace(ctx, prepared.Headers)
+	}
+	prepared.Headers = withRequestKey(ctx, prepared.Headers)
+	task = &prepared
+	if err := c.validate(func(v Validator) error { return v.ValidateTask(task) }); err != nil {
+		return nil, err
+	}
+
+	msg := NewCreateTaskCommand(topic, int32(c.selectPartition(ctx, topic)), task)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	queue := c.getTaskQueue()
+	if queue != nil && queue.Len() > 0 {
+		// Tasks queued before must be created first.
+		c.flushTaskQueue()
+		return nil, c.queueTask(queue, topic, task)
+	}
+	response, err := c.executeCommand(ctx, msg)
+	if queue != nil && isUnreachable(err) {
+		return nil, c.queueTask(queue, topic, task)
+	}
+	return response, err
+}
+
+// CreateTasks will create the tasks on the topic, pipelining the requests over the connection with at most BatchWindow
+// of them in flight, so bulk imports don't wait for a round trip per task. Tasks may be created in different order.
+// Every task has request timeout of its own, RequestKeyOption must not be given, as all tasks would get the same key.
+// Responses are returned in order of the tasks, nil for failed ones. If any task failed, error is *BatchError.
+func (c *Client) CreateTasks(topic string, tasks []*Task, opts ...RequestOption) ([]*Message, error) {
+	return c.createTasks(topic, tasks, func() (context.Context, context.CancelFunc) {
+		return c.requestContext(opts...)
+	})
+}
+
+// CreateTasksCtx is same as CreateTasks, but tasks not created yet are aborted once ctx is done.
+func (c *Client) CreateTasksCtx(ctx context.Context, topic string, tasks []*Task) ([]*Message, error) {
+	return c.createTasks(topic, tasks, func() (context.Context, context.CancelFunc) {
+		return context.WithCancel(ctx)
+	})
+}
+
+func (c *Client) createTasks(topic string, tasks []*Task, taskContext func() (context.Context, context.CancelFunc)) ([]*Message, error) {
+	messages := make([]*Message, len(tasks))
+	errs := make([]error, len(tasks))
+	window := make(chan struct{}, c.BatchWindow())
+
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		window <- struct{}{}
+		wg.Add(1)
+		go func(i int, task *Task) {
+			defer wg.Done()
+			ctx, cancel := taskContext()
+			messages[i], errs[i] = c.CreateTaskCtx(ctx, topic, task)
+			cancel()
+			<-window
+		}(i, task)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return messages, &BatchError{Errors: errs}
+		}
+	}
+	return messages, nil
+}
+
+// CompleteTask will complete the task received through task subscription. Payload will replace payload of the task, nil will keep it unchanged.
+func (c *Client) CompleteTask(task *sbe.SubscribedEvent, payload map[string]interface{}, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.CompleteTaskCtx(ctx, task, payload)
+}
+
+// CompleteTaskCtx is same as CompleteTask, but request is aborted once ctx is done.
+func (c *Client) CompleteTaskCtx(ctx context.Context, task *sbe.SubscribedEvent, payload map[string]interface{}) (*Message, error) {
+	var b []byte
+	if payload != nil {
+		err := c.validate(func(v Validator) error { return v.ValidatePayload(payload) })
+		if err != nil {
+			return nil, err
+		}
+		if b, err = c.payloadCodec(ctx).Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := NewCompleteTaskCommand(task, b)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	response, err := c.executeCommand(ctx, msg)
+	if err == nil {
+		c.acknowledged(task, true)
+	}
+	return response, err
+}
+
+// FailTask will fail the task received through task subscription. Retries is the number of retries left for the task,
+// usually retries of the task decremented by one. When no retries are left, broker creates an incident. Error message is
+// attached to the headers of the task.
+func (c *Client) FailTask(task *sbe.SubscribedEvent, retries int, errorMessage string, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.FailTaskCtx(ctx, task, retries, errorMessage)
+}
+
+// FailTaskCtx is same as FailTask, but request is aborted once ctx is done.
+func (c *Client) FailTaskCtx(ctx context.Context, task *sbe.SubscribedEvent, retries int, errorMessage string) (*Message, error) {
+	decoded, err := decodeTask(task, c.payloadCodec(ctx))
+	if err != nil {
+		return nil, err
+	}
+	decoded.Retries = retries
+	if err := c.validate(func(v Validator) error { return v.ValidateTask(decoded) }); err != nil {
+		return nil, err
+	}
+
+	headers := decoded.Headers
+	if headers == nil {
+		headers = make(map[string]interface{})
+	}
+	headers["errorMessage"] = errorMessage
+
+	msg := NewFailTaskCommand(task, retries, headers)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	response, err := c.executeCommand(ctx, msg)
+	if err == nil {
+		c.acknowledged(task, true)
+	}
+	return response, err
+}
+
+// UpdateTaskRetries will set retries of the failed task with given key, so it can be locked by task subscriptions again.
+// It's used to resolve incidents created for tasks without retries left. Broker replaces the task with the command,
+// so the latest event of the task is looked up by replaying the partition first, same as ListWorkflows does.
+// Response state is RETRIES_UPDATED or UPDATE_RETRIES_REJECTED.
+func (c *Client) UpdateTaskRetries(topic string, partitionID int32, key int64, retries int, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.UpdateTaskRetriesCtx(ctx, topic, partitionID, key, retries)
+}
+
+// UpdateTaskRetriesCtx is same as UpdateTaskRetries, but replay and request are aborted once ctx is done.
+func (c *Client) UpdateTaskRetriesCtx(ctx context.Context, topic string, partitionID int32, key int64, retries int) (*Message, error) {
+	isTask := func(event *sbe.SubscribedEvent) bool {
+		return event.EventType == sbe.EventType.TASK_EVENT && event.Key == uint64(key)
+	}
+
+	var task *sbe.SubscribedEvent
+	err := c.replayTopic(ctx, topic, partitionID, "zbc-task-retries", isTask, func(message *Message) error {
+		task = (*message.SbeMessage).(*sbe.SubscribedEvent)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, errTaskNotFound
+	}
+	err = c.validate(func(v Validator) error {
+		decoded, err := decodeTask(task, c.payloadCodec(ctx))
+		if err != nil {
+			return err
+		}
+		decoded.Retries = retries
+		return v.ValidateTask(decoded)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := NewUpdateTaskRetriesCommand(task, retries)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	return c.executeCommand(ctx, msg)
+}
+
+// DeployWorkflow will deploy BPMN or YAML workflow definition on the given topic, its type is detected by content, see
+// ResourceTypeOf. Response contains deployedWorkflows created by the broker, it is decoded by Message.DeploymentResponse.
+func (c *Client) DeployWorkflow(topic string, bpmnBytes []byte, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.DeployWorkflowCtx(ctx, topic, bpmnBytes)
+}
+
+// DeployWorkflowCtx is same as DeployWorkflow, but request is aborted once ctx is done.
+func (c *Client) DeployWorkflowCtx(ctx context.Context, topic string, bpmnBytes []byte) (*Message, error) {
+	return c.DeployWorkflowResourceCtx(ctx, topic, ResourceTypeOf("", bpmnBytes), bpmnBytes)
+}
+
+// CreateWorkflowInstance will create new instance of the workflow with given bpmnProcessId. Version -1 means latest version.
+// Partition is chosen by PartitionSelector of the client. Response is decoded by Message.WorkflowInstanceResponse.
+func (c *Client) CreateWorkflowInstance(topic, bpmnProcessId string, version int, payload map[string]interface{}, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.CreateWorkflowInstanceCtx(ctx, topic, bpmnProcessId, version, payload)
+}
+
+// CreateWorkflowInstanceCtx is same as CreateWorkflowInstance, but request is aborted once ctx is done.
+func (c *Client) CreateWorkflowInstanceCtx(ctx context.Context, topic, bpmnProcessId string, version int, payload map[string]interface{}) (*Message, error) {
+	workflowInstance, err := c.newWorkflowInstance(ctx, bpmnProcessId, version, payload)
+	if err != nil {
+		return nil, err
+	}
+	return c.createWorkflowInstance(ctx, topic, int32(c.selectPartition(ctx, topic)), workflowInstance)
+}
+
+// newWorkflowInstance builds validated workflow instance carrying request key and trace context of ctx.
+func (c *Client) newWorkflowInstance(ctx context.Context, bpmnProcessId string, version int, payload map[string]interface{}) (*WorkflowInstance, error) {
+	workflowInstance := &WorkflowInstance{
+		BpmnProcessId: bpmnProcessId,
+		Version:       version,
+		PayloadJson:   withRequestKey(ctx, c.injectTrace(ctx, payload)),
+		Codec:         c.payloadCodec(ctx),
+	}
+	if err := c.validate(func(v Validator) error { return v.ValidateWorkflowInstance(workflowInstance) }); err != nil {
+		return nil, err
+	}
+	return workflowInstance, nil
+}
+
+func (c *Client) createWorkflowInstance(ctx context.Context, topic string, partitionID int32, workflowInstance *WorkflowInstance) (*Message, error) {
+	msg := NewCreateWorkflowInstanceCommand(topic, partitionID, workflowInstance)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	return c.executeCommand(ctx, msg)
+}
+
+// CancelWorkflowInstance will cancel the workflow instance with given key. Response state is WORKFLOW_INSTANCE_CANCELED
+// or CANCEL_WORKFLOW_INSTANCE_REJECTED.
+func (c *Client) CancelWorkflowInstance(topic string, partitionID int32, key int64, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.CancelWorkflowInstanceCtx(ctx, topic, partitionID, key)
+}
+
+// CancelWorkflowInstanceCtx is same as CancelWorkflowInstance, but request is aborted once ctx is done.
+func (c *Client) CancelWorkflowInstanceCtx(ctx context.Context, topic string, partitionID int32, key int64) (*Message, error) {
+	msg := NewCancelWorkflowInstanceCommand(topic, partitionID, key)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	return c.executeCommand(ctx, msg)
+}
+
+// UpdateWorkflowInstancePayload will replace payload of the activity instance with given key, which belongs to the
+// workflow instance with workflowInstanceKey. Response state is PAYLOAD_UPDATED or UPDATE_PAYLOAD_REJECTED.
+func (c *Client) UpdateWorkflowInstancePayload(topic string, partitionID int32, activityInstanceKey, workflowInstanceKey int64, payload map[string]interface{}, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.UpdateWorkflowInstancePayloadCtx(ctx, topic, partitionID, activityInstanceKey, workflowInstanceKey, payload)
+}
+
+// UpdateWorkflowInstancePayloadCtx is same as UpdateWorkflowInstancePayload, but request is aborted once ctx is done.
+func (c *Client) UpdateWorkflowInstancePayloadCtx(ctx context.Context, topic string, partitionID int32, activityInstanceKey, workflowInstanceKey int64, payload map[string]interface{}) (*Message, error) {
+	if err := c.validate(func(v Validator) error { return v.ValidatePayload(payload) }); err != nil {
+		return nil, err
+	}
+	b, err := c.payloadCodec(ctx).Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := NewUpdatePayloadCommand(topic, partitionID, activityInstanceKey, workflowInstanceKey, b)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	return c.executeCommand(ctx, msg)
+}
@@ -0,0 +1,142 @@
+package zbc_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_UpdateTaskRetries(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	commands := make(chan *zbc.Task, 1)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		if err := request.UnmarshalData(&task); err != nil {
+			t.Error(err)
+		}
+		commands <- &task
+		return zbtest.CommandResponse(request, 7, map[string]interface{}{"state": "RETRIES_UPDATED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	type result struct {
+		response *zbc.Message
+		err      error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		response, err := client.UpdateTaskRetries("default-topic", 0, 7, 2)
+		resultCh <- result{response, err}
+	}()
+
+	events := []struct {
+		key   uint64
+		event map[string]interface{}
+	}{
+		{7, map[string]interface{}{"state": "CREATED", "type": "foo", "retries": 1}},
+		{8, map[string]interface{}{"state": "CREATED", "type": "bar", "retries": 1}},
+		{7, map[string]interface{}{"state": "FAILED", "type": "foo", "retries": 0, "headers": map[string]interface{}{"errorMessage": "boom"}}},
+	}
+	for _, e := range events {
+		for broker.PushTopicEvent("default-topic", e.key, sbe.EventType.TASK_EVENT, e.event) != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	r := <-resultCh
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if state := (*r.response.Data)["state"]; state != "RETRIES_UPDATED" {
+		t.Fatalf("Expected RETRIES_UPDATED, received %v", state)
+	}
+
+	task := <-commands
+	if task.State != "UPDATE_RETRIES" || task.Retries != 2 || task.Type != "foo" || task.Headers["errorMessage"] != "boom" {
+		t.Fatalf("Expected latest event of the task with updated retries, received %+v", task)
+	}
+}
+
+func TestClient_CreateTasks(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		if task.Type == "bad" {
+			return zbtest.ErrorResponse(sbe.ErrorCode.REQUEST_PROCESSING_FAILURE, "bad task")
+		}
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.TaskCreated, "type": task.Type})
+	})
+
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithBatchWindow(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	// Requests wait a bit before they are sent, so the window fills up.
+	var inFlight, maxInFlight int32
+	client.Use(zbc.InterceptorFuncs{Request: func(ctx context.Context, message *zbc.Message, invoke zbc.Invoker) (*zbc.Message, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return invoke(ctx, message)
+	}})
+
+	var tasks []*zbc.Task
+	for i := 0; i < 50; i++ {
+		tasks = append(tasks, &zbc.Task{Type: fmt.Sprintf("task-%d", i), Retries: 3})
+	}
+	tasks[7].Type = "bad"
+
+	responses, err := client.CreateTasks("default-topic", tasks)
+	batchErr, ok := err.(*zbc.BatchError)
+	if !ok {
+		t.Fatalf("Expected BatchError, received %v", err)
+	}
+	for i, task := range tasks {
+		if i == 7 {
+			if zbc.Cause(batchErr.Errors[i]) != zbc.ErrRequestProcessingFailure || responses[i] != nil {
+				t.Fatalf("Expected bad task to fail, received %+v, %v", responses[i], batchErr.Errors[i])
+			}
+			continue
+		}
+		if batchErr.Errors[i] != nil {
+			t.Fatalf("Creating task %d failed: %s", i, batchErr.Errors[i])
+		}
+		if taskType := (*responses[i].Data)["type"]; taskType != task.Type {
+			t.Fatalf("Expected response to %s at %d, received %v", task.Type, i, taskType)
+		}
+	}
+
+	if max := atomic.LoadInt32(&maxInFlight); max < 2 || max > 8 {
+		t.Fatalf("Expected requests pipelined within the window of 8, %d were in flight", max)
+	}
+}
@@ -0,0 +1,90 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// ControlMessageRequest is a builder of control messages, e.g. for subscriptions, credits or topology. Data is encoded
+// as message pack once the message is built.
+type ControlMessageRequest struct {
+	messageType sbe.ControlMessageTypeEnum
+	data        interface{}
+}
+
+// NewControlMessageRequest is a constructor for ControlMessageRequest with empty data.
+func NewControlMessageRequest(messageType sbe.ControlMessageTypeEnum) *ControlMessageRequest {
+	return &ControlMessageRequest{messageType: messageType, data: map[string]interface{}{}}
+}
+
+// Data sets data of the control message. Nil keeps the data empty.
+func (r *ControlMessageRequest) Data(data interface{}) *ControlMessageRequest {
+	if data != nil {
+		r.data = data
+	}
+	return r
+}
+
+// Build will encode the control message with all its headers, so it can be sent by Responder.
+func (r *ControlMessageRequest) Build() (*Message, error) {
+	b, err := msgpack.Marshal(r.data)
+	if err != nil {
+		return nil, err
+	}
+	controlRequest := &sbe.ControlMessageRequest{
+		MessageType: r.messageType,
+		Data:        b,
+	}
+
+	var msg Message
+	msg.SetSbeMessage(controlRequest)
+
+	length := 1 + uint32(2+len(controlRequest.Data)) + 18
+
+	var headers Headers
+	headers.SetSbeMessageHeader(&sbe.MessageHeader{
+		BlockLength: controlRequest.SbeBlockLength(),
+		TemplateId:  controlRequest.SbeTemplateId(),
+		SchemaId:    controlRequest.SbeSchemaId(),
+		Version:     controlRequest.SbeSchemaVersion(),
+	})
+
+	headers.SetRequestResponseHeader(protocol.NewRequestResponseHeader())
+	headers.SetTransportHeader(protocol.NewTransportHeader(protocol.RequestResponse))
+	headers.SetFrameHeader(protocol.NewFrameHeader(uint32(length), 0, 0, 0, 2))
+
+	msg.SetHeaders(&headers)
+	return &msg, nil
+}
+
+// SendControlMessage will send control message of given type with data to the broker and return its response.
+// It is meant for control messages which have no method of their own.
+func (c *Client) SendControlMessage(messageType sbe.ControlMessageTypeEnum, data map[string]interface{}, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.SendControlMessageCtx(ctx, messageType, data)
+}
+
+// SendControlMessageCtx is same as SendControlMessage, but request is aborted once ctx is done.
+func (c *Client) SendControlMessageCtx(ctx context.Context, messageType sbe.ControlMessageTypeEnum, data map[string]interface{}) (*Message, error) {
+	return c.sendControl(ctx, NewControlMessageRequest(messageType).Data(data))
+}
+
+// sendControl will build the control message and send it. Response other than ControlMessageResponse is an error.
+func (c *Client) sendControl(ctx context.Context, request *ControlMessageRequest) (*Message, error) {
+	msg, err := request.Build()
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.ResponderCtx(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := (*response.SbeMessage).(*sbe.ControlMessageResponse); !ok {
+		return nil, errUnexpectedResponse
+	}
+	return response, nil
+}
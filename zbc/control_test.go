@@ -0,0 +1,72 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SendControlMessage(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	broker.HandleControl(sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION, func(request *zbc.Message) zbtest.Response {
+		var data map[string]interface{}
+		request.UnmarshalData(&data)
+		if data["topicName"] != "default-topic" {
+			return zbtest.ErrorResponse(sbe.ErrorCode.TOPIC_NOT_FOUND, "Topic not found")
+		}
+		return zbtest.ControlResponse(data)
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	response, err := client.SendControlMessage(sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION, map[string]interface{}{
+		"topicName":     "default-topic",
+		"partitionId":   0,
+		"subscriberKey": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if (*response.Data)["topicName"] != "default-topic" {
+		t.Fatalf("Expected data of the request echoed, received %v", *response.Data)
+	}
+
+	_, err = client.SendControlMessage(sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION, nil)
+	if zbc.Cause(err) != zbc.ErrTopicNotFound {
+		t.Fatalf("Expected error response of the broker, received %v", err)
+	}
+}
+
+func TestClient_OpenTaskSubscriptionUnexpectedResponse(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	// Response without subscriber key must fail opening instead of crashing the client.
+	broker.HandleControl(sbe.ControlMessageType.ADD_TASK_SUBSCRIPTION, func(request *zbc.Message) zbtest.Response {
+		return zbtest.ControlResponse(map[string]interface{}{"subscriberKey": "1"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	_, err = client.OpenTaskSubscription(&zbc.TaskSubscription{TopicName: "default-topic", TaskType: "foo", LockOwner: "zbc", Credits: 32})
+	if err == nil || zbc.Cause(err).Error() != "Received unexpected response" {
+		t.Fatalf("Expected unexpected response error, received %v", err)
+	}
+}
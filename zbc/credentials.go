@@ -0,0 +1,244 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+var errCredentialsMissing = errors.New("Credentials provider returned no token")
+
+// DefaultCredentialsField is the field of commands and control messages carrying the token, if WithCredentials
+// is given no field.
+const DefaultCredentialsField = "authorization"
+
+// credentialsRefreshMargin is time before expiry in which the token is refreshed, so it doesn't expire on the way
+// to the proxy.
+const credentialsRefreshMargin = 30 * time.Second
+
+// Credentials is a token authenticating the client to a proxy in front of the broker.
+type Credentials struct {
+	Token  string
+	Expiry time.Time // Zero means the token doesn't expire.
+}
+
+// expires tells if the token expires within margin.
+func (c *Credentials) expires(margin time.Duration) bool {
+	return !c.Expiry.IsZero() && time.Now().Add(margin).After(c.Expiry)
+}
+
+// CredentialsProvider returns token of the client, e.g. from an OAuth server. Client keeps the token until shortly
+// before its Expiry, then asks for a new one. Connections of one Client share the token and ask for it one at a time,
+// but provider given to several clients is called by each of them.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// CredentialsProviderFunc implements CredentialsProvider with a function.
+type CredentialsProviderFunc func(ctx context.Context) (*Credentials, error)
+
+// Credentials calls the function.
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return f(ctx)
+}
+
+// StaticToken returns CredentialsProvider of a token which never expires.
+func StaticToken(token string) CredentialsProvider {
+	return CredentialsProviderFunc(func(ctx context.Context) (*Credentials, error) {
+		return &Credentials{Token: token}, nil
+	})
+}
+
+// credentials keeps token of the provider and says where it's injected. It's shared by all connections of the pool.
+type credentials struct {
+	provider CredentialsProvider
+	field    string // Field of requests carrying the token, empty if it's not injected into requests.
+	preamble string // Format of the preamble written to new connections, empty if there is none.
+
+	mu      sync.Mutex
+	current *Credentials
+}
+
+// token returns current token, it asks the provider for a new one if the current one expires soon. Token which
+// didn't expire yet is kept if the provider fails.
+func (c *credentials) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.current != nil && !c.current.expires(credentialsRefreshMargin) {
+		return c.current.Token, nil
+	}
+
+	creds, err := c.provider.Credentials(ctx)
+	if err == nil && (creds == nil || len(creds.Token) == 0) {
+		err = errCredentialsMissing
+	}
+	if err != nil {
+		if c.current != nil && !c.current.expires(0) {
+			return c.current.Token, nil
+		}
+		return "", err
+	}
+	c.current = creds
+	return creds.Token, nil
+}
+
+func (c *credentials) invalidate() {
+	c.mu.Lock()
+	c.current = nil
+	c.mu.Unlock()
+}
+
+// WithCredentials injects token of the provider into every command and control message under the field, where a proxy
+// in front of the broker checks it. Empty field means DefaultCredentialsField. Requests fail if there is no token.
+func WithCredentials(provider CredentialsProvider, field string) ClientOption {
+	return func(c *Client) {
+		if len(field) == 0 {
+			field = DefaultCredentialsField
+		}
+		if c.credentials == nil {
+			c.credentials = &credentials{}
+		}
+		c.credentials.provider = provider
+		c.credentials.field = field
+	}
+}
+
+// WithCredentialsPreamble writes token of the provider formatted by format, e.g. "AUTH %s\n", to every connection
+// before its first frame, for proxies which authenticate whole connections. Open connection keeps its token once it
+// expires, the proxy is expected to close it and the client reconnects with a new one.
+func WithCredentialsPreamble(provider CredentialsProvider, format string) ClientOption {
+	return func(c *Client) {
+		if c.credentials == nil {
+			c.credentials = &credentials{}
+		}
+		c.credentials.provider = provider
+		c.credentials.preamble = format
+	}
+}
+
+// InvalidateCredentials will drop the token, so the next request asks the provider for a new one, e.g. once the proxy
+// rejected it before its expiry.
+func (c *Client) InvalidateCredentials() {
+	if c.credentials != nil {
+		c.credentials.invalidate()
+	}
+}
+
+// dialBroker will dial the broker of the client and write the credentials preamble, if there is one.
+func (c *Client) dialBroker() (net.Conn, error) {
+	conn, err := dial(c.addr, c.tlsConfig, &c.socketOptions, c.dialer)
+	if err != nil {
+		return nil, err
+	}
+	if c.credentials == nil || len(c.credentials.preamble) == 0 {
+		return conn, nil
+	}
+
+	ctx, cancel := c.requestContext()
+	defer cancel()
+	token, err := c.credentials.token(ctx)
+	if err == nil {
+		deadline, _ := ctx.Deadline()
+		conn.SetWriteDeadline(deadline)
+		_, err = fmt.Fprintf(conn, c.credentials.preamble, token)
+		conn.SetWriteDeadline(time.Time{})
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// authorize returns copy of the request carrying the token, or the request itself if token isn't injected
+// into requests.
+func (c *Client) authorize(ctx context.Context, message *Message) (*Message, error) {
+	if c.credentials == nil || len(c.credentials.field) == 0 || message.SbeMessage == nil {
+		return message, nil
+	}
+
+	switch request := (*message.SbeMessage).(type) {
+	case *sbe.ExecuteCommandRequest:
+		token, err := c.credentials.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		document, err := withField(request.Command, c.credentials.field, token)
+		if err != nil {
+			return nil, err
+		}
+		b, err := msgpack.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		command := *request
+		command.Command = b
+		return withBody(message, &command, len(b)-len(request.Command)), nil
+
+	case *sbe.ControlMessageRequest:
+		token, err := c.credentials.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := withField(request.Data, c.credentials.field, token)
+		if err != nil {
+			return nil, err
+		}
+		b, err := msgpack.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		control := *request
+		control.Data = b
+		return withBody(message, &control, len(b)-len(request.Data)), nil
+	}
+	return message, nil
+}
+
+// withBody returns copy of the message with its SBE message replaced, so the headers, e.g. flags and stream ID of
+// the frame, stay as they are. Length of the frame is changed by the number of bytes the body grew.
+func withBody(message *Message, body SBE, grown int) *Message {
+	headers := *message.Headers
+	frame := *headers.FrameHeader
+	frame.Length = uint32(int(frame.Length) + grown)
+	headers.FrameHeader = &frame
+	if headers.RequestResponseHeader != nil {
+		// Request ID is assigned to the copy, the original may be sent again.
+		requestResponse := *headers.RequestResponseHeader
+		headers.RequestResponseHeader = &requestResponse
+	}
+
+	copied := *message
+	copied.Headers = &headers
+	copied.SetSbeMessage(body)
+	return &copied
+}
+
+// withField decodes document encoded as message pack and sets the field in it.
+func withField(document []byte, field, value string) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	if len(document) > 0 {
+		if err := unmarshalMessagePack(document, &m); err != nil {
+			return nil, err
+		}
+	}
+	if m == nil {
+		m = make(map[string]interface{})
+	}
+	m[field] = value
+	return m, nil
+}
+
+// withSharedCredentials makes connection of the pool use credentials of the seed, so they share the token.
+func withSharedCredentials(creds *credentials) ClientOption {
+	return func(c *Client) {
+		c.credentials = creds
+	}
+}
@@ -0,0 +1,169 @@
+package zbc_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_Credentials(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	tokens := make(chan interface{}, 3)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		tokens <- (*request.Data)["auth"]
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "CREATED"})
+	})
+
+	// Tokens expiring within the refresh margin are replaced by every request, others are kept.
+	var calls, expiring int32 = 0, 1
+	provider := zbc.CredentialsProviderFunc(func(ctx context.Context) (*zbc.Credentials, error) {
+		creds := &zbc.Credentials{Token: fmt.Sprintf("t%d", atomic.AddInt32(&calls, 1)), Expiry: time.Now().Add(time.Hour)}
+		if atomic.LoadInt32(&expiring) == 1 {
+			creds.Expiry = time.Now().Add(time.Second)
+		}
+		return creds, nil
+	})
+
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithCredentials(provider, "auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	createTask := func() interface{} {
+		if _, err := client.CreateTask("default-topic", &zbc.Task{Type: "foo", Retries: 3}); err != nil {
+			t.Fatal(err)
+		}
+		return <-tokens
+	}
+
+	first, second := createTask(), createTask()
+	if first == nil || first == second {
+		t.Fatalf("Expected expiring token to be refreshed, received %v and %v", first, second)
+	}
+	atomic.StoreInt32(&expiring, 0)
+	client.InvalidateCredentials()
+	if first, second = createTask(), createTask(); first == nil || first != second {
+		t.Fatalf("Expected token to be kept until it expires, received %v and %v", first, second)
+	}
+
+	failing := zbc.CredentialsProviderFunc(func(ctx context.Context) (*zbc.Credentials, error) {
+		return nil, nil
+	})
+	client, err = zbc.NewClient(broker.Addr(), zbc.WithCredentials(failing, ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if _, err := client.CreateTask("default-topic", &zbc.Task{Type: "foo", Retries: 3}); err == nil {
+		t.Fatal("Expected request without token to fail")
+	}
+}
+
+// Token is injected into control messages too, headers of the request are kept.
+func TestClient_CredentialsControlMessage(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	received := make(chan *zbc.Message, 1)
+	broker.HandleControl(sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION, func(request *zbc.Message) zbtest.Response {
+		received <- request
+		return zbtest.ControlResponse(*request.Data)
+	})
+
+	provider := zbc.CredentialsProviderFunc(func(ctx context.Context) (*zbc.Credentials, error) {
+		return &zbc.Credentials{Token: "t1", Expiry: time.Now().Add(time.Hour)}, nil
+	})
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithCredentials(provider, "auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	msg, err := zbc.NewControlMessageRequest(sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION).Data(map[string]interface{}{"topicName": "default-topic"}).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.Headers.FrameHeader.StreamID = 3
+	if _, err := client.Responder(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	request := <-received
+	if (*request.Data)["auth"] != "t1" || (*request.Data)["topicName"] != "default-topic" {
+		t.Fatalf("Expected token added to data, received %v", *request.Data)
+	}
+	if request.Headers.FrameHeader.StreamID != 3 {
+		t.Fatalf("Expected stream ID of the request kept, received %+v", request.Headers.FrameHeader)
+	}
+}
+
+func TestClient_CredentialsPreamble(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Proxy strips the preamble and forwards the rest of the connection to the broker.
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	preambles := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		preamble, err := r.ReadString('\n')
+		preambles <- preamble
+		if err != nil {
+			return
+		}
+
+		upstream, err := net.Dial("tcp4", broker.Addr())
+		if err != nil {
+			return
+		}
+		defer upstream.Close()
+		go io.Copy(conn, upstream)
+		io.Copy(upstream, r)
+	}()
+
+	client, err := zbc.NewClient(listener.Addr().String(), zbc.WithCredentialsPreamble(zbc.StaticToken("secret"), "AUTH %s\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if preamble := <-preambles; preamble != "AUTH secret\n" {
+		t.Fatalf("Expected preamble with token, received %q", preamble)
+	}
+	if _, err := client.Topology(); err != nil {
+		t.Fatal(err)
+	}
+}
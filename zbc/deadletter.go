@@ -0,0 +1,103 @@
+package zbc
+
+import (
+	"errors"
+)
+
+// Headers of tasks created on dead letter topic, they tell where the task comes from and why it was dead lettered.
+const (
+	DeadLetterKeyHeader   = "deadLetterKey"
+	DeadLetterTopicHeader = "deadLetterTopic"
+	DeadLetterTypeHeader  = "deadLetterType"
+	DeadLetterErrorHeader = "deadLetterError"
+)
+
+var errDeadLetterPolicy = errors.New("Dead letter policy has neither handler nor topic")
+
+// DeadLetterPolicy diverts tasks on which the handler of the Worker keeps failing. Once the handler fails MaxFailures
+// times on the same task, the task is passed to Handler and created on Topic, if they are set. Task is completed
+// afterwards if Complete is set, otherwise it's failed without retries, so broker raises an incident for it. If
+// diverting fails, task is failed as usual and diverted again when the handler fails on it next time.
+// Failures are counted by the Worker in memory, they are lost when it stops or the task is locked by another Worker.
+type DeadLetterPolicy struct {
+	MaxFailures int                               // Failures of the handler after which task is diverted. Default is 3.
+	Handler     func(task *Task, err error) error // Called with the task and the last error of the handler.
+	Topic       string                            // Topic on which copy of the task is created.
+	TaskType    string                            // Type of the copy. Default is type of the task with suffix -dead-letter.
+	Complete    bool                              // Complete the task once it's diverted instead of failing it.
+}
+
+// WithDeadLetterPolicy sets how Worker diverts tasks on which its handler keeps failing. Tasks are only failed by default.
+func WithDeadLetterPolicy(policy *DeadLetterPolicy) WorkerOption {
+	return func(w *Worker) {
+		w.deadLetter = policy
+	}
+}
+
+func (p *DeadLetterPolicy) maxFailures() int {
+	if p.MaxFailures <= 0 {
+		return 3
+	}
+	return p.MaxFailures
+}
+
+// failed will count failure of the handler on the task with given key. True is returned once task should be diverted.
+func (w *Worker) failed(key uint64) bool {
+	if w.deadLetter == nil {
+		return false
+	}
+
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+	w.failures[key]++
+	return w.failures[key] >= w.deadLetter.maxFailures()
+}
+
+// forget will drop failures counted for the task, e.g. once it's completed or won't be locked again.
+func (w *Worker) forget(key uint64) {
+	w.runningMu.Lock()
+	delete(w.failures, key)
+	w.runningMu.Unlock()
+}
+
+// divert will pass the task to handler and dead letter topic of the policy.
+func (w *Worker) divert(key uint64, task *Task, cause error) error {
+	policy := w.deadLetter
+	if policy.Handler == nil && len(policy.Topic) == 0 {
+		return errDeadLetterPolicy
+	}
+
+	if policy.Handler != nil {
+		if err := policy.Handler(task, cause); err != nil {
+			return err
+		}
+	}
+	if len(policy.Topic) == 0 {
+		return nil
+	}
+
+	taskType := policy.TaskType
+	if len(taskType) == 0 {
+		taskType = task.Type + "-dead-letter"
+	}
+	headers := make(map[string]interface{}, len(task.Headers)+4)
+	for k, v := range task.Headers {
+		headers[k] = v
+	}
+	headers[DeadLetterKeyHeader] = key
+	headers[DeadLetterTopicHeader] = w.subscription.TopicName
+	headers[DeadLetterTypeHeader] = task.Type
+	headers[DeadLetterErrorHeader] = cause.Error()
+
+	diverted := &Task{
+		Type:    taskType,
+		Retries: task.Retries,
+		Headers: headers,
+		Payload: task.Payload,
+		Codec:   task.Codec,
+	}
+	ctx, cancel := w.client.requestContext()
+	defer cancel()
+	_, err := w.client.CreateTaskCtx(ctx, policy.Topic, diverted)
+	return err
+}
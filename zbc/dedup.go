@@ -0,0 +1,116 @@
+package zbc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultDeduplicationWindow specifies how long Client remembers response of a command with request key.
+const DefaultDeduplicationWindow = 5 * time.Minute
+
+// sentCommand is a command with request key sent by the Client. Done is closed once its response or error is known.
+type sentCommand struct {
+	key      string
+	done     chan struct{}
+	response *Message
+	err      error
+	expires  time.Time
+}
+
+// sentCommands remembers commands with request key until their deduplication window passes. Commands which failed
+// are forgotten at once, it's not known if the broker executed them.
+type sentCommands struct {
+	mu       sync.Mutex
+	commands map[string]*sentCommand
+	finished []*sentCommand // Succeeded commands in order in which they expire.
+}
+
+// claim returns command sent with the key. True is returned if there is none, the key is claimed then and the caller
+// must send the command and finish it.
+func (s *sentCommands) claim(key string) (*sentCommand, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(time.Now())
+
+	if cmd, ok := s.commands[key]; ok {
+		return cmd, false
+	}
+	if s.commands == nil {
+		s.commands = make(map[string]*sentCommand)
+	}
+	cmd := &sentCommand{key: key, done: make(chan struct{})}
+	s.commands[key] = cmd
+	return cmd, true
+}
+
+// finish will record result of the claimed command and wake up commands waiting for it.
+func (s *sentCommands) finish(cmd *sentCommand, response *Message, err error, window time.Duration) {
+	s.mu.Lock()
+	cmd.response, cmd.err = response, err
+	if err != nil {
+		delete(s.commands, cmd.key)
+	} else {
+		cmd.expires = time.Now().Add(window)
+		s.finished = append(s.finished, cmd)
+	}
+	s.mu.Unlock()
+	close(cmd.done)
+}
+
+// expire will forget commands whose window passed. Must be called under mu.
+func (s *sentCommands) expire(now time.Time) {
+	n := 0
+	for ; n < len(s.finished) && !now.Before(s.finished[n].expires); n++ {
+		delete(s.commands, s.finished[n].key)
+		s.finished[n] = nil
+	}
+	s.finished = s.finished[n:]
+}
+
+// DeduplicationWindow is a getter for time during which response of a command with request key is remembered.
+func (c *Client) DeduplicationWindow() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.dedupWindow))
+}
+
+// SetDeduplicationWindow is a setter for time during which response of a command with request key is remembered.
+// Command sent again with the same key in that time, e.g. by the application once the connection was reestablished,
+// is not sent to the broker. Remembered response is returned instead, with Duplicate set. Command sent while the first
+// one is still waiting for its response waits for it too. Failed commands are not remembered. Zero disables it.
+func (c *Client) SetDeduplicationWindow(window time.Duration) {
+	atomic.StoreInt64(&c.dedupWindow, int64(window))
+}
+
+// deduplicate will call send unless a command with request key of ctx was sent already in the deduplication window.
+func (c *Client) deduplicate(ctx context.Context, send func() (*Message, error)) (*Message, error) {
+	key := requestKey(ctx)
+	if len(key) == 0 || c.DeduplicationWindow() <= 0 {
+		return send()
+	}
+
+	for {
+		cmd, claimed := c.sent.claim(key)
+		if claimed {
+			response, err := send()
+			c.sent.finish(cmd, response, err, c.DeduplicationWindow())
+			return response, err
+		}
+
+		select {
+		case <-cmd.done:
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, ErrRequestTimeout
+			}
+			return nil, ctx.Err()
+		}
+		if cmd.err == nil {
+			c.log().Debug("Command was sent already, returning its response", F("requestKey", key))
+			duplicate := *cmd.response
+			duplicate.Duplicate = true
+			return &duplicate, nil
+		}
+		// Command failed and its key was released, it's claimed again.
+	}
+}
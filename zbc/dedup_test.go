@@ -0,0 +1,75 @@
+package zbc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SetDeduplicationWindow(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// The first request fails, so its key is released and the command is sent again.
+	var received int32
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		if atomic.AddInt32(&received, 1) == 1 {
+			return zbtest.ErrorResponse(sbe.ErrorCode.REQUEST_WRITE_FAILURE, "log is full")
+		}
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.TaskCreated})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if client.DeduplicationWindow() != zbc.DefaultDeduplicationWindow {
+		t.Fatalf("Expected default deduplication window, received %s", client.DeduplicationWindow())
+	}
+
+	task := &zbc.Task{Type: "foo", Retries: 3}
+	if _, err := client.CreateTask("default-topic", task, zbc.RequestKeyOption("order-1")); zbc.Cause(err) != zbc.ErrRequestWriteFailure {
+		t.Fatalf("Expected failure of the first request, received %v", err)
+	}
+	first, err := client.CreateTask("default-topic", task, zbc.RequestKeyOption("order-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Duplicate {
+		t.Fatal("Expected response of the broker, failed command must not be remembered")
+	}
+
+	second, err := client.CreateTask("default-topic", task, zbc.RequestKeyOption("order-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Duplicate {
+		t.Fatal("Expected remembered response of the command sent already")
+	}
+	if second.Headers != first.Headers {
+		t.Fatal("Expected response of the first command")
+	}
+	if n := atomic.LoadInt32(&received); n != 2 {
+		t.Fatalf("Expected 2 requests received by the broker, received %d", n)
+	}
+
+	if _, err := client.CreateTask("default-topic", task, zbc.RequestKeyOption("order-2")); err != nil {
+		t.Fatal(err)
+	}
+	client.SetDeduplicationWindow(0)
+	if third, err := client.CreateTask("default-topic", task, zbc.RequestKeyOption("order-1")); err != nil || third.Duplicate {
+		t.Fatalf("Expected command sent with deduplication disabled, received %v", err)
+	}
+	if n := atomic.LoadInt32(&received); n != 4 {
+		t.Fatalf("Expected 4 requests received by the broker, received %d", n)
+	}
+}
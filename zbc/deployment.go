@@ -0,0 +1,115 @@
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Resource types of deployed workflow definitions.
+const (
+	ResourceTypeBPMN = "BPMN_XML"
+	ResourceTypeYAML = "YAML_WORKFLOW"
+)
+
+// ResourceTypeOf returns type of the workflow definition with given file name. Files ending with .yaml or .yml
+// are YAML workflows, .bpmn and .xml are BPMN. Other names, e.g. of standard input, are detected by content,
+// XML starts with '<'.
+func ResourceTypeOf(name string, resource []byte) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		return ResourceTypeYAML
+	case ".bpmn", ".xml":
+		return ResourceTypeBPMN
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(bytes.TrimPrefix(resource, []byte("\xef\xbb\xbf"))), []byte("<")) {
+		return ResourceTypeBPMN
+	}
+	return ResourceTypeYAML
+}
+
+// yamlWorkflow is structure of YAML workflow as far as it's checked before deployment.
+type yamlWorkflow struct {
+	Name  string `yaml:"name"`
+	Tasks []struct {
+		ID     string `yaml:"id"`
+		Type   string `yaml:"type"`
+		Goto   string `yaml:"goto"`
+		Switch []struct {
+			Goto string `yaml:"goto"`
+		} `yaml:"switch"`
+	} `yaml:"tasks"`
+}
+
+// validateYAMLWorkflow checks that the workflow has a name and tasks with unique IDs and types, and that every goto
+// refers to one of the tasks. Broker validates the rest.
+func validateYAMLWorkflow(resource []byte) error {
+	var wf yamlWorkflow
+	if err := yaml.Unmarshal(resource, &wf); err != nil {
+		return &ValidationError{"resource", err.Error()}
+	}
+	if len(wf.Name) == 0 {
+		return &ValidationError{"name", "workflow name is empty"}
+	}
+	if len(wf.Tasks) == 0 {
+		return &ValidationError{"tasks", "workflow has no tasks"}
+	}
+
+	ids := make(map[string]bool, len(wf.Tasks))
+	for i, task := range wf.Tasks {
+		field := fmt.Sprintf("tasks[%d]", i)
+		if len(task.ID) == 0 {
+			return &ValidationError{field + ".id", "task ID is empty"}
+		}
+		if ids[task.ID] {
+			return &ValidationError{field + ".id", fmt.Sprintf("task ID %s is not unique", task.ID)}
+		}
+		if len(task.Type) == 0 {
+			return &ValidationError{field + ".type", "task type is empty"}
+		}
+		ids[task.ID] = true
+	}
+	for i, task := range wf.Tasks {
+		field := fmt.Sprintf("tasks[%d]", i)
+		if len(task.Goto) > 0 && !ids[task.Goto] {
+			return &ValidationError{field + ".goto", fmt.Sprintf("task %s doesn't exist", task.Goto)}
+		}
+		for j, c := range task.Switch {
+			if len(c.Goto) > 0 && !ids[c.Goto] {
+				return &ValidationError{fmt.Sprintf("%s.switch[%d].goto", field, j), fmt.Sprintf("task %s doesn't exist", c.Goto)}
+			}
+		}
+	}
+	return nil
+}
+
+// DeployWorkflowResource will deploy workflow definition of given resource type on the topic. YAML workflows are
+// checked before they are sent, *ValidationError is returned for invalid ones.
+func (c *Client) DeployWorkflowResource(topic, resourceType string, resource []byte, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.DeployWorkflowResourceCtx(ctx, topic, resourceType, resource)
+}
+
+// DeployWorkflowResourceCtx is same as DeployWorkflowResource, but request is aborted once ctx is done.
+func (c *Client) DeployWorkflowResourceCtx(ctx context.Context, topic, resourceType string, resource []byte) (*Message, error) {
+	switch resourceType {
+	case ResourceTypeBPMN:
+	case ResourceTypeYAML:
+		if err := validateYAMLWorkflow(resource); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, &ValidationError{"resourceType", fmt.Sprintf("resource type %s is neither %s nor %s", resourceType, ResourceTypeBPMN, ResourceTypeYAML)}
+	}
+
+	msg := NewCreateResourceDeploymentCommand(topic, resourceType, resource)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+	return c.executeCommand(ctx, msg)
+}
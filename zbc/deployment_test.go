@@ -0,0 +1,95 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+const orderProcessYAML = `
+name: order-process
+tasks:
+    - id: collect-money
+      type: payment-service
+    - id: fetch-items
+      type: inventory-service
+      goto: ship-parcel
+    - id: ship-parcel
+      type: shipment-service
+`
+
+func TestResourceTypeOf(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource string
+		expected string
+	}{
+		{"order.yaml", "", zbc.ResourceTypeYAML},
+		{"order.YML", "", zbc.ResourceTypeYAML},
+		{"order.bpmn", "", zbc.ResourceTypeBPMN},
+		{"-", "\xef\xbb\xbf  <?xml version=\"1.0\"?><definitions/>", zbc.ResourceTypeBPMN},
+		{"-", orderProcessYAML, zbc.ResourceTypeYAML},
+	}
+	for _, c := range cases {
+		if resourceType := zbc.ResourceTypeOf(c.name, []byte(c.resource)); resourceType != c.expected {
+			t.Errorf("Expected %s to be %s, received %s", c.name, c.expected, resourceType)
+		}
+	}
+}
+
+func TestClient_DeployWorkflowResource(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	deployments := make(chan map[string]interface{}, 2)
+	broker.HandleCommand(sbe.EventType.DEPLOYMENT_EVENT, func(request *zbc.Message) zbtest.Response {
+		deployments <- *request.Data
+		return zbtest.CommandResponse(request, 7, map[string]interface{}{"state": zbc.DeploymentCreated})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if _, err := client.DeployWorkflow("default-topic", []byte(orderProcessYAML)); err != nil {
+		t.Fatal(err)
+	}
+	if deployment := <-deployments; deployment["resourceType"] != zbc.ResourceTypeYAML || string(deployment["resource"].([]byte)) != orderProcessYAML {
+		t.Fatalf("Expected YAML workflow in resource, received %v", deployment)
+	}
+
+	// BPMN keeps the field older brokers understand.
+	if _, err := client.DeployWorkflowResource("default-topic", zbc.ResourceTypeBPMN, []byte("<definitions/>")); err != nil {
+		t.Fatal(err)
+	}
+	if deployment := <-deployments; deployment["resourceType"] != nil || string(deployment["bpmnXml"].([]byte)) != "<definitions/>" {
+		t.Fatalf("Expected BPMN in bpmnXml, received %v", deployment)
+	}
+
+	invalid := []string{
+		"tasks: [{id: a, type: b}]",
+		"name: order-process\ntasks: [{id: a, type: b}, {id: a, type: c}]",
+		"name: order-process\ntasks: [{id: a}]",
+		"name: order-process\ntasks: [{id: a, type: b, goto: c}]",
+		"name: [",
+	}
+	for _, resource := range invalid {
+		_, err := client.DeployWorkflowResource("default-topic", zbc.ResourceTypeYAML, []byte(resource))
+		if _, ok := err.(*zbc.ValidationError); !ok {
+			t.Errorf("Expected ValidationError for %q, received %v", resource, err)
+		}
+	}
+	select {
+	case deployment := <-deployments:
+		t.Fatalf("Expected invalid workflows not to be sent, received %v", deployment)
+	default:
+	}
+}
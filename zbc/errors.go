@@ -0,0 +1,79 @@
+package zbc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// Kinds of errors returned when broker rejects the request. Use Cause to find out the kind of an error.
+var (
+	ErrMessageNotSupported      = errors.New("Message not supported by the broker")
+	ErrTopicNotFound            = errors.New("Topic not found")
+	ErrPartitionNotFound        = errors.New("Partition not found")
+	ErrRequestWriteFailure      = errors.New("Broker failed to write the request")
+	ErrInvalidClientVersion     = errors.New("Client version not supported by the broker")
+	ErrRequestTimeout           = errors.New("Request timeout")
+	ErrRequestProcessingFailure = errors.New("Broker failed to process the request")
+	ErrBroker                   = errors.New("Broker error")
+)
+
+var errorKinds = map[sbe.ErrorCodeEnum]error{
+	sbe.ErrorCode.MESSAGE_NOT_SUPPORTED:      ErrMessageNotSupported,
+	sbe.ErrorCode.TOPIC_NOT_FOUND:            ErrTopicNotFound,
+	sbe.ErrorCode.REQUEST_WRITE_FAILURE:      ErrRequestWriteFailure,
+	sbe.ErrorCode.INVALID_CLIENT_VERSION:     ErrInvalidClientVersion,
+	sbe.ErrorCode.REQUEST_TIMEOUT:            ErrRequestTimeout,
+	sbe.ErrorCode.REQUEST_PROCESSING_FAILURE: ErrRequestProcessingFailure,
+}
+
+// ErrorDetails is the error response sent by the broker.
+type ErrorDetails struct {
+	Code    sbe.ErrorCodeEnum
+	Message string
+}
+
+// BrokerError is returned when broker rejects the request. Kind is one of the ErrX variables of this package.
+type BrokerError struct {
+	Kind    error
+	Details ErrorDetails
+}
+
+func (e *BrokerError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Details.Message)
+}
+
+func newBrokerError(errResp *sbe.ErrorResponse) *BrokerError {
+	kind, ok := errorKinds[errResp.ErrorCode]
+	if !ok {
+		kind = ErrBroker
+	}
+
+	return &BrokerError{
+		Kind: kind,
+		Details: ErrorDetails{
+			Code:    errResp.ErrorCode,
+			Message: string(errResp.ErrorData),
+		},
+	}
+}
+
+// Cause will return kind of the error if it was returned by the broker, ErrUnsupportedSchemaVersion for
+// UnsupportedSchemaError, otherwise the error itself.
+// Timeouts on client side are reported as ErrRequestTimeout too.
+func Cause(err error) error {
+	switch e := err.(type) {
+	case *BrokerError:
+		return e.Kind
+	case *UnsupportedSchemaError:
+		return ErrUnsupportedSchemaVersion
+	}
+	return err
+}
+
+// isNotLeader will decide if request failed because broker doesn't lead the partition.
+func isNotLeader(err error) bool {
+	brokerErr, ok := err.(*BrokerError)
+	return ok && brokerErr.Details.Code == sbe.ErrorCode.TOPIC_NOT_FOUND
+}
@@ -0,0 +1,162 @@
+package zbc
+
+import (
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// States of task events. Commands are sent by clients, broker answers with events or rejections.
+const (
+	TaskCreate                = "CREATE"
+	TaskCreated               = "CREATED"
+	TaskLocked                = "LOCKED"
+	TaskLockExpired           = "LOCK_EXPIRED"
+	TaskComplete              = "COMPLETE"
+	TaskCompleted             = "COMPLETED"
+	TaskCompleteRejected      = "COMPLETE_REJECTED"
+	TaskFail                  = "FAIL"
+	TaskFailed                = "FAILED"
+	TaskFailRejected          = "FAIL_REJECTED"
+	TaskUpdateRetries         = "UPDATE_RETRIES"
+	TaskRetriesUpdated        = "RETRIES_UPDATED"
+	TaskUpdateRetriesRejected = "UPDATE_RETRIES_REJECTED"
+	TaskCanceled              = "CANCELED"
+)
+
+// States of workflow instance events.
+const (
+	WorkflowInstanceCreate                = "CREATE_WORKFLOW_INSTANCE"
+	WorkflowInstanceCreated               = "WORKFLOW_INSTANCE_CREATED"
+	WorkflowInstanceRejected              = "WORKFLOW_INSTANCE_REJECTED"
+	WorkflowInstanceCompleted             = "WORKFLOW_INSTANCE_COMPLETED"
+	WorkflowInstanceCancel                = "CANCEL_WORKFLOW_INSTANCE"
+	WorkflowInstanceCanceled              = "WORKFLOW_INSTANCE_CANCELED"
+	WorkflowInstanceCancelRejected        = "CANCEL_WORKFLOW_INSTANCE_REJECTED"
+	WorkflowInstanceUpdatePayload         = "UPDATE_PAYLOAD"
+	WorkflowInstancePayloadUpdated        = "PAYLOAD_UPDATED"
+	WorkflowInstanceUpdatePayloadRejected = "UPDATE_PAYLOAD_REJECTED"
+	WorkflowInstanceActivityActivated     = "ACTIVITY_ACTIVATED"
+)
+
+// States of deployment, workflow, incident and subscription events.
+const (
+	DeploymentCreate   = "CREATE_DEPLOYMENT"
+	DeploymentCreated  = "DEPLOYMENT_CREATED"
+	DeploymentRejected = "DEPLOYMENT_REJECTED"
+
+	WorkflowCreated = "CREATED"
+
+	IncidentCreated         = "CREATED"
+	IncidentResolve         = "RESOLVE"
+	IncidentResolved        = "RESOLVED"
+	IncidentResolveRejected = "RESOLVE_REJECTED"
+	IncidentResolveFailed   = "RESOLVE_FAILED"
+	IncidentDeleted         = "DELETED"
+
+	TopicCreate         = "CREATE"
+	TopicCreated        = "CREATED"
+	TopicCreateRejected = "CREATE_REJECTED"
+
+	SubscriberSubscribe      = "SUBSCRIBE"
+	SubscriberSubscribed     = "SUBSCRIBED"
+	SubscriptionAcknowledge  = "ACKNOWLEDGE"
+	SubscriptionAcknowledged = "ACKNOWLEDGED"
+)
+
+// NewCreateTaskCommand is constructor for Message which will create the task on the partition of the topic.
+// Payload of the task is encoded by its Codec unless it's already set.
+func NewCreateTaskCommand(topic string, partitionID int32, task *Task) *Message {
+	task.State = TaskCreate
+	return NewTaskMessage(&sbe.ExecuteCommandRequest{
+		PartitionId: uint16(partitionID),
+		TopicName:   []uint8(topic),
+	}, task)
+}
+
+// NewCompleteTaskCommand is constructor for Message which will complete the task received through task subscription.
+// Payload is encoded already, nil keeps payload of the task unchanged.
+func NewCompleteTaskCommand(event *sbe.SubscribedEvent, payload []byte) *Message {
+	changes := make(map[string]interface{})
+	if payload != nil {
+		changes["payload"] = payload
+	}
+	return newTaskEventMessage(event, TaskComplete, changes)
+}
+
+// NewFailTaskCommand is constructor for Message which will fail the task received through task subscription.
+// Headers replace headers of the task.
+func NewFailTaskCommand(event *sbe.SubscribedEvent, retries int, headers map[string]interface{}) *Message {
+	return newTaskEventMessage(event, TaskFail, map[string]interface{}{
+		"retries": retries,
+		"headers": headers,
+	})
+}
+
+// NewUpdateTaskRetriesCommand is constructor for Message which will set retries of the failed task.
+func NewUpdateTaskRetriesCommand(event *sbe.SubscribedEvent, retries int) *Message {
+	return newTaskEventMessage(event, TaskUpdateRetries, map[string]interface{}{"retries": retries})
+}
+
+// NewCreateDeploymentCommand is constructor for Message which will deploy BPMN workflow definition on the topic.
+func NewCreateDeploymentCommand(topic string, bpmnXML []byte) *Message {
+	return NewDeploymentMessage(&sbe.ExecuteCommandRequest{
+		TopicName: []uint8(topic),
+	}, &Deployment{State: DeploymentCreate, BpmnXml: bpmnXML})
+}
+
+// NewCreateResourceDeploymentCommand is constructor for Message which will deploy workflow definition of given
+// resource type on the topic.
+func NewCreateResourceDeploymentCommand(topic, resourceType string, resource []byte) *Message {
+	if resourceType == ResourceTypeBPMN {
+		return NewCreateDeploymentCommand(topic, resource)
+	}
+	return NewDeploymentMessage(&sbe.ExecuteCommandRequest{
+		TopicName: []uint8(topic),
+	}, &Deployment{State: DeploymentCreate, Resource: resource, ResourceType: resourceType})
+}
+
+// NewCreateTopicCommand is constructor for Message which will create the topic with given number of partitions.
+// Topics are managed on the system topic.
+func NewCreateTopicCommand(name string, partitions int) *Message {
+	return NewCommandRequestMessage(&sbe.ExecuteCommandRequest{
+		EventType:   sbe.EventType.TOPIC_EVENT,
+		PartitionId: SystemPartitionID,
+		TopicName:   []uint8(SystemTopic),
+	}, &Topic{State: TopicCreate, Name: name, Partitions: partitions})
+}
+
+// NewCreateWorkflowInstanceCommand is constructor for Message which will create the workflow instance on the partition
+// of the topic. Payload of the instance is encoded by its Codec unless it's already set.
+func NewCreateWorkflowInstanceCommand(topic string, partitionID int32, wf *WorkflowInstance) *Message {
+	wf.State = WorkflowInstanceCreate
+	return NewWorkflowMessage(&sbe.ExecuteCommandRequest{
+		PartitionId: uint16(partitionID),
+		TopicName:   []uint8(topic),
+	}, wf)
+}
+
+// NewCancelWorkflowInstanceCommand is constructor for Message which will cancel the workflow instance with given key.
+func NewCancelWorkflowInstanceCommand(topic string, partitionID int32, key int64) *Message {
+	return newWorkflowInstanceCommandMessage(topic, partitionID, key, map[string]interface{}{
+		"state": WorkflowInstanceCancel,
+	})
+}
+
+// NewUpdatePayloadCommand is constructor for Message which will replace payload of the activity instance with given key.
+// Payload is encoded already.
+func NewUpdatePayloadCommand(topic string, partitionID int32, activityInstanceKey, workflowInstanceKey int64, payload []byte) *Message {
+	return newWorkflowInstanceCommandMessage(topic, partitionID, activityInstanceKey, map[string]interface{}{
+		"state":               WorkflowInstanceUpdatePayload,
+		"workflowInstanceKey": workflowInstanceKey,
+		"payload":             payload,
+	})
+}
+
+// NewResolveIncidentCommand is constructor for Message which will resolve the incident received through incident
+// subscription. Payload is encoded already, nil keeps payload of the failed activity unchanged.
+func NewResolveIncidentCommand(event *sbe.SubscribedEvent, payload []byte) *Message {
+	changes := make(map[string]interface{})
+	if payload != nil {
+		changes["payload"] = payload
+	}
+	return newEventCommandMessage(event, sbe.EventType.INCIDENT_EVENT, IncidentResolve, changes)
+}
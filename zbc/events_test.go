@@ -0,0 +1,35 @@
+package zbc
+
+import (
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestNewCompleteTaskCommand(t *testing.T) {
+	event, err := msgpack.Marshal(&Task{State: TaskLocked, Type: "foo", Retries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := &sbe.SubscribedEvent{PartitionId: 1, Position: 10, Key: 7, TopicName: []uint8("topic"), Event: event}
+
+	payload, _ := msgpack.Marshal(map[string]interface{}{"a": 1})
+	msg := NewCompleteTaskCommand(locked, payload)
+	if msg == nil {
+		t.Fatal("Command not built")
+	}
+
+	request := (*msg.SbeMessage).(*sbe.ExecuteCommandRequest)
+	if request.EventType != sbe.EventType.TASK_EVENT || request.Key != 7 || request.PartitionId != 1 || string(request.TopicName) != "topic" {
+		t.Fatalf("Expected command on the locked task, received %+v", request)
+	}
+
+	var task Task
+	if err := msgpack.Unmarshal(request.Command, &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.State != TaskComplete || task.Type != "foo" || string(task.Payload) != string(payload) {
+		t.Fatalf("Expected task completed with the payload, received %+v", task)
+	}
+}
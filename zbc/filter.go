@@ -0,0 +1,105 @@
+package zbc
+
+import (
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// EventFilter decides if event received by subscription is passed to the consumer. Filters are set by Filters of
+// TaskSubscription and TopicSubscription, events not passing all of them are dropped before they reach the channel of
+// the subscription and are reported to Observer implementing FilterObserver. Filtered tasks stay locked by the
+// subscription until their lock expires, so task subscriptions should only filter out tasks no one else handles.
+// Filters are called from the goroutine of the subscription, one event at a time.
+type EventFilter func(event *sbe.SubscribedEvent) bool
+
+// eventAttributes are attributes of events which filters look at. Workflow instance and incident events carry BPMN
+// process ID, tasks carry it in their headers.
+type eventAttributes struct {
+	State         string `msgpack:"state"`
+	BpmnProcessID string `msgpack:"bpmnProcessId"`
+	Headers       struct {
+		BpmnProcessID string `msgpack:"bpmnProcessId"`
+	} `msgpack:"headers"`
+	CustomHeaders map[string]interface{} `msgpack:"customHeaders"`
+}
+
+func decodeAttributes(event *sbe.SubscribedEvent) (*eventAttributes, bool) {
+	var attributes eventAttributes
+	if err := unmarshalMessagePack(event.Event, &attributes); err != nil {
+		return nil, false
+	}
+	if len(attributes.BpmnProcessID) == 0 {
+		attributes.BpmnProcessID = attributes.Headers.BpmnProcessID
+	}
+	return &attributes, true
+}
+
+// EventTypeFilter passes events of given types.
+func EventTypeFilter(types ...sbe.EventTypeEnum) EventFilter {
+	return func(event *sbe.SubscribedEvent) bool {
+		for _, eventType := range types {
+			if event.EventType == eventType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// StateFilter passes events in given states, e.g. TaskCreated or WorkflowInstanceCompleted.
+func StateFilter(states ...string) EventFilter {
+	return func(event *sbe.SubscribedEvent) bool {
+		attributes, ok := decodeAttributes(event)
+		return ok && contains(states, attributes.State)
+	}
+}
+
+// CustomHeaderFilter passes tasks with custom header of given name set to one of the values. Tasks without the
+// header and other events are filtered out.
+func CustomHeaderFilter(name string, values ...string) EventFilter {
+	return func(event *sbe.SubscribedEvent) bool {
+		attributes, ok := decodeAttributes(event)
+		if !ok {
+			return false
+		}
+		value, ok := attributes.CustomHeaders[name].(string)
+		return ok && contains(values, value)
+	}
+}
+
+// BpmnProcessIDFilter passes events of workflow instances, their tasks and incidents with given BPMN process IDs.
+// Events which don't belong to any workflow are filtered out.
+func BpmnProcessIDFilter(ids ...string) EventFilter {
+	return func(event *sbe.SubscribedEvent) bool {
+		attributes, ok := decodeAttributes(event)
+		return ok && len(attributes.BpmnProcessID) > 0 && contains(ids, attributes.BpmnProcessID)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// accepts tells if the event passes internal filter of the subscription and all filters given by the consumer.
+func (s *Subscription) accepts(event *sbe.SubscribedEvent) bool {
+	if s.filter != nil && !s.filter(event) {
+		return false
+	}
+	for _, filter := range s.filters() {
+		if !filter(event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Subscription) filters() []EventFilter {
+	if s.task != nil {
+		return s.task.Filters
+	}
+	return s.topic.Filters
+}
@@ -0,0 +1,80 @@
+package zbc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+type filterCounter struct {
+	dropCounter
+	filtered int32
+}
+
+func (f *filterCounter) EventFiltered(string) { atomic.AddInt32(&f.filtered, 1) }
+
+func TestTopicSubscription_Filters(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+	observer := &filterCounter{}
+	client.SetObserver(observer)
+
+	sub, err := client.OpenTopicSubscription(&zbc.TopicSubscription{
+		TopicName:        "default-topic",
+		Name:             "orders",
+		PrefetchCapacity: 10,
+		Filters: []zbc.EventFilter{
+			zbc.EventTypeFilter(sbe.EventType.TASK_EVENT, sbe.EventType.WORKFLOW_INSTANCE_EVENT),
+			zbc.StateFilter(zbc.TaskCreated, zbc.WorkflowInstanceCompleted),
+			zbc.BpmnProcessIDFilter("order-process"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []struct {
+		eventType sbe.EventTypeEnum
+		event     map[string]interface{}
+	}{
+		{sbe.EventType.INCIDENT_EVENT, map[string]interface{}{"state": zbc.IncidentCreated, "bpmnProcessId": "order-process"}},
+		{sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCreated, "headers": map[string]interface{}{"bpmnProcessId": "order-process"}}},
+		{sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCompleted, "headers": map[string]interface{}{"bpmnProcessId": "order-process"}}},
+		{sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCreated, "type": "foo"}},
+		{sbe.EventType.WORKFLOW_INSTANCE_EVENT, map[string]interface{}{"state": zbc.WorkflowInstanceCompleted, "bpmnProcessId": "invoice"}},
+		{sbe.EventType.WORKFLOW_INSTANCE_EVENT, map[string]interface{}{"state": zbc.WorkflowInstanceCompleted, "bpmnProcessId": "order-process"}},
+	}
+	for i, e := range events {
+		if err := broker.PushTopicEvent("default-topic", uint64(i+1), e.eventType, e.event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, expected := range []uint64{2, 6} {
+		select {
+		case msg := <-sub.Events():
+			if key := (*msg.SbeMessage).(*sbe.SubscribedEvent).Key; key != expected {
+				t.Fatalf("Expected event %d, received %d", expected, key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Event %d was not delivered", expected)
+		}
+	}
+	if filtered := atomic.LoadInt32(&observer.filtered); filtered != 4 {
+		t.Fatalf("Expected 4 events filtered out, observed %d", filtered)
+	}
+}
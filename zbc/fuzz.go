@@ -0,0 +1,80 @@
+//go:build gofuzz
+// +build gofuzz
+
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// Targets for go-fuzz, they feed data received from a misbehaving broker or proxy to the decoders. Build and run
+// one of them with:
+//
+//	make fuzz FUZZ=FuzzReadHeaders
+//
+// Decoders must return errors for such data, any panic is a crash found by go-fuzz.
+
+// fuzzMaxMessageLength keeps frames claiming huge lengths from taking all memory of the fuzzer.
+const fuzzMaxMessageLength = 1024 * 1024
+
+// FuzzReadHeaders reads frames of data with ReadHeaders and parses their messages.
+func FuzzReadHeaders(data []byte) int {
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(data)))
+	r.MaxMessageLength = fuzzMaxMessageLength
+
+	result := 0
+	for {
+		headers, body, err := r.ReadHeaders()
+		if err == ErrFrameTooLarge || Cause(err) == ErrUnsupportedSchemaVersion {
+			continue
+		}
+		if err != nil {
+			return result
+		}
+		if body == nil {
+			continue
+		}
+		if _, err := r.ParseMessage(headers, body); err == nil {
+			result = 1
+		}
+	}
+}
+
+// FuzzReadMessage reads frames of data with ReadMessage, which the Client uses.
+func FuzzReadMessage(data []byte) int {
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(data)))
+	r.MaxMessageLength = fuzzMaxMessageLength
+
+	result := 0
+	for {
+		msg, err := r.ReadMessage()
+		if err != nil && msg == nil && err != ErrFrameTooLarge && err != errUnexpectedFragment {
+			return result
+		}
+		if err == nil && msg.SbeMessage != nil {
+			result = 1
+		}
+	}
+}
+
+// FuzzParseMessage decodes data as SBE message following its SBE message header.
+func FuzzParseMessage(data []byte) int {
+	if len(data) < SBEMessageHeaderSize {
+		return -1
+	}
+	var header sbe.MessageHeader
+	if err := header.Decode(bytes.NewReader(data), protocol.ByteOrder, 0); err != nil {
+		return -1
+	}
+	body := data[SBEMessageHeaderSize:]
+
+	r := &MessageReader{}
+	if _, err := r.ParseMessage(&Headers{SbeMessageHeader: &header}, &body); err != nil {
+		return 0
+	}
+	return 1
+}
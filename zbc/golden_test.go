@@ -0,0 +1,76 @@
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// goldenDumps are frames captured from a broker, see tests/test-zbdump. They are read as they were sent over the
+// wire, so decoder and encoder are checked against the broker instead of against each other.
+var goldenDumps = []string{
+	"create-task-request",
+	"create-task-response",
+	"open-task-subscription",
+	"open-task-subscription-response",
+	"close-task-subscription",
+	"close-task-subscription-response",
+	"open-topic-subscription",
+	"open-topic-subscription-response",
+	"close-topic-subscription",
+	"close-topic-subscription-response",
+}
+
+func readGoldenDump(t *testing.T, name string) ([]byte, *Message) {
+	dump, err := ioutil.ReadFile(filepath.Join("..", "tests", "test-zbdump", "dumps", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(dump)))
+	headers, tail, err := r.ReadHeaders()
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	msg, err := r.ParseMessage(headers, tail)
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	return dump, msg
+}
+
+// TestGolden_RoundTrip decodes every captured frame and encodes it again, which must give the same bytes.
+func TestGolden_RoundTrip(t *testing.T) {
+	for _, name := range goldenDumps {
+		dump, msg := readGoldenDump(t, name)
+
+		var frame bytes.Buffer
+		NewMessageWriter(msg).Write(&frame)
+		if !bytes.Equal(dump, frame.Bytes()) {
+			t.Errorf("%s: captured\n% x\nre-encoded\n% x", name, dump, frame.Bytes())
+		}
+	}
+}
+
+func TestGolden_Decode(t *testing.T) {
+	_, msg := readGoldenDump(t, "create-task-request")
+	var task Task
+	if err := msg.UnmarshalData(&task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Type != "foo" || task.Retries != 3 {
+		t.Fatalf("Expected task foo with 3 retries, decoded %+v", task)
+	}
+
+	_, msg = readGoldenDump(t, "create-task-response")
+	// Broker of the captures sends state of the event as eventType.
+	var created map[string]interface{}
+	if err := msg.UnmarshalData(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created["eventType"] != TaskCreated {
+		t.Fatalf("Expected created task, decoded %+v", created)
+	}
+}
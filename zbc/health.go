@@ -0,0 +1,123 @@
+package zbc
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// BrokerHealth is result of pinging one broker of the cluster.
+type BrokerHealth struct {
+	Address   string `json:"address"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"` // Why the broker is not reachable.
+}
+
+// Health describes state of the cluster as seen by the client, see HealthCheck.
+type Health struct {
+	Brokers          []BrokerHealth `json:"brokers"`
+	Partitions       int            `json:"partitions"`       // Partitions which have a leader in topology.
+	ReachableLeaders int            `json:"reachableLeaders"` // Partitions whose leader responded to the ping.
+}
+
+// Healthy tells if at least one broker responded and leaders of all partitions are reachable, so every command can
+// be sent. Unreachable brokers which don't lead any partition don't make the cluster unhealthy.
+func (h *Health) Healthy() bool {
+	for _, broker := range h.Brokers {
+		if broker.Reachable {
+			return h.ReachableLeaders == h.Partitions
+		}
+	}
+	return false
+}
+
+// ReachableBrokers returns number of brokers which responded to the ping.
+func (h *Health) ReachableBrokers() int {
+	n := 0
+	for _, broker := range h.Brokers {
+		if broker.Reachable {
+			n++
+		}
+	}
+	return n
+}
+
+// HealthCheck will request topology and ping every broker in it with a topology request, without retries, so it is
+// cheap enough for readiness probes. Error is returned only if topology cannot be requested at all.
+func (c *Client) HealthCheck(opts ...RequestOption) (*Health, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.HealthCheckCtx(ctx)
+}
+
+// HealthCheckCtx is same as HealthCheck, but pings are aborted once ctx is done.
+func (c *Client) HealthCheckCtx(ctx context.Context) (*Health, error) {
+	topology, err := c.requestTopology(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := map[string]bool{c.addr: true}
+	for _, broker := range topology.Brokers {
+		addrs[broker.String()] = true
+	}
+	for _, leader := range topology.TopicLeaders {
+		addrs[leader.String()] = true
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan BrokerHealth, len(addrs))
+	for addr := range addrs {
+		if addr == c.addr {
+			// Seed has just responded with the topology.
+			results <- BrokerHealth{Address: addr, Reachable: true}
+			continue
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			results <- c.pingBroker(ctx, addr)
+		}(addr)
+	}
+	wg.Wait()
+	close(results)
+
+	health := &Health{Partitions: len(topology.TopicLeaders)}
+	reachable := make(map[string]bool, len(addrs))
+	for result := range results {
+		health.Brokers = append(health.Brokers, result)
+		reachable[result.Address] = result.Reachable
+	}
+	sort.Sort(brokerHealths(health.Brokers))
+	for _, leader := range topology.TopicLeaders {
+		if reachable[leader.String()] {
+			health.ReachableLeaders++
+		}
+	}
+	return health, nil
+}
+
+func (c *Client) pingBroker(ctx context.Context, addr string) BrokerHealth {
+	health := BrokerHealth{Address: addr}
+	if c.pool == nil {
+		health.Error = "not connected, client has no broker pool"
+		return health
+	}
+
+	client, err := c.pool.Client(addr)
+	if err == nil {
+		_, err = client.requestTopology(ctx)
+	}
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	health.Reachable = true
+	return health
+}
+
+type brokerHealths []BrokerHealth
+
+func (b brokerHealths) Len() int           { return len(b) }
+func (b brokerHealths) Less(i, j int) bool { return b[i].Address < b[j].Address }
+func (b brokerHealths) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
@@ -0,0 +1,72 @@
+package zbc_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func brokerAddress(t *testing.T, addr string) zbc.BrokerAddress {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	return zbc.BrokerAddress{Host: host, Port: port}
+}
+
+func TestClient_HealthCheck(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Second broker leads one partition, but nothing listens on its address.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := brokerAddress(t, listener.Addr().String())
+	listener.Close()
+
+	up := brokerAddress(t, broker.Addr())
+	leaders := []zbc.TopicLeader{
+		{BrokerAddress: up, TopicName: "orders", PartitionID: 0},
+		{BrokerAddress: down, TopicName: "orders", PartitionID: 1},
+	}
+	var partitions int32 = 1
+	broker.HandleControl(sbe.ControlMessageType.REQUEST_TOPOLOGY, func(request *zbc.Message) zbtest.Response {
+		topology := &zbc.Topology{TopicLeaders: leaders[:atomic.LoadInt32(&partitions)], Brokers: []zbc.BrokerAddress{up, down}}
+		return zbtest.ControlResponse(topology)
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	health, err := client.HealthCheck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !health.Healthy() || health.ReachableBrokers() != 1 || len(health.Brokers) != 2 {
+		t.Fatalf("Expected healthy cluster with one of two brokers reachable, received %+v", health)
+	}
+
+	atomic.StoreInt32(&partitions, 2)
+	health, err = client.HealthCheck()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Healthy() || health.Partitions != 2 || health.ReachableLeaders != 1 {
+		t.Fatalf("Expected unhealthy cluster with leader of one partition unreachable, received %+v", health)
+	}
+}
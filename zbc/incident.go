@@ -0,0 +1,68 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// Incident is created by the broker when workflow instance cannot continue, e.g. when task has no retries left
+// or payload cannot be mapped. Use Message.UnmarshalData to decode it from the event.
+type Incident struct {
+	State                string `msgpack:"state"`
+	ErrorType            string `msgpack:"errorType"`
+	ErrorMessage         string `msgpack:"errorMessage"`
+	FailureEventPosition uint64 `msgpack:"failureEventPosition"`
+	BpmnProcessId        string `msgpack:"bpmnProcessId"`
+	WorkflowInstanceKey  int64  `msgpack:"workflowInstanceKey"`
+	ActivityId           string `msgpack:"activityId"`
+	ActivityInstanceKey  int64  `msgpack:"activityInstanceKey"`
+	TaskKey              int64  `msgpack:"taskKey"`
+	Payload              []byte `msgpack:"payload"`
+}
+
+func isIncidentEvent(event *sbe.SubscribedEvent) bool {
+	return event.EventType == sbe.EventType.INCIDENT_EVENT
+}
+
+// OpenIncidentSubscription will open topic subscription which passes only incident events to the consumer.
+// Events should be acknowledged with AcknowledgeTopicEvent same as with any topic subscription.
+func (c *Client) OpenIncidentSubscription(ts *TopicSubscription, opts ...RequestOption) (*Subscription, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.OpenIncidentSubscriptionCtx(ctx, ts)
+}
+
+// OpenIncidentSubscriptionCtx is same as OpenIncidentSubscription, but opening of the subscription is aborted once ctx is done.
+func (c *Client) OpenIncidentSubscriptionCtx(ctx context.Context, ts *TopicSubscription) (*Subscription, error) {
+	return c.openTopicSubscription(ctx, ts, isIncidentEvent)
+}
+
+// ResolveIncident will resolve the incident received through incident subscription. Payload will replace payload
+// of the failed activity, nil will keep it unchanged. Response state is RESOLVED, RESOLVE_REJECTED or RESOLVE_FAILED.
+func (c *Client) ResolveIncident(incident *sbe.SubscribedEvent, payload map[string]interface{}, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.ResolveIncidentCtx(ctx, incident, payload)
+}
+
+// ResolveIncidentCtx is same as ResolveIncident, but request is aborted once ctx is done.
+func (c *Client) ResolveIncidentCtx(ctx context.Context, incident *sbe.SubscribedEvent, payload map[string]interface{}) (*Message, error) {
+	var b []byte
+	if payload != nil {
+		err := c.validate(func(v Validator) error { return v.ValidatePayload(payload) })
+		if err != nil {
+			return nil, err
+		}
+		if b, err = c.payloadCodec(ctx).Marshal(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := NewResolveIncidentCommand(incident, b)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+
+	return c.executeCommand(ctx, msg)
+}
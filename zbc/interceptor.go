@@ -0,0 +1,93 @@
+package zbc
+
+import (
+	"context"
+)
+
+// Invoker sends the request and returns response of the broker.
+type Invoker func(ctx context.Context, message *Message) (*Message, error)
+
+// EventHandler passes event of a subscription on to the consumer.
+type EventHandler func(event *Message)
+
+// Interceptor wraps requests sent by the Client and events received through subscriptions, e.g. for tracing,
+// logging or adding headers to payloads. InterceptRequest can change the request before calling invoke and the
+// response or error returned by it, or skip invoke at all. InterceptEvent can change the event or drop it
+// by not calling next. InterceptRequest runs on the goroutine of the caller, InterceptEvent on the receiver of the
+// connection the event arrived on, so one Interceptor sees requests and events of several brokers at once.
+type Interceptor interface {
+	InterceptRequest(ctx context.Context, message *Message, invoke Invoker) (*Message, error)
+	InterceptEvent(event *Message, next EventHandler)
+}
+
+// InterceptorFuncs implements Interceptor with functions. Nil function passes requests or events through unchanged.
+type InterceptorFuncs struct {
+	Request func(ctx context.Context, message *Message, invoke Invoker) (*Message, error)
+	Event   func(event *Message, next EventHandler)
+}
+
+// InterceptRequest calls Request function, if it's set.
+func (f InterceptorFuncs) InterceptRequest(ctx context.Context, message *Message, invoke Invoker) (*Message, error) {
+	if f.Request == nil {
+		return invoke(ctx, message)
+	}
+	return f.Request(ctx, message, invoke)
+}
+
+// InterceptEvent calls Event function, if it's set.
+func (f InterceptorFuncs) InterceptEvent(event *Message, next EventHandler) {
+	if f.Event == nil {
+		next(event)
+		return
+	}
+	f.Event(event, next)
+}
+
+// Use will add interceptors to the chain. First added interceptor is outermost, it sees requests first and responses last.
+// Interceptors apply to all connections of the pool, also to those opened afterwards.
+func (c *Client) Use(interceptors ...Interceptor) {
+	clients := []*Client{c}
+	if c.pool != nil {
+		clients = c.pool.Clients()
+	}
+	for _, client := range clients {
+		client.addInterceptors(interceptors)
+	}
+}
+
+func (c *Client) addInterceptors(interceptors []Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	chain := append(c.getInterceptors(), interceptors...)
+	c.interceptors.Store(chain[:len(chain):len(chain)])
+}
+
+func (c *Client) getInterceptors() []Interceptor {
+	interceptors, _ := c.interceptors.Load().([]Interceptor)
+	return interceptors
+}
+
+// intercept will pass the request through interceptors, innermost of them calls invoke.
+func (c *Client) intercept(ctx context.Context, message *Message, invoke Invoker) (*Message, error) {
+	interceptors := c.getInterceptors()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoke
+		invoke = func(ctx context.Context, message *Message) (*Message, error) {
+			return interceptor.InterceptRequest(ctx, message, next)
+		}
+	}
+	return invoke(ctx, message)
+}
+
+// interceptEvent will pass the event through interceptors, innermost of them calls deliver.
+func (c *Client) interceptEvent(event *Message, deliver EventHandler) {
+	interceptors := c.getInterceptors()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], deliver
+		deliver = func(event *Message) {
+			interceptor.InterceptEvent(event, next)
+		}
+	}
+	deliver(event)
+}
@@ -0,0 +1,74 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_Use(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	var calls []string
+	tracer := func(name string) zbc.Interceptor {
+		return zbc.InterceptorFuncs{
+			Request: func(ctx context.Context, message *zbc.Message, invoke zbc.Invoker) (*zbc.Message, error) {
+				calls = append(calls, name+" request")
+				response, err := invoke(ctx, message)
+				calls = append(calls, name+" response")
+				return response, err
+			},
+		}
+	}
+	dropper := zbc.InterceptorFuncs{
+		Event: func(event *zbc.Message, next zbc.EventHandler) {
+			if (*event.SbeMessage).(*sbe.SubscribedEvent).Key != 1 {
+				next(event)
+			}
+		},
+	}
+	client.Use(tracer("outer"), tracer("inner"), dropper)
+
+	if _, err := client.Topology(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"outer request", "inner request", "inner response", "outer response"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, received %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("Expected calls %v, received %v", expected, calls)
+		}
+	}
+
+	sub, err := client.OpenTaskSubscription(&zbc.TaskSubscription{TopicName: "default-topic", TaskType: "foo", Credits: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.PushTask(1, &zbc.Task{State: "LOCKED", Type: "foo"})
+	broker.PushTask(2, &zbc.Task{State: "LOCKED", Type: "foo"})
+
+	select {
+	case msg := <-sub.Events():
+		if key := (*msg.SbeMessage).(*sbe.SubscribedEvent).Key; key != 2 {
+			t.Fatalf("Expected task 1 to be dropped, received task %d", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Task was not delivered")
+	}
+}
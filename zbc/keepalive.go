@@ -0,0 +1,73 @@
+package zbc
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+)
+
+// DefaultKeepAliveInterval specifies how often Client sends heartbeat to the broker.
+const DefaultKeepAliveInterval = 10 * time.Second
+
+// keepAliveTimeoutFactor is number of intervals without any frame from the broker after which connection is considered dead.
+const keepAliveTimeoutFactor = 3
+
+// keepAliveFrame is control frame without body. It's 16 bytes long, frame header is padded to 8 byte boundary.
+var keepAliveFrame = func() []byte {
+	frame := make([]byte, 16)
+	protocol.ByteOrder.PutUint16(frame[6:], protocol.ControlKeepAlive)
+	return frame
+}()
+
+// KeepAliveInterval is a getter for interval of heartbeats.
+func (c *Client) KeepAliveInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.keepAlive))
+}
+
+// SetKeepAliveInterval is a setter for interval of heartbeats. Zero will disable heartbeats and liveness detection.
+func (c *Client) SetKeepAliveInterval(interval time.Duration) {
+	atomic.StoreInt64(&c.keepAlive, int64(interval))
+}
+
+// heartbeat will send keep alive frame every interval. If nothing was received from the broker during the last interval,
+// topology is requested too, so the receiver notices dead connection by its read deadline and reconnects.
+func (c *Client) heartbeat() {
+	for {
+		interval := c.KeepAliveInterval()
+		enabled := interval > 0
+		if !enabled {
+			interval = DefaultKeepAliveInterval
+		}
+
+		select {
+		case <-c.done:
+			return
+		case <-c.lifetime.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		if !enabled {
+			continue
+		}
+
+		// Failed write closes the connection, so the receiver reconnects.
+		if err := c.writes.write(keepAliveFrame, time.Now().Add(interval), c.lifetime.Done()); err == ErrClientClosed {
+			return
+		} else if err != nil {
+			c.log().Warn("Sending keep alive failed", F("addr", c.addr), F("error", err))
+			continue
+		}
+
+		lastReceived := time.Unix(0, atomic.LoadInt64(&c.lastReceived))
+		if time.Since(lastReceived) > interval {
+			c.spawn(func() {
+				ctx, cancel := context.WithTimeout(c.lifetime, interval)
+				defer cancel()
+				c.requestTopology(ctx)
+			})
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build go1.9
+// +build go1.9
+
+package zbc
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// withLabels will run f with pprof labels set on the goroutine, so CPU profiles attribute time of handlers to their
+// task type or topic. Context passed to f carries the labels, goroutines started with pprof.Do on it inherit them.
+func withLabels(ctx context.Context, f func(ctx context.Context), labels ...string) {
+	pprof.Do(ctx, pprof.Labels(labels...), f)
+}
@@ -0,0 +1,11 @@
+//go:build !go1.9
+// +build !go1.9
+
+package zbc
+
+import "context"
+
+// withLabels will run f, pprof labels need Go 1.9.
+func withLabels(ctx context.Context, f func(ctx context.Context), labels ...string) {
+	f(ctx)
+}
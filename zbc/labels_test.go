@@ -0,0 +1,54 @@
+//go:build go1.9
+// +build go1.9
+
+package zbc_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestWorker_ProfilerLabels(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.TaskCompleted})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	labels := make(chan [2]string, 1)
+	worker := client.NewWorker("payment-service", func(task *zbc.Task) (map[string]interface{}, error) {
+		taskType, _ := pprof.Label(task.Context(), "taskType")
+		topic, _ := pprof.Label(task.Context(), "topic")
+		labels <- [2]string{taskType, topic}
+		return nil, nil
+	})
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop()
+
+	broker.PushTask(1, &zbc.Task{State: zbc.TaskLocked, Type: "payment-service"})
+	select {
+	case received := <-labels:
+		if received != [2]string{"payment-service", "default-topic"} {
+			t.Fatalf("Expected labels of task type and topic, received %v", received)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Task was not handled")
+	}
+}
@@ -0,0 +1,87 @@
+package zbc
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+// Field is a key value pair attached to a log message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F is a constructor for Field.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger receives log messages of the Client about connection lifecycle, retries and subscriptions. Receiver,
+// heartbeat, reconnect and requests of the Client log independently, so messages can arrive at the same time.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+}
+
+type noopLogger struct{}
+
+func (noopLogger) Debug(string, ...Field) {}
+func (noopLogger) Info(string, ...Field)  {}
+func (noopLogger) Warn(string, ...Field)  {}
+func (noopLogger) Error(string, ...Field) {}
+
+// stdLogger writes messages through standard library logger as level, message and fields in key=value form.
+type stdLogger struct {
+	out   *log.Logger
+	debug bool
+}
+
+// NewStdLogger returns Logger which writes to out. Debug messages are dropped unless debug is set.
+func NewStdLogger(out *log.Logger, debug bool) Logger {
+	return &stdLogger{out: out, debug: debug}
+}
+
+func (l *stdLogger) print(level, msg string, fields []Field) {
+	var buf bytes.Buffer
+	buf.WriteString(level)
+	buf.WriteByte(' ')
+	buf.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&buf, " %s=%v", f.Key, f.Value)
+	}
+	l.out.Println(buf.String())
+}
+
+func (l *stdLogger) Debug(msg string, fields ...Field) {
+	if l.debug {
+		l.print("DEBUG", msg, fields)
+	}
+}
+
+func (l *stdLogger) Info(msg string, fields ...Field)  { l.print("INFO", msg, fields) }
+func (l *stdLogger) Warn(msg string, fields ...Field)  { l.print("WARN", msg, fields) }
+func (l *stdLogger) Error(msg string, fields ...Field) { l.print("ERROR", msg, fields) }
+
+// loggerHolder lets us keep Logger interface in atomic.Value, which needs values of the same concrete type.
+type loggerHolder struct {
+	logger Logger
+}
+
+// SetLogger will send log messages of the Client to the logger. Nothing is logged by default.
+// Connections to other brokers opened afterwards use the logger too.
+func (c *Client) SetLogger(logger Logger) {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	c.logger.Store(loggerHolder{logger})
+}
+
+func (c *Client) log() Logger {
+	if holder, ok := c.logger.Load().(loggerHolder); ok {
+		return holder.logger
+	}
+	return noopLogger{}
+}
@@ -0,0 +1,20 @@
+package zbc
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), false)
+
+	logger.Debug("dropped")
+	logger.Warn("Reconnect attempt failed", F("addr", "localhost:51015"), F("attempt", 2))
+
+	expected := "WARN Reconnect attempt failed addr=localhost:51015 attempt=2\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected %q, received %q", expected, buf.String())
+	}
+}
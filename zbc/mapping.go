@@ -0,0 +1,232 @@
+package zbc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Mapping copies value at Source path of one payload to Target path of another, like input and output mappings of
+// BPMN tasks in Zeebe. Paths are JSONPath-like: $ is the whole payload, $.order.id a nested property, $.items[0]
+// an element of an array and $['total price'] a property whose name isn't a plain word. Target can't refer
+// to an element of an array, objects on the way to it are created if they are missing.
+type Mapping struct {
+	Source string
+	Target string
+}
+
+// WithInputMappings sets mappings which build payload passed to the handler out of payload of the task, so the handler
+// sees only the part it cares about. Handler gets the whole payload by default. Task whose payload misses source of
+// a mapping is failed without calling the handler.
+func WithInputMappings(mappings ...Mapping) WorkerOption {
+	return func(w *Worker) {
+		w.inputMappings = mappings
+	}
+}
+
+// WithOutputMappings sets mappings which take payload returned by the handler and merge it into payload of the task,
+// the result completes the task. By default payload returned by the handler completes the task as it is. Task is
+// failed if payload returned by the handler misses source of a mapping.
+func WithOutputMappings(mappings ...Mapping) WorkerOption {
+	return func(w *Worker) {
+		w.outputMappings = mappings
+	}
+}
+
+// mapInput returns copy of the task with payload built by input mappings, or the task itself if there are none.
+func (w *Worker) mapInput(task *Task) (*Task, error) {
+	if len(w.inputMappings) == 0 {
+		return task, nil
+	}
+	payload, err := applyMappings(w.inputMappings, task.PayloadJson, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := *task
+	mapped.PayloadJson = payload
+	if err := mapped.SetPayloadObject(payload); err != nil {
+		return nil, err
+	}
+	return &mapped, nil
+}
+
+// mapOutput returns payload of the task merged with payload returned by the handler through output mappings,
+// or payload of the handler if there are none.
+func (w *Worker) mapOutput(task *Task, payload map[string]interface{}) (map[string]interface{}, error) {
+	if len(w.outputMappings) == 0 {
+		return payload, nil
+	}
+	return applyMappings(w.outputMappings, payload, task.PayloadJson)
+}
+
+// applyMappings will copy values of source to target by mappings. Target is not modified, its copy is returned.
+func applyMappings(mappings []Mapping, source, target map[string]interface{}) (map[string]interface{}, error) {
+	var doc interface{}
+	if source != nil {
+		doc = source
+	}
+	result := target
+	if result == nil {
+		result = make(map[string]interface{})
+	}
+	for _, m := range mappings {
+		sourcePath, err := parsePath(m.Source)
+		if err != nil {
+			return nil, err
+		}
+		targetPath, err := parsePath(m.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := lookup(doc, sourcePath)
+		if !ok {
+			return nil, fmt.Errorf("Source %s of mapping not found in payload", m.Source)
+		}
+		if result, err = assign(result, targetPath, value); err != nil {
+			return nil, fmt.Errorf("Cannot map %s to %s: %s", m.Source, m.Target, err)
+		}
+	}
+	return result, nil
+}
+
+// pathSegment is property of an object or element of an array, if index isn't negative.
+type pathSegment struct {
+	key   string
+	index int
+}
+
+// parsePath will split the path into segments following $.
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("Path %s must start with $", path)
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("Path %s has empty property name", path)
+			}
+			segments = append(segments, pathSegment{key: rest[1 : end+1], index: -1})
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("Path %s has unclosed bracket", path)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1], index: -1})
+			} else if index, err := strconv.Atoi(inner); err == nil && index >= 0 {
+				segments = append(segments, pathSegment{index: index})
+			} else {
+				return nil, fmt.Errorf("Path %s has invalid subscript [%s]", path, inner)
+			}
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("Path %s has unexpected character %q", path, rest[0])
+		}
+	}
+	return segments, nil
+}
+
+// lookup returns value at path of the document. Objects decoded from message pack may have keys of type interface{}.
+// Document which is nil has no value, not even at $.
+func lookup(doc interface{}, path []pathSegment) (interface{}, bool) {
+	if doc == nil {
+		return nil, false
+	}
+	for _, segment := range path {
+		var ok bool
+		if segment.index >= 0 {
+			array, isArray := doc.([]interface{})
+			if !isArray || segment.index >= len(array) {
+				return nil, false
+			}
+			doc = array[segment.index]
+			continue
+		}
+
+		switch object := doc.(type) {
+		case map[string]interface{}:
+			doc, ok = object[segment.key]
+		case map[interface{}]interface{}:
+			doc, ok = object[segment.key]
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+// assign returns copy of the document with value set at path. Objects on the path are copied, so the document
+// is not modified.
+func assign(doc map[string]interface{}, path []pathSegment, value interface{}) (map[string]interface{}, error) {
+	if len(path) == 0 {
+		object, ok := toObject(value)
+		if !ok {
+			return nil, errors.New("Value mapped to $ must be an object")
+		}
+		return object, nil
+	}
+
+	segment := path[0]
+	if segment.index >= 0 {
+		return nil, errors.New("Target can't refer to an element of an array")
+	}
+
+	result := make(map[string]interface{}, len(doc)+1)
+	for k, v := range doc {
+		result[k] = v
+	}
+	if len(path) == 1 {
+		result[segment.key] = value
+		return result, nil
+	}
+
+	child := make(map[string]interface{})
+	if existing := result[segment.key]; existing != nil {
+		object, ok := toObject(existing)
+		if !ok {
+			return nil, fmt.Errorf("Property %s is not an object", segment.key)
+		}
+		child = object
+	}
+	child, err := assign(child, path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	result[segment.key] = child
+	return result, nil
+}
+
+// toObject converts object decoded from message pack to map with string keys.
+func toObject(v interface{}) (map[string]interface{}, bool) {
+	switch object := v.(type) {
+	case map[string]interface{}:
+		return object, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(object))
+		for k, v := range object {
+			key, ok := k.(string)
+			if !ok {
+				return nil, false
+			}
+			converted[key] = v
+		}
+		return converted, true
+	}
+	return nil, false
+}
@@ -0,0 +1,70 @@
+package zbc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyMappings(t *testing.T) {
+	source := map[string]interface{}{
+		"order": map[interface{}]interface{}{"id": "o-1", "total price": 42},
+		"items": []interface{}{"book", "pen"},
+		"empty": nil,
+	}
+
+	tests := []struct {
+		mappings []Mapping
+		target   map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			mappings: []Mapping{{Source: "$.order.id", Target: "$.orderId"}, {Source: "$.items[1]", Target: "$.item"}},
+			expected: map[string]interface{}{"orderId": "o-1", "item": "pen"},
+		},
+		{
+			mappings: []Mapping{{Source: "$.order['total price']", Target: "$.payment.amount"}, {Source: "$.empty", Target: "$.note"}},
+			target:   map[string]interface{}{"payment": map[interface{}]interface{}{"currency": "EUR"}},
+			expected: map[string]interface{}{"payment": map[string]interface{}{"currency": "EUR", "amount": 42}, "note": nil},
+		},
+		{
+			mappings: []Mapping{{Source: "$.order", Target: "$"}},
+			expected: map[string]interface{}{"id": "o-1", "total price": 42},
+		},
+	}
+
+	for _, test := range tests {
+		result, err := applyMappings(test.mappings, source, test.target)
+		if err != nil {
+			t.Fatalf("Mappings %+v failed: %s", test.mappings, err)
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Fatalf("Mappings %+v expected %v, received %v", test.mappings, test.expected, result)
+		}
+	}
+}
+
+func TestApplyMappings_Errors(t *testing.T) {
+	source := map[string]interface{}{"order": map[string]interface{}{"id": "o-1"}, "items": []interface{}{"book"}}
+	target := map[string]interface{}{"order": "o-1"}
+
+	for _, m := range []Mapping{
+		{Source: "order.id", Target: "$.id"},
+		{Source: "$.order.", Target: "$.id"},
+		{Source: "$.items[x]", Target: "$.id"},
+		{Source: "$.items[1]", Target: "$.id"},
+		{Source: "$.missing", Target: "$.id"},
+		{Source: "$.items", Target: "$"},
+		{Source: "$.items[0]", Target: "$.items[0]"},
+		{Source: "$.order.id", Target: "$.order.id"},
+	} {
+		if _, err := applyMappings([]Mapping{m}, source, target); err == nil {
+			t.Fatalf("Expected mapping %+v to fail", m)
+		}
+	}
+	if len(target) != 1 || target["order"] != "o-1" {
+		t.Fatalf("Target was modified: %v", target)
+	}
+	if _, err := applyMappings([]Mapping{{Source: "$", Target: "$"}}, nil, nil); err == nil {
+		t.Fatal("Expected mapping of missing payload to fail")
+	}
+}
@@ -2,19 +2,32 @@ package zbc
 
 import (
 	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"io"
+	"strings"
 
 	"github.com/zeebe-io/zbc-go/zbc/protocol"
 	"github.com/zeebe-io/zbc-go/zbc/sbe"
 )
 
+var errNoData = errors.New("Message has no message pack data")
+
 const (
+	templateIDErrorResponse          = 0
 	templateIDExecuteCommandRequest  = 20
 	templateIDExecuteCommandResponse = 21
+	templateIDControlMessageRequest  = 10
 	templateIDControlMessageResponse = 11
 	templateIDSubscriptionEvent      = 30
 )
 
+// SchemaID and SchemaVersion identify SBE schema of the messages this client understands.
+const (
+	SchemaID      = 0
+	SchemaVersion = 2
+)
+
 const (
 	FrameHeaderSize = 12
 	TransportHeaderSize = 2
@@ -53,11 +66,84 @@ func (h *Headers) IsSingleMessage() bool {
 	return h.RequestResponseHeader == nil
 }
 
+// IsControlFrame is helper to determine if frame carries no message, like keep alive frame.
+func (h *Headers) IsControlFrame() bool {
+	return h.FrameHeader != nil && h.FrameHeader.TypeID != protocol.FrameTypeMessage
+}
+
 // SetSbeMessageHeader is a setter for SBEMessageHeader.
 func (h *Headers) SetSbeMessageHeader(header *sbe.MessageHeader) {
 	h.SbeMessageHeader = header
 }
 
+// FrameLength returns length of the frame body, zero if FrameHeader is not set.
+func (h *Headers) FrameLength() uint32 {
+	if h.FrameHeader == nil {
+		return 0
+	}
+	return h.FrameHeader.Length
+}
+
+// StreamID returns stream of the frame, zero if FrameHeader is not set.
+func (h *Headers) StreamID() uint32 {
+	if h.FrameHeader == nil {
+		return 0
+	}
+	return h.FrameHeader.StreamID
+}
+
+// ProtocolID returns protocol of the transport layer. False is returned if TransportHeader is not set.
+func (h *Headers) ProtocolID() (uint16, bool) {
+	if h.TransportHeader == nil {
+		return 0, false
+	}
+	return h.TransportHeader.ProtocolID, true
+}
+
+// RequestID returns ID of the request the message belongs to. False is returned for single messages.
+func (h *Headers) RequestID() (uint64, bool) {
+	if h.RequestResponseHeader == nil {
+		return 0, false
+	}
+	return h.RequestResponseHeader.RequestID, true
+}
+
+// TemplateID returns SBE template of the message. False is returned if SbeMessageHeader is not set.
+func (h *Headers) TemplateID() (uint16, bool) {
+	if h.SbeMessageHeader == nil {
+		return 0, false
+	}
+	return h.SbeMessageHeader.TemplateId, true
+}
+
+// String returns all headers which are set in a form suitable for logs.
+func (h *Headers) String() string {
+	var layers []string
+	if h.FrameHeader != nil {
+		layers = append(layers, h.FrameHeader.String())
+	}
+	if h.TransportHeader != nil {
+		layers = append(layers, h.TransportHeader.String())
+	}
+	if h.RequestResponseHeader != nil {
+		layers = append(layers, h.RequestResponseHeader.String())
+	}
+	if h.SbeMessageHeader != nil {
+		layers = append(layers, h.SbeMessageHeader.String())
+	}
+	return "Headers{" + strings.Join(layers, ", ") + "}"
+}
+
+// MarshalJSON encodes headers which are set under keys frame, transport, requestResponse and sbe.
+func (h *Headers) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Frame           *protocol.FrameHeader           `json:"frame,omitempty"`
+		Transport       *protocol.TransportHeader       `json:"transport,omitempty"`
+		RequestResponse *protocol.RequestResponseHeader `json:"requestResponse,omitempty"`
+		Sbe             *sbe.MessageHeader              `json:"sbe,omitempty"`
+	}{h.FrameHeader, h.TransportHeader, h.RequestResponseHeader, h.SbeMessageHeader})
+}
+
 // SBE interface is apstraction over all SBE Messages.
 type SBE interface {
 	Encode(writer io.Writer, order binary.ByteOrder, doRangeCheck bool) error
@@ -69,6 +155,7 @@ type Message struct {
 	Headers    *Headers
 	SbeMessage *SBE
 	Data       *map[string]interface{}
+	Duplicate  bool // Response remembered from the first command with the same request key, see SetDeduplicationWindow.
 }
 
 // SetHeaders is a setter for Headers attribute.
@@ -85,3 +172,27 @@ func (m *Message) SetSbeMessage(data SBE) {
 func (m *Message) SetData(data *map[string]interface{}) {
 	m.Data = data
 }
+
+// UnmarshalData will decode message pack layer of the message into v, which can be any type with msgpack struct tags.
+func (m *Message) UnmarshalData(v interface{}) error {
+	if m.SbeMessage == nil {
+		return errNoData
+	}
+
+	var data []byte
+	switch sbeMessage := (*m.SbeMessage).(type) {
+	case *sbe.ExecuteCommandRequest:
+		data = sbeMessage.Command
+	case *sbe.ExecuteCommandResponse:
+		data = sbeMessage.Event
+	case *sbe.ControlMessageRequest:
+		data = sbeMessage.Data
+	case *sbe.ControlMessageResponse:
+		data = sbeMessage.Data
+	case *sbe.SubscribedEvent:
+		data = sbeMessage.Event
+	default:
+		return errNoData
+	}
+	return unmarshalMessagePack(data, v)
+}
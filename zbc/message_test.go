@@ -0,0 +1,87 @@
+package zbc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestHeaders_String(t *testing.T) {
+	msg := NewTopologyRequestMessage()
+	msg.Headers.RequestResponseHeader.RequestID = 3
+
+	if id, ok := msg.Headers.RequestID(); !ok || id != 3 {
+		t.Fatalf("Expected request ID 3, received %d", id)
+	}
+	if template, ok := msg.Headers.TemplateID(); !ok || template != (sbe.ControlMessageRequest{}).SbeTemplateId() {
+		t.Fatalf("Expected template of control message request, received %d", template)
+	}
+
+	expected := "Headers{FrameHeader{length: 22, version: 0, flags: none, type: message, stream: 2}, " +
+		"TransportHeader{protocol: requestResponse}, RequestResponseHeader{requestId: 3}, " +
+		"MessageHeader{blockLength: 1, template: ControlMessageRequest, schema: 0, version: 2}}"
+	if msg.Headers.String() != expected {
+		t.Fatalf("Expected %s, received %s", expected, msg.Headers.String())
+	}
+
+	single := &Headers{}
+	if _, ok := single.RequestID(); ok {
+		t.Fatal("Expected no request ID of single message")
+	}
+	content, err := json.Marshal(single)
+	if err != nil || string(content) != "{}" {
+		t.Fatalf("Expected headers which are not set omitted, received %s, %v", content, err)
+	}
+
+	content, err = json.Marshal(msg.Headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]map[string]interface{}
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["requestResponse"]["requestId"] != float64(3) || decoded["sbe"]["templateId"] != float64(10) {
+		t.Fatalf("Expected headers encoded with lower camel case keys, received %s", content)
+	}
+}
+
+func TestMessage_TaskEvent(t *testing.T) {
+	payload, _ := msgpack.Marshal(map[string]interface{}{"orderId": 31243})
+	b, _ := msgpack.Marshal(map[string]interface{}{
+		"state":     TaskLocked,
+		"type":      "foo",
+		"retries":   3,
+		"lockOwner": "zbc",
+		"lockTime":  int64(1500000000000),
+		"headers":   map[string]interface{}{"bpmnProcessId": "order-process"},
+		"payload":   payload,
+	})
+	event := &sbe.SubscribedEvent{Key: 7, PartitionId: 1, Position: 12, EventType: sbe.EventType.TASK_EVENT, TopicName: []byte("default-topic"), Event: b}
+	msg := &Message{}
+	msg.SetSbeMessage(event)
+
+	task, err := msg.TaskEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Key != 7 || task.TopicName != "default-topic" || task.Type != "foo" || task.Retries != 3 || task.Event != event {
+		t.Fatalf("Expected task foo with key 7, received %+v", task)
+	}
+	if task.Headers["bpmnProcessId"] != "order-process" || task.LockExpiration().Unix() != 1500000000 {
+		t.Fatalf("Expected headers and lock time decoded, received %+v", task)
+	}
+	var p struct {
+		OrderID int `msgpack:"orderId"`
+	}
+	if err := task.UnmarshalPayload(&p); err != nil || p.OrderID != 31243 {
+		t.Fatalf("Expected raw payload kept, decoded %+v, %v", p, err)
+	}
+
+	event.EventType = sbe.EventType.INCIDENT_EVENT
+	if _, err := msg.TaskEvent(); err != errNotTaskEvent {
+		t.Fatalf("Expected incident rejected, received %v", err)
+	}
+}
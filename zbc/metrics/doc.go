@@ -0,0 +1,16 @@
+// Package metrics exports health of the zbc Client as Prometheus metrics or expvar counters. Prometheus client library
+// is not vendored, so Metrics is built only with the prometheus build tag:
+//
+//	go get github.com/prometheus/client_golang/prometheus
+//	go build -tags prometheus
+//
+// Usage:
+//
+//	m, err := metrics.New(prometheus.DefaultRegisterer)
+//	client.SetObserver(m)
+//
+// Expvar needs no build tag. Its counters are served as JSON by /debug/vars of http.DefaultServeMux:
+//
+//	v, err := metrics.NewExpvar("zbc")
+//	client.SetObserver(v)
+package metrics
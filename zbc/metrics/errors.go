@@ -0,0 +1,22 @@
+package metrics
+
+import "github.com/zeebe-io/zbc-go/zbc"
+
+var errorLabels = map[error]string{
+	zbc.ErrMessageNotSupported:      "message_not_supported",
+	zbc.ErrTopicNotFound:            "topic_not_found",
+	zbc.ErrPartitionNotFound:        "partition_not_found",
+	zbc.ErrRequestWriteFailure:      "request_write_failure",
+	zbc.ErrInvalidClientVersion:     "invalid_client_version",
+	zbc.ErrRequestTimeout:           "timeout",
+	zbc.ErrRequestProcessingFailure: "request_processing_failure",
+	zbc.ErrBroker:                   "broker",
+}
+
+// errorLabel keeps number of label values low, errors which are not returned by the broker are counted together.
+func errorLabel(err error) string {
+	if label, ok := errorLabels[zbc.Cause(err)]; ok {
+		return label
+	}
+	return "other"
+}
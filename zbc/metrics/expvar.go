@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"encoding/json"
+	"errors"
+	"expvar"
+	"strconv"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+var errExpvarPublished = errors.New("Expvar with this name is published already")
+
+// Expvar is zbc.Observer which counts requests and events of the Client in expvar maps published under one name.
+// Counters are keyed by type of the request or event, credits and statistics of subscriptions keep their last value.
+type Expvar struct {
+	requests      *expvar.Map
+	responses     *expvar.Map
+	errors        *expvar.Map // Failed requests by type of the request and error label, e.g. CREATE/timeout.
+	latency       *expvar.Map // Sum of latencies in milliseconds by type of the request, divide by responses for mean.
+	events        *expvar.Map
+	dropped       *expvar.Map
+	filtered      *expvar.Map
+	credits       *expvar.Map
+	queued        *expvar.Int
+	subscriptions *expvar.Map // Statistics by subscription and partition, e.g. payment-service/0.
+}
+
+// NewExpvar will publish counters of the Client under the name. Expvar can't be unpublished, so every name can be
+// used once per process.
+func NewExpvar(name string) (*Expvar, error) {
+	if expvar.Get(name) != nil {
+		return nil, errExpvarPublished
+	}
+
+	v := &Expvar{
+		requests:      new(expvar.Map).Init(),
+		responses:     new(expvar.Map).Init(),
+		errors:        new(expvar.Map).Init(),
+		latency:       new(expvar.Map).Init(),
+		events:        new(expvar.Map).Init(),
+		dropped:       new(expvar.Map).Init(),
+		filtered:      new(expvar.Map).Init(),
+		credits:       new(expvar.Map).Init(),
+		queued:        new(expvar.Int),
+		subscriptions: new(expvar.Map).Init(),
+	}
+
+	root := expvar.NewMap(name)
+	root.Set("requestsSent", v.requests)
+	root.Set("responsesReceived", v.responses)
+	root.Set("requestErrors", v.errors)
+	root.Set("requestLatencyMs", v.latency)
+	root.Set("eventsReceived", v.events)
+	root.Set("eventsDropped", v.dropped)
+	root.Set("eventsFiltered", v.filtered)
+	root.Set("credits", v.credits)
+	root.Set("queuedTasks", v.queued)
+	root.Set("subscriptions", v.subscriptions)
+	return v, nil
+}
+
+// RequestSent implements zbc.Observer.
+func (v *Expvar) RequestSent(requestType string) {
+	v.requests.Add(requestType, 1)
+}
+
+// ResponseReceived implements zbc.Observer.
+func (v *Expvar) ResponseReceived(requestType string, latency time.Duration) {
+	v.responses.Add(requestType, 1)
+	v.latency.AddFloat(requestType, float64(latency)/float64(time.Millisecond))
+}
+
+// RequestFailed implements zbc.Observer.
+func (v *Expvar) RequestFailed(requestType string, err error) {
+	v.errors.Add(requestType+"/"+errorLabel(err), 1)
+}
+
+// EventReceived implements zbc.Observer.
+func (v *Expvar) EventReceived(eventType string) {
+	v.events.Add(eventType, 1)
+}
+
+// EventDropped implements zbc.DropObserver.
+func (v *Expvar) EventDropped(eventType string) {
+	v.dropped.Add(eventType, 1)
+}
+
+// EventFiltered implements zbc.FilterObserver.
+func (v *Expvar) EventFiltered(eventType string) {
+	v.filtered.Add(eventType, 1)
+}
+
+// CreditsChanged implements zbc.Observer.
+func (v *Expvar) CreditsChanged(taskType string, credits int32) {
+	value := new(expvar.Int)
+	value.Set(int64(credits))
+	v.credits.Set(taskType, value)
+}
+
+// TasksQueued implements zbc.QueueObserver.
+func (v *Expvar) TasksQueued(depth int) {
+	v.queued.Set(int64(depth))
+}
+
+// SubscriptionStatsChanged implements zbc.SubscriptionObserver.
+func (v *Expvar) SubscriptionStatsChanged(subscription string, partitionID uint16, stats zbc.SubscriptionStats) {
+	v.subscriptions.Set(subscription+"/"+strconv.Itoa(int(partitionID)), subscriptionStats(stats))
+}
+
+// subscriptionStats is expvar.Var of the statistics, printed as JSON with their tags.
+type subscriptionStats zbc.SubscriptionStats
+
+func (s subscriptionStats) String() string {
+	b, err := json.Marshal(zbc.SubscriptionStats(s))
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+func TestExpvar(t *testing.T) {
+	v, err := NewExpvar("zbc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewExpvar("zbc-test"); err != errExpvarPublished {
+		t.Fatalf("Expected name to be published once, received %v", err)
+	}
+
+	v.RequestSent("TASK_EVENT")
+	v.RequestSent("TASK_EVENT")
+	v.ResponseReceived("TASK_EVENT", 3*time.Millisecond)
+	v.RequestFailed("TASK_EVENT", zbc.ErrRequestTimeout)
+	v.CreditsChanged("foo", 32)
+	v.CreditsChanged("foo", 31)
+	v.SubscriptionStatsChanged("foo", 0, zbc.SubscriptionStats{Received: 2, Pending: 1})
+
+	var published struct {
+		RequestsSent  map[string]int                   `json:"requestsSent"`
+		RequestErrors map[string]int                   `json:"requestErrors"`
+		Credits       map[string]int                   `json:"credits"`
+		Subscriptions map[string]zbc.SubscriptionStats `json:"subscriptions"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("zbc-test").String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	if published.RequestsSent["TASK_EVENT"] != 2 || published.RequestErrors["TASK_EVENT/timeout"] != 1 || published.Credits["foo"] != 31 {
+		t.Fatalf("Unexpected counters %+v", published)
+	}
+	if stats := published.Subscriptions["foo/0"]; stats.Received != 2 || stats.Pending != 1 {
+		t.Fatalf("Unexpected statistics of subscription %+v", stats)
+	}
+}
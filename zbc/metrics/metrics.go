@@ -0,0 +1,151 @@
+//go:build prometheus
+// +build prometheus
+
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+// Metrics is zbc.Observer which records requests and events of the Client as Prometheus metrics.
+type Metrics struct {
+	requests  *prometheus.CounterVec
+	responses *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	latency   *prometheus.HistogramVec
+	events    *prometheus.CounterVec
+	dropped   *prometheus.CounterVec
+	filtered  *prometheus.CounterVec
+	credits   *prometheus.GaugeVec
+	queued    prometheus.Gauge
+	pending   *prometheus.GaugeVec
+	buffered  *prometheus.GaugeVec
+	lag       *prometheus.GaugeVec
+}
+
+// New will create metrics and register them against the registerer.
+func New(registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zbc",
+			Name:      "requests_sent_total",
+			Help:      "Number of requests sent to the broker.",
+		}, []string{"type"}),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zbc",
+			Name:      "responses_received_total",
+			Help:      "Number of successful responses received from the broker.",
+		}, []string{"type"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zbc",
+			Name:      "request_errors_total",
+			Help:      "Number of failed requests by kind of the error.",
+		}, []string{"type", "error"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "zbc",
+			Name:      "request_latency_seconds",
+			Help:      "Time between sending the request and receiving its response.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"type"}),
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zbc",
+			Name:      "subscription_events_received_total",
+			Help:      "Number of events received through subscriptions.",
+		}, []string{"event_type"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zbc",
+			Name:      "subscription_events_dropped_total",
+			Help:      "Number of events dropped because buffer of the subscription was full.",
+		}, []string{"event_type"}),
+		filtered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "zbc",
+			Name:      "subscription_events_filtered_total",
+			Help:      "Number of events dropped by filters of subscriptions.",
+		}, []string{"event_type"}),
+		credits: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "zbc",
+			Name:      "task_subscription_credits",
+			Help:      "Credits of task subscriptions which are outstanding.",
+		}, []string{"task_type"}),
+		queued: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "zbc",
+			Name:      "queued_tasks",
+			Help:      "Tasks waiting in task queue until the broker is reachable.",
+		}),
+		pending: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "zbc",
+			Name:      "subscription_events_pending",
+			Help:      "Events taken by the consumer of the subscription but not yet acknowledged, completed or failed.",
+		}, []string{"subscription", "partition"}),
+		buffered: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "zbc",
+			Name:      "subscription_events_buffered",
+			Help:      "Events received by the subscription but not yet taken by its consumer.",
+		}, []string{"subscription", "partition"}),
+		lag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "zbc",
+			Name:      "subscription_position_lag",
+			Help:      "Distance in the log between the last event received and the last event acknowledged.",
+		}, []string{"subscription", "partition"}),
+	}
+
+	for _, collector := range []prometheus.Collector{m.requests, m.responses, m.errors, m.latency, m.events, m.dropped, m.filtered, m.credits, m.queued, m.pending, m.buffered, m.lag} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// RequestSent implements zbc.Observer.
+func (m *Metrics) RequestSent(requestType string) {
+	m.requests.WithLabelValues(requestType).Inc()
+}
+
+// ResponseReceived implements zbc.Observer.
+func (m *Metrics) ResponseReceived(requestType string, latency time.Duration) {
+	m.responses.WithLabelValues(requestType).Inc()
+	m.latency.WithLabelValues(requestType).Observe(latency.Seconds())
+}
+
+// RequestFailed implements zbc.Observer.
+func (m *Metrics) RequestFailed(requestType string, err error) {
+	m.errors.WithLabelValues(requestType, errorLabel(err)).Inc()
+}
+
+// EventReceived implements zbc.Observer.
+func (m *Metrics) EventReceived(eventType string) {
+	m.events.WithLabelValues(eventType).Inc()
+}
+
+// EventDropped implements zbc.DropObserver.
+func (m *Metrics) EventDropped(eventType string) {
+	m.dropped.WithLabelValues(eventType).Inc()
+}
+
+// EventFiltered implements zbc.FilterObserver.
+func (m *Metrics) EventFiltered(eventType string) {
+	m.filtered.WithLabelValues(eventType).Inc()
+}
+
+// CreditsChanged implements zbc.Observer.
+func (m *Metrics) CreditsChanged(taskType string, credits int32) {
+	m.credits.WithLabelValues(taskType).Set(float64(credits))
+}
+
+// TasksQueued implements zbc.QueueObserver.
+func (m *Metrics) TasksQueued(depth int) {
+	m.queued.Set(float64(depth))
+}
+
+// SubscriptionStatsChanged implements zbc.SubscriptionObserver.
+func (m *Metrics) SubscriptionStatsChanged(subscription string, partitionID uint16, stats zbc.SubscriptionStats) {
+	partition := strconv.Itoa(int(partitionID))
+	m.pending.WithLabelValues(subscription, partition).Set(float64(stats.Pending))
+	m.buffered.WithLabelValues(subscription, partition).Set(float64(stats.Buffered))
+	m.lag.WithLabelValues(subscription, partition).Set(float64(stats.PositionLag))
+}
@@ -0,0 +1,786 @@
+// Code generated by gen.go from zbc.ZeebeClient. DO NOT EDIT.
+
+package mock
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// Client is stub of zbc.ZeebeClient. Every method records the call and calls function in the field of the same
+// name with Func suffix. Method whose function isn't set returns zero values and ErrNotStubbed, if it returns error.
+type Client struct {
+	recorder
+
+	AcknowledgeTopicEventFunc               func(*sbe.SubscribedEvent, ...zbc.RequestOption) (*zbc.Message, error)
+	AcknowledgeTopicEventCtxFunc            func(context.Context, *sbe.SubscribedEvent) (*zbc.Message, error)
+	BrokerProtocolFunc                      func(...zbc.RequestOption) (*zbc.BrokerProtocol, error)
+	BrokerProtocolCtxFunc                   func(context.Context) (*zbc.BrokerProtocol, error)
+	CancelWorkflowInstanceFunc              func(string, int32, int64, ...zbc.RequestOption) (*zbc.Message, error)
+	CancelWorkflowInstanceCtxFunc           func(context.Context, string, int32, int64) (*zbc.Message, error)
+	CloseFunc                               func(context.Context) error
+	CompleteTaskFunc                        func(*sbe.SubscribedEvent, map[string]interface{}, ...zbc.RequestOption) (*zbc.Message, error)
+	CompleteTaskCtxFunc                     func(context.Context, *sbe.SubscribedEvent, map[string]interface{}) (*zbc.Message, error)
+	CreateTaskFunc                          func(string, *zbc.Task, ...zbc.RequestOption) (*zbc.Message, error)
+	CreateTaskCtxFunc                       func(context.Context, string, *zbc.Task) (*zbc.Message, error)
+	CreateTasksFunc                         func(string, []*zbc.Task, ...zbc.RequestOption) ([]*zbc.Message, error)
+	CreateTasksCtxFunc                      func(context.Context, string, []*zbc.Task) ([]*zbc.Message, error)
+	CreateTopicFunc                         func(string, int, ...zbc.RequestOption) (*zbc.Topic, error)
+	CreateTopicCtxFunc                      func(context.Context, string, int) (*zbc.Topic, error)
+	CreateWorkflowInstanceFunc              func(string, string, int, map[string]interface{}, ...zbc.RequestOption) (*zbc.Message, error)
+	CreateWorkflowInstanceCtxFunc           func(context.Context, string, string, int, map[string]interface{}) (*zbc.Message, error)
+	CreateWorkflowInstanceWithResultFunc    func(string, string, int, map[string]interface{}, ...zbc.RequestOption) (*zbc.WorkflowInstanceResult, error)
+	CreateWorkflowInstanceWithResultCtxFunc func(context.Context, string, string, int, map[string]interface{}) (*zbc.WorkflowInstanceResult, error)
+	DeployWorkflowFunc                      func(string, []byte, ...zbc.RequestOption) (*zbc.Message, error)
+	DeployWorkflowCtxFunc                   func(context.Context, string, []byte) (*zbc.Message, error)
+	DeployWorkflowResourceFunc              func(string, string, []byte, ...zbc.RequestOption) (*zbc.Message, error)
+	DeployWorkflowResourceCtxFunc           func(context.Context, string, string, []byte) (*zbc.Message, error)
+	FailTaskFunc                            func(*sbe.SubscribedEvent, int, string, ...zbc.RequestOption) (*zbc.Message, error)
+	FailTaskCtxFunc                         func(context.Context, *sbe.SubscribedEvent, int, string) (*zbc.Message, error)
+	FlushTaskQueueFunc                      func() error
+	FlushTaskQueueCtxFunc                   func(context.Context) error
+	GetWorkflowFunc                         func(string, string, int, ...zbc.RequestOption) (*zbc.Workflow, error)
+	GetWorkflowCtxFunc                      func(context.Context, string, string, int) (*zbc.Workflow, error)
+	HealthCheckFunc                         func(...zbc.RequestOption) (*zbc.Health, error)
+	HealthCheckCtxFunc                      func(context.Context) (*zbc.Health, error)
+	ListTasksFunc                           func(string, ...zbc.RequestOption) ([]*zbc.TaskSnapshot, error)
+	ListTasksCtxFunc                        func(context.Context, string) ([]*zbc.TaskSnapshot, error)
+	ListWorkflowInstancesFunc               func(string, ...zbc.RequestOption) ([]*zbc.WorkflowInstanceSnapshot, error)
+	ListWorkflowInstancesCtxFunc            func(context.Context, string) ([]*zbc.WorkflowInstanceSnapshot, error)
+	ListWorkflowsFunc                       func(string, ...zbc.RequestOption) ([]*zbc.Workflow, error)
+	ListWorkflowsCtxFunc                    func(context.Context, string) ([]*zbc.Workflow, error)
+	NewWorkerFunc                           func(string, zbc.TaskHandler, ...zbc.WorkerOption) *zbc.Worker
+	OpenIncidentSubscriptionFunc            func(*zbc.TopicSubscription, ...zbc.RequestOption) (*zbc.Subscription, error)
+	OpenIncidentSubscriptionCtxFunc         func(context.Context, *zbc.TopicSubscription) (*zbc.Subscription, error)
+	OpenTaskSubscriptionFunc                func(*zbc.TaskSubscription, ...zbc.RequestOption) (*zbc.Subscription, error)
+	OpenTaskSubscriptionCtxFunc             func(context.Context, *zbc.TaskSubscription) (*zbc.Subscription, error)
+	OpenTopicSubscriptionFunc               func(*zbc.TopicSubscription, ...zbc.RequestOption) (*zbc.Subscription, error)
+	OpenTopicSubscriptionCtxFunc            func(context.Context, *zbc.TopicSubscription) (*zbc.Subscription, error)
+	ResolveIncidentFunc                     func(*sbe.SubscribedEvent, map[string]interface{}, ...zbc.RequestOption) (*zbc.Message, error)
+	ResolveIncidentCtxFunc                  func(context.Context, *sbe.SubscribedEvent, map[string]interface{}) (*zbc.Message, error)
+	ResponderFunc                           func(*zbc.Message, ...zbc.RequestOption) (*zbc.Message, error)
+	ResponderCtxFunc                        func(context.Context, *zbc.Message) (*zbc.Message, error)
+	SendAsyncFunc                           func(*zbc.Message, ...zbc.RequestOption) (<-chan *zbc.Response, error)
+	SendAsyncCtxFunc                        func(context.Context, *zbc.Message) (<-chan *zbc.Response, error)
+	SendBatchFunc                           func([]*zbc.Message, ...zbc.RequestOption) ([]*zbc.Message, error)
+	SendBatchCtxFunc                        func(context.Context, []*zbc.Message) ([]*zbc.Message, error)
+	SendControlMessageFunc                  func(sbe.ControlMessageTypeEnum, map[string]interface{}, ...zbc.RequestOption) (*zbc.Message, error)
+	SendControlMessageCtxFunc               func(context.Context, sbe.ControlMessageTypeEnum, map[string]interface{}) (*zbc.Message, error)
+	SendRawCommandFunc                      func(string, sbe.EventTypeEnum, int32, int64, []byte, ...zbc.RequestOption) (map[string]interface{}, error)
+	SendRawCommandCtxFunc                   func(context.Context, string, sbe.EventTypeEnum, int32, int64, []byte) (map[string]interface{}, error)
+	SubscribeTopicFunc                      func(string, zbc.TopicEventHandler, ...zbc.TopicWorkerOption) (*zbc.TopicWorker, error)
+	SubscribeTopicCtxFunc                   func(context.Context, string, zbc.TopicEventHandler, ...zbc.TopicWorkerOption) (*zbc.TopicWorker, error)
+	TaskConsumerFunc                        func(*zbc.TaskSubscription, ...zbc.RequestOption) (chan *zbc.Message, error)
+	TaskConsumerCtxFunc                     func(context.Context, *zbc.TaskSubscription) (chan *zbc.Message, error)
+	TopicConsumerFunc                       func(*zbc.TopicSubscription, ...zbc.RequestOption) (chan *zbc.Message, error)
+	TopicConsumerCtxFunc                    func(context.Context, *zbc.TopicSubscription) (chan *zbc.Message, error)
+	TopicStatsFunc                          func(string, ...zbc.RequestOption) ([]*zbc.PartitionStats, error)
+	TopicStatsCtxFunc                       func(context.Context, string) ([]*zbc.PartitionStats, error)
+	TopologyFunc                            func(...zbc.RequestOption) (*zbc.Topology, error)
+	TopologyCtxFunc                         func(context.Context) (*zbc.Topology, error)
+	UpdateTaskRetriesFunc                   func(string, int32, int64, int, ...zbc.RequestOption) (*zbc.Message, error)
+	UpdateTaskRetriesCtxFunc                func(context.Context, string, int32, int64, int) (*zbc.Message, error)
+	UpdateWorkflowInstancePayloadFunc       func(string, int32, int64, int64, map[string]interface{}, ...zbc.RequestOption) (*zbc.Message, error)
+	UpdateWorkflowInstancePayloadCtxFunc    func(context.Context, string, int32, int64, int64, map[string]interface{}) (*zbc.Message, error)
+}
+
+// AcknowledgeTopicEvent calls AcknowledgeTopicEventFunc.
+func (m *Client) AcknowledgeTopicEvent(a0 *sbe.SubscribedEvent, a1 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("AcknowledgeTopicEvent", a0, a1)
+	if m.AcknowledgeTopicEventFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.AcknowledgeTopicEventFunc(a0, a1...)
+}
+
+// AcknowledgeTopicEventCtx calls AcknowledgeTopicEventCtxFunc.
+func (m *Client) AcknowledgeTopicEventCtx(a0 context.Context, a1 *sbe.SubscribedEvent) (r0 *zbc.Message, r1 error) {
+	m.record("AcknowledgeTopicEventCtx", a0, a1)
+	if m.AcknowledgeTopicEventCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.AcknowledgeTopicEventCtxFunc(a0, a1)
+}
+
+// BrokerProtocol calls BrokerProtocolFunc.
+func (m *Client) BrokerProtocol(a0 ...zbc.RequestOption) (r0 *zbc.BrokerProtocol, r1 error) {
+	m.record("BrokerProtocol", a0)
+	if m.BrokerProtocolFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.BrokerProtocolFunc(a0...)
+}
+
+// BrokerProtocolCtx calls BrokerProtocolCtxFunc.
+func (m *Client) BrokerProtocolCtx(a0 context.Context) (r0 *zbc.BrokerProtocol, r1 error) {
+	m.record("BrokerProtocolCtx", a0)
+	if m.BrokerProtocolCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.BrokerProtocolCtxFunc(a0)
+}
+
+// CancelWorkflowInstance calls CancelWorkflowInstanceFunc.
+func (m *Client) CancelWorkflowInstance(a0 string, a1 int32, a2 int64, a3 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("CancelWorkflowInstance", a0, a1, a2, a3)
+	if m.CancelWorkflowInstanceFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CancelWorkflowInstanceFunc(a0, a1, a2, a3...)
+}
+
+// CancelWorkflowInstanceCtx calls CancelWorkflowInstanceCtxFunc.
+func (m *Client) CancelWorkflowInstanceCtx(a0 context.Context, a1 string, a2 int32, a3 int64) (r0 *zbc.Message, r1 error) {
+	m.record("CancelWorkflowInstanceCtx", a0, a1, a2, a3)
+	if m.CancelWorkflowInstanceCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CancelWorkflowInstanceCtxFunc(a0, a1, a2, a3)
+}
+
+// Close calls CloseFunc.
+func (m *Client) Close(a0 context.Context) (r0 error) {
+	m.record("Close", a0)
+	if m.CloseFunc == nil {
+		r0 = ErrNotStubbed
+		return
+	}
+	return m.CloseFunc(a0)
+}
+
+// CompleteTask calls CompleteTaskFunc.
+func (m *Client) CompleteTask(a0 *sbe.SubscribedEvent, a1 map[string]interface{}, a2 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("CompleteTask", a0, a1, a2)
+	if m.CompleteTaskFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CompleteTaskFunc(a0, a1, a2...)
+}
+
+// CompleteTaskCtx calls CompleteTaskCtxFunc.
+func (m *Client) CompleteTaskCtx(a0 context.Context, a1 *sbe.SubscribedEvent, a2 map[string]interface{}) (r0 *zbc.Message, r1 error) {
+	m.record("CompleteTaskCtx", a0, a1, a2)
+	if m.CompleteTaskCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CompleteTaskCtxFunc(a0, a1, a2)
+}
+
+// CreateTask calls CreateTaskFunc.
+func (m *Client) CreateTask(a0 string, a1 *zbc.Task, a2 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("CreateTask", a0, a1, a2)
+	if m.CreateTaskFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateTaskFunc(a0, a1, a2...)
+}
+
+// CreateTaskCtx calls CreateTaskCtxFunc.
+func (m *Client) CreateTaskCtx(a0 context.Context, a1 string, a2 *zbc.Task) (r0 *zbc.Message, r1 error) {
+	m.record("CreateTaskCtx", a0, a1, a2)
+	if m.CreateTaskCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateTaskCtxFunc(a0, a1, a2)
+}
+
+// CreateTasks calls CreateTasksFunc.
+func (m *Client) CreateTasks(a0 string, a1 []*zbc.Task, a2 ...zbc.RequestOption) (r0 []*zbc.Message, r1 error) {
+	m.record("CreateTasks", a0, a1, a2)
+	if m.CreateTasksFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateTasksFunc(a0, a1, a2...)
+}
+
+// CreateTasksCtx calls CreateTasksCtxFunc.
+func (m *Client) CreateTasksCtx(a0 context.Context, a1 string, a2 []*zbc.Task) (r0 []*zbc.Message, r1 error) {
+	m.record("CreateTasksCtx", a0, a1, a2)
+	if m.CreateTasksCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateTasksCtxFunc(a0, a1, a2)
+}
+
+// CreateTopic calls CreateTopicFunc.
+func (m *Client) CreateTopic(a0 string, a1 int, a2 ...zbc.RequestOption) (r0 *zbc.Topic, r1 error) {
+	m.record("CreateTopic", a0, a1, a2)
+	if m.CreateTopicFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateTopicFunc(a0, a1, a2...)
+}
+
+// CreateTopicCtx calls CreateTopicCtxFunc.
+func (m *Client) CreateTopicCtx(a0 context.Context, a1 string, a2 int) (r0 *zbc.Topic, r1 error) {
+	m.record("CreateTopicCtx", a0, a1, a2)
+	if m.CreateTopicCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateTopicCtxFunc(a0, a1, a2)
+}
+
+// CreateWorkflowInstance calls CreateWorkflowInstanceFunc.
+func (m *Client) CreateWorkflowInstance(a0 string, a1 string, a2 int, a3 map[string]interface{}, a4 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("CreateWorkflowInstance", a0, a1, a2, a3, a4)
+	if m.CreateWorkflowInstanceFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateWorkflowInstanceFunc(a0, a1, a2, a3, a4...)
+}
+
+// CreateWorkflowInstanceCtx calls CreateWorkflowInstanceCtxFunc.
+func (m *Client) CreateWorkflowInstanceCtx(a0 context.Context, a1 string, a2 string, a3 int, a4 map[string]interface{}) (r0 *zbc.Message, r1 error) {
+	m.record("CreateWorkflowInstanceCtx", a0, a1, a2, a3, a4)
+	if m.CreateWorkflowInstanceCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateWorkflowInstanceCtxFunc(a0, a1, a2, a3, a4)
+}
+
+// CreateWorkflowInstanceWithResult calls CreateWorkflowInstanceWithResultFunc.
+func (m *Client) CreateWorkflowInstanceWithResult(a0 string, a1 string, a2 int, a3 map[string]interface{}, a4 ...zbc.RequestOption) (r0 *zbc.WorkflowInstanceResult, r1 error) {
+	m.record("CreateWorkflowInstanceWithResult", a0, a1, a2, a3, a4)
+	if m.CreateWorkflowInstanceWithResultFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateWorkflowInstanceWithResultFunc(a0, a1, a2, a3, a4...)
+}
+
+// CreateWorkflowInstanceWithResultCtx calls CreateWorkflowInstanceWithResultCtxFunc.
+func (m *Client) CreateWorkflowInstanceWithResultCtx(a0 context.Context, a1 string, a2 string, a3 int, a4 map[string]interface{}) (r0 *zbc.WorkflowInstanceResult, r1 error) {
+	m.record("CreateWorkflowInstanceWithResultCtx", a0, a1, a2, a3, a4)
+	if m.CreateWorkflowInstanceWithResultCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.CreateWorkflowInstanceWithResultCtxFunc(a0, a1, a2, a3, a4)
+}
+
+// DeployWorkflow calls DeployWorkflowFunc.
+func (m *Client) DeployWorkflow(a0 string, a1 []byte, a2 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("DeployWorkflow", a0, a1, a2)
+	if m.DeployWorkflowFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.DeployWorkflowFunc(a0, a1, a2...)
+}
+
+// DeployWorkflowCtx calls DeployWorkflowCtxFunc.
+func (m *Client) DeployWorkflowCtx(a0 context.Context, a1 string, a2 []byte) (r0 *zbc.Message, r1 error) {
+	m.record("DeployWorkflowCtx", a0, a1, a2)
+	if m.DeployWorkflowCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.DeployWorkflowCtxFunc(a0, a1, a2)
+}
+
+// DeployWorkflowResource calls DeployWorkflowResourceFunc.
+func (m *Client) DeployWorkflowResource(a0 string, a1 string, a2 []byte, a3 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("DeployWorkflowResource", a0, a1, a2, a3)
+	if m.DeployWorkflowResourceFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.DeployWorkflowResourceFunc(a0, a1, a2, a3...)
+}
+
+// DeployWorkflowResourceCtx calls DeployWorkflowResourceCtxFunc.
+func (m *Client) DeployWorkflowResourceCtx(a0 context.Context, a1 string, a2 string, a3 []byte) (r0 *zbc.Message, r1 error) {
+	m.record("DeployWorkflowResourceCtx", a0, a1, a2, a3)
+	if m.DeployWorkflowResourceCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.DeployWorkflowResourceCtxFunc(a0, a1, a2, a3)
+}
+
+// FailTask calls FailTaskFunc.
+func (m *Client) FailTask(a0 *sbe.SubscribedEvent, a1 int, a2 string, a3 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("FailTask", a0, a1, a2, a3)
+	if m.FailTaskFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.FailTaskFunc(a0, a1, a2, a3...)
+}
+
+// FailTaskCtx calls FailTaskCtxFunc.
+func (m *Client) FailTaskCtx(a0 context.Context, a1 *sbe.SubscribedEvent, a2 int, a3 string) (r0 *zbc.Message, r1 error) {
+	m.record("FailTaskCtx", a0, a1, a2, a3)
+	if m.FailTaskCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.FailTaskCtxFunc(a0, a1, a2, a3)
+}
+
+// FlushTaskQueue calls FlushTaskQueueFunc.
+func (m *Client) FlushTaskQueue() (r0 error) {
+	m.record("FlushTaskQueue")
+	if m.FlushTaskQueueFunc == nil {
+		r0 = ErrNotStubbed
+		return
+	}
+	return m.FlushTaskQueueFunc()
+}
+
+// FlushTaskQueueCtx calls FlushTaskQueueCtxFunc.
+func (m *Client) FlushTaskQueueCtx(a0 context.Context) (r0 error) {
+	m.record("FlushTaskQueueCtx", a0)
+	if m.FlushTaskQueueCtxFunc == nil {
+		r0 = ErrNotStubbed
+		return
+	}
+	return m.FlushTaskQueueCtxFunc(a0)
+}
+
+// GetWorkflow calls GetWorkflowFunc.
+func (m *Client) GetWorkflow(a0 string, a1 string, a2 int, a3 ...zbc.RequestOption) (r0 *zbc.Workflow, r1 error) {
+	m.record("GetWorkflow", a0, a1, a2, a3)
+	if m.GetWorkflowFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.GetWorkflowFunc(a0, a1, a2, a3...)
+}
+
+// GetWorkflowCtx calls GetWorkflowCtxFunc.
+func (m *Client) GetWorkflowCtx(a0 context.Context, a1 string, a2 string, a3 int) (r0 *zbc.Workflow, r1 error) {
+	m.record("GetWorkflowCtx", a0, a1, a2, a3)
+	if m.GetWorkflowCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.GetWorkflowCtxFunc(a0, a1, a2, a3)
+}
+
+// HealthCheck calls HealthCheckFunc.
+func (m *Client) HealthCheck(a0 ...zbc.RequestOption) (r0 *zbc.Health, r1 error) {
+	m.record("HealthCheck", a0)
+	if m.HealthCheckFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.HealthCheckFunc(a0...)
+}
+
+// HealthCheckCtx calls HealthCheckCtxFunc.
+func (m *Client) HealthCheckCtx(a0 context.Context) (r0 *zbc.Health, r1 error) {
+	m.record("HealthCheckCtx", a0)
+	if m.HealthCheckCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.HealthCheckCtxFunc(a0)
+}
+
+// ListTasks calls ListTasksFunc.
+func (m *Client) ListTasks(a0 string, a1 ...zbc.RequestOption) (r0 []*zbc.TaskSnapshot, r1 error) {
+	m.record("ListTasks", a0, a1)
+	if m.ListTasksFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ListTasksFunc(a0, a1...)
+}
+
+// ListTasksCtx calls ListTasksCtxFunc.
+func (m *Client) ListTasksCtx(a0 context.Context, a1 string) (r0 []*zbc.TaskSnapshot, r1 error) {
+	m.record("ListTasksCtx", a0, a1)
+	if m.ListTasksCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ListTasksCtxFunc(a0, a1)
+}
+
+// ListWorkflowInstances calls ListWorkflowInstancesFunc.
+func (m *Client) ListWorkflowInstances(a0 string, a1 ...zbc.RequestOption) (r0 []*zbc.WorkflowInstanceSnapshot, r1 error) {
+	m.record("ListWorkflowInstances", a0, a1)
+	if m.ListWorkflowInstancesFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ListWorkflowInstancesFunc(a0, a1...)
+}
+
+// ListWorkflowInstancesCtx calls ListWorkflowInstancesCtxFunc.
+func (m *Client) ListWorkflowInstancesCtx(a0 context.Context, a1 string) (r0 []*zbc.WorkflowInstanceSnapshot, r1 error) {
+	m.record("ListWorkflowInstancesCtx", a0, a1)
+	if m.ListWorkflowInstancesCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ListWorkflowInstancesCtxFunc(a0, a1)
+}
+
+// ListWorkflows calls ListWorkflowsFunc.
+func (m *Client) ListWorkflows(a0 string, a1 ...zbc.RequestOption) (r0 []*zbc.Workflow, r1 error) {
+	m.record("ListWorkflows", a0, a1)
+	if m.ListWorkflowsFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ListWorkflowsFunc(a0, a1...)
+}
+
+// ListWorkflowsCtx calls ListWorkflowsCtxFunc.
+func (m *Client) ListWorkflowsCtx(a0 context.Context, a1 string) (r0 []*zbc.Workflow, r1 error) {
+	m.record("ListWorkflowsCtx", a0, a1)
+	if m.ListWorkflowsCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ListWorkflowsCtxFunc(a0, a1)
+}
+
+// NewWorker calls NewWorkerFunc.
+func (m *Client) NewWorker(a0 string, a1 zbc.TaskHandler, a2 ...zbc.WorkerOption) (r0 *zbc.Worker) {
+	m.record("NewWorker", a0, a1, a2)
+	if m.NewWorkerFunc == nil {
+		return
+	}
+	return m.NewWorkerFunc(a0, a1, a2...)
+}
+
+// OpenIncidentSubscription calls OpenIncidentSubscriptionFunc.
+func (m *Client) OpenIncidentSubscription(a0 *zbc.TopicSubscription, a1 ...zbc.RequestOption) (r0 *zbc.Subscription, r1 error) {
+	m.record("OpenIncidentSubscription", a0, a1)
+	if m.OpenIncidentSubscriptionFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.OpenIncidentSubscriptionFunc(a0, a1...)
+}
+
+// OpenIncidentSubscriptionCtx calls OpenIncidentSubscriptionCtxFunc.
+func (m *Client) OpenIncidentSubscriptionCtx(a0 context.Context, a1 *zbc.TopicSubscription) (r0 *zbc.Subscription, r1 error) {
+	m.record("OpenIncidentSubscriptionCtx", a0, a1)
+	if m.OpenIncidentSubscriptionCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.OpenIncidentSubscriptionCtxFunc(a0, a1)
+}
+
+// OpenTaskSubscription calls OpenTaskSubscriptionFunc.
+func (m *Client) OpenTaskSubscription(a0 *zbc.TaskSubscription, a1 ...zbc.RequestOption) (r0 *zbc.Subscription, r1 error) {
+	m.record("OpenTaskSubscription", a0, a1)
+	if m.OpenTaskSubscriptionFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.OpenTaskSubscriptionFunc(a0, a1...)
+}
+
+// OpenTaskSubscriptionCtx calls OpenTaskSubscriptionCtxFunc.
+func (m *Client) OpenTaskSubscriptionCtx(a0 context.Context, a1 *zbc.TaskSubscription) (r0 *zbc.Subscription, r1 error) {
+	m.record("OpenTaskSubscriptionCtx", a0, a1)
+	if m.OpenTaskSubscriptionCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.OpenTaskSubscriptionCtxFunc(a0, a1)
+}
+
+// OpenTopicSubscription calls OpenTopicSubscriptionFunc.
+func (m *Client) OpenTopicSubscription(a0 *zbc.TopicSubscription, a1 ...zbc.RequestOption) (r0 *zbc.Subscription, r1 error) {
+	m.record("OpenTopicSubscription", a0, a1)
+	if m.OpenTopicSubscriptionFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.OpenTopicSubscriptionFunc(a0, a1...)
+}
+
+// OpenTopicSubscriptionCtx calls OpenTopicSubscriptionCtxFunc.
+func (m *Client) OpenTopicSubscriptionCtx(a0 context.Context, a1 *zbc.TopicSubscription) (r0 *zbc.Subscription, r1 error) {
+	m.record("OpenTopicSubscriptionCtx", a0, a1)
+	if m.OpenTopicSubscriptionCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.OpenTopicSubscriptionCtxFunc(a0, a1)
+}
+
+// ResolveIncident calls ResolveIncidentFunc.
+func (m *Client) ResolveIncident(a0 *sbe.SubscribedEvent, a1 map[string]interface{}, a2 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("ResolveIncident", a0, a1, a2)
+	if m.ResolveIncidentFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ResolveIncidentFunc(a0, a1, a2...)
+}
+
+// ResolveIncidentCtx calls ResolveIncidentCtxFunc.
+func (m *Client) ResolveIncidentCtx(a0 context.Context, a1 *sbe.SubscribedEvent, a2 map[string]interface{}) (r0 *zbc.Message, r1 error) {
+	m.record("ResolveIncidentCtx", a0, a1, a2)
+	if m.ResolveIncidentCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ResolveIncidentCtxFunc(a0, a1, a2)
+}
+
+// Responder calls ResponderFunc.
+func (m *Client) Responder(a0 *zbc.Message, a1 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("Responder", a0, a1)
+	if m.ResponderFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ResponderFunc(a0, a1...)
+}
+
+// ResponderCtx calls ResponderCtxFunc.
+func (m *Client) ResponderCtx(a0 context.Context, a1 *zbc.Message) (r0 *zbc.Message, r1 error) {
+	m.record("ResponderCtx", a0, a1)
+	if m.ResponderCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.ResponderCtxFunc(a0, a1)
+}
+
+// SendAsync calls SendAsyncFunc.
+func (m *Client) SendAsync(a0 *zbc.Message, a1 ...zbc.RequestOption) (r0 <-chan *zbc.Response, r1 error) {
+	m.record("SendAsync", a0, a1)
+	if m.SendAsyncFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendAsyncFunc(a0, a1...)
+}
+
+// SendAsyncCtx calls SendAsyncCtxFunc.
+func (m *Client) SendAsyncCtx(a0 context.Context, a1 *zbc.Message) (r0 <-chan *zbc.Response, r1 error) {
+	m.record("SendAsyncCtx", a0, a1)
+	if m.SendAsyncCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendAsyncCtxFunc(a0, a1)
+}
+
+// SendBatch calls SendBatchFunc.
+func (m *Client) SendBatch(a0 []*zbc.Message, a1 ...zbc.RequestOption) (r0 []*zbc.Message, r1 error) {
+	m.record("SendBatch", a0, a1)
+	if m.SendBatchFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendBatchFunc(a0, a1...)
+}
+
+// SendBatchCtx calls SendBatchCtxFunc.
+func (m *Client) SendBatchCtx(a0 context.Context, a1 []*zbc.Message) (r0 []*zbc.Message, r1 error) {
+	m.record("SendBatchCtx", a0, a1)
+	if m.SendBatchCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendBatchCtxFunc(a0, a1)
+}
+
+// SendControlMessage calls SendControlMessageFunc.
+func (m *Client) SendControlMessage(a0 sbe.ControlMessageTypeEnum, a1 map[string]interface{}, a2 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("SendControlMessage", a0, a1, a2)
+	if m.SendControlMessageFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendControlMessageFunc(a0, a1, a2...)
+}
+
+// SendControlMessageCtx calls SendControlMessageCtxFunc.
+func (m *Client) SendControlMessageCtx(a0 context.Context, a1 sbe.ControlMessageTypeEnum, a2 map[string]interface{}) (r0 *zbc.Message, r1 error) {
+	m.record("SendControlMessageCtx", a0, a1, a2)
+	if m.SendControlMessageCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendControlMessageCtxFunc(a0, a1, a2)
+}
+
+// SendRawCommand calls SendRawCommandFunc.
+func (m *Client) SendRawCommand(a0 string, a1 sbe.EventTypeEnum, a2 int32, a3 int64, a4 []byte, a5 ...zbc.RequestOption) (r0 map[string]interface{}, r1 error) {
+	m.record("SendRawCommand", a0, a1, a2, a3, a4, a5)
+	if m.SendRawCommandFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendRawCommandFunc(a0, a1, a2, a3, a4, a5...)
+}
+
+// SendRawCommandCtx calls SendRawCommandCtxFunc.
+func (m *Client) SendRawCommandCtx(a0 context.Context, a1 string, a2 sbe.EventTypeEnum, a3 int32, a4 int64, a5 []byte) (r0 map[string]interface{}, r1 error) {
+	m.record("SendRawCommandCtx", a0, a1, a2, a3, a4, a5)
+	if m.SendRawCommandCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SendRawCommandCtxFunc(a0, a1, a2, a3, a4, a5)
+}
+
+// SubscribeTopic calls SubscribeTopicFunc.
+func (m *Client) SubscribeTopic(a0 string, a1 zbc.TopicEventHandler, a2 ...zbc.TopicWorkerOption) (r0 *zbc.TopicWorker, r1 error) {
+	m.record("SubscribeTopic", a0, a1, a2)
+	if m.SubscribeTopicFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SubscribeTopicFunc(a0, a1, a2...)
+}
+
+// SubscribeTopicCtx calls SubscribeTopicCtxFunc.
+func (m *Client) SubscribeTopicCtx(a0 context.Context, a1 string, a2 zbc.TopicEventHandler, a3 ...zbc.TopicWorkerOption) (r0 *zbc.TopicWorker, r1 error) {
+	m.record("SubscribeTopicCtx", a0, a1, a2, a3)
+	if m.SubscribeTopicCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.SubscribeTopicCtxFunc(a0, a1, a2, a3...)
+}
+
+// TaskConsumer calls TaskConsumerFunc.
+func (m *Client) TaskConsumer(a0 *zbc.TaskSubscription, a1 ...zbc.RequestOption) (r0 chan *zbc.Message, r1 error) {
+	m.record("TaskConsumer", a0, a1)
+	if m.TaskConsumerFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TaskConsumerFunc(a0, a1...)
+}
+
+// TaskConsumerCtx calls TaskConsumerCtxFunc.
+func (m *Client) TaskConsumerCtx(a0 context.Context, a1 *zbc.TaskSubscription) (r0 chan *zbc.Message, r1 error) {
+	m.record("TaskConsumerCtx", a0, a1)
+	if m.TaskConsumerCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TaskConsumerCtxFunc(a0, a1)
+}
+
+// TopicConsumer calls TopicConsumerFunc.
+func (m *Client) TopicConsumer(a0 *zbc.TopicSubscription, a1 ...zbc.RequestOption) (r0 chan *zbc.Message, r1 error) {
+	m.record("TopicConsumer", a0, a1)
+	if m.TopicConsumerFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TopicConsumerFunc(a0, a1...)
+}
+
+// TopicConsumerCtx calls TopicConsumerCtxFunc.
+func (m *Client) TopicConsumerCtx(a0 context.Context, a1 *zbc.TopicSubscription) (r0 chan *zbc.Message, r1 error) {
+	m.record("TopicConsumerCtx", a0, a1)
+	if m.TopicConsumerCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TopicConsumerCtxFunc(a0, a1)
+}
+
+// TopicStats calls TopicStatsFunc.
+func (m *Client) TopicStats(a0 string, a1 ...zbc.RequestOption) (r0 []*zbc.PartitionStats, r1 error) {
+	m.record("TopicStats", a0, a1)
+	if m.TopicStatsFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TopicStatsFunc(a0, a1...)
+}
+
+// TopicStatsCtx calls TopicStatsCtxFunc.
+func (m *Client) TopicStatsCtx(a0 context.Context, a1 string) (r0 []*zbc.PartitionStats, r1 error) {
+	m.record("TopicStatsCtx", a0, a1)
+	if m.TopicStatsCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TopicStatsCtxFunc(a0, a1)
+}
+
+// Topology calls TopologyFunc.
+func (m *Client) Topology(a0 ...zbc.RequestOption) (r0 *zbc.Topology, r1 error) {
+	m.record("Topology", a0)
+	if m.TopologyFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TopologyFunc(a0...)
+}
+
+// TopologyCtx calls TopologyCtxFunc.
+func (m *Client) TopologyCtx(a0 context.Context) (r0 *zbc.Topology, r1 error) {
+	m.record("TopologyCtx", a0)
+	if m.TopologyCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.TopologyCtxFunc(a0)
+}
+
+// UpdateTaskRetries calls UpdateTaskRetriesFunc.
+func (m *Client) UpdateTaskRetries(a0 string, a1 int32, a2 int64, a3 int, a4 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("UpdateTaskRetries", a0, a1, a2, a3, a4)
+	if m.UpdateTaskRetriesFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.UpdateTaskRetriesFunc(a0, a1, a2, a3, a4...)
+}
+
+// UpdateTaskRetriesCtx calls UpdateTaskRetriesCtxFunc.
+func (m *Client) UpdateTaskRetriesCtx(a0 context.Context, a1 string, a2 int32, a3 int64, a4 int) (r0 *zbc.Message, r1 error) {
+	m.record("UpdateTaskRetriesCtx", a0, a1, a2, a3, a4)
+	if m.UpdateTaskRetriesCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.UpdateTaskRetriesCtxFunc(a0, a1, a2, a3, a4)
+}
+
+// UpdateWorkflowInstancePayload calls UpdateWorkflowInstancePayloadFunc.
+func (m *Client) UpdateWorkflowInstancePayload(a0 string, a1 int32, a2 int64, a3 int64, a4 map[string]interface{}, a5 ...zbc.RequestOption) (r0 *zbc.Message, r1 error) {
+	m.record("UpdateWorkflowInstancePayload", a0, a1, a2, a3, a4, a5)
+	if m.UpdateWorkflowInstancePayloadFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.UpdateWorkflowInstancePayloadFunc(a0, a1, a2, a3, a4, a5...)
+}
+
+// UpdateWorkflowInstancePayloadCtx calls UpdateWorkflowInstancePayloadCtxFunc.
+func (m *Client) UpdateWorkflowInstancePayloadCtx(a0 context.Context, a1 string, a2 int32, a3 int64, a4 int64, a5 map[string]interface{}) (r0 *zbc.Message, r1 error) {
+	m.record("UpdateWorkflowInstancePayloadCtx", a0, a1, a2, a3, a4, a5)
+	if m.UpdateWorkflowInstancePayloadCtxFunc == nil {
+		r1 = ErrNotStubbed
+		return
+	}
+	return m.UpdateWorkflowInstancePayloadCtxFunc(a0, a1, a2, a3, a4, a5)
+}
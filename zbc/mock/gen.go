@@ -0,0 +1,171 @@
+//go:build ignore
+// +build ignore
+
+// gen generates Client of package mock from methods of zbc.ZeebeClient. Run it with go generate after the interface
+// changes.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+func main() {
+	iface := reflect.TypeOf((*zbc.ZeebeClient)(nil)).Elem()
+
+	imports := map[string]bool{}
+	var methods bytes.Buffer
+	for i := 0; i < iface.NumMethod(); i++ {
+		writeMethod(&methods, iface.Method(i), imports)
+	}
+
+	var paths []string
+	for p := range imports {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var out bytes.Buffer
+	fmt.Fprintln(&out, "// Code generated by gen.go from zbc.ZeebeClient. DO NOT EDIT.")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, "package mock")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, "import (")
+	std := true
+	for _, p := range paths {
+		// Standard library comes first, packages with domain in their path follow it.
+		if std && strings.Contains(strings.Split(p, "/")[0], ".") {
+			fmt.Fprintln(&out)
+			std = false
+		}
+		fmt.Fprintf(&out, "\t%q\n", p)
+	}
+	fmt.Fprintln(&out, ")")
+	fmt.Fprintln(&out)
+	fmt.Fprintln(&out, "// Client is stub of zbc.ZeebeClient. Every method records the call and calls function in the field of the same")
+	fmt.Fprintln(&out, "// name with Func suffix. Method whose function isn't set returns zero values and ErrNotStubbed, if it returns error.")
+	fmt.Fprintln(&out, "type Client struct {")
+	fmt.Fprintln(&out, "\trecorder")
+	fmt.Fprintln(&out)
+	for i := 0; i < iface.NumMethod(); i++ {
+		m := iface.Method(i)
+		fmt.Fprintf(&out, "\t%sFunc func%s\n", m.Name, signature(m.Type, imports))
+	}
+	fmt.Fprintln(&out, "}")
+	out.Write(methods.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("Formatting generated code failed: %s\n%s", err, out.Bytes())
+	}
+	if err := ioutil.WriteFile("client.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeMethod(w *bytes.Buffer, m reflect.Method, imports map[string]bool) {
+	t := m.Type
+	var params, args []string
+	for i := 0; i < t.NumIn(); i++ {
+		name := fmt.Sprintf("a%d", i)
+		params = append(params, name+" "+paramType(t, i, imports))
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			name += "..."
+		}
+		args = append(args, name)
+	}
+	var results []string
+	for i := 0; i < t.NumOut(); i++ {
+		results = append(results, fmt.Sprintf("r%d %s", i, typeName(t.Out(i), imports)))
+	}
+
+	fmt.Fprintf(w, "\n// %s calls %sFunc.\n", m.Name, m.Name)
+	fmt.Fprintf(w, "func (m *Client) %s(%s) (%s) {\n", m.Name, strings.Join(params, ", "), strings.Join(results, ", "))
+	fmt.Fprintf(w, "\tm.record(%q%s)\n", m.Name, strings.Join(append([]string{""}, argNames(t)...), ", "))
+	fmt.Fprintf(w, "\tif m.%sFunc == nil {\n", m.Name)
+	if n := t.NumOut(); n > 0 && t.Out(n-1) == reflect.TypeOf((*error)(nil)).Elem() {
+		fmt.Fprintf(w, "\t\tr%d = ErrNotStubbed\n", n-1)
+	}
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\t}")
+	if t.NumOut() > 0 {
+		fmt.Fprintf(w, "\treturn m.%sFunc(%s)\n", m.Name, strings.Join(args, ", "))
+	} else {
+		fmt.Fprintf(w, "\tm.%sFunc(%s)\n", m.Name, strings.Join(args, ", "))
+		fmt.Fprintln(w, "\treturn")
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func argNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumIn(); i++ {
+		names = append(names, fmt.Sprintf("a%d", i))
+	}
+	return names
+}
+
+// signature returns parameters and results of the method type.
+func signature(t reflect.Type, imports map[string]bool) string {
+	var params, results []string
+	for i := 0; i < t.NumIn(); i++ {
+		params = append(params, paramType(t, i, imports))
+	}
+	for i := 0; i < t.NumOut(); i++ {
+		results = append(results, typeName(t.Out(i), imports))
+	}
+	return fmt.Sprintf("(%s) (%s)", strings.Join(params, ", "), strings.Join(results, ", "))
+}
+
+func paramType(t reflect.Type, i int, imports map[string]bool) string {
+	if t.IsVariadic() && i == t.NumIn()-1 {
+		return "..." + typeName(t.In(i).Elem(), imports)
+	}
+	return typeName(t.In(i), imports)
+}
+
+// typeName returns name of the type as it's written in package mock and adds packages it refers to into imports.
+func typeName(t reflect.Type, imports map[string]bool) string {
+	if len(t.Name()) > 0 {
+		if len(t.PkgPath()) == 0 {
+			return t.Name()
+		}
+		imports[t.PkgPath()] = true
+		return path.Base(t.PkgPath()) + "." + t.Name()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + typeName(t.Elem(), imports)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && len(t.Elem().PkgPath()) == 0 {
+			return "[]byte"
+		}
+		return "[]" + typeName(t.Elem(), imports)
+	case reflect.Map:
+		return "map[" + typeName(t.Key(), imports) + "]" + typeName(t.Elem(), imports)
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + typeName(t.Elem(), imports)
+		case reflect.SendDir:
+			return "chan<- " + typeName(t.Elem(), imports)
+		}
+		return "chan " + typeName(t.Elem(), imports)
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+	log.Fatalf("Type %s not supported", t)
+	return ""
+}
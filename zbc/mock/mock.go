@@ -0,0 +1,61 @@
+// Package mock provides Client, a stub of zbc.ZeebeClient, so code built on zbc can be unit tested without any broker.
+// Client is generated from the interface, functions of the methods a test needs are set on it:
+//
+//	client := &mock.Client{
+//		CreateTaskFunc: func(topic string, task *zbc.Task, opts ...zbc.RequestOption) (*zbc.Message, error) {
+//			return nil, nil
+//		},
+//	}
+//
+// To test against the protocol of the broker instead, use MockBroker of package zbc/zbtest.
+package mock
+
+//go:generate go run gen.go
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+// ErrNotStubbed is returned by methods of Client whose function is not set.
+var ErrNotStubbed = errors.New("Method not stubbed")
+
+var _ zbc.ZeebeClient = (*Client)(nil)
+
+// Call is a method call recorded by Client. Variadic arguments are passed as one slice.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// recorder keeps calls of Client, it's safe for concurrent use.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	r.mu.Unlock()
+}
+
+// Calls returns calls of all methods in the order they were made.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsOf returns calls of the method in the order they were made.
+func (r *recorder) CallsOf(method string) []Call {
+	var calls []Call
+	for _, call := range r.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
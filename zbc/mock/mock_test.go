@@ -0,0 +1,44 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/mock"
+)
+
+// createOrder is code under test, it depends only on the interface.
+func createOrder(client zbc.ZeebeClient, orderID string) error {
+	task := &zbc.Task{Type: "payment", Retries: 3, Headers: map[string]interface{}{"orderId": orderID}}
+	_, err := client.CreateTask("orders", task)
+	return err
+}
+
+func TestClient_Stub(t *testing.T) {
+	client := &mock.Client{
+		CreateTaskFunc: func(topic string, task *zbc.Task, opts ...zbc.RequestOption) (*zbc.Message, error) {
+			if topic != "orders" || task.Type != "payment" {
+				t.Errorf("Unexpected task %+v on topic %s", task, topic)
+			}
+			return nil, nil
+		},
+	}
+
+	if err := createOrder(client, "o-1"); err != nil {
+		t.Fatal(err)
+	}
+	calls := client.CallsOf("CreateTask")
+	if len(calls) != 1 || calls[0].Args[0] != "orders" {
+		t.Fatalf("Expected one call of CreateTask, recorded %+v", client.Calls())
+	}
+}
+
+func TestClient_NotStubbed(t *testing.T) {
+	client := &mock.Client{}
+	if _, err := client.CreateWorkflowInstance("orders", "order-process", -1, nil); err != mock.ErrNotStubbed {
+		t.Fatalf("Expected ErrNotStubbed, received %v", err)
+	}
+	if worker := client.NewWorker("payment", nil); worker != nil {
+		t.Fatalf("Expected no worker, received %v", worker)
+	}
+}
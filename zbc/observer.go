@@ -0,0 +1,132 @@
+package zbc
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// Observer is notified about requests and events of the Client, e.g. to export metrics. Methods are called from
+// the goroutines of the Client, so they must be safe for concurrent use and must not block.
+type Observer interface {
+	RequestSent(requestType string)
+	ResponseReceived(requestType string, latency time.Duration)
+	RequestFailed(requestType string, err error) // Use Cause to find out the kind of err.
+	EventReceived(eventType string)
+	CreditsChanged(taskType string, credits int32) // Credits which broker can still use to push tasks.
+}
+
+// DropObserver can be implemented by Observer to be notified about events dropped by subscriptions with OverflowDrop.
+type DropObserver interface {
+	EventDropped(eventType string)
+}
+
+// FilterObserver can be implemented by Observer to be notified about events dropped by filters of subscriptions.
+type FilterObserver interface {
+	EventFiltered(eventType string)
+}
+
+// observerHolder lets us keep Observer interface in atomic.Value, which needs values of the same concrete type.
+type observerHolder struct {
+	observer Observer
+}
+
+// SetObserver will start notifying the observer. Connections to other brokers opened afterwards are observed too.
+func (c *Client) SetObserver(observer Observer) {
+	c.observer.Store(observerHolder{observer})
+}
+
+func (c *Client) getObserver() Observer {
+	if holder, ok := c.observer.Load().(observerHolder); ok {
+		return holder.observer
+	}
+	return nil
+}
+
+var eventTypeNames = map[sbe.EventTypeEnum]string{
+	sbe.EventType.TASK_EVENT:              "TASK_EVENT",
+	sbe.EventType.RAFT_EVENT:              "RAFT_EVENT",
+	sbe.EventType.SUBSCRIPTION_EVENT:      "SUBSCRIPTION_EVENT",
+	sbe.EventType.SUBSCRIBER_EVENT:        "SUBSCRIBER_EVENT",
+	sbe.EventType.DEPLOYMENT_EVENT:        "DEPLOYMENT_EVENT",
+	sbe.EventType.WORKFLOW_INSTANCE_EVENT: "WORKFLOW_INSTANCE_EVENT",
+	sbe.EventType.INCIDENT_EVENT:          "INCIDENT_EVENT",
+	sbe.EventType.WORKFLOW_EVENT:          "WORKFLOW_EVENT",
+	sbe.EventType.NOOP_EVENT:              "NOOP_EVENT",
+	sbe.EventType.TOPIC_EVENT:             "TOPIC_EVENT",
+}
+
+var controlMessageTypeNames = map[sbe.ControlMessageTypeEnum]string{
+	sbe.ControlMessageType.ADD_TASK_SUBSCRIPTION:              "ADD_TASK_SUBSCRIPTION",
+	sbe.ControlMessageType.REMOVE_TASK_SUBSCRIPTION:           "REMOVE_TASK_SUBSCRIPTION",
+	sbe.ControlMessageType.INCREASE_TASK_SUBSCRIPTION_CREDITS: "INCREASE_TASK_SUBSCRIPTION_CREDITS",
+	sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION:          "REMOVE_TOPIC_SUBSCRIPTION",
+	sbe.ControlMessageType.REQUEST_TOPOLOGY:                   "REQUEST_TOPOLOGY",
+}
+
+// EventTypeName returns name of the event type, e.g. TASK_EVENT. Unknown types are named by their number.
+func EventTypeName(eventType sbe.EventTypeEnum) string {
+	if name, ok := eventTypeNames[eventType]; ok {
+		return name
+	}
+	return fmt.Sprintf("EVENT_TYPE_%d", eventType)
+}
+
+// requestType returns name of the event type for commands and name of the message type for control messages.
+func requestType(message *Message) string {
+	switch request := (*message.SbeMessage).(type) {
+	case *sbe.ExecuteCommandRequest:
+		return EventTypeName(request.EventType)
+	case *sbe.ControlMessageRequest:
+		if name, ok := controlMessageTypeNames[request.MessageType]; ok {
+			return name
+		}
+		return fmt.Sprintf("CONTROL_MESSAGE_%d", request.MessageType)
+	}
+	return "UNKNOWN"
+}
+
+// observeRequest will notify the observer that the request is sent. Returned function reports the outcome.
+func (c *Client) observeRequest(message *Message) func(err error) {
+	observer := c.getObserver()
+	if observer == nil {
+		return func(error) {}
+	}
+
+	kind := requestType(message)
+	observer.RequestSent(kind)
+	start := time.Now()
+	return func(err error) {
+		if err != nil {
+			observer.RequestFailed(kind, err)
+			return
+		}
+		observer.ResponseReceived(kind, time.Since(start))
+	}
+}
+
+func (c *Client) observeEvent(event *sbe.SubscribedEvent) {
+	if observer := c.getObserver(); observer != nil {
+		observer.EventReceived(EventTypeName(event.EventType))
+	}
+}
+
+func (c *Client) observeDrop(event *sbe.SubscribedEvent) {
+	if observer, ok := c.getObserver().(DropObserver); ok {
+		observer.EventDropped(EventTypeName(event.EventType))
+	}
+}
+
+func (c *Client) observeFilter(event *sbe.SubscribedEvent) {
+	if observer, ok := c.getObserver().(FilterObserver); ok {
+		observer.EventFiltered(EventTypeName(event.EventType))
+	}
+}
+
+func (s *Subscription) observeCredits() {
+	if observer := s.owner().getObserver(); observer != nil && s.task != nil {
+		observer.CreditsChanged(s.task.TaskType, atomic.LoadInt32(&s.credits))
+	}
+}
@@ -0,0 +1,168 @@
+package zbc
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// DefaultReadBufferSize specifies size of the buffer in which frames received from the broker are read.
+const DefaultReadBufferSize = 20000
+
+// ClientOption configures Client created by NewClient. Options are applied before the broker is dialed, so
+// the Client is configured before the first frame is sent. Settings can be changed later by setters of the Client.
+type ClientOption func(*Client)
+
+// WithTLS makes Client communicate with the broker over TLS. Nil config disables TLS.
+func WithTLS(config *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithSocketOptions tunes TCP connections to the brokers, they are dialed with Go defaults otherwise. Options apply to
+// reconnects and to connections to other brokers of the cluster too.
+func WithSocketOptions(opts SocketOptions) ClientOption {
+	return func(c *Client) {
+		c.socketOptions = opts
+	}
+}
+
+// WithDialer makes Client open connections to the brokers with the dialer instead of dialing TCP. TLS and credentials
+// preamble are applied on top of the connection. Dialer is used for reconnects and connections to other brokers too.
+func WithDialer(dialer DialerFunc) ClientOption {
+	return func(c *Client) {
+		c.dialer = dialer
+	}
+}
+
+// WithRequestTimeout sets time after which requests without deadline are aborted. Default is 5 seconds.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.SetRequestTimeout(timeout)
+	}
+}
+
+// WithKeepAliveInterval sets interval of heartbeats. Default is DefaultKeepAliveInterval, zero disables heartbeats.
+func WithKeepAliveInterval(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.SetKeepAliveInterval(interval)
+	}
+}
+
+// WithReconnectPolicy sets how Client reconnects once the socket breaks. Default is DefaultReconnectPolicy, nil
+// disables reconnects.
+func WithReconnectPolicy(policy *ReconnectPolicy) ClientOption {
+	return func(c *Client) {
+		c.SetReconnectPolicy(policy)
+	}
+}
+
+// WithRetryPolicy sets how Client retries failed requests. Requests are not retried by default.
+func WithRetryPolicy(policy *RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.SetRetryPolicy(policy)
+	}
+}
+
+// WithRateLimit limits rate of commands sent by Client. Commands are not limited by default.
+func WithRateLimit(limit *RateLimit) ClientOption {
+	return func(c *Client) {
+		c.SetRateLimit(limit)
+	}
+}
+
+// WithTaskQueue keeps tasks created while the broker is unreachable in the queue, see SetTaskQueue.
+func WithTaskQueue(queue *TaskQueue) ClientOption {
+	return func(c *Client) {
+		c.SetTaskQueue(queue)
+	}
+}
+
+// WithDeduplicationWindow sets how long responses of commands with request key are remembered, so the commands are
+// not sent twice. Default is DefaultDeduplicationWindow, zero disables deduplication.
+func WithDeduplicationWindow(window time.Duration) ClientOption {
+	return func(c *Client) {
+		c.SetDeduplicationWindow(window)
+	}
+}
+
+// WithLogger sets Logger of the Client. Nothing is logged by default.
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		c.SetLogger(logger)
+	}
+}
+
+// WithObserver sets Observer of the Client.
+func WithObserver(observer Observer) ClientOption {
+	return func(c *Client) {
+		c.SetObserver(observer)
+	}
+}
+
+// WithTracer sets Tracer propagating trace context through tasks and workflow instances.
+func WithTracer(tracer Tracer) ClientOption {
+	return func(c *Client) {
+		c.SetTracer(tracer)
+	}
+}
+
+// WithPayloadCodec sets Codec of payloads. Default is MsgpackCodec.
+func WithPayloadCodec(codec Codec) ClientOption {
+	return func(c *Client) {
+		c.SetCodec(codec)
+	}
+}
+
+// WithValidator sets Validator checking commands before they are sent. Commands are not validated by default.
+func WithValidator(validator Validator) ClientOption {
+	return func(c *Client) {
+		c.SetValidator(validator)
+	}
+}
+
+// WithPartitionSelector sets PartitionSelector used by CreateTask and CreateWorkflowInstance. Default is RoundRobinSelector.
+func WithPartitionSelector(selector PartitionSelector) ClientOption {
+	return func(c *Client) {
+		c.SetPartitionSelector(selector)
+	}
+}
+
+// WithMaxFrameLength sets length of the longest frame sent to the broker. Default is DefaultMaxFrameLength.
+func WithMaxFrameLength(length int) ClientOption {
+	return func(c *Client) {
+		c.SetMaxFrameLength(length)
+	}
+}
+
+// WithMaxMessageLength sets length of the longest message received from the broker. Default is DefaultMaxMessageLength.
+func WithMaxMessageLength(length int) ClientOption {
+	return func(c *Client) {
+		c.SetMaxMessageLength(length)
+	}
+}
+
+// WithFrameTimeout sets time in which rest of the frame must be received once its header arrives. Default is zero,
+// which disables it.
+func WithFrameTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.SetFrameTimeout(timeout)
+	}
+}
+
+// WithBatchWindow sets number of requests CreateTasks keeps in flight at once. Default is DefaultBatchWindow.
+func WithBatchWindow(window int) ClientOption {
+	return func(c *Client) {
+		c.SetBatchWindow(window)
+	}
+}
+
+// WithReadBufferSize sets size of the buffer in which frames are read. Default is DefaultReadBufferSize. Bigger buffer
+// needs fewer syscalls when broker pushes many events.
+func WithReadBufferSize(size int) ClientOption {
+	return func(c *Client) {
+		if size > 0 {
+			c.readBufferSize = size
+		}
+	}
+}
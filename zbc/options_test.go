@@ -0,0 +1,85 @@
+package zbc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestNewClient_Options(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr(),
+		zbc.WithRequestTimeout(time.Second),
+		zbc.WithKeepAliveInterval(0),
+		zbc.WithReconnectPolicy(nil),
+		zbc.WithMaxFrameLength(4096),
+		zbc.WithReadBufferSize(64*1024),
+		zbc.WithSocketOptions(zbc.SocketOptions{
+			ConnectTimeout: time.Second,
+			KeepAlive:      time.Minute,
+			SendBuffer:     64 * 1024,
+			ReceiveBuffer:  64 * 1024,
+			Nagle:          true,
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if client.RequestTimeout() != time.Second || client.KeepAliveInterval() != 0 || client.MaxFrameLength() != 4096 {
+		t.Fatalf("Options not applied: timeout %s, keep alive %s, max frame length %d",
+			client.RequestTimeout(), client.KeepAliveInterval(), client.MaxFrameLength())
+	}
+	if _, err := client.Topology(); err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := zbc.NewClient(broker.Addr(), zbc.WithTLS(nil))
+	if err != nil {
+		t.Fatalf("Expected nil TLS config to dial without TLS, received %v", err)
+	}
+	plain.Close(context.Background())
+}
+
+func TestNewClient_WithDialer(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	dialed := make(chan string, 1)
+	client, err := zbc.NewClient("in-memory:51015", zbc.WithDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed <- addr
+		return broker.Dial(ctx, network, addr)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if addr := <-dialed; addr != "in-memory:51015" {
+		t.Fatalf("Expected dialer called with address of the client, received %s", addr)
+	}
+	if _, err := client.Topology(); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("Proxy refused connection")
+	}
+	if _, err := zbc.NewClient("in-memory:51015", zbc.WithDialer(failing)); err == nil {
+		t.Fatal("Expected error of the dialer")
+	}
+}
@@ -0,0 +1,55 @@
+package zbc
+
+import (
+	"context"
+	"sync"
+)
+
+// PartitionSelector decides to which partition of the topic new task or workflow instance is created.
+// Partitions are sorted IDs of partitions learned from topology, there is always at least one.
+// It's called from many goroutines, so it must be safe for concurrent use.
+type PartitionSelector interface {
+	SelectPartition(topic string, partitions []uint16) uint16
+}
+
+// RoundRobinSelector spreads commands evenly over partitions of every topic. It's the default PartitionSelector.
+type RoundRobinSelector struct {
+	mu   sync.Mutex
+	next map[string]int
+}
+
+// NewRoundRobinSelector is a constructor for RoundRobinSelector.
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{next: make(map[string]int)}
+}
+
+// SelectPartition returns partition following the one returned last time for the topic.
+func (s *RoundRobinSelector) SelectPartition(topic string, partitions []uint16) uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.next[topic] % len(partitions)
+	s.next[topic] = i + 1
+	return partitions[i]
+}
+
+// SetPartitionSelector is a setter for PartitionSelector used by CreateTask and CreateWorkflowInstance.
+func (c *Client) SetPartitionSelector(selector PartitionSelector) {
+	c.mu.Lock()
+	c.partitionSelector = selector
+	c.mu.Unlock()
+}
+
+// selectPartition returns partition for new task or workflow instance on the topic. If topology doesn't know
+// the topic, partition 0 is used.
+func (c *Client) selectPartition(ctx context.Context, topic string) uint16 {
+	partitions := c.cachedTopology(ctx).Partitions(topic)
+	if len(partitions) == 0 {
+		return 0
+	}
+
+	c.mu.Lock()
+	selector := c.partitionSelector
+	c.mu.Unlock()
+	return selector.SelectPartition(topic, partitions)
+}
@@ -0,0 +1,55 @@
+package zbc_test
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_CreateWorkflowInstanceRoundRobin(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	host, portStr, _ := net.SplitHostPort(broker.Addr())
+	port, _ := strconv.Atoi(portStr)
+	addr := zbc.BrokerAddress{Host: host, Port: port}
+	broker.HandleControl(sbe.ControlMessageType.REQUEST_TOPOLOGY, func(request *zbc.Message) zbtest.Response {
+		var leaders []zbc.TopicLeader
+		for _, partitionID := range []uint16{2, 0, 1} {
+			leaders = append(leaders, zbc.TopicLeader{BrokerAddress: addr, TopicName: "default-topic", PartitionID: partitionID})
+		}
+		return zbtest.ControlResponse(&zbc.Topology{TopicLeaders: leaders, Brokers: []zbc.BrokerAddress{addr}})
+	})
+	broker.HandleCommand(sbe.EventType.WORKFLOW_INSTANCE_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "WORKFLOW_INSTANCE_CREATED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	var partitions []uint16
+	for i := 0; i < 4; i++ {
+		response, err := client.CreateWorkflowInstance("default-topic", "process", -1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partitions = append(partitions, (*response.SbeMessage).(*sbe.ExecuteCommandResponse).PartitionId)
+	}
+
+	expected := []uint16{0, 1, 2, 0}
+	for i := range expected {
+		if partitions[i] != expected[i] {
+			t.Fatalf("Expected partitions %v, received %v", expected, partitions)
+		}
+	}
+}
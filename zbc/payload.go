@@ -0,0 +1,161 @@
+package zbc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+// Codec converts payload objects into payload documents and back. Broker accepts only message pack documents
+// as payload, so every Codec must produce message pack. Codecs differ in how objects are mapped, e.g. which struct
+// tags name the fields. Methods are called from many goroutines, so they must be safe for concurrent use.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// MsgpackCodec maps objects with msgpack.v2, fields are named by msgpack struct tags. It's the default Codec.
+type MsgpackCodec struct{}
+
+// Marshal will encode v as message pack.
+func (MsgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+// Unmarshal will decode message pack data into v.
+func (MsgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return unmarshalMessagePack(data, v)
+}
+
+// JSONCodec maps objects with encoding/json, fields are named by json struct tags and json.Marshaler and
+// json.Unmarshaler are respected. Objects are converted through JSON into message pack, so it is slower than
+// MsgpackCodec. Binary values are not supported by JSON, they are decoded as base64 strings.
+type JSONCodec struct{}
+
+// Marshal will encode v as JSON and convert the document into message pack.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return msgpack.Marshal(fromJSON(document))
+}
+
+// Unmarshal will convert message pack data into JSON document and decode it into v.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	var document interface{}
+	if err := unmarshalMessagePack(data, &document); err != nil {
+		return err
+	}
+
+	converted, err := toJSON(document)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(converted)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// fromJSON replaces JSON numbers by integers where possible, so they are not encoded as floats.
+func fromJSON(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = fromJSON(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = fromJSON(item)
+		}
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	}
+	return v
+}
+
+// toJSON replaces maps with non-string keys decoded from message pack, encoding/json cannot encode them.
+func toJSON(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			var err error
+			if m[fmt.Sprint(k)], err = toJSON(item); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case map[string]interface{}:
+		for k, item := range value {
+			var err error
+			if value[k], err = toJSON(item); err != nil {
+				return nil, err
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			var err error
+			if value[i], err = toJSON(item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// codecOrDefault returns codec, or MsgpackCodec if it's nil.
+func codecOrDefault(codec Codec) Codec {
+	if codec == nil {
+		return MsgpackCodec{}
+	}
+	return codec
+}
+
+type codecKey struct{}
+
+// ContextWithCodec returns copy of ctx which selects codec for payloads of requests sent with it. It's meant for
+// the Ctx variants of the commands, others take CodecOption.
+func ContextWithCodec(ctx context.Context, codec Codec) context.Context {
+	return context.WithValue(ctx, codecKey{}, codec)
+}
+
+// CodecOption will override Codec of the Client for payloads of one request.
+func CodecOption(codec Codec) RequestOption {
+	return func(o *requestOptions) {
+		o.codec = codec
+	}
+}
+
+// SetCodec is a setter for Codec used for payloads. Default is MsgpackCodec.
+func (c *Client) SetCodec(codec Codec) {
+	c.mu.Lock()
+	c.codec = codec
+	c.mu.Unlock()
+}
+
+// payloadCodec returns Codec selected by ctx, or Codec of the client if ctx selects none.
+func (c *Client) payloadCodec(ctx context.Context) Codec {
+	if codec, ok := ctx.Value(codecKey{}).(Codec); ok && codec != nil {
+		return codec
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return codecOrDefault(c.codec)
+}
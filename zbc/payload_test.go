@@ -0,0 +1,97 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+type order struct {
+	OrderID string   `json:"orderId"`
+	Amount  int      `json:"amount"`
+	Items   []string `json:"items"`
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := zbc.JSONCodec{}
+	b, err := codec.Marshal(&order{OrderID: "a-1", Amount: 42, Items: []string{"foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var document map[string]interface{}
+	if err := msgpack.Unmarshal(b, &document); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := document["amount"].(float64); ok || document["orderId"] != "a-1" {
+		t.Fatalf("payload is not message pack named by json tags: %#v", document)
+	}
+
+	var decoded order
+	if err := codec.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.OrderID != "a-1" || decoded.Amount != 42 || len(decoded.Items) != 1 || decoded.Items[0] != "foo" {
+		t.Fatalf("unexpected order: %+v", decoded)
+	}
+}
+
+func TestClient_CodecOption(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	created := make(chan *zbc.Task, 1)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		if err := request.UnmarshalData(&task); err != nil {
+			t.Error(err)
+		}
+		created <- &task
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "CREATED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	task := &zbc.Task{State: "CREATE", Type: "foo", Retries: 3}
+	task.Codec = zbc.JSONCodec{}
+	if err := task.SetPayloadObject(&order{OrderID: "a-1", Amount: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateTask("default-topic", task); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded order
+	received := <-created
+	received.Codec = zbc.JSONCodec{}
+	if err := received.UnmarshalPayload(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.OrderID != "a-1" || decoded.Amount != 42 {
+		t.Fatalf("unexpected order: %+v", decoded)
+	}
+
+	payload := map[string]interface{}{"orderId": "a-2"}
+	if _, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo", PayloadJson: payload}, zbc.CodecOption(zbc.JSONCodec{})); err != nil {
+		t.Fatal(err)
+	}
+	received = <-created
+	if received.PayloadJson != nil {
+		t.Fatal("payload of received task is decoded only by Worker")
+	}
+	var document map[string]interface{}
+	if err := msgpack.Unmarshal(received.Payload, &document); err != nil || document["orderId"] != "a-2" {
+		t.Fatalf("unexpected payload %v: %v", document, err)
+	}
+}
@@ -0,0 +1,262 @@
+package zbc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHealthCheckInterval specifies how often BrokerPool checks its connections.
+const DefaultHealthCheckInterval = 30 * time.Second
+
+// BrokerPool maintains connections to brokers of the cluster. Requests and subscriptions for a partition are sent
+// through connection to the broker leading it. Brokers are discovered through topology and connected on first use.
+type BrokerPool struct {
+	seed *Client
+
+	mu                   sync.Mutex // Guards fields below.
+	brokers              map[string][]*pooledConnection
+	next                 map[string]int
+	connectionsPerBroker int
+	healthCheckInterval  time.Duration
+	stopCh               chan struct{}
+}
+
+// pooledConnection is one connection of the pool together with result of its last health check.
+type pooledConnection struct {
+	client  *Client
+	healthy int32 // Accessed atomically.
+}
+
+func (pc *pooledConnection) isHealthy() bool {
+	return atomic.LoadInt32(&pc.healthy) == 1
+}
+
+func (pc *pooledConnection) setHealthy(healthy bool) {
+	var v int32
+	if healthy {
+		v = 1
+	}
+	atomic.StoreInt32(&pc.healthy, v)
+}
+
+func newBrokerPool(seed *Client) *BrokerPool {
+	p := &BrokerPool{
+		seed:                 seed,
+		brokers:              make(map[string][]*pooledConnection),
+		next:                 make(map[string]int),
+		connectionsPerBroker: 1,
+		healthCheckInterval:  DefaultHealthCheckInterval,
+		stopCh:               make(chan struct{}),
+	}
+	p.brokers[seed.addr] = []*pooledConnection{{client: seed, healthy: 1}}
+	return p
+}
+
+// SetConnectionsPerBroker is a setter for number of connections opened to every broker. Requests are spread over them.
+func (p *BrokerPool) SetConnectionsPerBroker(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.mu.Lock()
+	p.connectionsPerBroker = n
+	p.mu.Unlock()
+}
+
+// SetHealthCheckInterval is a setter for interval of health checks. Subscriptions are rebalanced after every round
+// of health checks, see Rebalance. Zero will disable health checking.
+func (p *BrokerPool) SetHealthCheckInterval(interval time.Duration) {
+	p.mu.Lock()
+	p.healthCheckInterval = interval
+	p.mu.Unlock()
+}
+
+// Client returns connection to the broker with given address. Connections are established on first use
+// and used in round robin. Connections which failed the last health check are skipped while others are available.
+// Brokers are dialed without holding the lock of the pool, so a slow broker doesn't block lookups of the others.
+func (p *BrokerPool) Client(addr string) (*Client, error) {
+	p.mu.Lock()
+	if atomic.LoadInt32(&p.seed.closing) == 1 {
+		p.mu.Unlock()
+		return nil, ErrClientClosed
+	}
+	dial := len(p.brokers[addr]) < p.connectionsPerBroker
+	p.mu.Unlock()
+
+	if dial {
+		client, err := p.dial(addr)
+		p.mu.Lock()
+		conns := p.brokers[addr]
+		if err != nil {
+			p.mu.Unlock()
+			if len(conns) == 0 {
+				return nil, err
+			}
+			p.seed.log().Warn("Opening pooled connection failed", F("addr", addr), F("error", err))
+		} else if !p.isClosed() && len(conns) < p.connectionsPerBroker {
+			p.brokers[addr] = append(conns, &pooledConnection{client: client, healthy: 1})
+			p.mu.Unlock()
+			return client, nil
+		} else {
+			// Another caller connected the broker in the meantime, or the pool was closed.
+			p.mu.Unlock()
+			ctx, cancel := context.WithTimeout(context.Background(), p.seed.RequestTimeout())
+			client.closeConnection(ctx)
+			cancel()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conns := p.brokers[addr]
+	if len(conns) == 0 {
+		return nil, ErrClientClosed
+	}
+	start := p.next[addr]
+	p.next[addr] = (start + 1) % len(conns)
+	for i := range conns {
+		if pc := conns[(start+i)%len(conns)]; pc.isHealthy() {
+			return pc.client, nil
+		}
+	}
+	return conns[start%len(conns)].client, nil
+}
+
+// dial will open new connection to the broker, configured like the seed client.
+func (p *BrokerPool) dial(addr string) (*Client, error) {
+	client, err := newConnection(addr, []ClientOption{WithTLS(p.seed.tlsConfig), WithSocketOptions(p.seed.socketOptions), WithDialer(p.seed.dialer), WithReadBufferSize(p.seed.readBufferSize), WithFrameTimeout(p.seed.FrameTimeout()), withSharedCredentials(p.seed.credentials)})
+	if err != nil {
+		return nil, err
+	}
+	client.SetReconnectPolicy(p.seed.getReconnectPolicy())
+	client.SetKeepAliveInterval(p.seed.KeepAliveInterval())
+	client.SetRequestTimeout(p.seed.RequestTimeout())
+	client.SetMaxFrameLength(p.seed.MaxFrameLength())
+	client.SetMaxMessageLength(p.seed.MaxMessageLength())
+	client.SetLogger(p.seed.log())
+	client.addInterceptors(p.seed.getInterceptors())
+	if observer := p.seed.getObserver(); observer != nil {
+		client.SetObserver(observer)
+	}
+	return client, nil
+}
+
+// isClosed tells if close was called. Caller must hold the lock.
+func (p *BrokerPool) isClosed() bool {
+	select {
+	case <-p.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Clients returns all connections of the pool.
+func (p *BrokerPool) Clients() []*Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var clients []*Client
+	for _, conns := range p.brokers {
+		for _, pc := range conns {
+			clients = append(clients, pc.client)
+		}
+	}
+	return clients
+}
+
+// Close will stop health checking and close connections to other brokers. Connection of the seed client is kept open.
+// Goroutines of the closed connections are waited for up to request timeout of the seed.
+func (p *BrokerPool) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.seed.RequestTimeout())
+	defer cancel()
+	p.close(ctx)
+}
+
+// close is same as Close, but waiting for goroutines of the connections is aborted once ctx is done.
+func (p *BrokerPool) close(ctx context.Context) error {
+	p.mu.Lock()
+	select {
+	case <-p.stopCh:
+		p.mu.Unlock()
+		return nil
+	default:
+		close(p.stopCh)
+	}
+
+	var clients []*Client
+	for addr, conns := range p.brokers {
+		for _, pc := range conns {
+			if pc.client != p.seed {
+				clients = append(clients, pc.client)
+			}
+		}
+		if addr != p.seed.addr {
+			delete(p.brokers, addr)
+		}
+	}
+	p.mu.Unlock()
+
+	// Goroutines of the connections may use the pool, so they are waited for without holding its lock.
+	var err error
+	for _, client := range clients {
+		if closeErr := client.closeConnection(ctx); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// healthCheck will periodically request topology through every connection. Connections which don't respond are closed,
+// so their receiver reconnects. Topology of the seed client is refreshed along the way and subscriptions follow leaders.
+func (p *BrokerPool) healthCheck() {
+	for {
+		p.mu.Lock()
+		interval := p.healthCheckInterval
+		p.mu.Unlock()
+		if interval <= 0 {
+			interval = DefaultHealthCheckInterval
+		}
+
+		select {
+		case <-p.stopCh:
+			return
+		case <-p.seed.lifetime.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		p.mu.Lock()
+		enabled := p.healthCheckInterval > 0
+		var conns []*pooledConnection
+		for _, c := range p.brokers {
+			conns = append(conns, c...)
+		}
+		p.mu.Unlock()
+
+		if enabled {
+			for _, pc := range conns {
+				p.check(pc)
+			}
+			if err := p.Rebalance(); err != nil {
+				p.seed.log().Warn("Rebalancing subscriptions failed", F("error", err))
+			}
+		}
+	}
+}
+
+func (p *BrokerPool) check(pc *pooledConnection) {
+	ctx, cancel := pc.client.requestContext()
+	defer cancel()
+
+	if _, err := pc.client.requestTopology(ctx); err != nil {
+		p.seed.log().Warn("Health check failed", F("addr", pc.client.addr), F("error", err))
+		pc.setHealthy(false)
+		if err == ErrRequestTimeout || isConnectionError(err) {
+			pc.client.connection().Close()
+		}
+		return
+	}
+	pc.setHealthy(true)
+}
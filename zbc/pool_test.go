@@ -0,0 +1,63 @@
+package zbc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestBrokerPool_SlowBroker(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	const slowAddr = "slow-broker:51015"
+	dialing, release := make(chan struct{}), make(chan struct{})
+	dialer := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == slowAddr {
+			close(dialing)
+			<-release
+			return nil, errors.New("Broker unreachable")
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithDialer(dialer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.Pool().Client(slowAddr)
+		slow <- err
+	}()
+	<-dialing
+
+	// Connections to other brokers are looked up while the slow one is being dialed.
+	found := make(chan struct{})
+	go func() {
+		client.Pool().Client(broker.Addr())
+		client.Pool().Clients()
+		close(found)
+	}()
+	select {
+	case <-found:
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatal("Expected lookup not to wait for the slow broker")
+	}
+
+	close(release)
+	if err := <-slow; err == nil {
+		t.Fatal("Expected dialing of the slow broker to fail")
+	}
+}
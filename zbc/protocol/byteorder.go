@@ -0,0 +1,8 @@
+package protocol
+
+import "encoding/binary"
+
+// ByteOrder is order of all integers on the wire, in frame and transport headers as well as in SBE messages. Broker
+// writes little endian independent of its host, so encoding and decoding must never use the native order of the host.
+// It's a variable of the concrete little endian type rather than binary.ByteOrder, so calls of its methods are inlined.
+var ByteOrder = binary.LittleEndian
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
@@ -25,18 +26,26 @@ const (
 	ProtocolControlFrame
 )
 
+const (
+	// FrameFlagBegin marks first fragment of a message split into several frames.
+	FrameFlagBegin = 1 << 7
+
+	// FrameFlagEnd marks last fragment of a message split into several frames.
+	FrameFlagEnd = 1 << 6
+)
+
 // FrameHeader is first layer which we use in framing.
 type FrameHeader struct {
-	Length   uint32
-	Version  uint8
-	Flags    uint8
-	TypeID   uint16 // One of the above defined constants.
-	StreamID uint32
+	Length   uint32 `json:"length"`
+	Version  uint8  `json:"version"`
+	Flags    uint8  `json:"flags"`
+	TypeID   uint16 `json:"typeId"` // One of the above defined constants.
+	StreamID uint32 `json:"streamId"`
 }
 
 // Encode is used to serialize structure to byte array.
 func (fh FrameHeader) Encode(writer io.Writer) error {
-	return binary.Write(writer, binary.LittleEndian, fh)
+	return binary.Write(writer, ByteOrder, fh)
 }
 
 // Decode is use to deserialize byte array to structure.
@@ -44,6 +53,50 @@ func (fh *FrameHeader) Decode(reader io.Reader, order binary.ByteOrder, _ uint16
 	return binary.Read(reader, order, fh)
 }
 
+// IsFragment will tell if the frame is a part of a message split into several frames. Frame without any of the
+// fragment flags carries the whole message.
+func (fh *FrameHeader) IsFragment() bool {
+	flags := fh.Flags & (FrameFlagBegin | FrameFlagEnd)
+	return flags != 0 && flags != FrameFlagBegin|FrameFlagEnd
+}
+
+// String returns the header in a form suitable for logs, e.g.
+// FrameHeader{length: 40, version: 0, flags: begin, type: message, stream: 0}.
+func (fh FrameHeader) String() string {
+	return fmt.Sprintf("FrameHeader{length: %d, version: %d, flags: %s, type: %s, stream: %d}",
+		fh.Length, fh.Version, frameFlagsName(fh.Flags), frameTypeName(fh.TypeID), fh.StreamID)
+}
+
+func frameFlagsName(flags uint8) string {
+	switch flags {
+	case 0:
+		return "none"
+	case FrameFlagBegin:
+		return "begin"
+	case FrameFlagEnd:
+		return "end"
+	case FrameFlagBegin | FrameFlagEnd:
+		return "begin|end"
+	}
+	return fmt.Sprintf("%#x", flags)
+}
+
+func frameTypeName(typeID uint16) string {
+	switch typeID {
+	case FrameTypeMessage:
+		return "message"
+	case ControlClose:
+		return "close"
+	case ControlEndOfStream:
+		return "endOfStream"
+	case ControlKeepAlive:
+		return "keepAlive"
+	case ProtocolControlFrame:
+		return "protocolControl"
+	}
+	return fmt.Sprintf("unknown(%d)", typeID)
+}
+
 // NewFrameHeader is constructor used to construct new FrameHeader object. Used mainly for writing purposes.
 func NewFrameHeader(length uint32, version uint8, flags uint8, typeID uint16, streamID uint32) *FrameHeader {
 	return &FrameHeader{
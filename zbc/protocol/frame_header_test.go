@@ -2,7 +2,7 @@ package protocol
 
 import (
 	"bytes"
-	"encoding/binary"
+	"encoding/json"
 	"testing"
 	"unsafe"
 )
@@ -64,7 +64,7 @@ func TestFrameHeader_Decode(t *testing.T) {
 	}
 
 	var frameHeader FrameHeader
-	err := frameHeader.Decode(bytes.NewReader(payload), binary.LittleEndian, 0)
+	err := frameHeader.Decode(bytes.NewReader(payload), ByteOrder, 0)
 
 	if err != nil {
 		t.Fatalf("Oh boy! %s", err)
@@ -86,3 +86,20 @@ func TestFrameHeader_Decode(t *testing.T) {
 		t.Fatalf("Wrong StreamId. Received %d", frameHeader.StreamID)
 	}
 }
+
+func TestFrameHeader_String(t *testing.T) {
+	frame := NewFrameHeader(40, 0, FrameFlagBegin, ControlKeepAlive, 2)
+	expected := "FrameHeader{length: 40, version: 0, flags: begin, type: keepAlive, stream: 2}"
+	if frame.String() != expected {
+		t.Fatalf("Expected %s, received %s", expected, frame.String())
+	}
+
+	content, err := json.Marshal(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{"length":40,"version":0,"flags":128,"typeId":103,"streamId":2}`
+	if string(content) != expected {
+		t.Fatalf("Expected %s, received %s", expected, content)
+	}
+}
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 	"math/rand"
 	"time"
@@ -9,12 +10,12 @@ import (
 
 // RequestResponseHeader is layer to represent Request-Response model of communication. With it we keep transaction house keeping.
 type RequestResponseHeader struct {
-	RequestID    uint64 // tid
+	RequestID    uint64 `json:"requestId"` // tid
 }
 
 // Encode is used to serialize structure to byte array.
 func (fh RequestResponseHeader) Encode(writer io.Writer) error {
-	return binary.Write(writer, binary.LittleEndian, fh)
+	return binary.Write(writer, ByteOrder, fh)
 }
 
 // Decode is use to deserialize byte array to structure.
@@ -22,6 +23,11 @@ func (fh *RequestResponseHeader) Decode(reader io.Reader, order binary.ByteOrder
 	return binary.Read(reader, order, fh)
 }
 
+// String returns the header in a form suitable for logs, e.g. RequestResponseHeader{requestId: 3}.
+func (fh RequestResponseHeader) String() string {
+	return fmt.Sprintf("RequestResponseHeader{requestId: %d}", fh.RequestID)
+}
+
 // NewRequestResponseHeader is constructor for RequestResponseHeader object. Constructor will generate random ID's for fields.
 func NewRequestResponseHeader() *RequestResponseHeader {
 	max := ^uint64(0)
@@ -2,7 +2,6 @@ package protocol
 
 import (
 	"bytes"
-	"encoding/binary"
 	"reflect"
 	"testing"
 	"unsafe"
@@ -40,7 +39,7 @@ func TestRequestResponseHeader_Decode(t *testing.T) {
 	}
 
 	var rrHeader RequestResponseHeader
-	err := rrHeader.Decode(bytes.NewReader(payload), binary.LittleEndian, 0)
+	err := rrHeader.Decode(bytes.NewReader(payload), ByteOrder, 0)
 
 	if err != nil {
 		t.Fatalf("Decoding went wrong. %s", err)
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"encoding/binary"
+	"fmt"
 	"io"
 )
 
@@ -14,12 +15,12 @@ const (
 
 // TransportHeader contains information about ProtocolID in use.
 type TransportHeader struct {
-	ProtocolID uint16
+	ProtocolID uint16 `json:"protocolId"`
 }
 
 // Encode is used to serialize structure to byte array.
 func (fh TransportHeader) Encode(writer io.Writer) error {
-	return binary.Write(writer, binary.LittleEndian, fh)
+	return binary.Write(writer, ByteOrder, fh)
 }
 
 // Decode is use to deserialize byte array to structure.
@@ -27,6 +28,18 @@ func (fh *TransportHeader) Decode(reader io.Reader, order binary.ByteOrder, _ ui
 	return binary.Read(reader, order, fh)
 }
 
+// String returns the header in a form suitable for logs, e.g. TransportHeader{protocol: requestResponse}.
+func (fh TransportHeader) String() string {
+	name := fmt.Sprintf("unknown(%d)", fh.ProtocolID)
+	switch fh.ProtocolID {
+	case RequestResponse:
+		name = "requestResponse"
+	case FullDuplexSingleMessage:
+		name = "fullDuplexSingleMessage"
+	}
+	return fmt.Sprintf("TransportHeader{protocol: %s}", name)
+}
+
 // NewTransportHeader constructor
 func NewTransportHeader(pid uint16) *TransportHeader {
 	return &TransportHeader{
@@ -2,7 +2,6 @@ package protocol
 
 import (
 	"bytes"
-	"encoding/binary"
 	"reflect"
 	"testing"
 	"unsafe"
@@ -51,7 +50,7 @@ func TestTransportHeader_Decode(t *testing.T) {
 	payload := []byte{0x00, 0x00}
 
 	var transport TransportHeader
-	err := transport.Decode(bytes.NewReader(payload), binary.LittleEndian, 0)
+	err := transport.Decode(bytes.NewReader(payload), ByteOrder, 0)
 
 	if err != nil {
 		t.Fatalf("Decoding went wrong. %s", err)
@@ -66,7 +65,7 @@ func TestTransportHeader_Decode2(t *testing.T) {
 	payload := []byte{0x01, 0x00}
 
 	var transport TransportHeader
-	err := transport.Decode(bytes.NewReader(payload), binary.LittleEndian, 0)
+	err := transport.Decode(bytes.NewReader(payload), ByteOrder, 0)
 
 	if err != nil {
 		t.Fatalf("Decoding went wrong. %s", err)
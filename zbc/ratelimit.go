@@ -0,0 +1,100 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned for commands exceeding RateLimit of the Client if the limit doesn't wait for them.
+var ErrRateLimited = errors.New("Command rate limit exceeded")
+
+// RateLimit limits how many commands Client sends per second, so batch imports don't overwhelm the broker. It is a
+// token bucket: Burst commands can be sent at once, then tokens are refilled at Rate per second. Every attempt counts,
+// retried commands take a token each. Topology requests, subscriptions and other control messages are not limited.
+type RateLimit struct {
+	Rate  float64 // Commands per second.
+	Burst int     // Commands which can be sent at once. At least one.
+	Wait  bool    // Wait for a token until the request times out instead of failing with ErrRateLimited.
+}
+
+// rateLimiter is token bucket of RateLimit. Waiting commands reserve their token upfront, so tokens go negative and
+// commands are sent in the order in which they arrived.
+type rateLimiter struct {
+	limit RateLimit
+
+	mu     sync.Mutex // Guards tokens and last.
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &rateLimiter{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// take will take a token, waiting for it if the limit allows it.
+func (l *rateLimiter) take(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.limit.Rate
+	if burst := float64(l.limit.Burst); l.tokens > burst {
+		l.tokens = burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	if !l.limit.Wait || l.limit.Rate <= 0 {
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+	l.tokens--
+	wait := time.Duration(-l.tokens / l.limit.Rate * float64(time.Second))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the reserved token back to commands waiting behind this one.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrRequestTimeout
+		}
+		return ctx.Err()
+	}
+}
+
+// SetRateLimit is a setter for RateLimit of commands. Nil disables limiting, which is the default.
+func (c *Client) SetRateLimit(limit *RateLimit) {
+	var limiter *rateLimiter
+	if limit != nil {
+		limiter = newRateLimiter(*limit)
+	}
+	c.mu.Lock()
+	c.rateLimiter = limiter
+	c.mu.Unlock()
+}
+
+// takeRateToken will wait for permission to send a command, if rate of commands is limited.
+func (c *Client) takeRateToken(ctx context.Context) error {
+	c.mu.Lock()
+	limiter := c.rateLimiter
+	c.mu.Unlock()
+
+	if limiter == nil {
+		return nil
+	}
+	return limiter.take(ctx)
+}
@@ -0,0 +1,58 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SetRateLimit(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		task.State = zbc.TaskCreated
+		return zbtest.CommandResponse(request, 1, &task)
+	})
+
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithRateLimit(&zbc.RateLimit{Rate: 10, Burst: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	task := &zbc.Task{Type: "foo", Retries: 3}
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateTask("default-topic", task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.CreateTask("default-topic", task); err != zbc.ErrRateLimited {
+		t.Fatalf("Expected command over the burst rate limited, received %v", err)
+	}
+
+	client.SetRateLimit(&zbc.RateLimit{Rate: 20, Burst: 1, Wait: true})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.CreateTask("default-topic", task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("Expected commands to wait for tokens, three commands took %s", elapsed)
+	}
+
+	client.SetRateLimit(&zbc.RateLimit{Rate: 0.1, Burst: 1, Wait: true})
+	client.CreateTask("default-topic", task)
+	if _, err := client.CreateTask("default-topic", task, zbc.TimeoutOption(50*time.Millisecond)); err != zbc.ErrRequestTimeout {
+		t.Fatalf("Expected waiting command to time out, received %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// SendRawCommand will execute command on event of eventType with given key, for commands which the Client doesn't
+// wrap yet. Body is the command encoded as message pack, usually a map with state and properties of the event.
+// Body is sent as it is, so it must match what the broker expects. Response event is returned decoded, rejection
+// of the command is told by its state.
+func (c *Client) SendRawCommand(topic string, eventType sbe.EventTypeEnum, partitionID int32, key int64, body []byte, opts ...RequestOption) (map[string]interface{}, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.SendRawCommandCtx(ctx, topic, eventType, partitionID, key, body)
+}
+
+// SendRawCommandCtx is same as SendRawCommand, but request is aborted once ctx is done.
+func (c *Client) SendRawCommandCtx(ctx context.Context, topic string, eventType sbe.EventTypeEnum, partitionID int32, key int64, body []byte) (map[string]interface{}, error) {
+	msg := newRawCommandMessage(&sbe.ExecuteCommandRequest{
+		PartitionId: uint16(partitionID),
+		Position:    0,
+		Key:         uint64(key),
+		EventType:   eventType,
+		TopicName:   []uint8(topic),
+	}, body)
+
+	response, err := c.executeCommand(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return map[string]interface{}{}, nil
+	}
+	return *response.Data, nil
+}
@@ -0,0 +1,46 @@
+package zbc_test
+
+import (
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestClient_SendRawCommand(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	broker.HandleCommand(sbe.EventType.WORKFLOW_INSTANCE_EVENT, func(request *zbc.Message) zbtest.Response {
+		cmdReq := (*request.SbeMessage).(*sbe.ExecuteCommandRequest)
+		if cmdReq.Key != 42 || cmdReq.PartitionId != 1 {
+			t.Errorf("Expected key 42 on partition 1, received key %d on partition %d", cmdReq.Key, cmdReq.PartitionId)
+		}
+		return zbtest.CommandResponse(request, cmdReq.Key, map[string]interface{}{
+			"state":  "FOO_DONE",
+			"answer": (*request.Data)["question"],
+		})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := msgpack.Marshal(map[string]interface{}{"state": "FOO", "question": "bar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := client.SendRawCommand("default-topic", sbe.EventType.WORKFLOW_INSTANCE_EVENT, 1, 42, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response["state"] != "FOO_DONE" || response["answer"] != "bar" {
+		t.Fatalf("Unexpected response %v", response)
+	}
+}
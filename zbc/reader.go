@@ -6,6 +6,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"io/ioutil"
+	"time"
 
 	"github.com/zeebe-io/zbc-go/zbc/protocol"
 	"github.com/zeebe-io/zbc-go/zbc/sbe"
@@ -13,38 +15,193 @@ import (
 )
 
 var (
-	errFrameHeaderRead    = errors.New("Cannot read bytes for frame header")
-	errFrameHeaderDecode  = errors.New("Cannot decode bytes into frame header")
-	errProtocolIDNotFound = errors.New("ProtocolId not found")
+	errMalformedMessagePack = errors.New("Malformed message pack")
+	errProtocolIDNotFound   = errors.New("ProtocolId not found")
+	errUnexpectedFragment   = errors.New("Received fragment without beginning of the message")
+	errFrameTooShort        = errors.New("Frame is shorter than its headers")
+)
+
+// Errors returned by MessageReader for frames it refuses to decode. Such frame is skipped, so reading can continue
+// with the next one.
+var (
+	ErrUnsupportedSchemaVersion = errors.New("SBE schema or its version not supported")
+	ErrFrameTooLarge            = errors.New("Frame is longer than maximum message length")
 )
 
 // MessageReader is builder which will read byte array and construct Message with all their parts.
 type MessageReader struct {
 	io.Reader
+
+	// MaxMessageLength is length of the longest frame or message reassembled from fragments which is read.
+	// Longer ones are discarded and ErrFrameTooLarge is returned. Zero disables the check.
+	MaxMessageLength uint32
+
+	// Conn gets read deadlines of IdleTimeout and FrameTimeout, if it's set. It's the connection Reader reads from.
+	Conn DeadlineSetter
+	// IdleTimeout is time to wait for the next frame to begin. Zero waits forever.
+	IdleTimeout time.Duration
+	// FrameTimeout is time in which rest of the frame must arrive once its header is read, so a stalled frame is
+	// detected even while the connection is otherwise alive. Zero keeps deadline of IdleTimeout for the whole frame.
+	FrameTimeout time.Duration
 }
 
+// DeadlineSetter is implemented by net.Conn, MessageReader sets read deadlines on it.
+type DeadlineSetter interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// readChunkSize is length up to which frames are read into buffer allocated at once. Longer ones are read into
+// growing buffer, so memory is taken by data which arrived rather than by length claimed in the frame header.
+const readChunkSize = 64 * 1024
+
+// readNext will read exactly n bytes. Connection may return frame in several segments, so one read isn't enough.
+// If stream ends in the middle, io.ErrUnexpectedEOF is returned.
 func (mr *MessageReader) readNext(n uint32) ([]byte, error) {
-	buffer := make([]byte, n)
+	if n > readChunkSize {
+		var buffer bytes.Buffer
+		if _, err := io.CopyN(&buffer, mr, int64(n)); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return buffer.Bytes(), nil
+	}
 
-	numBytes, err := mr.Read(buffer)
-	if uint32(numBytes) != n || err != nil {
+	buffer := make([]byte, n)
+	if _, err := io.ReadFull(mr, buffer); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
 		return nil, err
 	}
-
 	return buffer, nil
 }
 
-func (mr *MessageReader) readFrameHeader(data io.Reader) (*protocol.FrameHeader, error) {
+// waitFrame will set deadline for the next frame to begin.
+func (mr *MessageReader) waitFrame() error {
+	if mr.Conn == nil {
+		return nil
+	}
+	if mr.IdleTimeout <= 0 {
+		return mr.Conn.SetReadDeadline(time.Time{})
+	}
+	return mr.Conn.SetReadDeadline(time.Now().Add(mr.IdleTimeout))
+}
+
+// readFrameHeader will read header of the next frame. Once it's read, rest of the frame must arrive within FrameTimeout.
+// Stream which ends before the header begins returns io.EOF, in the middle of the header io.ErrUnexpectedEOF.
+func (mr *MessageReader) readFrameHeader(frameHeader *protocol.FrameHeader) error {
+	if err := binary.Read(mr, protocol.ByteOrder, frameHeader); err != nil {
+		return err
+	}
+	if mr.Conn == nil || mr.FrameTimeout <= 0 {
+		return nil
+	}
+	return mr.Conn.SetReadDeadline(time.Now().Add(mr.FrameTimeout))
+}
+
+// align will consume padding after the frame. Frames are padded with zeros so that every frame starts at 8 byte boundary.
+func (mr *MessageReader) align(frameHeader *protocol.FrameHeader) error {
+	frameLength := FrameHeaderSize + int(frameHeader.Length)
+	paddingLength := ((frameLength + 7) & ^7) - frameLength
+	if paddingLength == 0 {
+		return nil
+	}
+
+	var padding [8]byte
+	_, err := io.ReadFull(mr, padding[:paddingLength])
+	return err
+}
+
+// tooLarge will decide if message of given length exceeds MaxMessageLength.
+func (mr *MessageReader) tooLarge(length int) bool {
+	return mr.MaxMessageLength > 0 && length > int(mr.MaxMessageLength)
+}
+
+// discard will skip body of the frame and its padding.
+func (mr *MessageReader) discard(frameHeader *protocol.FrameHeader) error {
+	if _, err := io.CopyN(ioutil.Discard, mr, int64(frameHeader.Length)); err != nil {
+		return err
+	}
+	return mr.align(frameHeader)
+}
+
+// readFragments will read remaining fragments of the message and return the whole message. Frame header is updated
+// to describe the whole message.
+func (mr *MessageReader) readFragments(frameHeader *protocol.FrameHeader, message []byte) ([]byte, error) {
+	if frameHeader.Flags&protocol.FrameFlagBegin == 0 {
+		return nil, errUnexpectedFragment
+	}
+
+	for {
+		fragmentHeader, err := mr.readFragmentHeader()
+		if err != nil {
+			return nil, err
+		}
+
+		if mr.tooLarge(len(message) + int(fragmentHeader.Length)) {
+			// Remaining fragments are discarded, so the next message is read correctly.
+			if err := mr.discard(fragmentHeader); err != nil {
+				return nil, err
+			}
+			if fragmentHeader.Flags&protocol.FrameFlagEnd == 0 {
+				if err := mr.skipFragments(); err != nil {
+					return nil, err
+				}
+			}
+			return nil, ErrFrameTooLarge
+		}
+
+		fragment, err := mr.readNext(fragmentHeader.Length)
+		if err != nil {
+			return nil, err
+		}
+		if err := mr.align(fragmentHeader); err != nil {
+			return nil, err
+		}
+		message = append(message, fragment...)
+
+		if fragmentHeader.Flags&protocol.FrameFlagEnd != 0 {
+			break
+		}
+	}
+
+	frameHeader.Length = uint32(len(message))
+	frameHeader.Flags |= protocol.FrameFlagEnd
+	return message, nil
+}
+
+// skipFragments will discard remaining fragments of the message.
+func (mr *MessageReader) skipFragments() error {
+	for {
+		fragmentHeader, err := mr.readFragmentHeader()
+		if err != nil {
+			return err
+		}
+		if err := mr.discard(fragmentHeader); err != nil {
+			return err
+		}
+		if fragmentHeader.Flags&protocol.FrameFlagEnd != 0 {
+			return nil
+		}
+	}
+}
+
+func (mr *MessageReader) readFragmentHeader() (*protocol.FrameHeader, error) {
 	var frameHeader protocol.FrameHeader
-	if frameHeader.Decode(data, binary.LittleEndian, 0) != nil {
-		return nil, errFrameHeaderDecode
+	if err := mr.readFrameHeader(&frameHeader); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
 	}
 	return &frameHeader, nil
 }
 
 func (mr *MessageReader) readTransportHeader(data io.Reader) (*protocol.TransportHeader, error) {
 	var transport protocol.TransportHeader
-	err := transport.Decode(data, binary.LittleEndian, 0)
+	err := transport.Decode(data, protocol.ByteOrder, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -57,7 +214,7 @@ func (mr *MessageReader) readTransportHeader(data io.Reader) (*protocol.Transpor
 
 func (mr *MessageReader) readRequestResponseHeader(data io.Reader) (*protocol.RequestResponseHeader, error) {
 	var requestResponse protocol.RequestResponseHeader
-	err := requestResponse.Decode(data, binary.LittleEndian, 0)
+	err := requestResponse.Decode(data, protocol.ByteOrder, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -67,34 +224,58 @@ func (mr *MessageReader) readRequestResponseHeader(data io.Reader) (*protocol.Re
 
 func (mr *MessageReader) readSbeMessageHeader(data io.Reader) (*sbe.MessageHeader, error) {
 	var sbeMessageHeader sbe.MessageHeader
-	err := sbeMessageHeader.Decode(data, binary.LittleEndian, 0)
+	err := sbeMessageHeader.Decode(data, protocol.ByteOrder, 0)
 	if err != nil {
 		return nil, err
 	}
-	return &sbeMessageHeader, nil
+	// Header is returned with the error, so the request waiting for the message can be failed by it.
+	return &sbeMessageHeader, checkSchema(&sbeMessageHeader)
 }
 
 // ReadHeaders will read entire message and interpret all headers. It will return pointer to headers object and tail of the message as byte array.
 func (mr *MessageReader) ReadHeaders() (*Headers, *[]byte, error) {
 	var header Headers
 
-	headerByte, err := mr.readNext(FrameHeaderSize)
-	if err != nil {
-		return nil, nil, errFrameHeaderRead
+	if err := mr.waitFrame(); err != nil {
+		return nil, nil, err
 	}
-
-	frameHeaderReader := bytes.NewReader(headerByte)
-	frameHeader, err := mr.readFrameHeader(frameHeaderReader)
-	if err != nil {
+	frameHeader := &protocol.FrameHeader{}
+	if err := mr.readFrameHeader(frameHeader); err != nil {
 		return nil, nil, err
 	}
 	header.SetFrameHeader(frameHeader)
 
+	if mr.tooLarge(int(frameHeader.Length)) {
+		if err := mr.discard(frameHeader); err != nil {
+			return nil, nil, err
+		}
+		if frameHeader.IsFragment() && frameHeader.Flags&protocol.FrameFlagBegin != 0 {
+			if err := mr.skipFragments(); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, nil, ErrFrameTooLarge
+	}
+
 	message, err := mr.readNext(frameHeader.Length)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := mr.align(frameHeader); err != nil {
+		return nil, nil, err
+	}
+	if header.IsControlFrame() {
+		return &header, nil, nil
+	}
+	if frameHeader.IsFragment() {
+		if message, err = mr.readFragments(frameHeader, message); err != nil {
+			return nil, nil, err
+		}
+	}
 
+	if len(message) < TransportHeaderSize+SBEMessageHeaderSize {
+		return nil, nil, errFrameTooShort
+	}
 	transportReader := bytes.NewReader(message[:TransportHeaderSize])
 	transport, err := mr.readTransportHeader(transportReader)
 	if err != nil {
@@ -105,10 +286,13 @@ func (mr *MessageReader) ReadHeaders() (*Headers, *[]byte, error) {
 	sbeIndex := TransportHeaderSize
 	switch transport.ProtocolID {
 	case protocol.RequestResponse:
+		if len(message) < TransportHeaderSize+RequestResponseHeaderSize+SBEMessageHeaderSize {
+			return nil, nil, errFrameTooShort
+		}
 		reqRespReader := bytes.NewReader(message[TransportHeaderSize:TransportHeaderSize+RequestResponseHeaderSize])
 		requestResponse, errHeader := mr.readRequestResponseHeader(reqRespReader)
 		if errHeader != nil {
-			return nil, nil, err
+			return nil, nil, errHeader
 		}
 		header.SetRequestResponseHeader(requestResponse)
 		sbeIndex = TransportHeaderSize + RequestResponseHeaderSize
@@ -130,53 +314,20 @@ func (mr *MessageReader) ReadHeaders() (*Headers, *[]byte, error) {
 	return &header, &body, nil
 }
 
-func (mr *MessageReader) decodeCmdRequest(reader *bytes.Reader, header *sbe.MessageHeader) (*sbe.ExecuteCommandRequest, error) {
-	var commandRequest sbe.ExecuteCommandRequest
-	err := commandRequest.Decode(reader,
-		binary.LittleEndian,
-		header.Version,
-		header.BlockLength,
-		true)
-	if err != nil {
-		return nil, err
-	}
-	return &commandRequest, nil
-}
-
-func (mr *MessageReader) decodeCmdResponse(reader *bytes.Reader, header *sbe.MessageHeader) (*sbe.ExecuteCommandResponse, error) {
-	var commandResponse sbe.ExecuteCommandResponse
-	err := commandResponse.Decode(reader,
-		binary.LittleEndian,
-		header.Version,
-		header.BlockLength,
-		true)
-	if err != nil {
-		return nil, err
-	}
-	return &commandResponse, nil
-}
-
-func (mr *MessageReader) decodeCtlResponse(reader *bytes.Reader, header *sbe.MessageHeader) (*sbe.ControlMessageResponse, error) {
-	var controlResponse sbe.ControlMessageResponse
-	err := controlResponse.Decode(reader, binary.LittleEndian, header.Version, header.BlockLength, true)
-	if err != nil {
-		return nil, err
-	}
-	return &controlResponse, nil
-}
-
-func (mr *MessageReader) decodeSubEvent(reader *bytes.Reader, header *sbe.MessageHeader) (*sbe.SubscribedEvent, error) {
-	var subEvent sbe.SubscribedEvent
-	err := subEvent.Decode(reader, binary.LittleEndian, header.Version, header.BlockLength, true)
-	if err != nil {
-		return nil, err
-	}
-	return &subEvent, nil
+// unmarshalMessagePack will decode message pack data into v. Decoder panics on some malformed documents, e.g. map
+// with an array as key, such documents return error instead, so a misbehaving broker can't crash the client.
+func unmarshalMessagePack(data []byte, v interface{}) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = errMalformedMessagePack
+		}
+	}()
+	return msgpack.Unmarshal(data, v)
 }
 
 func (mr *MessageReader) parseMessagePack(data *[]byte) (*map[string]interface{}, error) {
 	var item map[string]interface{}
-	err := msgpack.Unmarshal(*data, &item)
+	err := unmarshalMessagePack(*data, &item)
 
 	if err != nil {
 		return nil, err
@@ -186,67 +337,34 @@ func (mr *MessageReader) parseMessagePack(data *[]byte) (*map[string]interface{}
 
 // ParseMessage will take the headers and tail and construct Message.
 func (mr *MessageReader) ParseMessage(headers *Headers, message *[]byte) (*Message, error) {
+	return mr.parseMessage(headers, bytes.NewReader(*message))
+}
+
+// parseMessage will decode SBE message described by the headers from reader and construct Message. Decoders are
+// chosen by schema version of the headers. Message of unknown template is returned with headers only.
+func (mr *MessageReader) parseMessage(headers *Headers, reader io.Reader) (*Message, error) {
 	var msg Message
 	msg.SetHeaders(headers)
-	reader := bytes.NewReader(*message)
-
-	switch headers.SbeMessageHeader.TemplateId {
-
-	case templateIDExecuteCommandRequest: // Testing purposes.
-		commandRequest, err := mr.decodeCmdRequest(reader, headers.SbeMessageHeader)
-		if err != nil {
-			return nil, err
-		}
-		msg.SetSbeMessage(commandRequest)
 
-		msgPackData, err := mr.parseMessagePack(&commandRequest.Command)
-		if err != nil {
-			return nil, err
-		}
-
-		msg.SetData(msgPackData)
-		break
-
-	case templateIDExecuteCommandResponse: // Read response from the socket.
-		commandResponse, err := mr.decodeCmdResponse(reader, headers.SbeMessageHeader)
-		if err != nil {
-			return nil, err
-		}
-		msg.SetSbeMessage(commandResponse)
-
-		msgPackData, err := mr.parseMessagePack(&commandResponse.Event)
-		if err != nil {
-			return nil, err
-		}
-		msg.SetData(msgPackData)
-		break
-
-	case templateIDControlMessageResponse:
-		ctlResponse, err := mr.decodeCtlResponse(reader, headers.SbeMessageHeader)
-		if err != nil {
-			return nil, err
-		}
-		msg.SetSbeMessage(ctlResponse)
-		msgPackData, err := mr.parseMessagePack(&ctlResponse.Data)
-		if err != nil {
-			return nil, err
-		}
-		msg.SetData(msgPackData)
-		break
-
-	case templateIDSubscriptionEvent:
-		subscribedEvent, err := mr.decodeSubEvent(reader, headers.SbeMessageHeader)
-		if err != nil {
-			return nil, err
-		}
+	if err := checkSchema(headers.SbeMessageHeader); err != nil {
+		return nil, err
+	}
+	decode, ok := schemaDecoders[headers.SbeMessageHeader.Version][headers.SbeMessageHeader.TemplateId]
+	if !ok {
+		return &msg, nil
+	}
+	body, err := decode(reader, headers.SbeMessageHeader)
+	if err != nil {
+		return nil, err
+	}
+	msg.SetSbeMessage(body)
 
-		msg.SetSbeMessage(subscribedEvent)
-		msgPackData, err := mr.parseMessagePack(&subscribedEvent.Event)
+	if data, ok := messagePack(body); ok {
+		msgPackData, err := mr.parseMessagePack(&data)
 		if err != nil {
 			return nil, err
 		}
 		msg.SetData(msgPackData)
-		break
 	}
 	return &msg, nil
 }
@@ -254,6 +372,6 @@ func (mr *MessageReader) ParseMessage(headers *Headers, message *[]byte) (*Messa
 // NewMessageReader is constructor for MessageReader builder.
 func NewMessageReader(rd *bufio.Reader) *MessageReader {
 	return &MessageReader{
-		rd,
+		Reader: rd,
 	}
 }
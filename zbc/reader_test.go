@@ -0,0 +1,312 @@
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// chunkReader returns its chunks one by one, like a socket which receives data in several segments.
+type chunkReader struct {
+	chunks [][]byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.chunks[0])
+	if n == len(r.chunks[0]) {
+		r.chunks = r.chunks[1:]
+	} else {
+		r.chunks[0] = r.chunks[0][n:]
+	}
+	return n, nil
+}
+
+func writeTestFrame(t *testing.T, requestID uint64, topic string) []byte {
+	msg := NewCommandRequestMessage(&sbe.ExecuteCommandRequest{
+		EventType: sbe.EventType.TASK_EVENT,
+		TopicName: []uint8(topic),
+	}, &Task{State: "CREATE", Type: "foo", Retries: 3})
+	msg.Headers.RequestResponseHeader.RequestID = requestID
+
+	buffer := &bytes.Buffer{}
+	NewMessageWriter(msg).Write(buffer)
+
+	frameLength := FrameHeaderSize + int(msg.Headers.FrameHeader.Length)
+	if frameLength%8 == 0 {
+		t.Fatalf("Frame %d is aligned, test needs padding", requestID)
+	}
+	return buffer.Bytes()
+}
+
+func readTestFrames(t *testing.T, rd io.Reader, requestIDs ...uint64) {
+	r := NewMessageReader(bufio.NewReader(rd))
+	for _, requestID := range requestIDs {
+		headers, _, err := r.ReadHeaders()
+		if err != nil {
+			t.Fatalf("Reading headers of frame %d failed: %s", requestID, err)
+		}
+
+		if headers.RequestResponseHeader.RequestID != requestID {
+			t.Fatalf("Expected frame %d, received %d", requestID, headers.RequestResponseHeader.RequestID)
+		}
+	}
+
+	if _, _, err := r.ReadHeaders(); err != io.EOF {
+		t.Fatalf("Expected end of stream, received %v", err)
+	}
+}
+
+func TestMessageReader_PaddedFrames(t *testing.T) {
+	stream := append(writeTestFrame(t, 1, "topic-a"), writeTestFrame(t, 2, "other-topic")...)
+	readTestFrames(t, bytes.NewReader(stream), 1, 2)
+}
+
+func TestMessageReader_PaddingInSeparateSegment(t *testing.T) {
+	first := writeTestFrame(t, 1, "topic-a")
+	second := writeTestFrame(t, 2, "other-topic")
+
+	// Split the first frame right before its padding.
+	frameLength := FrameHeaderSize + int(binaryLength(first))
+	rd := &chunkReader{chunks: [][]byte{first[:frameLength], first[frameLength:], second}}
+	readTestFrames(t, rd, 1, 2)
+}
+
+func binaryLength(frame []byte) uint32 {
+	return uint32(frame[0]) | uint32(frame[1])<<8 | uint32(frame[2])<<16 | uint32(frame[3])<<24
+}
+
+func TestMessageReader_SkipsKeepAliveFrames(t *testing.T) {
+	stream := append(append([]byte{}, keepAliveFrame...), writeTestFrame(t, 1, "topic-a")...)
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(stream)))
+
+	headers, _, err := r.ReadHeaders()
+	if err != nil || !headers.IsControlFrame() {
+		t.Fatalf("Expected control frame, received %+v, %v", headers, err)
+	}
+	readTestFrames(t, r, 1)
+}
+
+func TestMessageReader_FrameTooLarge(t *testing.T) {
+	first := writeTestFrame(t, 1, "other-topic")
+	second := writeTestFrame(t, 2, "topic-a")
+
+	msg := NewCommandRequestMessage(&sbe.ExecuteCommandRequest{
+		EventType: sbe.EventType.TASK_EVENT,
+		TopicName: []uint8("other-topic"),
+	}, &Task{State: "CREATE", Type: "foo", Retries: 3})
+	fragmented := &bytes.Buffer{}
+	NewMessageWriter(msg).WriteFragments(fragmented, 16)
+
+	stream := append(append(append([]byte{}, first...), fragmented.Bytes()...), second...)
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(stream)))
+	r.MaxMessageLength = binaryLength(second)
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := r.ReadHeaders(); err != ErrFrameTooLarge {
+			t.Fatalf("Expected ErrFrameTooLarge, received %v", err)
+		}
+	}
+
+	// Frames which are too large are skipped, following ones are read.
+	readTestFrames(t, r, 2)
+}
+
+func TestMessageReader_UnsupportedSchemaVersion(t *testing.T) {
+	frame := writeTestFrame(t, 1, "topic-a")
+	// Version of SBE header follows frame, transport and request response headers and three other fields.
+	offset := FrameHeaderSize + TransportHeaderSize + RequestResponseHeaderSize + 6
+	frame[offset] = SchemaVersion + 1
+
+	stream := append(frame, writeTestFrame(t, 2, "other-topic")...)
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(stream)))
+	if _, _, err := r.ReadHeaders(); Cause(err) != ErrUnsupportedSchemaVersion {
+		t.Fatalf("Expected ErrUnsupportedSchemaVersion, received %v", err)
+	}
+	readTestFrames(t, r, 2)
+}
+
+// TestMessageReader_SchemaVersion1 decodes request captured from broker speaking version 1, which has no position.
+func TestMessageReader_SchemaVersion1(t *testing.T) {
+	frame, err := ioutil.ReadFile("../tests/test-zbdump/dumps/create-task-request")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(frame)))
+	headers, tail, err := r.ReadHeaders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if headers.SbeMessageHeader.Version != 1 {
+		t.Fatalf("Expected version 1, received %d", headers.SbeMessageHeader.Version)
+	}
+	msg, err := r.ParseMessage(headers, tail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := (*msg.SbeMessage).(*sbe.ExecuteCommandRequest)
+	if string(req.TopicName) != "default-topic" || req.EventType != sbe.EventType.TASK_EVENT {
+		t.Fatalf("Expected task event of default-topic, decoded %+v", req)
+	}
+	if req.Position != req.PositionNullValue() {
+		t.Fatalf("Expected null position, decoded %d", req.Position)
+	}
+}
+
+func TestMessageReader_FragmentedNetwork(t *testing.T) {
+	var stream []byte
+	for requestID := uint64(1); requestID <= 3; requestID++ {
+		stream = append(stream, writeTestFrame(t, requestID, "topic-a")...)
+	}
+
+	// Smallest buffer over socket returning a byte at a time makes every frame arrive in many short reads.
+	r := NewMessageReader(bufio.NewReaderSize(iotest.OneByteReader(bytes.NewReader(stream)), 16))
+	readTestFrames(t, r, 1, 2, 3)
+}
+
+func TestMessageReader_SlowNetwork(t *testing.T) {
+	var stream []byte
+	for requestID := uint64(1); requestID <= 3; requestID++ {
+		stream = append(stream, writeTestFrame(t, requestID, "topic-a")...)
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		// Segments of odd length cut frames at every possible place: in headers, body and padding.
+		for len(stream) > 0 {
+			n := 7
+			if n > len(stream) {
+				n = len(stream)
+			}
+			pw.Write(stream[:n])
+			stream = stream[n:]
+			time.Sleep(time.Millisecond)
+		}
+		pw.Close()
+	}()
+
+	r := NewMessageReader(bufio.NewReaderSize(pr, 16))
+	for requestID := uint64(1); requestID <= 3; requestID++ {
+		message, err := r.ReadMessage()
+		if err != nil {
+			t.Fatalf("Reading message %d failed: %s", requestID, err)
+		}
+		if message.Headers.RequestResponseHeader.RequestID != requestID {
+			t.Fatalf("Expected message %d, received %d", requestID, message.Headers.RequestResponseHeader.RequestID)
+		}
+	}
+	if _, err := r.ReadMessage(); err != io.EOF {
+		t.Fatalf("Expected end of stream, received %v", err)
+	}
+}
+
+func TestMessageReader_TruncatedFrame(t *testing.T) {
+	frame := writeTestFrame(t, 1, "topic-a")
+	for _, length := range []int{FrameHeaderSize / 2, FrameHeaderSize + 4, len(frame) - 1} {
+		r := NewMessageReader(bufio.NewReader(bytes.NewReader(frame[:length])))
+		if _, _, err := r.ReadHeaders(); err != io.ErrUnexpectedEOF {
+			t.Fatalf("Expected io.ErrUnexpectedEOF for %d bytes, received %v", length, err)
+		}
+	}
+}
+
+func TestMessageReader_MalformedMessagePack(t *testing.T) {
+	// Nested map with an array as key makes the message pack decoder panic.
+	msg := newRawCommandMessage(&sbe.ExecuteCommandRequest{
+		EventType: sbe.EventType.TASK_EVENT,
+		TopicName: []uint8("topic-a"),
+	}, []byte{0x81, 0xa1, 0x61, 0x81, 0x91, 0x01, 0x01})
+	buffer := &bytes.Buffer{}
+	NewMessageWriter(msg).Write(buffer)
+
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(buffer.Bytes())))
+	headers, body, err := r.ReadHeaders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.ParseMessage(headers, body); err != errMalformedMessagePack {
+		t.Fatalf("Expected errMalformedMessagePack, received %v", err)
+	}
+
+	r = NewMessageReader(bufio.NewReader(bytes.NewReader(buffer.Bytes())))
+	if _, err := r.ReadMessage(); err != errMalformedMessagePack {
+		t.Fatalf("Expected errMalformedMessagePack, received %v", err)
+	}
+}
+
+// TestMessageReader_CorruptedFrames reads frame with every byte corrupted in turn, readers must fail without panic.
+func TestMessageReader_CorruptedFrames(t *testing.T) {
+	frame := writeTestFrame(t, 1, "topic-a")
+	for i := range frame {
+		corrupted := append([]byte(nil), frame...)
+		corrupted[i] ^= 0xff
+
+		r := NewMessageReader(bufio.NewReader(bytes.NewReader(corrupted)))
+		r.MaxMessageLength = 1024
+		if headers, body, err := r.ReadHeaders(); err == nil && body != nil {
+			r.ParseMessage(headers, body)
+		}
+
+		r = NewMessageReader(bufio.NewReader(bytes.NewReader(corrupted)))
+		r.MaxMessageLength = 1024
+		r.ReadMessage()
+	}
+}
+
+func TestMessageReader_FrameTimeout(t *testing.T) {
+	frame := writeTestFrame(t, 1, "topic-a")
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		// Waiting for the frame to begin is not limited by FrameTimeout.
+		time.Sleep(100 * time.Millisecond)
+		server.Write(frame)
+		// Frame which stalls after its header is.
+		server.Write(frame[:FrameHeaderSize+4])
+	}()
+
+	r := NewMessageReader(bufio.NewReader(client))
+	r.Conn = client
+	r.FrameTimeout = 50 * time.Millisecond
+
+	message, err := r.ReadMessage()
+	if err != nil || message.Headers.RequestResponseHeader.RequestID != 1 {
+		t.Fatalf("Expected message 1, received %+v, %v", message, err)
+	}
+	if _, err := r.ReadMessage(); !isConnectionError(err) {
+		t.Fatalf("Expected timeout, received %v", err)
+	}
+}
+
+func BenchmarkMessageReader_Decode(b *testing.B) {
+	buffer := &bytes.Buffer{}
+	NewMessageWriter(newTestCommandMessage()).Write(buffer)
+	frame := buffer.Bytes()
+
+	stream := bytes.NewReader(frame)
+	rd := bufio.NewReader(stream)
+	r := NewMessageReader(rd)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stream.Reset(frame)
+		rd.Reset(stream)
+		headers, tail, err := r.ReadHeaders()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := r.ParseMessage(headers, tail); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package zbc
+
+import (
+	"context"
+)
+
+// partition returns topic and partition of the subscription.
+func (s *Subscription) partition() (string, uint16) {
+	if s.task != nil {
+		return s.task.TopicName, uint16(s.task.PartitionID)
+	}
+	return s.topic.TopicName, uint16(s.topic.PartitionID)
+}
+
+// Rebalance will request topology and move subscriptions of partitions whose leader changed to their new leaders.
+// Subscriptions which can't be moved stay where they are and are moved by the next Rebalance. It's called after
+// every round of health checks, so subscriptions don't silently stop once leader of their partition moves.
+func (p *BrokerPool) Rebalance() error {
+	ctx, cancel := p.seed.requestContext()
+	defer cancel()
+	return p.RebalanceCtx(ctx)
+}
+
+// RebalanceCtx is same as Rebalance, but it gives up once ctx is done.
+func (p *BrokerPool) RebalanceCtx(ctx context.Context) error {
+	topology, err := p.seed.requestTopology(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, client := range p.Clients() {
+		client.mu.Lock()
+		subs := make([]*Subscription, len(client.openedSubscriptions))
+		copy(subs, client.openedSubscriptions)
+		client.mu.Unlock()
+
+		for _, sub := range subs {
+			topic, partitionID := sub.partition()
+			addr, ok := topology.Leader(topic, partitionID)
+			if !ok || addr == client.addr {
+				continue
+			}
+
+			leader, err := p.Client(addr)
+			if err != nil {
+				p.seed.log().Warn("Connecting new leader failed", F("subscription", sub), F("addr", addr), F("error", err))
+				continue
+			}
+			if err := client.moveSubscription(ctx, sub, leader); err != nil {
+				p.seed.log().Warn("Moving subscription to new leader failed", F("subscription", sub), F("addr", addr), F("error", err))
+				continue
+			}
+			p.seed.log().Info("Subscription moved to new leader", F("subscription", sub), F("from", client.addr), F("addr", addr))
+		}
+	}
+	return nil
+}
+
+// moveSubscription will open the subscription on connection to the new leader and remove it from this connection.
+// Events keep arriving on the same channel. If it can't be opened, subscription is left on this connection.
+func (c *Client) moveSubscription(ctx context.Context, sub *Subscription, leader *Client) error {
+	c.mu.Lock()
+	found := false
+	for i, opened := range c.openedSubscriptions {
+		if opened == sub {
+			c.openedSubscriptions = append(c.openedSubscriptions[:i], c.openedSubscriptions[i+1:]...)
+			found = true
+			break
+		}
+	}
+	key := sub.key
+	c.mu.Unlock()
+	if !found {
+		// Subscription was closed meanwhile.
+		return nil
+	}
+
+	sub.setOwner(leader)
+	if err := leader.openSubscription(ctx, sub); err != nil {
+		sub.setOwner(c)
+		c.mu.Lock()
+		c.openedSubscriptions = append(c.openedSubscriptions, sub)
+		c.mu.Unlock()
+		return err
+	}
+
+	leader.mu.Lock()
+	leader.openedSubscriptions = append(leader.openedSubscriptions, sub)
+	leader.mu.Unlock()
+
+	c.mu.Lock()
+	if c.subscriptions[key] == sub {
+		delete(c.subscriptions, key)
+	}
+	c.mu.Unlock()
+
+	// Former leader may still know the subscription, e.g. if it's a follower now.
+	if msg := sub.closeMessage(key); msg != nil {
+		if _, err := c.ResponderCtx(ctx, msg); err != nil {
+			c.log().Debug("Removing subscription from former leader failed", F("subscription", sub), F("addr", c.addr), F("error", err))
+		}
+	}
+	return nil
+}
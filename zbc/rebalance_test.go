@@ -0,0 +1,71 @@
+package zbc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestBrokerPool_Rebalance(t *testing.T) {
+	var brokers [2]*zbtest.MockBroker
+	for i := range brokers {
+		broker, err := zbtest.NewMockBroker()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer broker.Close()
+		brokers[i] = broker
+	}
+
+	var leader int32
+	topology := func(request *zbc.Message) zbtest.Response {
+		addr := brokerAddress(t, brokers[atomic.LoadInt32(&leader)].Addr())
+		return zbtest.ControlResponse(&zbc.Topology{
+			TopicLeaders: []zbc.TopicLeader{{BrokerAddress: addr, TopicName: "default-topic", PartitionID: 0}},
+			Brokers:      []zbc.BrokerAddress{brokerAddress(t, brokers[0].Addr()), brokerAddress(t, brokers[1].Addr())},
+		})
+	}
+	for _, broker := range brokers {
+		broker.HandleControl(sbe.ControlMessageType.REQUEST_TOPOLOGY, topology)
+	}
+
+	client, err := zbc.NewClient(brokers[0].Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	sub, err := client.OpenTaskSubscription(&zbc.TaskSubscription{
+		TopicName:    "default-topic",
+		TaskType:     "foo",
+		LockDuration: 1000,
+		LockOwner:    "zbc",
+		Credits:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Leader of the partition moves to the second broker.
+	atomic.StoreInt32(&leader, 1)
+	if err := client.Pool().Rebalance(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := brokers[1].PushTask(1, &zbc.Task{Type: "foo", Retries: 3}); err != nil {
+		t.Fatalf("Expected subscription opened on the new leader, received %v", err)
+	}
+	select {
+	case <-sub.Events():
+	case <-time.After(time.Second):
+		t.Fatal("Expected task of the new leader on the same channel")
+	}
+	if err := brokers[0].PushTask(2, &zbc.Task{Type: "foo", Retries: 3}); err == nil {
+		t.Fatal("Expected subscription removed from the former leader")
+	}
+}
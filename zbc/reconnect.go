@@ -0,0 +1,131 @@
+package zbc
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+)
+
+var errReconnectFailed = errors.New("Reconnecting to the broker failed")
+
+// ReconnectPolicy describes how Client will try to re-establish connection to the broker once the socket breaks.
+type ReconnectPolicy struct {
+	InitialBackoff time.Duration // Time to wait before first reconnect attempt.
+	MaxBackoff     time.Duration // Upper bound for time between two attempts.
+	Multiplier     float64       // Factor by which backoff grows after every failed attempt.
+	Jitter         float64       // Randomization factor in range [0, 1] applied to every backoff.
+	MaxAttempts    int           // Number of attempts before giving up. Zero means retry forever.
+}
+
+// DefaultReconnectPolicy is used by every new Client.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	MaxAttempts:    0,
+}
+
+// Backoff returns time to wait before given reconnect attempt. Attempts are counted from 1.
+func (p *ReconnectPolicy) Backoff(attempt int) time.Duration {
+	return exponentialBackoff(p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter, attempt)
+}
+
+// exponentialBackoff returns initial backoff multiplied for every previous attempt, capped at max and randomized by jitter.
+func exponentialBackoff(initial, max time.Duration, multiplier, jitter float64, attempt int) time.Duration {
+	backoff := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if backoff > float64(max) {
+		backoff = float64(max)
+	}
+
+	delta := jitter * backoff
+	backoff = backoff - delta + rand.Float64()*2*delta
+	return time.Duration(backoff)
+}
+
+// isConnectionError will decide if error returned by the socket means that the connection is broken. In-memory
+// connections of custom dialers, e.g. net.Pipe, fail with io.ErrClosedPipe.
+func isConnectionError(err error) bool {
+	if err == io.EOF || err == io.ErrUnexpectedEOF || err == io.ErrClosedPipe {
+		return true
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// dial will connect to the broker with the dialer, or over TCP if it's nil, and perform TLS handshake if tlsConfig is set.
+func dial(addr string, tlsConfig *tls.Config, opts *SocketOptions, dialer DialerFunc) (net.Conn, error) {
+	if dialer == nil {
+		if tlsConfig != nil {
+			return opts.dialTLS(addr, tlsConfig)
+		}
+		return opts.dialTCP("tcp4", addr) // TODO: support IPv6
+	}
+
+	conn, err := opts.dialWith(dialer, addr)
+	if err != nil || tlsConfig == nil {
+		return conn, err
+	}
+	return opts.handshake(conn, addr, tlsConfig)
+}
+
+// reconnect will dial the broker until it succeeds or ReconnectPolicy gives up. Open subscriptions are reopened on the new connection
+// and queued tasks are created. ErrClientClosed is returned once Close ends lifetime of the client.
+func (c *Client) reconnect() error {
+	policy := c.getReconnectPolicy()
+	if policy == nil {
+		return errReconnectFailed
+	}
+
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		select {
+		case <-c.lifetime.Done():
+			return ErrClientClosed
+		case <-time.After(policy.Backoff(attempt)):
+		}
+
+		conn, err := c.dialBroker()
+		if err != nil {
+			c.log().Warn("Reconnect attempt failed", F("addr", c.addr), F("attempt", attempt), F("error", err))
+			continue
+		}
+
+		if !c.replaceConnection(conn) {
+			return ErrClientClosed
+		}
+		c.log().Info("Reconnected", F("addr", c.addr), F("attempts", attempt))
+
+		// Receiver must be running before we can receive responses for reopened subscriptions.
+		c.spawn(c.resubscribe)
+		c.flushTaskQueue()
+		return nil
+	}
+	return errReconnectFailed
+}
+
+// resubscribe will open every known subscription on the current connection. Events will keep arriving on the same channel.
+func (c *Client) resubscribe() {
+	c.mu.Lock()
+	c.subscriptions = make(map[uint64]*Subscription)
+	subs := make([]*Subscription, len(c.openedSubscriptions))
+	copy(subs, c.openedSubscriptions)
+	c.mu.Unlock()
+
+	for _, sub := range subs {
+		ctx, cancel := c.requestContext()
+		err := c.openSubscription(ctx, sub)
+		cancel()
+		if err == ErrClientClosed {
+			return
+		}
+		if err != nil {
+			c.log().Error("Reopening subscription failed", F("subscription", sub), F("error", err))
+			continue
+		}
+		c.log().Info("Subscription reopened", F("subscription", sub))
+	}
+}
@@ -1,18 +1,36 @@
 package zbc
 
 import (
+	"context"
+
 	"github.com/zeebe-io/zbc-go/zbc/protocol"
 	"github.com/zeebe-io/zbc-go/zbc/sbe"
 	"gopkg.in/vmihailenco/msgpack.v2"
 )
 
+// Task is command creating a task and task received through subscription. Headers are set by the broker for tasks of
+// workflow instances, e.g. workflowInstanceKey and activityId, custom headers are set by whoever creates the task,
+// by the task definition of the workflow or by CreateTask, and are kept by the broker as they are.
 type Task struct {
-	State       string                 `yaml:"state" msgpack:"state"`
-	Headers     map[string]interface{} `yaml:"headers" msgpack:"headers"`
-	Retries     int                    `yaml:"retries" msgpack:"retries"`
-	Type        string                 `yaml:"type" msgpack:"type"`
-	Payload     []uint8                `yaml:"-" msgpack:"payload"`
-	PayloadJson map[string]interface{} `yaml:"payload" msgpack:"-"`
+	State         string                 `yaml:"state" msgpack:"state"`
+	Headers       map[string]interface{} `yaml:"headers" msgpack:"headers"`
+	CustomHeaders map[string]string      `yaml:"customHeaders" msgpack:"customHeaders,omitempty"`
+	Retries       int                    `yaml:"retries" msgpack:"retries"`
+	Type          string                 `yaml:"type" msgpack:"type"`
+	Payload       []uint8                `yaml:"-" msgpack:"payload"`
+	PayloadJson   map[string]interface{} `yaml:"payload" msgpack:"-"`
+	Codec         Codec                  `yaml:"-" msgpack:"-"` // Codec of the payload, MsgpackCodec if nil.
+
+	ctx context.Context
+}
+
+// Context returns context of the task, which carries trace context extracted by Worker and pprof labels of the handler.
+// It is never nil.
+func (t *Task) Context() context.Context {
+	if t.ctx != nil {
+		return t.ctx
+	}
+	return context.Background()
 }
 
 type WorkflowInstance struct {
@@ -21,16 +39,65 @@ type WorkflowInstance struct {
 	Version       int                    `yaml:"version" msgpack:"version"`
 	Payload       []uint8                `yaml:"-" msgpack:"payload"`
 	PayloadJson   map[string]interface{} `yaml:"payload" msgpack:"-"`
+	Codec         Codec                  `yaml:"-" msgpack:"-"` // Codec of the payload, MsgpackCodec if nil.
+}
+
+// SetPayloadObject will marshal v into payload of the task using Codec of the task. With the default codec
+// fields of v are named by msgpack struct tags.
+func (t *Task) SetPayloadObject(v interface{}) error {
+	b, err := codecOrDefault(t.Codec).Marshal(v)
+	if err != nil {
+		return err
+	}
+	t.Payload = b
+	return nil
+}
+
+// UnmarshalPayload will decode payload of the task into v using Codec of the task.
+func (t *Task) UnmarshalPayload(v interface{}) error {
+	return codecOrDefault(t.Codec).Unmarshal(t.Payload, v)
+}
+
+// decodeTask will unmarshal task and its payload out of the subscribed event. Payload is decoded by codec,
+// which is kept in the task.
+func decodeTask(event *sbe.SubscribedEvent, codec Codec) (*Task, error) {
+	var task Task
+	if err := unmarshalMessagePack(event.Event, &task); err != nil {
+		return nil, err
+	}
+	task.Codec = codec
+
+	if len(task.Payload) > 0 {
+		if err := task.UnmarshalPayload(&task.PayloadJson); err != nil {
+			return nil, err
+		}
+	}
+	return &task, nil
+}
+
+// SetPayloadObject will marshal v into payload of the workflow instance using Codec of the workflow instance.
+// With the default codec fields of v are named by msgpack struct tags.
+func (wf *WorkflowInstance) SetPayloadObject(v interface{}) error {
+	b, err := codecOrDefault(wf.Codec).Marshal(v)
+	if err != nil {
+		return err
+	}
+	wf.Payload = b
+	return nil
 }
 
+// Deployment is command deploying workflow definition. BPMN goes into BpmnXml, which every broker understands,
+// other resource types are sent in Resource with their ResourceType.
 type Deployment struct {
-	State   string `yaml:"state" msgpack:"state"`
-	BpmnXml []byte `yaml:"bpmnXml" msgpack:"bpmnXml"`
+	State        string `yaml:"state" msgpack:"state"`
+	BpmnXml      []byte `yaml:"bpmnXml" msgpack:"bpmnXml,omitempty"`
+	Resource     []byte `yaml:"resource" msgpack:"resource,omitempty"`
+	ResourceType string `yaml:"resourceType" msgpack:"resourceType,omitempty"`
 }
 
 func NewCompleteTaskMessage(taskMessage *Message) *Message {
 	payload := *taskMessage.Data
-	payload["state"] = "COMPLETE"
+	payload["state"] = TaskComplete
 	cmdReq := &sbe.ExecuteCommandRequest{
 		PartitionId: (*taskMessage.SbeMessage).(*sbe.SubscribedEvent).PartitionId,
 		Position:    (*taskMessage.SbeMessage).(*sbe.SubscribedEvent).Position,
@@ -41,11 +108,40 @@ func NewCompleteTaskMessage(taskMessage *Message) *Message {
 	return NewCommandRequestMessage(cmdReq, payload)
 }
 
+// newTaskEventMessage is constructor for Message which will execute command with given state on a task received through subscription.
+// Partition, key and lock owner are taken from the subscribed event. Changes will overwrite attributes of the task.
+func newTaskEventMessage(event *sbe.SubscribedEvent, state string, changes map[string]interface{}) *Message {
+	return newEventCommandMessage(event, sbe.EventType.TASK_EVENT, state, changes)
+}
+
+// newEventCommandMessage is constructor for Message which will execute command with given state on an event received through subscription.
+func newEventCommandMessage(event *sbe.SubscribedEvent, eventType sbe.EventTypeEnum, state string, changes map[string]interface{}) *Message {
+	var command map[string]interface{}
+	if err := unmarshalMessagePack(event.Event, &command); err != nil {
+		return nil
+	}
+	command["state"] = state
+
+	for k, v := range changes {
+		command[k] = v
+	}
+
+	cmdReq := &sbe.ExecuteCommandRequest{
+		PartitionId: event.PartitionId,
+		Position:    event.Position,
+		Key:         event.Key,
+		EventType:   eventType,
+		TopicName:   event.TopicName,
+	}
+
+	return NewCommandRequestMessage(cmdReq, command)
+}
+
 func NewTaskMessage(commandRequest *sbe.ExecuteCommandRequest, task *Task) *Message {
-	commandRequest.EventType = sbe.EventTypeEnum(0)
+	commandRequest.EventType = sbe.EventType.TASK_EVENT
 
 	if task.Payload == nil {
-		b, err := msgpack.Marshal(task.PayloadJson)
+		b, err := codecOrDefault(task.Codec).Marshal(task.PayloadJson)
 		if err != nil {
 			return nil
 		}
@@ -56,10 +152,10 @@ func NewTaskMessage(commandRequest *sbe.ExecuteCommandRequest, task *Task) *Mess
 }
 
 func NewWorkflowMessage(commandRequest *sbe.ExecuteCommandRequest, wf *WorkflowInstance) *Message {
-	commandRequest.EventType = sbe.EventTypeEnum(5)
+	commandRequest.EventType = sbe.EventType.WORKFLOW_INSTANCE_EVENT
 
 	if wf.Payload == nil {
-		b, err := msgpack.Marshal(wf.PayloadJson)
+		b, err := codecOrDefault(wf.Codec).Marshal(wf.PayloadJson)
 		if err != nil {
 			return nil
 		}
@@ -69,19 +165,36 @@ func NewWorkflowMessage(commandRequest *sbe.ExecuteCommandRequest, wf *WorkflowI
 	return NewCommandRequestMessage(commandRequest, wf)
 }
 
+// newWorkflowInstanceCommandMessage is constructor for Message which will execute command on workflow instance event with the given key.
+func newWorkflowInstanceCommandMessage(topic string, partitionID int32, key int64, command map[string]interface{}) *Message {
+	cmdReq := &sbe.ExecuteCommandRequest{
+		PartitionId: uint16(partitionID),
+		Position:    0,
+		Key:         uint64(key),
+		EventType:   sbe.EventType.WORKFLOW_INSTANCE_EVENT,
+		TopicName:   []uint8(topic),
+	}
+	return NewCommandRequestMessage(cmdReq, command)
+}
+
 func NewDeploymentMessage(commandRequest *sbe.ExecuteCommandRequest, d *Deployment) *Message {
-	commandRequest.EventType = sbe.EventTypeEnum(4)
+	commandRequest.EventType = sbe.EventType.DEPLOYMENT_EVENT
 	return NewCommandRequestMessage(commandRequest, d)
 }
 
 func NewCommandRequestMessage(commandRequest *sbe.ExecuteCommandRequest, command interface{}) *Message {
-	var msg Message
-
 	b, err := msgpack.Marshal(command)
 	if err != nil {
 		return nil
 	}
-	commandRequest.Command = b
+	return newRawCommandMessage(commandRequest, b)
+}
+
+// newRawCommandMessage is constructor for Message which will execute command already encoded as message pack.
+func newRawCommandMessage(commandRequest *sbe.ExecuteCommandRequest, command []byte) *Message {
+	var msg Message
+
+	commandRequest.Command = command
 	msg.SetSbeMessage(commandRequest)
 
 	// We add +2 to every variable length attribute since all variable length attributes will have 2 bytes in front
@@ -112,41 +225,121 @@ func NewCommandRequestMessage(commandRequest *sbe.ExecuteCommandRequest, command
 type TaskSubscription struct {
 	SubscriberKey uint64 `msgpack:"subscriberKey"`
 	TopicName     string `msgpack:"topicName"`
-	PartitionID   int32  `msgpack:"partitionId"`
+	PartitionID   int32  `msgpack:"partitionId"` // AllPartitions opens one subscription per partition of the topic.
 	TaskType      string `msgpack:"taskType"`
 	LockDuration  uint64 `msgpack:"lockDuration"`
 	LockOwner     string `msgpack:"lockOwner"`
 	Credits       int32  `msgpack:"credits"`
+
+	CreditsThreshold int32 `msgpack:"-"` // Credits are increased once less than this are left. Defaults to half of Credits.
+	CreditsBatch     int32 `msgpack:"-"` // Number of credits added at once. Defaults to as many as fit into the buffer.
+
+	BufferSize int32          `msgpack:"-"` // Events received but not yet taken by the consumer. Defaults to Credits.
+	Overflow   OverflowPolicy `msgpack:"-"` // Applied to events arriving when the buffer is full. Defaults to OverflowBlock.
+
+	Filters []EventFilter `msgpack:"-"` // Tasks not passing all filters are dropped, see EventFilter.
 }
 
-// NewTaskSubscriptionMessage is a constructor for Message object which will contain TaskSubscription as payload.
-func NewTaskSubscriptionMessage(ts *TaskSubscription) *Message {
-	var msg Message
+// TailPosition as StartPosition of TopicSubscription skips events written before the subscription is opened.
+const TailPosition = -1
 
-	b, err := msgpack.Marshal(ts)
-	if err != nil {
-		return nil
-	}
-	controlRequest := &sbe.ControlMessageRequest{
-		MessageType: sbe.ControlMessageType.ADD_TASK_SUBSCRIPTION,
-		Data:        b,
+// TopicSubscription is structure which we use to open a subscription on all events of the topic partition.
+type TopicSubscription struct {
+	TopicName        string `msgpack:"-"`
+	PartitionID      int32  `msgpack:"-"`
+	State            string `msgpack:"state"`
+	Name             string `msgpack:"name"`          // Broker remembers acknowledged position under this name.
+	StartPosition    int64  `msgpack:"startPosition"` // Used when subscription with the name is opened for the first time or ForceStart is set.
+	PrefetchCapacity int32  `msgpack:"prefetchCapacity"`
+	ForceStart       bool   `msgpack:"forceStart"`
+
+	BufferSize int32          `msgpack:"-"` // Events received but not yet taken by the consumer. Defaults to PrefetchCapacity.
+	Overflow   OverflowPolicy `msgpack:"-"` // Applied to events arriving when the buffer is full. Defaults to OverflowBlock.
+
+	// Checkpoints saves positions of acknowledged events. Subscription is opened after the saved position then,
+	// overriding StartPosition and the position kept by the broker. Nil leaves positions to the broker.
+	Checkpoints CheckpointStore `msgpack:"-"`
+
+	Filters []EventFilter `msgpack:"-"` // Events not passing all filters are dropped, see EventFilter.
+}
+
+// topicSubscriptionAck is command which will acknowledge position of the topic subscription.
+type topicSubscriptionAck struct {
+	State       string `msgpack:"state"`
+	Name        string `msgpack:"name"`
+	AckPosition uint64 `msgpack:"ackPosition"`
+}
+
+// topicSubscriptionRemove is control message which will close topic subscription.
+type topicSubscriptionRemove struct {
+	TopicName     string `msgpack:"topicName"`
+	PartitionID   int32  `msgpack:"partitionId"`
+	SubscriberKey uint64 `msgpack:"subscriberKey"`
+}
+
+// NewTopicSubscriptionMessage is a constructor for Message which will open topic subscription.
+func NewTopicSubscriptionMessage(ts *TopicSubscription) *Message {
+	ts.State = SubscriberSubscribe
+	cmdReq := &sbe.ExecuteCommandRequest{
+		PartitionId: uint16(ts.PartitionID),
+		Position:    0,
+		Key:         0,
+		EventType:   sbe.EventType.SUBSCRIBER_EVENT,
+		TopicName:   []uint8(ts.TopicName),
 	}
-	msg.SetSbeMessage(controlRequest)
+	return NewCommandRequestMessage(cmdReq, ts)
+}
 
-	length := 1 + uint32(2+len(controlRequest.Data)) + 18
+// NewTopicSubscriptionAckMessage is a constructor for Message which will acknowledge that events of topic subscription
+// up to the position are processed.
+func NewTopicSubscriptionAckMessage(ts *TopicSubscription, subscriberKey uint64, position uint64) *Message {
+	cmdReq := &sbe.ExecuteCommandRequest{
+		PartitionId: uint16(ts.PartitionID),
+		Position:    0,
+		Key:         subscriberKey,
+		EventType:   sbe.EventType.SUBSCRIPTION_EVENT,
+		TopicName:   []uint8(ts.TopicName),
+	}
+	return NewCommandRequestMessage(cmdReq, &topicSubscriptionAck{
+		State:       SubscriptionAcknowledge,
+		Name:        ts.Name,
+		AckPosition: position,
+	})
+}
 
-	var headers Headers
-	headers.SetSbeMessageHeader(&sbe.MessageHeader{
-		BlockLength: controlRequest.SbeBlockLength(),
-		TemplateId:  controlRequest.SbeTemplateId(),
-		SchemaId:    controlRequest.SbeSchemaId(),
-		Version:     controlRequest.SbeSchemaVersion(),
+// NewCloseTopicSubscriptionMessage is a constructor for Message which will remove topic subscription with given subscriber key.
+func NewCloseTopicSubscriptionMessage(ts *TopicSubscription, subscriberKey uint64) *Message {
+	return newControlMessage(sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION, &topicSubscriptionRemove{
+		TopicName:     ts.TopicName,
+		PartitionID:   ts.PartitionID,
+		SubscriberKey: subscriberKey,
 	})
+}
 
-	headers.SetRequestResponseHeader(protocol.NewRequestResponseHeader())
-	headers.SetTransportHeader(protocol.NewTransportHeader(protocol.RequestResponse))
-	headers.SetFrameHeader(protocol.NewFrameHeader(uint32(length), 0, 0, 0, 2))
+// NewTaskSubscriptionMessage is a constructor for Message object which will contain TaskSubscription as payload.
+func NewTaskSubscriptionMessage(ts *TaskSubscription) *Message {
+	return newControlMessage(sbe.ControlMessageType.ADD_TASK_SUBSCRIPTION, ts)
+}
 
-	msg.SetHeaders(&headers)
-	return &msg
+// NewCloseTaskSubscriptionMessage is a constructor for Message which will remove subscription with SubscriberKey.
+func NewCloseTaskSubscriptionMessage(ts *TaskSubscription) *Message {
+	return newControlMessage(sbe.ControlMessageType.REMOVE_TASK_SUBSCRIPTION, ts)
+}
+
+// NewIncreaseTaskSubscriptionCreditsMessage is a constructor for Message which will give subscription with SubscriberKey more credits.
+func NewIncreaseTaskSubscriptionCreditsMessage(ts *TaskSubscription) *Message {
+	return newControlMessage(sbe.ControlMessageType.INCREASE_TASK_SUBSCRIPTION_CREDITS, ts)
+}
+
+// NewTopologyRequestMessage is a constructor for Message which will request topology of the cluster.
+func NewTopologyRequestMessage() *Message {
+	return newControlMessage(sbe.ControlMessageType.REQUEST_TOPOLOGY, nil)
+}
+
+func newControlMessage(messageType sbe.ControlMessageTypeEnum, data interface{}) *Message {
+	msg, err := NewControlMessageRequest(messageType).Data(data).Build()
+	if err != nil {
+		return nil
+	}
+	return msg
 }
@@ -0,0 +1,114 @@
+package zbc
+
+import (
+	"errors"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errNotCommandResponse = errors.New("Message is not response to a command")
+
+// CreateTaskResponse is response to CreateTask, decoded by Message.CreateTaskResponse.
+type CreateTaskResponse struct {
+	TaskKey   uint64 `msgpack:"-"`
+	Partition uint16 `msgpack:"-"`
+	TopicName string `msgpack:"-"`
+
+	State   string                 `msgpack:"state"` // TaskCreated unless broker rejected the task.
+	Type    string                 `msgpack:"type"`
+	Retries int                    `msgpack:"retries"`
+	Headers map[string]interface{} `msgpack:"headers"`
+}
+
+// Created tells if the broker created the task.
+func (r *CreateTaskResponse) Created() bool {
+	return r.State == TaskCreated
+}
+
+// WorkflowInstanceResponse is response to CreateWorkflowInstance, decoded by Message.WorkflowInstanceResponse.
+type WorkflowInstanceResponse struct {
+	WorkflowInstanceKey uint64 `msgpack:"-"`
+	Partition           uint16 `msgpack:"-"`
+	TopicName           string `msgpack:"-"`
+
+	State         string `msgpack:"state"` // WorkflowInstanceCreated or WorkflowInstanceRejected.
+	BpmnProcessId string `msgpack:"bpmnProcessId"`
+	Version       int    `msgpack:"version"`
+	WorkflowKey   uint64 `msgpack:"workflowKey"`
+}
+
+// Created tells if the broker created the workflow instance.
+func (r *WorkflowInstanceResponse) Created() bool {
+	return r.State == WorkflowInstanceCreated
+}
+
+// DeployedWorkflow is one process of the deployment.
+type DeployedWorkflow struct {
+	BpmnProcessId string `msgpack:"bpmnProcessId"`
+	Version       int    `msgpack:"version"`
+}
+
+// DeploymentResponse is response to DeployWorkflow, decoded by Message.DeploymentResponse.
+type DeploymentResponse struct {
+	DeploymentKey uint64 `msgpack:"-"`
+	Partition     uint16 `msgpack:"-"`
+	TopicName     string `msgpack:"-"`
+
+	State             string             `msgpack:"state"` // DeploymentCreated or DeploymentRejected.
+	DeployedWorkflows []DeployedWorkflow `msgpack:"deployedWorkflows"`
+	ErrorMessage      string             `msgpack:"errorMessage"` // Reason of the rejection.
+}
+
+// Created tells if the broker deployed the workflows.
+func (r *DeploymentResponse) Created() bool {
+	return r.State == DeploymentCreated
+}
+
+// CreateTaskResponse will decode response to CreateTask. Error is returned for messages which are not command responses.
+func (m *Message) CreateTaskResponse() (*CreateTaskResponse, error) {
+	var r CreateTaskResponse
+	response, err := m.decodeCommandResponse(&r)
+	if err != nil {
+		return nil, err
+	}
+	r.TaskKey, r.Partition, r.TopicName = response.Key, response.PartitionId, string(response.TopicName)
+	return &r, nil
+}
+
+// WorkflowInstanceResponse will decode response to CreateWorkflowInstance. Error is returned for messages which are
+// not command responses.
+func (m *Message) WorkflowInstanceResponse() (*WorkflowInstanceResponse, error) {
+	var r WorkflowInstanceResponse
+	response, err := m.decodeCommandResponse(&r)
+	if err != nil {
+		return nil, err
+	}
+	r.WorkflowInstanceKey, r.Partition, r.TopicName = response.Key, response.PartitionId, string(response.TopicName)
+	return &r, nil
+}
+
+// DeploymentResponse will decode response to DeployWorkflow. Error is returned for messages which are not command responses.
+func (m *Message) DeploymentResponse() (*DeploymentResponse, error) {
+	var r DeploymentResponse
+	response, err := m.decodeCommandResponse(&r)
+	if err != nil {
+		return nil, err
+	}
+	r.DeploymentKey, r.Partition, r.TopicName = response.Key, response.PartitionId, string(response.TopicName)
+	return &r, nil
+}
+
+// decodeCommandResponse will unmarshal event of the command response into v.
+func (m *Message) decodeCommandResponse(v interface{}) (*sbe.ExecuteCommandResponse, error) {
+	if m.SbeMessage == nil {
+		return nil, errNotCommandResponse
+	}
+	response, ok := (*m.SbeMessage).(*sbe.ExecuteCommandResponse)
+	if !ok {
+		return nil, errNotCommandResponse
+	}
+	if err := unmarshalMessagePack(response.Event, v); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,63 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestMessage_CommandResponses(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		task.State = zbc.TaskCreated
+		return zbtest.CommandResponse(request, 42, &task)
+	})
+	broker.HandleCommand(sbe.EventType.DEPLOYMENT_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 7, map[string]interface{}{
+			"state": zbc.DeploymentCreated,
+			"deployedWorkflows": []map[string]interface{}{
+				{"bpmnProcessId": "order-process", "version": 2},
+			},
+		})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	message, err := client.CreateTask("default-topic", &zbc.Task{Type: "foo", Retries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := message.CreateTaskResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Created() || task.TaskKey != 42 || task.Type != "foo" || task.Retries != 3 || task.TopicName != "default-topic" {
+		t.Fatalf("Expected task 42 of type foo created, received %+v", task)
+	}
+
+	message, err = client.DeployWorkflow("default-topic", []byte("<definitions/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployment, err := message.DeploymentResponse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !deployment.Created() || deployment.DeploymentKey != 7 || len(deployment.DeployedWorkflows) != 1 ||
+		deployment.DeployedWorkflows[0] != (zbc.DeployedWorkflow{BpmnProcessId: "order-process", Version: 2}) {
+		t.Fatalf("Expected deployment 7 of order-process version 2, received %+v", deployment)
+	}
+}
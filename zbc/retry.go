@@ -0,0 +1,144 @@
+package zbc
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// RequestKeyHeader is the key under which request key given by RequestKeyOption is stored in task headers
+// and workflow instance payload.
+const RequestKeyHeader = "zbcRequestKey"
+
+// RetryPolicy describes how Client retries requests which failed. Requests which cannot change state of the broker,
+// like topology requests and opening of subscriptions, are retried automatically. Commands are retried only if
+// RetryCommands is set:
+//
+// Commands on existing events, like CompleteTask, carry key of the event. If the first attempt succeeded but its response
+// was lost, broker rejects the repeated one, e.g. with COMPLETE_REJECTED.
+//
+// Commands creating new tasks, workflow instances or deployments would be executed twice in that case. They are retried
+// only if they carry request key given by RequestKeyOption or ContextWithRequestKey. Key is stored under RequestKeyHeader
+// in headers of the task or payload of the workflow instance, so consumers can detect duplicates. Deployments don't keep
+// the key, repeated deployment creates new version of the workflow.
+type RetryPolicy struct {
+	InitialBackoff time.Duration        // Time to wait before the second attempt.
+	MaxBackoff     time.Duration        // Upper bound for time between two attempts.
+	Multiplier     float64              // Factor by which backoff grows after every failed attempt.
+	Jitter         float64              // Randomization factor in range [0, 1] applied to every backoff.
+	MaxAttempts    int                  // Number of attempts including the first one.
+	Retryable      func(err error) bool // Decides which errors are retried. IsRetryable is used if nil.
+	RetryCommands  bool                 // Retry commands too, see above.
+}
+
+// DefaultRetryPolicy is a sensible RetryPolicy, requests are not retried unless it or another policy is set by SetRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	MaxAttempts:    3,
+}
+
+// Backoff returns time to wait before given attempt. Attempts are counted from 1, so the second attempt is the first retry.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	return exponentialBackoff(p.InitialBackoff, p.MaxBackoff, p.Multiplier, p.Jitter, attempt-1)
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// IsRetryable will decide if request which failed with err may succeed when sent again. Broken connections and requests
+// which broker failed to write or which timed out on the broker are retryable, rejections are not.
+func IsRetryable(err error) bool {
+	switch Cause(err) {
+	case ErrRequestWriteFailure, ErrRequestTimeout, errSocketWrite:
+		return true
+	}
+	return isConnectionError(err)
+}
+
+// SetRetryPolicy is a setter for RetryPolicy. Nil disables retries, which is the default.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.mu.Lock()
+	c.retryPolicy = policy
+	c.mu.Unlock()
+}
+
+func (c *Client) getRetryPolicy() *RetryPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.retryPolicy
+}
+
+// retry will call op until it succeeds, fails with an error which is not retryable, RetryPolicy gives up or ctx is done.
+// Error of the last attempt is returned. Commands are retried only if the policy allows it.
+func (c *Client) retry(ctx context.Context, command bool, op func() error) error {
+	policy := c.getRetryPolicy()
+	if policy == nil || (command && !policy.RetryCommands) {
+		return op()
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return err
+		}
+		c.log().Debug("Request failed, retrying", F("attempt", attempt), F("error", err))
+
+		select {
+		case <-time.After(policy.Backoff(attempt + 1)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+type requestKeyKey struct{}
+
+// RequestKeyOption will attach request key to one command creating a task or a workflow instance, so it is retried
+// by RetryPolicy with RetryCommands. Key must be unique for every task or workflow instance the application creates.
+// Command sent again with the same key gets response of the first one, see SetDeduplicationWindow.
+func RequestKeyOption(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.requestKey = key
+	}
+}
+
+// ContextWithRequestKey returns copy of ctx which attaches request key to the command sent with it. It's meant for the Ctx
+// variants of the commands, others take RequestKeyOption.
+func ContextWithRequestKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, requestKeyKey{}, key)
+}
+
+// requestKey returns request key attached to ctx, empty if there is none.
+func requestKey(ctx context.Context) string {
+	key, _ := ctx.Value(requestKeyKey{}).(string)
+	return key
+}
+
+// withRequestKey returns copy of values with request key of ctx under RequestKeyHeader. Values are returned unchanged
+// if ctx has no request key.
+func withRequestKey(ctx context.Context, values map[string]interface{}) map[string]interface{} {
+	key := requestKey(ctx)
+	if len(key) == 0 {
+		return values
+	}
+
+	copied := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		copied[k] = v
+	}
+	copied[RequestKeyHeader] = key
+	return copied
+}
+
+// isRepeatable will decide if the command can be sent again without creating a duplicate, see RetryPolicy.
+func isRepeatable(ctx context.Context, cmdReq *sbe.ExecuteCommandRequest) bool {
+	return cmdReq.Key != 0 || len(requestKey(ctx)) > 0
+}
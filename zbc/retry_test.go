@@ -0,0 +1,62 @@
+package zbc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SetRetryPolicy(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Every second request fails, so each request succeeds once it's retried.
+	var attempts int32
+	created := make(chan *zbc.Task, 10)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		if atomic.AddInt32(&attempts, 1)%2 == 1 {
+			return zbtest.ErrorResponse(sbe.ErrorCode.REQUEST_WRITE_FAILURE, "log is full")
+		}
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		created <- &task
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "CREATED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	policy := zbc.DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+	client.SetRetryPolicy(&policy)
+
+	if _, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo"}, zbc.RequestKeyOption("order-1")); zbc.Cause(err) != zbc.ErrRequestWriteFailure {
+		t.Fatalf("Expected command not retried without RetryCommands, received %v", err)
+	}
+
+	policy.RetryCommands = true
+	client.SetRetryPolicy(&policy)
+	atomic.StoreInt32(&attempts, 0)
+	if _, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo"}); zbc.Cause(err) != zbc.ErrRequestWriteFailure {
+		t.Fatalf("Expected command not retried without request key, received %v", err)
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	if _, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo"}, zbc.RequestKeyOption("order-1")); err != nil {
+		t.Fatal(err)
+	}
+	if task := <-created; task.Headers[zbc.RequestKeyHeader] != "order-1" {
+		t.Fatalf("Expected request key in headers, received %v", task.Headers)
+	}
+}
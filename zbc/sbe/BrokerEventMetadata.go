@@ -163,7 +163,7 @@ func (b BrokerEventMetadata) SbeSchemaId() (schemaId uint16) {
 }
 
 func (b BrokerEventMetadata) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (b BrokerEventMetadata) SbeSemanticType() (semanticType []byte) {
@@ -88,7 +88,7 @@ func (c ControlMessageRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (c ControlMessageRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (c ControlMessageRequest) SbeSemanticType() (semanticType []byte) {
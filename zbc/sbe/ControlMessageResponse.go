@@ -71,7 +71,7 @@ func (c ControlMessageResponse) SbeSchemaId() (schemaId uint16) {
 }
 
 func (c ControlMessageResponse) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (c ControlMessageResponse) SbeSemanticType() (semanticType []byte) {
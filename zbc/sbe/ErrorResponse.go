@@ -109,7 +109,7 @@ func (e ErrorResponse) SbeSchemaId() (schemaId uint16) {
 }
 
 func (e ErrorResponse) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (e ErrorResponse) SbeSemanticType() (semanticType []byte) {
@@ -20,10 +20,11 @@ type EventTypeValues struct {
 	INCIDENT_EVENT          EventTypeEnum
 	WORKFLOW_EVENT          EventTypeEnum
 	NOOP_EVENT              EventTypeEnum
+	TOPIC_EVENT             EventTypeEnum
 	NullValue               EventTypeEnum
 }
 
-var EventType = EventTypeValues{0, 1, 2, 3, 4, 5, 6, 7,  8,255}
+var EventType = EventTypeValues{0, 1, 2, 3, 4, 5, 6, 7,  8, 9,255}
 
 func (e EventTypeEnum) Encode(writer io.Writer, order binary.ByteOrder) error {
 	if err := binary.Write(writer, order, e); err != nil {
@@ -139,3 +140,15 @@ func (e EventTypeEnum) INCIDENT_EVENTInActingVersion(actingVersion uint16) bool
 func (e EventTypeEnum) INCIDENT_EVENTDeprecated() uint16 {
 	return 0
 }
+
+func (e EventTypeEnum) TOPIC_EVENTSinceVersion() uint16 {
+	return 0
+}
+
+func (e EventTypeEnum) TOPIC_EVENTInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= e.TOPIC_EVENTSinceVersion()
+}
+
+func (e EventTypeEnum) TOPIC_EVENTDeprecated() uint16 {
+	return 0
+}
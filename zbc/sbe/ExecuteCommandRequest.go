@@ -62,6 +62,13 @@ func (e *ExecuteCommandRequest) Decode(reader io.Reader, order binary.ByteOrder,
 			return err
 		}
 	}
+	if !e.PositionInActingVersion(actingVersion) {
+		e.Position = e.PositionNullValue()
+	} else {
+		if err := binary.Read(reader, order, &e.Position); err != nil {
+			return err
+		}
+	}
 	if !e.KeyInActingVersion(actingVersion) {
 		e.Key = e.KeyNullValue()
 	} else {
@@ -152,7 +159,7 @@ func (e ExecuteCommandRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (e ExecuteCommandRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (e ExecuteCommandRequest) SbeSemanticType() (semanticType []byte) {
@@ -220,7 +227,7 @@ func (e ExecuteCommandRequest) KeySinceVersion() uint16 {
 }
 
 func (e ExecuteCommandRequest) PositionSinceVersion() uint16 {
-	return 0
+	return 2
 }
 
 func (e ExecuteCommandRequest) KeyInActingVersion(actingVersion uint16) bool {
@@ -151,7 +151,7 @@ func (e ExecuteCommandResponse) SbeSchemaId() (schemaId uint16) {
 }
 
 func (e ExecuteCommandResponse) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (e ExecuteCommandResponse) SbeSemanticType() (semanticType []byte) {
@@ -219,7 +219,7 @@ func (e ExecuteCommandResponse) KeySinceVersion() uint16 {
 }
 
 func (e ExecuteCommandResponse) PositionSinceVersion() uint16 {
-	return 0
+	return 2
 }
 
 func (e ExecuteCommandResponse) KeyInActingVersion(actingVersion uint16) bool {
@@ -185,7 +185,7 @@ func (s SubscribedEvent) SbeSchemaId() (schemaId uint16) {
 }
 
 func (s SubscribedEvent) SbeSchemaVersion() (schemaVersion uint16) {
-	return 1
+	return 2
 }
 
 func (s SubscribedEvent) SbeSemanticType() (semanticType []byte) {
@@ -0,0 +1,57 @@
+package sbe
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Methods of ExecuteCommandRequest and ExecuteCommandResponse written by hand, so they survive regeneration. Generated
+// encoders write the block of the current schema version only.
+
+// SbeBlockLengthVersion1 returns length of the block in schema version 1.
+func (e ExecuteCommandRequest) SbeBlockLengthVersion1() uint16 {
+	return 11
+}
+
+// EncodeVersion1 will encode the request in layout of schema version 1, without position.
+func (e ExecuteCommandRequest) EncodeVersion1(writer io.Writer, order binary.ByteOrder) error {
+	if err := binary.Write(writer, order, e.PartitionId); err != nil {
+		return err
+	}
+	if err := binary.Write(writer, order, e.Key); err != nil {
+		return err
+	}
+	if err := e.EventType.Encode(writer, order); err != nil {
+		return err
+	}
+	if err := writeVarData(writer, order, e.TopicName); err != nil {
+		return err
+	}
+	return writeVarData(writer, order, e.Command)
+}
+
+// SbeBlockLengthVersion1 returns length of the block in schema version 1.
+func (e ExecuteCommandResponse) SbeBlockLengthVersion1() uint16 {
+	return 10
+}
+
+// EncodeVersion1 will encode the response in layout of schema version 1, without position.
+func (e ExecuteCommandResponse) EncodeVersion1(writer io.Writer, order binary.ByteOrder) error {
+	if err := binary.Write(writer, order, e.PartitionId); err != nil {
+		return err
+	}
+	if err := binary.Write(writer, order, e.Key); err != nil {
+		return err
+	}
+	if err := writeVarData(writer, order, e.TopicName); err != nil {
+		return err
+	}
+	return writeVarData(writer, order, e.Event)
+}
+
+func writeVarData(writer io.Writer, order binary.ByteOrder, data []uint8) error {
+	if err := binary.Write(writer, order, uint16(len(data))); err != nil {
+		return err
+	}
+	return binary.Write(writer, order, data)
+}
@@ -0,0 +1,10 @@
+// Package sbe contains encoders and decoders of the Zeebe client protocol. They are generated from protocol.xml by
+// the simple-binary-encoding tool. To regenerate them, point SBE_JAR to sbe-all jar and run go generate.
+//
+// Some generated files were patched by hand, check git diff after regenerating.
+// Methods written by hand live in files with lower case names, like message_header.go and execute_command.go,
+// which the generator doesn't touch. Changes of the protocol go to protocol.xml, e.g. fields added in a later
+// version are declared with sinceVersion.
+package sbe
+
+//go:generate sh -c "java -Dsbe.target.language=golang -Dsbe.output.dir=.. -Dsbe.generate.ir=false -jar ${SBE_JAR:?SBE_JAR must point to sbe-all jar} protocol.xml"
@@ -0,0 +1,45 @@
+package sbe
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Methods of MessageHeader written by hand, so they survive regeneration.
+
+// String returns the header in a form suitable for logs, e.g.
+// MessageHeader{blockLength: 13, template: ExecuteCommandRequest, schema: 0, version: 1}.
+func (m MessageHeader) String() string {
+	return fmt.Sprintf("MessageHeader{blockLength: %d, template: %s, schema: %d, version: %d}",
+		m.BlockLength, templateName(m.TemplateId), m.SchemaId, m.Version)
+}
+
+// MarshalJSON encodes the header with lower camel case keys, same as headers of the protocol package.
+func (m MessageHeader) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		BlockLength uint16 `json:"blockLength"`
+		TemplateID  uint16 `json:"templateId"`
+		SchemaID    uint16 `json:"schemaId"`
+		Version     uint16 `json:"version"`
+	}{m.BlockLength, m.TemplateId, m.SchemaId, m.Version})
+}
+
+func templateName(templateID uint16) string {
+	switch templateID {
+	case ErrorResponse{}.SbeTemplateId():
+		return "ErrorResponse"
+	case ControlMessageRequest{}.SbeTemplateId():
+		return "ControlMessageRequest"
+	case ControlMessageResponse{}.SbeTemplateId():
+		return "ControlMessageResponse"
+	case ExecuteCommandRequest{}.SbeTemplateId():
+		return "ExecuteCommandRequest"
+	case ExecuteCommandResponse{}.SbeTemplateId():
+		return "ExecuteCommandResponse"
+	case SubscribedEvent{}.SbeTemplateId():
+		return "SubscribedEvent"
+	case BrokerEventMetadata{}.SbeTemplateId():
+		return "BrokerEventMetadata"
+	}
+	return fmt.Sprintf("unknown(%d)", templateID)
+}
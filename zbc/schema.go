@@ -0,0 +1,122 @@
+package zbc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// templateDecoder will decode body of SBE message described by the header.
+type templateDecoder func(reader io.Reader, header *sbe.MessageHeader) (SBE, error)
+
+// generatedDecoder returns templateDecoder of message generated from the schema. Generated decoders read the acting
+// version of the header, fields added in later versions are left empty and block of newer versions is skipped.
+func generatedDecoder(newMessage func() SBE, rangeCheck bool) templateDecoder {
+	return func(reader io.Reader, header *sbe.MessageHeader) (SBE, error) {
+		message := newMessage()
+		if err := message.Decode(reader, protocol.ByteOrder, header.Version, header.BlockLength, rangeCheck); err != nil {
+			return nil, err
+		}
+		return message, nil
+	}
+}
+
+// generatedDecoders are decoders of messages generated from the schema, by template ID. They read fields which the
+// acting version has, so they decode every version since the schema was generated.
+var generatedDecoders = map[uint16]templateDecoder{
+	templateIDErrorResponse: generatedDecoder(func() SBE { return &sbe.ErrorResponse{} }, true),
+	// Requests are decoded for testing purposes and by proxies.
+	templateIDExecuteCommandRequest:  generatedDecoder(func() SBE { return &sbe.ExecuteCommandRequest{} }, true),
+	templateIDExecuteCommandResponse: generatedDecoder(func() SBE { return &sbe.ExecuteCommandResponse{} }, true),
+	// Data is message pack, range check would reject it as invalid UTF-8.
+	templateIDControlMessageRequest:  generatedDecoder(func() SBE { return &sbe.ControlMessageRequest{} }, false),
+	templateIDControlMessageResponse: generatedDecoder(func() SBE { return &sbe.ControlMessageResponse{} }, true),
+	templateIDSubscriptionEvent:      generatedDecoder(func() SBE { return &sbe.SubscribedEvent{} }, true),
+}
+
+// schemaDecoders are decoders of every version of the SBE schema which the client understands, by version and
+// template ID. Message is decoded by the version in its own header, so brokers speaking different versions can be
+// talked to at once. Version 1 has no position in command requests and responses, it's left as null value. Version
+// changing messages incompatibly gets decoders of its own, generated into a package of that version, while decoders
+// of the previous version are kept.
+var schemaDecoders = map[uint16]map[uint16]templateDecoder{
+	1: generatedDecoders,
+	2: generatedDecoders,
+}
+
+// encodeBody will encode the message in layout of the schema version in its header, so decoded messages are written
+// again as they were received, e.g. by proxies. Messages built by the client are encoded with SchemaVersion.
+func encodeBody(writer io.Writer, message SBE, header *sbe.MessageHeader) error {
+	if header != nil && header.Version == 1 {
+		// Version 1 has no position in command requests and responses.
+		switch m := message.(type) {
+		case *sbe.ExecuteCommandRequest:
+			return m.EncodeVersion1(writer, protocol.ByteOrder)
+		case *sbe.ExecuteCommandResponse:
+			return m.EncodeVersion1(writer, protocol.ByteOrder)
+		}
+	}
+	return message.Encode(writer, protocol.ByteOrder, false)
+}
+
+// SupportedSchemaVersions returns versions of the SBE schema which the client decodes, in ascending order. Requests
+// are always encoded with SchemaVersion.
+func SupportedSchemaVersions() []uint16 {
+	versions := make([]uint16, 0, len(schemaDecoders))
+	for version := range schemaDecoders {
+		versions = append(versions, version)
+	}
+	sort.Sort(uint16s(versions))
+	return versions
+}
+
+type uint16s []uint16
+
+func (s uint16s) Len() int           { return len(s) }
+func (s uint16s) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint16s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// UnsupportedSchemaError is returned for message of SBE schema or its version which the client can't decode, e.g.
+// response of a newer broker. Cause returns ErrUnsupportedSchemaVersion for it.
+type UnsupportedSchemaError struct {
+	SchemaID uint16
+	Version  uint16
+}
+
+func (e *UnsupportedSchemaError) Error() string {
+	versions := make([]string, 0, len(schemaDecoders))
+	for _, version := range SupportedSchemaVersions() {
+		versions = append(versions, fmt.Sprint(version))
+	}
+	return fmt.Sprintf("%s: message of schema %d version %d, client supports schema %d versions %s",
+		ErrUnsupportedSchemaVersion, e.SchemaID, e.Version, SchemaID, strings.Join(versions, ", "))
+}
+
+// checkSchema returns UnsupportedSchemaError unless the client decodes messages with the header.
+func checkSchema(header *sbe.MessageHeader) error {
+	if header.SchemaId != SchemaID || schemaDecoders[header.Version] == nil {
+		return &UnsupportedSchemaError{SchemaID: header.SchemaId, Version: header.Version}
+	}
+	return nil
+}
+
+// messagePack returns message pack document carried by the SBE message. Error response carries plain text only.
+func messagePack(message SBE) ([]byte, bool) {
+	switch m := message.(type) {
+	case *sbe.ExecuteCommandRequest:
+		return m.Command, true
+	case *sbe.ExecuteCommandResponse:
+		return m.Event, true
+	case *sbe.ControlMessageRequest:
+		return m.Data, true
+	case *sbe.ControlMessageResponse:
+		return m.Data, true
+	case *sbe.SubscribedEvent:
+		return m.Event, true
+	}
+	return nil, false
+}
@@ -0,0 +1,144 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClientClosed is returned for requests sent once Client is closed.
+var ErrClientClosed = errors.New("Client is closed")
+
+// pendingPollInterval is how often Close checks whether pending requests got their responses.
+const pendingPollInterval = 10 * time.Millisecond
+
+// Close will shut the client down gracefully. Started workers are stopped first and their running handlers are waited for.
+// Then subscriptions are closed on the broker, no new requests are accepted and pending requests are waited for.
+// At last sockets of all connections in the pool are closed and goroutines reading them, sending heartbeats or
+// reconnecting are waited for. Once ctx is done, steps left are not waited for and ctx.Err() is returned, sockets
+// are closed in any case.
+func (c *Client) Close(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&c.shutdown, 0, 1) {
+		return ErrClientClosed
+	}
+
+	var firstErr error
+	record := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	c.mu.Lock()
+	workers := make([]stopper, len(c.workers))
+	copy(workers, c.workers)
+	c.mu.Unlock()
+	for _, w := range workers {
+		record(w.StopCtx(ctx))
+	}
+
+	clients := []*Client{c}
+	if c.pool != nil {
+		clients = c.pool.Clients()
+	}
+	for _, client := range clients {
+		record(client.closeSubscriptions(ctx))
+	}
+	for _, client := range clients {
+		client.txMu.Lock()
+		atomic.StoreInt32(&client.closing, 1)
+		client.txMu.Unlock()
+	}
+	for _, client := range clients {
+		record(client.awaitPending(ctx))
+	}
+
+	if c.pool != nil {
+		record(c.pool.close(ctx))
+	}
+	record(c.closeConnection(ctx))
+
+	c.log().Info("Client closed", F("addr", c.addr))
+	return firstErr
+}
+
+func (c *Client) closeSubscriptions(ctx context.Context) error {
+	c.mu.Lock()
+	subs := make([]*Subscription, len(c.openedSubscriptions))
+	copy(subs, c.openedSubscriptions)
+	c.mu.Unlock()
+
+	var firstErr error
+	for _, sub := range subs {
+		if err := sub.CloseCtx(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// awaitPending will wait until all requests sent before closing got their response or gave up waiting.
+func (c *Client) awaitPending(ctx context.Context) error {
+	ticker := time.NewTicker(pendingPollInterval)
+	defer ticker.Stop()
+
+	for {
+		c.txMu.Lock()
+		pending := len(c.transactions)
+		c.txMu.Unlock()
+		if pending == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// closeConnection will end lifetime of the client, close the socket and wait for goroutines started by spawn,
+// i.e. receiver, heartbeat, reconnect and everything started after it.
+func (c *Client) closeConnection(ctx context.Context) error {
+	atomic.StoreInt32(&c.closing, 1)
+	c.lifetimeMu.Lock()
+	c.stop()
+	c.lifetimeMu.Unlock()
+	c.connection().Close()
+
+	stopped := make(chan struct{})
+	go func() {
+		c.goroutines.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stopper is Worker or TopicWorker, which Close stops before subscriptions are closed.
+type stopper interface {
+	StopCtx(ctx context.Context) error
+}
+
+func (c *Client) addWorker(w stopper) {
+	c.mu.Lock()
+	c.workers = append(c.workers, w)
+	c.mu.Unlock()
+}
+
+func (c *Client) removeWorker(w stopper) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, worker := range c.workers {
+		if worker == w {
+			c.workers = append(c.workers[:i], c.workers[i+1:]...)
+			return
+		}
+	}
+}
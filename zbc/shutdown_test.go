@@ -0,0 +1,127 @@
+package zbc_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_CloseDrainsWorkers(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	completed := make(chan struct{}, 1)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		completed <- struct{}{}
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "COMPLETED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return nil, nil
+	})
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := broker.PushTask(1, &zbc.Task{State: "LOCKED", Type: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-completed:
+	default:
+		t.Fatal("Task handled while closing was not completed")
+	}
+
+	if _, err := client.Topology(); err != zbc.ErrClientClosed {
+		t.Fatalf("Expected ErrClientClosed, received %v", err)
+	}
+	if err := client.Close(ctx); err != zbc.ErrClientClosed {
+		t.Fatalf("Expected ErrClientClosed on second Close, received %v", err)
+	}
+}
+
+// logWatch is written to by a logger, seen is closed once message is logged.
+type logWatch struct {
+	message string
+	seen    chan struct{}
+	once    sync.Once
+}
+
+func (w *logWatch) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte(w.message)) {
+		w.once.Do(func() { close(w.seen) })
+	}
+	return len(p), nil
+}
+
+func TestClient_CloseStopsGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.HandleCommand(sbe.EventType.SUBSCRIPTION_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.SubscriberSubscribed})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := &logWatch{message: "Connection broken", seen: make(chan struct{})}
+	client.SetLogger(zbc.NewStdLogger(log.New(broken, "", 0), false))
+	sub, err := client.OpenTopicSubscription(&zbc.TopicSubscription{TopicName: "default-topic", Name: "closing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Receiver is left waiting for the next reconnect attempt.
+	client.SetReconnectPolicy(&zbc.ReconnectPolicy{InitialBackoff: time.Hour, MaxBackoff: time.Hour, Multiplier: 1})
+	broker.Close()
+	<-broken.seen
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	client.Close(ctx)
+	if ctx.Err() != nil {
+		t.Fatal("Expected Close to return without waiting for reconnect")
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("Expected channel of the subscription to be closed")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d goroutines after Close, running %d", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,222 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// snapshotSubscriptionName is name of the topic subscription replaying the topic for ListWorkflowInstances and ListTasks.
+const snapshotSubscriptionName = "zbc-snapshot"
+
+// WorkflowInstanceSnapshot is state of a running workflow instance reconstructed from its events.
+type WorkflowInstanceSnapshot struct {
+	Key           uint64 `json:"key"`
+	PartitionID   uint16 `json:"partitionId"`
+	State         string `json:"state"` // State of the last event of the instance, e.g. ACTIVITY_ACTIVATED.
+	BpmnProcessId string `json:"bpmnProcessId"`
+	Version       int    `json:"version"`
+	WorkflowKey   uint64 `json:"workflowKey"`
+	ActivityID    string `json:"activityId"` // Activity which was activated last.
+	Position      uint64 `json:"position"`   // Position of the last event of the instance.
+}
+
+// TaskSnapshot is state of a task which is not completed yet, reconstructed from its events.
+type TaskSnapshot struct {
+	Key                 uint64            `json:"key"`
+	PartitionID         uint16            `json:"partitionId"`
+	Type                string            `json:"type"`
+	State               string            `json:"state"`
+	Retries             int               `json:"retries"`
+	LockOwner           string            `json:"lockOwner,omitempty"`
+	LockTime            int64             `json:"lockTime,omitempty"` // Milliseconds since epoch until which the task is locked.
+	WorkflowInstanceKey uint64            `json:"workflowInstanceKey,omitempty"`
+	ActivityID          string            `json:"activityId,omitempty"`
+	CustomHeaders       map[string]string `json:"customHeaders,omitempty"`
+	Position            uint64            `json:"position"` // Position of the last event of the task.
+}
+
+// workflowInstanceEvent holds attributes of workflow instance events, activities of the instance included.
+type workflowInstanceEvent struct {
+	State               string `msgpack:"state"`
+	BpmnProcessId       string `msgpack:"bpmnProcessId"`
+	Version             int    `msgpack:"version"`
+	WorkflowKey         uint64 `msgpack:"workflowKey"`
+	WorkflowInstanceKey uint64 `msgpack:"workflowInstanceKey"`
+	ActivityID          string `msgpack:"activityId"`
+}
+
+func isWorkflowInstanceEvent(event *sbe.SubscribedEvent) bool {
+	return event.EventType == sbe.EventType.WORKFLOW_INSTANCE_EVENT
+}
+
+func isTaskEvent(event *sbe.SubscribedEvent) bool {
+	return event.EventType == sbe.EventType.TASK_EVENT
+}
+
+// ListWorkflowInstances will return workflow instances of the topic which are neither completed nor canceled, in
+// order of creation within every partition. Broker has no query for them, so the topic is replayed same as by
+// TopicStats. If request times out during the replay, instances of the partitions replayed so far are returned
+// together with the error.
+func (c *Client) ListWorkflowInstances(topic string, opts ...RequestOption) ([]*WorkflowInstanceSnapshot, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.ListWorkflowInstancesCtx(ctx, topic)
+}
+
+// ListWorkflowInstancesCtx is same as ListWorkflowInstances, but replay is aborted once ctx is done.
+func (c *Client) ListWorkflowInstancesCtx(ctx context.Context, topic string) ([]*WorkflowInstanceSnapshot, error) {
+	var instances []*WorkflowInstanceSnapshot
+	for _, partitionID := range c.topicPartitions(ctx, topic) {
+		var keys []uint64
+		running := make(map[uint64]*WorkflowInstanceSnapshot)
+
+		err := c.replayTopic(ctx, topic, int32(partitionID), snapshotSubscriptionName, isWorkflowInstanceEvent, func(message *Message) error {
+			event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+			var wf workflowInstanceEvent
+			if err := unmarshalMessagePack(event.Event, &wf); err != nil {
+				return err
+			}
+			key := wf.WorkflowInstanceKey
+			if key == 0 {
+				key = event.Key
+			}
+
+			switch wf.State {
+			case WorkflowInstanceCreated:
+				keys = append(keys, key)
+				running[key] = &WorkflowInstanceSnapshot{
+					Key:           key,
+					PartitionID:   partitionID,
+					BpmnProcessId: wf.BpmnProcessId,
+					Version:       wf.Version,
+					WorkflowKey:   wf.WorkflowKey,
+				}
+			case WorkflowInstanceCompleted, WorkflowInstanceCanceled:
+				delete(running, key)
+				return nil
+			}
+
+			instance, ok := running[key]
+			if !ok {
+				// Commands and rejections of instances which were never created.
+				return nil
+			}
+			instance.State, instance.Position = wf.State, event.Position
+			if wf.State == WorkflowInstanceActivityActivated {
+				instance.ActivityID = wf.ActivityID
+			}
+			return nil
+		})
+		if err != nil {
+			return instances, err
+		}
+
+		for _, key := range keys {
+			if instance, ok := running[key]; ok {
+				instances = append(instances, instance)
+			}
+		}
+	}
+	return instances, nil
+}
+
+// ListTasks will return tasks of the topic which are neither completed nor canceled, in order of creation within
+// every partition. Tasks are reconstructed by replaying the topic same as ListWorkflowInstances does.
+func (c *Client) ListTasks(topic string, opts ...RequestOption) ([]*TaskSnapshot, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.ListTasksCtx(ctx, topic)
+}
+
+// ListTasksCtx is same as ListTasks, but replay is aborted once ctx is done.
+func (c *Client) ListTasksCtx(ctx context.Context, topic string) ([]*TaskSnapshot, error) {
+	var tasks []*TaskSnapshot
+	for _, partitionID := range c.topicPartitions(ctx, topic) {
+		var keys []uint64
+		open := make(map[uint64]*TaskSnapshot)
+
+		err := c.replayTopic(ctx, topic, int32(partitionID), snapshotSubscriptionName, isTaskEvent, func(message *Message) error {
+			event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+			var te TaskEvent
+			if err := unmarshalMessagePack(event.Event, &te); err != nil {
+				return err
+			}
+			if !taskEventStates[te.State] {
+				return nil
+			}
+
+			switch te.State {
+			case TaskCreated:
+				keys = append(keys, event.Key)
+				open[event.Key] = &TaskSnapshot{Key: event.Key, PartitionID: partitionID}
+			case TaskCompleted, TaskCanceled:
+				delete(open, event.Key)
+				return nil
+			}
+
+			task, ok := open[event.Key]
+			if !ok {
+				return nil
+			}
+			task.State, task.Retries, task.Position = te.State, te.Retries, event.Position
+			task.LockOwner, task.LockTime = te.LockOwner, te.LockTime
+			if len(te.Type) > 0 {
+				task.Type = te.Type
+			}
+			task.WorkflowInstanceKey, _ = toUint64(te.Headers["workflowInstanceKey"])
+			task.ActivityID, _ = te.Headers["activityId"].(string)
+			if len(te.CustomHeaders) > 0 {
+				task.CustomHeaders = make(map[string]string, len(te.CustomHeaders))
+				for name, value := range te.CustomHeaders {
+					if s, ok := value.(string); ok {
+						task.CustomHeaders[name] = s
+					}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return tasks, err
+		}
+
+		for _, key := range keys {
+			if task, ok := open[key]; ok {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// topicPartitions returns partitions of the topic known from topology, or partition 0 if topology doesn't know the topic.
+func (c *Client) topicPartitions(ctx context.Context, topic string) []uint16 {
+	partitions := c.cachedTopology(ctx).Partitions(topic)
+	if len(partitions) == 0 {
+		partitions = []uint16{0}
+	}
+	return partitions
+}
+
+// toUint64 converts key decoded from message pack, which is signed or unsigned integer, to uint64.
+func toUint64(v interface{}) (uint64, bool) {
+	switch n := v.(type) {
+	case uint64:
+		return n, true
+	case int64:
+		return uint64(n), true
+	case uint32:
+		return uint64(n), true
+	case int32:
+		return uint64(n), true
+	case uint16:
+		return uint64(n), true
+	case int16:
+		return uint64(n), true
+	case uint8:
+		return uint64(n), true
+	case int8:
+		return uint64(n), true
+	}
+	return 0, false
+}
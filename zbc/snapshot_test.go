@@ -0,0 +1,87 @@
+package zbc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+type topicEvent struct {
+	key       uint64
+	eventType sbe.EventTypeEnum
+	event     map[string]interface{}
+}
+
+// pushAll will push the events once the replay subscribes to the topic.
+func pushAll(broker *zbtest.MockBroker, events []topicEvent) {
+	for _, e := range events {
+		for broker.PushTopicEvent("default-topic", e.key, e.eventType, e.event) != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestClient_ListWorkflowInstancesAndTasks(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	instance := func(state string, key uint64, activity string) map[string]interface{} {
+		return map[string]interface{}{"state": state, "bpmnProcessId": "order", "version": 2, "workflowInstanceKey": key, "activityId": activity}
+	}
+	go pushAll(broker, []topicEvent{
+		{1, sbe.EventType.WORKFLOW_INSTANCE_EVENT, instance(zbc.WorkflowInstanceCreated, 1, "")},
+		{2, sbe.EventType.WORKFLOW_INSTANCE_EVENT, instance(zbc.WorkflowInstanceActivityActivated, 1, "collect-money")},
+		{3, sbe.EventType.WORKFLOW_INSTANCE_EVENT, instance(zbc.WorkflowInstanceCreated, 3, "")},
+		{3, sbe.EventType.WORKFLOW_INSTANCE_EVENT, instance(zbc.WorkflowInstanceCompleted, 3, "")},
+		{4, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCreated, "type": "a"}},
+	})
+	instances, err := client.ListWorkflowInstances("default-topic", zbc.TimeoutOption(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 1 || instances[0].Key != 1 || instances[0].ActivityID != "collect-money" || instances[0].Version != 2 {
+		t.Fatalf("Expected running instance 1 at collect-money, received %+v", instances)
+	}
+
+	task := func(state string, retries int) map[string]interface{} {
+		return map[string]interface{}{
+			"state":         state,
+			"type":          "payment",
+			"retries":       retries,
+			"lockOwner":     "worker-1",
+			"headers":       map[string]interface{}{"workflowInstanceKey": 1, "activityId": "collect-money"},
+			"customHeaders": map[string]interface{}{"region": "eu"},
+		}
+	}
+	go pushAll(broker, []topicEvent{
+		{5, sbe.EventType.TASK_EVENT, task(zbc.TaskCreated, 3)},
+		{5, sbe.EventType.TASK_EVENT, task(zbc.TaskLocked, 3)},
+		{5, sbe.EventType.TASK_EVENT, task(zbc.TaskFailed, 2)},
+		{6, sbe.EventType.TASK_EVENT, task(zbc.TaskCreated, 3)},
+		{6, sbe.EventType.TASK_EVENT, task(zbc.TaskCompleted, 3)},
+		{7, sbe.EventType.TASK_EVENT, task(zbc.TaskFail, 3)},
+	})
+	tasks, err := client.ListTasks("default-topic", zbc.TimeoutOption(10*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("Expected one open task, received %+v", tasks)
+	}
+	if found := tasks[0]; found.Key != 5 || found.State != zbc.TaskFailed || found.Retries != 2 || found.WorkflowInstanceKey != 1 ||
+		found.ActivityID != "collect-money" || found.CustomHeaders["region"] != "eu" {
+		t.Fatalf("Unexpected task %+v", found)
+	}
+}
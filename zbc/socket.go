@@ -0,0 +1,77 @@
+package zbc
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// DialerFunc opens connection to the broker at addr instead of dialing TCP, e.g. through a SOCKS or HTTP proxy,
+// an SSH tunnel or to a UNIX socket. Network is "tcp". Context is done once ConnectTimeout of SocketOptions passes.
+type DialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SocketOptions tune TCP connections to the brokers. Zero values keep defaults of the Go runtime and the OS.
+type SocketOptions struct {
+	ConnectTimeout time.Duration // Time after which dialing and TLS handshake fail. Zero means no timeout besides the one of the OS.
+	KeepAlive      time.Duration // Period of TCP keep-alive probes, see net.Dialer. Negative disables them.
+	SendBuffer     int           // Size of SO_SNDBUF in bytes.
+	ReceiveBuffer  int           // Size of SO_RCVBUF in bytes.
+	Nagle          bool          // Enable Nagle's algorithm, Go disables it by setting TCP_NODELAY. Batches small frames at cost of latency.
+}
+
+func (o *SocketOptions) dialer() *net.Dialer {
+	return &net.Dialer{Timeout: o.ConnectTimeout, KeepAlive: o.KeepAlive}
+}
+
+// dialTCP will dial the broker and apply options to the socket.
+func (o *SocketOptions) dialTCP(network, addr string) (net.Conn, error) {
+	conn, err := o.dialer().Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.apply(conn.(*net.TCPConn)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// dialWith will open the connection with the dialer. Options are applied if it returns TCP connection.
+func (o *SocketOptions) dialWith(dialer DialerFunc, addr string) (net.Conn, error) {
+	ctx := context.Background()
+	if o.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.ConnectTimeout)
+		defer cancel()
+	}
+	conn, err := dialer(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		if err := o.apply(tcpConn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (o *SocketOptions) apply(conn *net.TCPConn) error {
+	if o.SendBuffer > 0 {
+		if err := conn.SetWriteBuffer(o.SendBuffer); err != nil {
+			return err
+		}
+	}
+	if o.ReceiveBuffer > 0 {
+		if err := conn.SetReadBuffer(o.ReceiveBuffer); err != nil {
+			return err
+		}
+	}
+	if o.Nagle {
+		return conn.SetNoDelay(false)
+	}
+	return nil
+}
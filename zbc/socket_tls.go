@@ -0,0 +1,44 @@
+//go:build go1.8
+// +build go1.8
+
+package zbc
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// dialTLS will dial the broker, apply options to the socket and perform TLS handshake. Like tls.Dial, host of the
+// address is verified unless config names the server.
+func (o *SocketOptions) dialTLS(addr string, config *tls.Config) (net.Conn, error) {
+	conn, err := o.dialTCP("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return o.handshake(conn, addr, config)
+}
+
+// handshake will perform TLS handshake on the connection to the broker at addr. Connection is closed if it fails.
+func (o *SocketOptions) handshake(conn net.Conn, addr string, config *tls.Config) (net.Conn, error) {
+	if len(config.ServerName) == 0 {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if o.ConnectTimeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(o.ConnectTimeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
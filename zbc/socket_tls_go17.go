@@ -0,0 +1,31 @@
+//go:build !go1.8
+// +build !go1.8
+
+package zbc
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// dialTLS will dial the broker over TLS. Socket of the TLS connection is not reachable and tls.Config cannot be cloned
+// before Go 1.8, so only ConnectTimeout and KeepAlive apply.
+func (o *SocketOptions) dialTLS(addr string, config *tls.Config) (net.Conn, error) {
+	return tls.DialWithDialer(o.dialer(), "tcp", addr, config)
+}
+
+// handshake will perform TLS handshake on the connection to the broker. Config cannot be cloned, so it must name
+// the server unless it skips verification. Connection is closed if handshake fails.
+func (o *SocketOptions) handshake(conn net.Conn, addr string, config *tls.Config) (net.Conn, error) {
+	tlsConn := tls.Client(conn, config)
+	if o.ConnectTimeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(o.ConnectTimeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
@@ -0,0 +1,132 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// statsSubscriptionName is name of the topic subscription replaying the topic for TopicStats.
+const statsSubscriptionName = "zbc-stats"
+
+// TaskBacklog counts tasks of one type which are not completed yet.
+type TaskBacklog struct {
+	Waiting int `json:"waiting"` // Created, failed with retries left or with expired lock, waiting for a task subscription.
+	Locked  int `json:"locked"`  // Locked by a task subscription.
+	Failed  int `json:"failed"`  // Failed without retries left, waiting until their incident is resolved.
+}
+
+// PartitionStats is state of one partition of the topic reconstructed from its events.
+type PartitionStats struct {
+	PartitionID uint16                  `json:"partitionId"`
+	Position    uint64                  `json:"position"`    // Position of the last event in the log of the partition.
+	Events      int                     `json:"events"`      // Number of events in the log of the partition.
+	Subscribers int                     `json:"subscribers"` // Number of topic subscriptions opened on the partition.
+	Tasks       map[string]*TaskBacklog `json:"tasks"`       // Backlog of tasks by type.
+}
+
+// taskEventStates are states of task events which change the task, others are commands or their rejections.
+var taskEventStates = map[string]bool{
+	TaskCreated:        true,
+	TaskLocked:         true,
+	TaskLockExpired:    true,
+	TaskCompleted:      true,
+	TaskFailed:         true,
+	TaskRetriesUpdated: true,
+	TaskCanceled:       true,
+}
+
+// taskState is the last known state of one task during the replay.
+type taskState struct {
+	taskType string
+	state    string
+	retries  int
+}
+
+// TopicStats will return statistics of every partition of the topic. Broker has no query for them, so events
+// of each partition are replayed until no event arrives for a second, same as ListWorkflows does. Task
+// subscriptions are not written to the log, so only topic subscriptions are counted. If request times out during
+// the replay, statistics of the partitions replayed so far are returned together with the error.
+func (c *Client) TopicStats(topic string, opts ...RequestOption) ([]*PartitionStats, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.TopicStatsCtx(ctx, topic)
+}
+
+// TopicStatsCtx is same as TopicStats, but replay is aborted once ctx is done.
+func (c *Client) TopicStatsCtx(ctx context.Context, topic string) ([]*PartitionStats, error) {
+	var stats []*PartitionStats
+	for _, partitionID := range c.topicPartitions(ctx, topic) {
+		partition, err := c.partitionStats(ctx, topic, partitionID)
+		if err != nil {
+			return stats, err
+		}
+		stats = append(stats, partition)
+	}
+	return stats, nil
+}
+
+func (c *Client) partitionStats(ctx context.Context, topic string, partitionID uint16) (*PartitionStats, error) {
+	stats := &PartitionStats{PartitionID: partitionID, Tasks: make(map[string]*TaskBacklog)}
+	tasks := make(map[uint64]*taskState)
+	subscribers := make(map[string]bool)
+
+	err := c.replayTopic(ctx, topic, int32(partitionID), statsSubscriptionName, nil, func(message *Message) error {
+		event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+		stats.Events++
+		if event.Position > stats.Position {
+			stats.Position = event.Position
+		}
+
+		switch event.EventType {
+		case sbe.EventType.TASK_EVENT:
+			var task Task
+			if err := message.UnmarshalData(&task); err != nil {
+				return err
+			}
+			if !taskEventStates[task.State] {
+				return nil
+			}
+			if _, ok := tasks[event.Key]; !ok {
+				tasks[event.Key] = &taskState{}
+			}
+			state := tasks[event.Key]
+			state.state, state.retries = task.State, task.Retries
+			if len(task.Type) > 0 {
+				state.taskType = task.Type
+			}
+		case sbe.EventType.SUBSCRIBER_EVENT:
+			name, _ := (*message.Data)["name"].(string)
+			if (*message.Data)["state"] == SubscriberSubscribed && name != statsSubscriptionName {
+				subscribers[name] = true
+			}
+		}
+		return nil
+	})
+
+	stats.Subscribers = len(subscribers)
+	for _, task := range tasks {
+		backlog, ok := stats.Tasks[task.taskType]
+		if !ok {
+			backlog = &TaskBacklog{}
+		}
+
+		switch task.state {
+		case TaskCreated, TaskLockExpired, TaskRetriesUpdated:
+			backlog.Waiting++
+		case TaskLocked:
+			backlog.Locked++
+		case TaskFailed:
+			if task.retries > 0 {
+				backlog.Waiting++
+			} else {
+				backlog.Failed++
+			}
+		default:
+			// Completed and canceled tasks.
+			continue
+		}
+		stats.Tasks[task.taskType] = backlog
+	}
+	return stats, err
+}
@@ -0,0 +1,73 @@
+package zbc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_TopicStats(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	type result struct {
+		stats []*zbc.PartitionStats
+		err   error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		stats, err := client.TopicStats("default-topic", zbc.TimeoutOption(10*time.Second))
+		resultCh <- result{stats, err}
+	}()
+
+	events := []struct {
+		key       uint64
+		eventType sbe.EventTypeEnum
+		event     map[string]interface{}
+	}{
+		{1, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "CREATED", "type": "a", "retries": 3}},
+		{1, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "LOCK", "type": "a", "retries": 3}},
+		{1, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "LOCKED", "type": "a", "retries": 3}},
+		{2, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "CREATED", "type": "a", "retries": 3}},
+		{3, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "CREATED", "type": "b", "retries": 3}},
+		{3, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "COMPLETED", "type": "b", "retries": 3}},
+		{4, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": "FAILED", "type": "b", "retries": 0}},
+		{5, sbe.EventType.SUBSCRIBER_EVENT, map[string]interface{}{"state": "SUBSCRIBED", "name": "audit"}},
+	}
+	for _, e := range events {
+		for broker.PushTopicEvent("default-topic", e.key, e.eventType, e.event) != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	r := <-resultCh
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if len(r.stats) != 1 {
+		t.Fatalf("Expected stats of one partition, received %d", len(r.stats))
+	}
+
+	stats := r.stats[0]
+	if stats.Events != len(events) || stats.Subscribers != 1 {
+		t.Fatalf("Expected %d events and 1 subscriber, received %+v", len(events), stats)
+	}
+	if a := stats.Tasks["a"]; a == nil || *a != (zbc.TaskBacklog{Waiting: 1, Locked: 1}) {
+		t.Fatalf("Unexpected backlog of tasks a: %+v", a)
+	}
+	if b := stats.Tasks["b"]; b == nil || *b != (zbc.TaskBacklog{Failed: 1}) {
+		t.Fatalf("Unexpected backlog of tasks b: %+v", b)
+	}
+}
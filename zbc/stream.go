@@ -0,0 +1,190 @@
+package zbc
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+)
+
+// messageBody streams body of the message from the underlying reader, also across fragments of the message, so the
+// message is decoded without holding its frames in memory. It must be closed before the next message is read.
+type messageBody struct {
+	mr         *MessageReader
+	frame      protocol.FrameHeader // Header of the frame being read.
+	fragmented bool                 // Message is split into fragments. Middle fragments have no flags set.
+	remaining  uint32               // Bytes of the frame not read yet.
+	length     int                  // Bytes of all frames of the message so far, checked against MaxMessageLength.
+	err        error                // Once set, nothing more is read. Underlying reader failed or message was skipped.
+	skipped    bool                 // Rest of the message was discarded already, reader is positioned at the next frame.
+}
+
+func (b *messageBody) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	for b.remaining == 0 {
+		if b.last() {
+			return 0, io.EOF
+		}
+		if err := b.next(); err != nil {
+			b.err = err
+			return 0, err
+		}
+	}
+
+	if uint32(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.mr.Read(p)
+	b.remaining -= uint32(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		b.err = err
+	}
+	return n, err
+}
+
+// last tells if the frame being read is the last one of the message.
+func (b *messageBody) last() bool {
+	return !b.fragmented || b.frame.Flags&protocol.FrameFlagEnd != 0
+}
+
+// next will move to the following fragment of the message. Message growing over MaxMessageLength is discarded.
+func (b *messageBody) next() error {
+	if err := b.mr.align(&b.frame); err != nil {
+		return err
+	}
+	var fragment protocol.FrameHeader
+	if err := b.mr.readFrameHeader(&fragment); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	b.frame, b.remaining = fragment, fragment.Length
+	b.length += int(fragment.Length)
+
+	if b.mr.tooLarge(b.length) {
+		if err := b.mr.discard(&fragment); err != nil {
+			return err
+		}
+		if !b.last() {
+			if err := b.mr.skipFragments(); err != nil {
+				return err
+			}
+		}
+		b.skipped = true
+		return ErrFrameTooLarge
+	}
+	return nil
+}
+
+// close will discard unread rest of the message and padding of its last frame.
+func (b *messageBody) close() error {
+	if b.skipped {
+		return nil
+	}
+	if b.err != nil {
+		return b.err
+	}
+	if _, err := io.Copy(ioutil.Discard, b); err != nil {
+		return err
+	}
+	return b.mr.align(&b.frame)
+}
+
+// ReadMessage will read the next message and decode it as it's read, without reading whole frame into memory first.
+// Message split into fragments is decoded across its frames. Message of a control frame has only headers. If body of
+// the message cannot be decoded, Message with headers which were read is returned along with the error, so the
+// request waiting for it can be found. Connection errors are returned without Message, nothing more can be read then.
+func (mr *MessageReader) ReadMessage() (*Message, error) {
+	if err := mr.waitFrame(); err != nil {
+		return nil, err
+	}
+	var frameHeader protocol.FrameHeader
+	if err := mr.readFrameHeader(&frameHeader); err != nil {
+		return nil, err
+	}
+	var headers Headers
+	headers.SetFrameHeader(&frameHeader)
+
+	if mr.tooLarge(int(frameHeader.Length)) {
+		if err := mr.discard(&frameHeader); err != nil {
+			return nil, err
+		}
+		if frameHeader.IsFragment() && frameHeader.Flags&protocol.FrameFlagBegin != 0 {
+			if err := mr.skipFragments(); err != nil {
+				return nil, err
+			}
+		}
+		return nil, ErrFrameTooLarge
+	}
+	if headers.IsControlFrame() {
+		if err := mr.discard(&frameHeader); err != nil {
+			return nil, err
+		}
+		return &Message{Headers: &headers}, nil
+	}
+	if frameHeader.IsFragment() && frameHeader.Flags&protocol.FrameFlagBegin == 0 {
+		if err := mr.discard(&frameHeader); err != nil {
+			return nil, err
+		}
+		return nil, errUnexpectedFragment
+	}
+
+	body := &messageBody{
+		mr:         mr,
+		frame:      frameHeader,
+		fragmented: frameHeader.IsFragment(),
+		remaining:  frameHeader.Length,
+		length:     int(frameHeader.Length),
+	}
+	msg, err := mr.decodeBody(&headers, body)
+	if closeErr := body.close(); closeErr != nil {
+		return nil, closeErr
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = errFrameTooShort
+	}
+
+	// Headers describe the whole message, same as ReadHeaders returns them for fragmented messages.
+	frameHeader.Length = uint32(body.length)
+	if frameHeader.IsFragment() {
+		frameHeader.Flags |= protocol.FrameFlagEnd
+	}
+	return msg, err
+}
+
+// decodeBody will decode headers following the frame header and the SBE message from body.
+func (mr *MessageReader) decodeBody(headers *Headers, body io.Reader) (*Message, error) {
+	transport, err := mr.readTransportHeader(body)
+	if err != nil {
+		return nil, err
+	}
+	headers.SetTransportHeader(transport)
+
+	if transport.ProtocolID == protocol.RequestResponse {
+		requestResponse, err := mr.readRequestResponseHeader(body)
+		if err != nil {
+			return nil, err
+		}
+		headers.SetRequestResponseHeader(requestResponse)
+	}
+
+	sbeMessageHeader, err := mr.readSbeMessageHeader(body)
+	if sbeMessageHeader != nil {
+		headers.SetSbeMessageHeader(sbeMessageHeader)
+	}
+	if err != nil {
+		return &Message{Headers: headers}, err
+	}
+
+	msg, err := mr.parseMessage(headers, body)
+	if err != nil {
+		return &Message{Headers: headers}, err
+	}
+	return msg, nil
+}
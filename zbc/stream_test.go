@@ -0,0 +1,120 @@
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+func TestMessageReader_ReadMessage(t *testing.T) {
+	msg := NewCommandRequestMessage(&sbe.ExecuteCommandRequest{
+		EventType: sbe.EventType.TASK_EVENT,
+		TopicName: []uint8("other-topic"),
+	}, &Task{State: "CREATE", Type: "foo", Retries: 3})
+	msg.Headers.RequestResponseHeader.RequestID = 1
+	fragmented := &bytes.Buffer{}
+	NewMessageWriter(msg).WriteFragments(fragmented, 16)
+
+	stream := append(append(append([]byte{}, keepAliveFrame...), fragmented.Bytes()...), writeTestFrame(t, 2, "topic-a")...)
+
+	// Socket which returns a byte at a time must not make any difference.
+	chunks := make([][]byte, len(stream))
+	for i := range stream {
+		chunks[i] = stream[i : i+1]
+	}
+	r := NewMessageReader(bufio.NewReader(&chunkReader{chunks: chunks}))
+
+	control, err := r.ReadMessage()
+	if err != nil || !control.Headers.IsControlFrame() {
+		t.Fatalf("Expected control frame, received %+v, %v", control, err)
+	}
+
+	for _, requestID := range []uint64{1, 2} {
+		message, err := r.ReadMessage()
+		if err != nil {
+			t.Fatalf("Reading message %d failed: %s", requestID, err)
+		}
+		if message.Headers.RequestResponseHeader.RequestID != requestID {
+			t.Fatalf("Expected message %d, received %d", requestID, message.Headers.RequestResponseHeader.RequestID)
+		}
+		if task := *message.Data; task["type"] != "foo" || task["state"] != "CREATE" {
+			t.Fatalf("Expected task decoded, received %v", task)
+		}
+	}
+	if length := r.MaxMessageLength; length != 0 {
+		t.Fatalf("Unexpected max message length %d", length)
+	}
+
+	if _, err := r.ReadMessage(); err != io.EOF {
+		t.Fatalf("Expected end of stream, received %v", err)
+	}
+}
+
+func TestMessageReader_ReadMessageSameAsParseMessage(t *testing.T) {
+	frame := writeTestFrame(t, 1, "topic-a")
+
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(frame)))
+	headers, tail, err := r.ReadHeaders()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := r.ParseMessage(headers, tail)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamed, err := NewMessageReader(bufio.NewReader(bytes.NewReader(frame))).ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, streamed) {
+		t.Fatalf("Expected %+v, received %+v", parsed, streamed)
+	}
+}
+
+func TestMessageReader_ReadMessageErrors(t *testing.T) {
+	big := writeTestFrame(t, 1, "other-topic")
+	small := writeTestFrame(t, 2, "topic-a")
+
+	// Frame which ends in the middle of the request response header.
+	short := make([]byte, 16)
+	protocol.ByteOrder.PutUint32(short, 4)
+
+	stream := append(append(append([]byte{}, big...), short...), small...)
+	r := NewMessageReader(bufio.NewReader(bytes.NewReader(stream)))
+	r.MaxMessageLength = binaryLength(small)
+
+	if _, err := r.ReadMessage(); err != ErrFrameTooLarge {
+		t.Fatalf("Expected ErrFrameTooLarge, received %v", err)
+	}
+	if _, err := r.ReadMessage(); err != errFrameTooShort {
+		t.Fatalf("Expected errFrameTooShort, received %v", err)
+	}
+	message, err := r.ReadMessage()
+	if err != nil || message.Headers.RequestResponseHeader.RequestID != 2 {
+		t.Fatalf("Expected message following skipped frames, received %+v, %v", message, err)
+	}
+}
+
+func BenchmarkMessageReader_ReadMessage(b *testing.B) {
+	buffer := &bytes.Buffer{}
+	NewMessageWriter(newTestCommandMessage()).Write(buffer)
+	frame := buffer.Bytes()
+
+	stream := bytes.NewReader(frame)
+	rd := bufio.NewReader(stream)
+	r := NewMessageReader(rd)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		stream.Reset(frame)
+		rd.Reset(stream)
+		if _, err := r.ReadMessage(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errSubscriptionNotFound = errors.New("Subscription not found")
+
+// Subscription is an open task or topic subscription. It is kept track of, so it can be reopened after reconnect.
+// Receiver puts events into buffer, from where they are forwarded to the consumer through ch. Credits of task subscription
+// are increased once the consumer takes events out of ch, or once it reports them done if credits are held by handlers.
+type Subscription struct {
+	clientMu sync.Mutex
+	client   *Client // Client connected to the broker which pushes events of this subscription. Changes when leader moves.
+
+	task  *TaskSubscription  // Set only for task subscriptions.
+	topic *TopicSubscription // Set only for topic subscriptions.
+	key   uint64             // Subscriber key assigned by the broker. Changes after reconnect and when leader moves.
+
+	buffer  *eventBuffer
+	ch      chan *Message
+	credits int32                                 // Credits which broker can still use or which are used by events not yet taken by the consumer.
+	filter  func(event *sbe.SubscribedEvent) bool // Events of topic subscription not matching the filter are dropped.
+	held    bool                                  // Credits are given back through done instead of when events are taken from ch.
+
+	partitions map[uint16]*Subscription // Set only for task subscription on AllPartitions, their events are merged into ch.
+
+	countersMu sync.Mutex
+	counters   subscriptionCounters
+
+	closeCh   chan struct{}
+	closeOnce sync.Once
+	forwarded chan struct{} // Closed once events are no longer passed to ch, then ch is closed too.
+}
+
+func newTaskSubscription(client *Client, ts *TaskSubscription) *Subscription {
+	size := ts.BufferSize
+	if size <= 0 {
+		size = ts.Credits
+	}
+	return &Subscription{
+		client:    client,
+		task:      ts,
+		buffer:    newEventBuffer(size, ts.Overflow),
+		ch:        make(chan *Message),
+		credits:   ts.Credits,
+		closeCh:   make(chan struct{}),
+		forwarded: make(chan struct{}),
+	}
+}
+
+func newTopicSubscription(client *Client, ts *TopicSubscription) *Subscription {
+	size := ts.BufferSize
+	if size <= 0 {
+		size = ts.PrefetchCapacity
+	}
+	return &Subscription{
+		client:    client,
+		topic:     ts,
+		buffer:    newEventBuffer(size, ts.Overflow),
+		ch:        make(chan *Message),
+		closeCh:   make(chan struct{}),
+		forwarded: make(chan struct{}),
+	}
+}
+
+// owner returns Client connected to the broker which pushes events of the subscription.
+func (s *Subscription) owner() *Client {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+	return s.client
+}
+
+func (s *Subscription) setOwner(c *Client) {
+	s.clientMu.Lock()
+	s.client = c
+	s.clientMu.Unlock()
+}
+
+// Events returns channel where all the SubscribedEvents arrive. Channel is closed once subscription is closed.
+func (s *Subscription) Events() chan *Message {
+	return s.ch
+}
+
+// Close will remove the subscription on the broker and close its channel. Events not yet taken from the channel are dropped.
+func (s *Subscription) Close(opts ...RequestOption) error {
+	ctx, cancel := s.owner().requestContext(opts...)
+	defer cancel()
+	return s.CloseCtx(ctx)
+}
+
+// CloseCtx is same as Close, but waiting for the broker is aborted once ctx is done. Channel is closed in any case,
+// CloseCtx returns once it is closed or ctx is done.
+func (s *Subscription) CloseCtx(ctx context.Context) error {
+	if s.partitions != nil {
+		return s.closePartitions(ctx)
+	}
+	c := s.owner()
+
+	// Subscription must not be reopened by reconnect while it is being closed.
+	c.mu.Lock()
+	for i, sub := range c.openedSubscriptions {
+		if sub == s {
+			c.openedSubscriptions = append(c.openedSubscriptions[:i], c.openedSubscriptions[i+1:]...)
+			break
+		}
+	}
+	key := s.key
+	c.mu.Unlock()
+
+	msg := s.closeMessage(key)
+	var err error
+	if msg == nil {
+		err = errMessageBuild
+	} else {
+		_, err = c.ResponderCtx(ctx, msg)
+	}
+
+	c.mu.Lock()
+	if c.subscriptions[key] == s {
+		delete(c.subscriptions, key)
+	}
+	c.mu.Unlock()
+
+	if waitErr := s.stopForwarding(ctx); err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		c.log().Warn("Closing subscription failed", F("subscription", s), F("error", err))
+	} else {
+		c.log().Info("Subscription closed", F("subscription", s))
+	}
+	return err
+}
+
+// stopForwarding will stop passing events to the consumer and wait until channel of the subscription is closed.
+func (s *Subscription) stopForwarding(ctx context.Context) error {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
+	select {
+	case <-s.forwarded:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Subscription) closeMessage(key uint64) *Message {
+	if s.task != nil {
+		ts := *s.task
+		ts.SubscriberKey = key
+		return NewCloseTaskSubscriptionMessage(&ts)
+	}
+	return NewCloseTopicSubscriptionMessage(s.topic, key)
+}
+
+// deliver will pass event received from the broker to the forwarder. Events of closed subscription are dropped.
+func (s *Subscription) deliver(message *Message) {
+	event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+	s.countReceived(event)
+	err := s.buffer.put(message, s.closeCh)
+	if err == nil {
+		return
+	}
+
+	s.owner().log().Warn("Event dropped", F("subscription", s), F("key", event.Key), F("error", err))
+	s.owner().observeDrop(event)
+	if s.task != nil {
+		// Credit of the task is used up on the broker, consumer will never give it back.
+		s.consumed()
+	}
+}
+
+func (s *Subscription) String() string {
+	if s.task != nil {
+		return fmt.Sprintf("task subscription %s on %s", s.task.TaskType, s.task.TopicName)
+	}
+	return fmt.Sprintf("topic subscription %s on %s", s.topic.Name, s.topic.TopicName)
+}
+
+// creditsThreshold returns number of credits below which subscription will ask for more. Defaults to half of Credits.
+func (s *Subscription) creditsThreshold() int32 {
+	threshold := s.task.CreditsThreshold
+	if threshold <= 0 {
+		threshold = s.task.Credits / 2
+	}
+	if threshold < 1 {
+		threshold = 1
+	}
+	return threshold
+}
+
+// creditsBatch returns number of credits added at once. It is never more than fits into the buffer of the subscription.
+func (s *Subscription) creditsBatch() int32 {
+	max := s.task.Credits - s.creditsThreshold() + 1
+	batch := s.task.CreditsBatch
+	if batch <= 0 || batch > max {
+		batch = max
+	}
+	return batch
+}
+
+func (s *Subscription) forward() {
+	defer close(s.forwarded)
+	defer close(s.ch)
+	defer s.buffer.close()
+
+	for {
+		message, err := s.buffer.take(s.closeCh)
+		if err != nil {
+			s.owner().log().Error("Reading spilled events failed, they are dropped", F("subscription", s), F("error", err))
+			continue
+		}
+		if message == nil {
+			return
+		}
+
+		if event := (*message.SbeMessage).(*sbe.SubscribedEvent); !s.accepts(event) {
+			s.owner().observeFilter(event)
+			if s.task != nil {
+				// Filtered task never reaches the consumer, so its credit is given back here.
+				s.consumed()
+			}
+			continue
+		}
+		select {
+		case <-s.closeCh:
+			return
+		case s.ch <- message:
+		}
+		s.countDelivered()
+		if s.task != nil && !s.held {
+			s.consumed()
+		}
+	}
+}
+
+// done will give back credit of the event taken from ch by consumer holding the credits until events are handled.
+func (s *Subscription) done(message *Message) {
+	if s.partitions != nil {
+		if sub := s.partitionOf(message); sub != nil {
+			sub.done(message)
+		}
+		return
+	}
+	if s.held {
+		s.consumed()
+	}
+}
+
+// consumed will increase credits of the task subscription once they drop below the threshold.
+func (s *Subscription) consumed() {
+	credits := atomic.AddInt32(&s.credits, -1)
+	s.observeCredits()
+	if credits >= s.creditsThreshold() {
+		return
+	}
+
+	batch := s.creditsBatch()
+	atomic.AddInt32(&s.credits, batch)
+	s.owner().spawn(func() {
+		if err := s.increaseCredits(batch); err != nil {
+			s.owner().log().Warn("Increasing credits failed", F("subscription", s), F("error", err))
+			atomic.AddInt32(&s.credits, -batch)
+		} else {
+			s.owner().log().Debug("Credits increased", F("subscription", s), F("credits", batch))
+		}
+		s.observeCredits()
+	})
+}
+
+// increaseCredits will allow the broker to push given number of additional tasks on the subscription.
+func (s *Subscription) increaseCredits(credits int32) error {
+	s.owner().mu.Lock()
+	ts := *s.task
+	ts.SubscriberKey = s.key
+	s.owner().mu.Unlock()
+	ts.Credits = credits
+
+	_, err := s.owner().Responder(NewIncreaseTaskSubscriptionCreditsMessage(&ts))
+	return err
+}
+
+// open will send request for the subscription and return subscriber key assigned by the broker.
+func (s *Subscription) open(ctx context.Context) (uint64, error) {
+	if s.task != nil {
+		response, err := s.owner().ResponderCtx(ctx, NewTaskSubscriptionMessage(s.task))
+		if err != nil {
+			return 0, err
+		}
+		if response.Data == nil {
+			return 0, errUnexpectedResponse
+		}
+		subscriberKey, ok := (*response.Data)["subscriberKey"].(uint64)
+		if !ok {
+			return 0, errUnexpectedResponse
+		}
+
+		// Subscription starts with full credits on the broker.
+		atomic.StoreInt32(&s.credits, s.task.Credits)
+		s.observeCredits()
+		return subscriberKey, nil
+	}
+
+	ts, err := s.resumed()
+	if err != nil {
+		return 0, err
+	}
+	msg := NewTopicSubscriptionMessage(ts)
+	if msg == nil {
+		return 0, errMessageBuild
+	}
+	response, err := s.owner().ResponderCtx(ctx, msg)
+	if err != nil {
+		return 0, err
+	}
+	cmdResponse, ok := (*response.SbeMessage).(*sbe.ExecuteCommandResponse)
+	if !ok || response.Data == nil {
+		return 0, errUnexpectedResponse
+	}
+	if state := (*response.Data)["state"]; state != SubscriberSubscribed {
+		return 0, fmt.Errorf("Opening topic subscription failed with state %v", state)
+	}
+	return cmdResponse.Key, nil
+}
+
+func (c *Client) openSubscription(ctx context.Context, sub *Subscription) error {
+	key, err := sub.open(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	sub.key = key
+	c.subscriptions[sub.key] = sub
+	c.mu.Unlock()
+	return nil
+}
+
+// startSubscription will open the subscription and start forwarding its events to the consumer.
+func (c *Client) startSubscription(ctx context.Context, sub *Subscription) error {
+	if err := c.openSubscription(ctx, sub); err != nil {
+		return err
+	}
+	// Close closes subscriptions before it waits for spawned goroutines, which ends forwarding.
+	if !c.spawn(sub.forward) {
+		return ErrClientClosed
+	}
+
+	c.mu.Lock()
+	c.openedSubscriptions = append(c.openedSubscriptions, sub)
+	c.mu.Unlock()
+	c.log().Info("Subscription opened", F("subscription", sub), F("addr", c.addr))
+	return nil
+}
+
+// subscribe will open task subscription on the broker leading the partition. If held is set, credits are not given
+// back when events are taken from the channel, but once the consumer calls done.
+func (c *Client) subscribe(ctx context.Context, ts *TaskSubscription, held bool) (*Subscription, error) {
+	if ts.PartitionID == AllPartitions {
+		return c.subscribeAll(ctx, ts, held)
+	}
+	client, err := c.leaderClient(ctx, ts.TopicName, uint16(ts.PartitionID))
+	if err != nil {
+		return nil, err
+	}
+
+	sub := newTaskSubscription(client, ts)
+	sub.held = held
+	if err := c.retry(ctx, false, func() error { return client.startSubscription(ctx, sub) }); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// findSubscription will look up task or topic subscription which delivered the event on any of the brokers.
+func (c *Client) findSubscription(event *sbe.SubscribedEvent, task bool) (*Subscription, error) {
+	clients := []*Client{c}
+	if c.pool != nil {
+		clients = c.pool.Clients()
+	}
+
+	for _, client := range clients {
+		client.mu.Lock()
+		sub, ok := client.subscriptions[event.SubscriberKey]
+		client.mu.Unlock()
+
+		if !ok || (sub.task != nil) != task {
+			continue
+		}
+		if topic, partitionID := sub.partition(); topic == string(event.TopicName) && partitionID == event.PartitionId {
+			return sub, nil
+		}
+	}
+	return nil, errSubscriptionNotFound
+}
+
+// TaskConsumer opens a subscription on task on the broker leading its partition and returns a channel where all the SubscribedEvents will arrive.
+// Credits are increased automatically as events are taken from the channel, see CreditsThreshold and CreditsBatch of TaskSubscription.
+// If connection breaks, subscription is reopened after reconnect and events continue arriving on the same channel.
+// With PartitionID set to AllPartitions, tasks of all partitions of the topic arrive on the channel.
+func (c *Client) TaskConsumer(ts *TaskSubscription, opts ...RequestOption) (chan *Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.TaskConsumerCtx(ctx, ts)
+}
+
+// TaskConsumerCtx is same as TaskConsumer, but opening of the subscription is aborted once ctx is done.
+func (c *Client) TaskConsumerCtx(ctx context.Context, ts *TaskSubscription) (chan *Message, error) {
+	sub, err := c.subscribe(ctx, ts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return sub.ch, nil
+}
+
+// OpenTaskSubscription is same as TaskConsumer, but it returns the Subscription, so it can be closed.
+func (c *Client) OpenTaskSubscription(ts *TaskSubscription, opts ...RequestOption) (*Subscription, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.subscribe(ctx, ts, false)
+}
+
+// OpenTaskSubscriptionCtx is same as OpenTaskSubscription, but opening of the subscription is aborted once ctx is done.
+func (c *Client) OpenTaskSubscriptionCtx(ctx context.Context, ts *TaskSubscription) (*Subscription, error) {
+	return c.subscribe(ctx, ts, false)
+}
+
+// TopicConsumer opens a subscription on all events of the topic partition and returns a channel where all the SubscribedEvents will arrive.
+// Processed events should be acknowledged with AcknowledgeTopicEvent, so broker knows where to continue once subscription is reopened.
+func (c *Client) TopicConsumer(ts *TopicSubscription, opts ...RequestOption) (chan *Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.TopicConsumerCtx(ctx, ts)
+}
+
+// TopicConsumerCtx is same as TopicConsumer, but opening of the subscription is aborted once ctx is done.
+func (c *Client) TopicConsumerCtx(ctx context.Context, ts *TopicSubscription) (chan *Message, error) {
+	sub, err := c.OpenTopicSubscriptionCtx(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	return sub.ch, nil
+}
+
+// OpenTopicSubscription is same as TopicConsumer, but it returns the Subscription, so it can be closed.
+func (c *Client) OpenTopicSubscription(ts *TopicSubscription, opts ...RequestOption) (*Subscription, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.OpenTopicSubscriptionCtx(ctx, ts)
+}
+
+// OpenTopicSubscriptionCtx is same as OpenTopicSubscription, but opening of the subscription is aborted once ctx is done.
+func (c *Client) OpenTopicSubscriptionCtx(ctx context.Context, ts *TopicSubscription) (*Subscription, error) {
+	return c.openTopicSubscription(ctx, ts, nil)
+}
+
+// openTopicSubscription will open topic subscription on the broker leading the partition. Only events matching
+// the filter are passed to the consumer, nil filter passes all of them.
+func (c *Client) openTopicSubscription(ctx context.Context, ts *TopicSubscription, filter func(event *sbe.SubscribedEvent) bool) (*Subscription, error) {
+	client, err := c.leaderClient(ctx, ts.TopicName, uint16(ts.PartitionID))
+	if err != nil {
+		return nil, err
+	}
+
+	sub := newTopicSubscription(client, ts)
+	sub.filter = filter
+	if err := c.retry(ctx, false, func() error { return client.startSubscription(ctx, sub) }); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// AcknowledgeTopicEvent will tell the broker that event received through topic subscription is processed. Position
+// of the event is saved in CheckpointStore of the subscription, if it has one.
+func (c *Client) AcknowledgeTopicEvent(event *sbe.SubscribedEvent, opts ...RequestOption) (*Message, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.AcknowledgeTopicEventCtx(ctx, event)
+}
+
+// AcknowledgeTopicEventCtx is same as AcknowledgeTopicEvent, but request is aborted once ctx is done.
+func (c *Client) AcknowledgeTopicEventCtx(ctx context.Context, event *sbe.SubscribedEvent) (*Message, error) {
+	sub, err := c.findSubscription(event, false)
+	if err != nil {
+		return nil, err
+	}
+	return sub.acknowledge(ctx, event)
+}
+
+// acknowledge will tell the broker that events of the topic subscription up to the event are processed. Current
+// subscriber key is used, so events received before the subscription was reopened can be acknowledged too.
+func (s *Subscription) acknowledge(ctx context.Context, event *sbe.SubscribedEvent) (*Message, error) {
+	s.owner().mu.Lock()
+	key := s.key
+	s.owner().mu.Unlock()
+
+	msg := NewTopicSubscriptionAckMessage(s.topic, key, event.Position)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+	response, err := s.owner().ResponderCtx(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	s.countAcknowledged(event)
+	if err := s.checkpoint(event.Position); err != nil {
+		return response, err
+	}
+	return response, nil
+}
@@ -0,0 +1,131 @@
+package zbc
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// SubscriptionStats is a snapshot of counters of the subscription, e.g. to find out if its consumer keeps up.
+type SubscriptionStats struct {
+	Received      uint64    `json:"received"`      // Events pushed by the broker, including dropped and filtered ones.
+	Delivered     uint64    `json:"delivered"`     // Events taken from the channel by the consumer.
+	Acknowledged  uint64    `json:"acknowledged"`  // Topic events acknowledged, or tasks completed or failed.
+	Credits       int32     `json:"credits"`       // Credits which broker can still use, zero for topic subscriptions.
+	Buffered      int       `json:"buffered"`      // Events received but not yet taken by the consumer.
+	Pending       uint64    `json:"pending"`       // Events taken by the consumer but not yet acknowledged, completed or failed.
+	LastPosition  uint64    `json:"lastPosition"`  // Position of the last event received.
+	AckedPosition uint64    `json:"ackedPosition"` // Position of the last event acknowledged, completed or failed.
+	PositionLag   uint64    `json:"positionLag"`   // Distance between LastPosition and AckedPosition in the log.
+	LastEvent     time.Time `json:"lastEvent"`     // When the last event was received, zero if none was.
+}
+
+// SubscriptionObserver can be implemented by Observer to be notified about statistics of subscriptions whenever
+// an event is received or acknowledged. Subscription is named by its task type or by name of the topic subscription.
+type SubscriptionObserver interface {
+	SubscriptionStatsChanged(subscription string, partitionID uint16, stats SubscriptionStats)
+}
+
+// subscriptionCounters are counters of the subscription which are not kept anywhere else.
+type subscriptionCounters struct {
+	received      uint64
+	delivered     uint64
+	acknowledged  uint64
+	lastPosition  uint64
+	ackedPosition uint64
+	lastEvent     time.Time
+}
+
+// Stats returns statistics of the subscription. Subscription on AllPartitions sums counters of its partitions,
+// its positions are left zero as they are not comparable across partitions.
+func (s *Subscription) Stats() SubscriptionStats {
+	if s.partitions != nil {
+		var stats SubscriptionStats
+		for _, sub := range s.partitions {
+			partition := sub.Stats()
+			stats.Received += partition.Received
+			stats.Delivered += partition.Delivered
+			stats.Acknowledged += partition.Acknowledged
+			stats.Credits += partition.Credits
+			stats.Buffered += partition.Buffered
+			stats.Pending += partition.Pending
+			stats.PositionLag += partition.PositionLag
+			if partition.LastEvent.After(stats.LastEvent) {
+				stats.LastEvent = partition.LastEvent
+			}
+		}
+		return stats
+	}
+
+	s.countersMu.Lock()
+	counters := s.counters
+	s.countersMu.Unlock()
+
+	stats := SubscriptionStats{
+		Received:      counters.received,
+		Delivered:     counters.delivered,
+		Acknowledged:  counters.acknowledged,
+		Buffered:      s.buffer.len(),
+		LastPosition:  counters.lastPosition,
+		AckedPosition: counters.ackedPosition,
+		LastEvent:     counters.lastEvent,
+	}
+	if s.task != nil {
+		stats.Credits = atomic.LoadInt32(&s.credits)
+	}
+	if counters.delivered > counters.acknowledged {
+		stats.Pending = counters.delivered - counters.acknowledged
+	}
+	if counters.lastPosition > counters.ackedPosition {
+		stats.PositionLag = counters.lastPosition - counters.ackedPosition
+	}
+	return stats
+}
+
+// name returns task type of task subscription or name of topic subscription.
+func (s *Subscription) name() string {
+	if s.task != nil {
+		return s.task.TaskType
+	}
+	return s.topic.Name
+}
+
+func (s *Subscription) countReceived(event *sbe.SubscribedEvent) {
+	s.countersMu.Lock()
+	s.counters.received++
+	s.counters.lastPosition = event.Position
+	s.counters.lastEvent = time.Now()
+	s.countersMu.Unlock()
+	s.observeStats()
+}
+
+func (s *Subscription) countDelivered() {
+	s.countersMu.Lock()
+	s.counters.delivered++
+	s.countersMu.Unlock()
+}
+
+func (s *Subscription) countAcknowledged(event *sbe.SubscribedEvent) {
+	s.countersMu.Lock()
+	s.counters.acknowledged++
+	if event.Position > s.counters.ackedPosition {
+		s.counters.ackedPosition = event.Position
+	}
+	s.countersMu.Unlock()
+	s.observeStats()
+}
+
+// acknowledged will count the task or topic event as acknowledged by subscription which delivered it, if it's known.
+func (c *Client) acknowledged(event *sbe.SubscribedEvent, task bool) {
+	if sub, err := c.findSubscription(event, task); err == nil {
+		sub.countAcknowledged(event)
+	}
+}
+
+func (s *Subscription) observeStats() {
+	if observer, ok := s.owner().getObserver().(SubscriptionObserver); ok {
+		_, partitionID := s.partition()
+		observer.SubscriptionStatsChanged(s.name(), partitionID, s.Stats())
+	}
+}
@@ -0,0 +1,89 @@
+package zbc_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+type statsCounter struct {
+	dropCounter
+	changes int32
+}
+
+func (s *statsCounter) SubscriptionStatsChanged(string, uint16, zbc.SubscriptionStats) {
+	atomic.AddInt32(&s.changes, 1)
+}
+
+func TestSubscription_Stats(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+	broker.HandleCommand(sbe.EventType.SUBSCRIPTION_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.SubscriptionAcknowledged})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+	observer := &statsCounter{}
+	client.SetObserver(observer)
+
+	sub, err := client.OpenTopicSubscription(&zbc.TopicSubscription{
+		TopicName:        "default-topic",
+		Name:             "stats",
+		PrefetchCapacity: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		if err := broker.PushTopicEvent("default-topic", uint64(i), sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCreated}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := (*(<-sub.Events()).SbeMessage).(*sbe.SubscribedEvent)
+	second := (*(<-sub.Events()).SbeMessage).(*sbe.SubscribedEvent)
+	second.Position = 20
+	if _, err := client.AcknowledgeTopicEvent(first); err != nil {
+		t.Fatal(err)
+	}
+
+	// Third event is taken out of the buffer by the forwarder, which waits until the consumer takes it.
+	expected := zbc.SubscriptionStats{Received: 3, Delivered: 2, Acknowledged: 1, Pending: 1}
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := sub.Stats()
+		if stats.LastEvent.IsZero() {
+			t.Fatal("Expected time of the last event")
+		}
+		stats.LastEvent = time.Time{}
+		if stats == expected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected stats %+v, received %+v", expected, stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := client.AcknowledgeTopicEvent(second); err != nil {
+		t.Fatal(err)
+	}
+	if stats := sub.Stats(); stats.Pending != 0 || stats.AckedPosition != 20 {
+		t.Fatalf("Expected acknowledged position 20 and no pending events, received %+v", stats)
+	}
+	if changes := atomic.LoadInt32(&observer.changes); changes != 5 {
+		t.Fatalf("Expected observer notified about 3 received and 2 acknowledged events, received %d", changes)
+	}
+}
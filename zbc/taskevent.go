@@ -0,0 +1,68 @@
+package zbc
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errNotTaskEvent = errors.New("Message is not a task event received through subscription")
+
+// TaskEvent is task received through subscription, decoded by Message.TaskEvent. Payload is kept as raw message
+// pack, decode it by UnmarshalPayload or with the codec it was written with.
+type TaskEvent struct {
+	Key         uint64 `msgpack:"-"`
+	TopicName   string `msgpack:"-"`
+	PartitionID uint16 `msgpack:"-"`
+	Position    uint64 `msgpack:"-"`
+
+	State         string                 `msgpack:"state"`
+	Type          string                 `msgpack:"type"`
+	Retries       int                    `msgpack:"retries"`
+	LockOwner     string                 `msgpack:"lockOwner"`
+	LockTime      int64                  `msgpack:"lockTime"` // Milliseconds since epoch until which the task is locked.
+	Headers       map[string]interface{} `msgpack:"headers"`
+	CustomHeaders map[string]interface{} `msgpack:"customHeaders"` // Values are strings, see Task.CustomHeaders.
+	Payload       []byte                 `msgpack:"payload"`
+
+	Event *sbe.SubscribedEvent `msgpack:"-"` // Event the task was decoded from, pass it to CompleteTask or FailTask.
+}
+
+// TaskEvent will decode task event received through task or topic subscription. Error is returned for other messages.
+func (m *Message) TaskEvent() (*TaskEvent, error) {
+	if m.SbeMessage == nil {
+		return nil, errNotTaskEvent
+	}
+	event, ok := (*m.SbeMessage).(*sbe.SubscribedEvent)
+	if !ok || event.EventType != sbe.EventType.TASK_EVENT {
+		return nil, errNotTaskEvent
+	}
+
+	var task TaskEvent
+	if err := unmarshalMessagePack(event.Event, &task); err != nil {
+		return nil, err
+	}
+	task.Key = event.Key
+	task.TopicName = string(event.TopicName)
+	task.PartitionID = event.PartitionId
+	task.Position = event.Position
+	task.Event = event
+	return &task, nil
+}
+
+// LockExpiration returns time at which lock of the task expires. It is zero for tasks which are not locked.
+func (t *TaskEvent) LockExpiration() time.Time {
+	if t.LockTime <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, t.LockTime*int64(time.Millisecond))
+}
+
+// UnmarshalPayload will decode message pack payload of the task into v.
+func (t *TaskEvent) UnmarshalPayload(v interface{}) error {
+	if len(t.Payload) == 0 {
+		return nil
+	}
+	return unmarshalMessagePack(t.Payload, v)
+}
@@ -0,0 +1,266 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+var (
+	// ErrTaskQueued is returned by CreateTask when the broker is unreachable and the task is kept in TaskQueue instead.
+	ErrTaskQueued = errors.New("Broker unreachable, task queued until it's reachable again")
+	// ErrTaskQueueFull is returned by CreateTask when the broker is unreachable and TaskQueue has no room for the task.
+	ErrTaskQueueFull = errors.New("Task queue is full")
+)
+
+// QueueObserver can be implemented by Observer to be notified about number of tasks waiting in TaskQueue.
+type QueueObserver interface {
+	TasksQueued(depth int)
+}
+
+// queuedTask is task waiting in TaskQueue. Its payload is encoded already.
+type queuedTask struct {
+	Topic string `msgpack:"topic"`
+	Task  Task   `msgpack:"task"`
+}
+
+// TaskQueue keeps tasks created while the broker is unreachable in a file, so they survive restart. Tasks are created
+// in the order they were queued once the broker is reachable again. Task is created at least once, it may be created
+// twice if the process stops while the queue is being flushed.
+type TaskQueue struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File // Tasks are appended to the file as message pack values.
+	tasks   []*queuedTask
+	maxSize int
+
+	flushing int32
+}
+
+// NewTaskQueue is constructor for TaskQueue kept in the file at path. Tasks left in the file are loaded, missing file
+// is created. At most maxSize tasks are queued, zero means no limit.
+func NewTaskQueue(path string, maxSize int) (*TaskQueue, error) {
+	q := &TaskQueue{path: path, maxSize: maxSize}
+
+	f, err := os.Open(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		decoder := msgpack.NewDecoder(f)
+		for {
+			var task queuedTask
+			if err := decoder.Decode(&task); err != nil {
+				// Task written only partially when the process stopped is dropped by the rewrite below.
+				break
+			}
+			q.tasks = append(q.tasks, &task)
+		}
+		f.Close()
+	}
+
+	if err := q.rewrite(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Len returns number of queued tasks.
+func (q *TaskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tasks)
+}
+
+// Close will close the file of the queue. Queued tasks stay in the file.
+func (q *TaskQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
+
+// push will append the task to the queue and its file.
+func (q *TaskQueue) push(topic string, task *Task) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxSize > 0 && len(q.tasks) >= q.maxSize {
+		return len(q.tasks), ErrTaskQueueFull
+	}
+	queued := &queuedTask{Topic: topic, Task: *task}
+	b, err := msgpack.Marshal(queued)
+	if err != nil {
+		return len(q.tasks), err
+	}
+	if _, err := q.file.Write(b); err != nil {
+		return len(q.tasks), err
+	}
+	q.tasks = append(q.tasks, queued)
+	return len(q.tasks), nil
+}
+
+// peek returns the oldest queued task, nil if the queue is empty.
+func (q *TaskQueue) peek() *queuedTask {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.tasks) == 0 {
+		return nil
+	}
+	return q.tasks[0]
+}
+
+// pop will remove the oldest task from the queue. File is rewritten by flush once it's done.
+func (q *TaskQueue) pop() {
+	q.mu.Lock()
+	q.tasks = q.tasks[1:]
+	q.mu.Unlock()
+}
+
+// save will rewrite the file with tasks left in the queue.
+func (q *TaskQueue) save() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.rewrite()
+}
+
+// rewrite will write queued tasks into a temporary file which then replaces the file of the queue, so it's never
+// left half written. Must be called with mu held.
+func (q *TaskQueue) rewrite() error {
+	tmp, err := ioutil.TempFile(filepath.Dir(q.path), filepath.Base(q.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	encoder := msgpack.NewEncoder(tmp)
+	for _, task := range q.tasks {
+		if err = encoder.Encode(task); err != nil {
+			break
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), q.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if q.file != nil {
+		q.file.Close()
+	}
+	q.file, err = os.OpenFile(q.path, os.O_WRONLY|os.O_APPEND, 0600)
+	return err
+}
+
+// SetTaskQueue is a setter for TaskQueue, which keeps tasks created while the broker is unreachable. Nil returns
+// errors of such tasks to the caller, which is the default. Tasks left in the queue are created by FlushTaskQueue,
+// after reconnect or with the next CreateTask.
+func (c *Client) SetTaskQueue(queue *TaskQueue) {
+	c.mu.Lock()
+	c.taskQueue = queue
+	c.mu.Unlock()
+}
+
+func (c *Client) getTaskQueue() *TaskQueue {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.taskQueue
+}
+
+// isUnreachable will decide if command failed because the broker can't be reached, so it's worth queueing.
+func isUnreachable(err error) bool {
+	return err == ErrRequestTimeout || err == errSocketWrite || isConnectionError(err)
+}
+
+// queueTask will put the task into the queue. ErrTaskQueued is returned once it's there.
+func (c *Client) queueTask(queue *TaskQueue, topic string, task *Task) error {
+	depth, err := queue.push(topic, task)
+	if err != nil {
+		return err
+	}
+	c.observeQueue(depth)
+	c.log().Warn("Task queued", F("topic", topic), F("type", task.Type), F("depth", depth))
+	return ErrTaskQueued
+}
+
+// FlushTaskQueue will create queued tasks in the order they were queued. It stops at the first task which can't be
+// created because the broker is unreachable. Tasks rejected by the broker are dropped.
+func (c *Client) FlushTaskQueue() error {
+	return c.FlushTaskQueueCtx(context.Background())
+}
+
+// FlushTaskQueueCtx is same as FlushTaskQueue, but flushing stops once ctx is done. Every task is sent with
+// request timeout of the Client.
+func (c *Client) FlushTaskQueueCtx(ctx context.Context) error {
+	queue := c.getTaskQueue()
+	if queue == nil || !atomic.CompareAndSwapInt32(&queue.flushing, 0, 1) {
+		return nil
+	}
+	defer atomic.StoreInt32(&queue.flushing, 0)
+
+	var err error
+	flushed := 0
+	for queued := queue.peek(); queued != nil; queued = queue.peek() {
+		// Tasks stay queued when the client is closed meanwhile, the queue is flushed by the next client.
+		if err = c.createQueuedTask(ctx, queued); isUnreachable(err) || err == ErrClientClosed || ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			c.log().Error("Queued task rejected, it is dropped", F("topic", queued.Topic), F("type", queued.Task.Type), F("error", err))
+		}
+		queue.pop()
+		flushed++
+	}
+	if flushed == 0 {
+		return err
+	}
+
+	depth := queue.Len()
+	c.observeQueue(depth)
+	c.log().Info("Task queue flushed", F("created", flushed), F("depth", depth))
+	if saveErr := queue.save(); saveErr != nil {
+		return saveErr
+	}
+	if isUnreachable(err) || err == ErrClientClosed {
+		return err
+	}
+	return ctx.Err()
+}
+
+func (c *Client) createQueuedTask(ctx context.Context, queued *queuedTask) error {
+	ctx, cancel := context.WithTimeout(ctx, c.RequestTimeout())
+	defer cancel()
+
+	task := queued.Task
+	msg := NewCreateTaskCommand(queued.Topic, int32(c.selectPartition(ctx, queued.Topic)), &task)
+	if msg == nil {
+		return errMessageBuild
+	}
+	_, err := c.executeCommand(ctx, msg)
+	return err
+}
+
+// flushTaskQueue will flush the queue in the background, if there is anything queued.
+func (c *Client) flushTaskQueue() {
+	if queue := c.getTaskQueue(); queue != nil && queue.Len() > 0 {
+		c.spawn(func() {
+			if err := c.FlushTaskQueue(); err != nil {
+				c.log().Warn("Flushing task queue failed", F("error", err))
+			}
+		})
+	}
+}
+
+func (c *Client) observeQueue(depth int) {
+	if observer, ok := c.getObserver().(QueueObserver); ok {
+		observer.TasksQueued(depth)
+	}
+}
@@ -0,0 +1,100 @@
+package zbc_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SetTaskQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zbc-queue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tasks")
+
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Broker which doesn't respond is unreachable for the client.
+	var down int32 = 1
+	var mu sync.Mutex
+	var created []string
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		if atomic.LoadInt32(&down) == 1 {
+			return nil
+		}
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		mu.Lock()
+		created = append(created, task.Type)
+		mu.Unlock()
+		task.State = zbc.TaskCreated
+		return zbtest.CommandResponse(request, 1, &task)
+	})
+
+	queue, err := zbc.NewTaskQueue(path, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer queue.Close()
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithTaskQueue(queue), zbc.WithRequestTimeout(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	for _, taskType := range []string{"a", "b", "c"} {
+		if _, err := client.CreateTask("default-topic", &zbc.Task{Type: taskType, Retries: 3}); err != zbc.ErrTaskQueued {
+			t.Fatalf("Expected task %s queued, received %v", taskType, err)
+		}
+	}
+	if _, err := client.CreateTask("default-topic", &zbc.Task{Type: "d", Retries: 3}); err != zbc.ErrTaskQueueFull {
+		t.Fatalf("Expected full queue, received %v", err)
+	}
+
+	// Queue is loaded from its file after restart.
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path+"-copy", content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := zbc.NewTaskQueue(path+"-copy", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Len() != 3 {
+		t.Fatalf("Expected three tasks loaded from the file, received %d", reopened.Len())
+	}
+	reopened.Close()
+
+	atomic.StoreInt32(&down, 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for queue.Len() > 0 && time.Now().Before(deadline) {
+		if err := client.FlushTaskQueue(); err != nil {
+			t.Log(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(created) != 3 || created[0] != "a" || created[1] != "b" || created[2] != "c" {
+		t.Fatalf("Expected queued tasks created in order, received %v", created)
+	}
+}
@@ -0,0 +1,87 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SystemTopic and SystemPartitionID identify partition on which broker manages topics.
+const (
+	SystemTopic       = "internal-system"
+	SystemPartitionID = 0
+)
+
+// ErrTopicRejected is returned by CreateTopic once broker rejects creation of the topic, e.g. because it exists already.
+var ErrTopicRejected = errors.New("Topic creation rejected, topic exists already")
+
+// topicPollInterval is time between topology requests while waiting for partitions of the new topic.
+const topicPollInterval = 100 * time.Millisecond
+
+// Topic is event of topic management on the system topic.
+type Topic struct {
+	State      string `msgpack:"state" json:"state"`
+	Name       string `msgpack:"name" json:"name"`
+	Partitions int    `msgpack:"partitions" json:"partitions"`
+}
+
+// CreateTopic will create the topic with given number of partitions and wait until all of them have a leader, so
+// the topic is usable once it returns. If the request times out while waiting, created topic is returned together
+// with the error.
+func (c *Client) CreateTopic(name string, partitions int, opts ...RequestOption) (*Topic, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.CreateTopicCtx(ctx, name, partitions)
+}
+
+// CreateTopicCtx is same as CreateTopic, but request and waiting are aborted once ctx is done.
+func (c *Client) CreateTopicCtx(ctx context.Context, name string, partitions int) (*Topic, error) {
+	if len(name) == 0 {
+		return nil, &ValidationError{"name", "topic name is empty"}
+	}
+	if partitions < 1 {
+		return nil, &ValidationError{"partitions", fmt.Sprintf("%d partitions, topic needs at least one", partitions)}
+	}
+
+	msg := NewCreateTopicCommand(name, partitions)
+	if msg == nil {
+		return nil, errMessageBuild
+	}
+	response, err := c.executeCommand(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var topic Topic
+	if err := response.UnmarshalData(&topic); err != nil {
+		return nil, err
+	}
+	if topic.State != TopicCreated {
+		return nil, ErrTopicRejected
+	}
+	c.log().Info("Topic created, waiting for leaders of its partitions", F("topic", name), F("partitions", partitions))
+	return &topic, c.awaitTopic(ctx, name, partitions)
+}
+
+// awaitTopic will request topology until all partitions of the topic have a leader.
+func (c *Client) awaitTopic(ctx context.Context, name string, partitions int) error {
+	for {
+		topology, err := c.TopologyCtx(ctx)
+		if err != nil {
+			return err
+		}
+		if len(topology.Partitions(name)) >= partitions {
+			return nil
+		}
+
+		select {
+		case <-time.After(topicPollInterval):
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return ErrRequestTimeout
+			}
+			return ctx.Err()
+		}
+	}
+}
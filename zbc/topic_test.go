@@ -0,0 +1,84 @@
+package zbc_test
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_CreateTopic(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Partitions get their leaders one by one, with every topology request.
+	host, portStr, _ := net.SplitHostPort(broker.Addr())
+	port, _ := strconv.Atoi(portStr)
+	addr := zbc.BrokerAddress{Host: host, Port: port}
+	var created, requests int32
+	broker.HandleControl(sbe.ControlMessageType.REQUEST_TOPOLOGY, func(request *zbc.Message) zbtest.Response {
+		var leaders []zbc.TopicLeader
+		if atomic.LoadInt32(&created) > 0 {
+			n := atomic.AddInt32(&requests, 1)
+			for i := int32(0); i < n && i < 3; i++ {
+				leaders = append(leaders, zbc.TopicLeader{BrokerAddress: addr, TopicName: "orders", PartitionID: uint16(i)})
+			}
+		}
+		return zbtest.ControlResponse(&zbc.Topology{TopicLeaders: leaders, Brokers: []zbc.BrokerAddress{addr}})
+	})
+	broker.HandleCommand(sbe.EventType.TOPIC_EVENT, func(request *zbc.Message) zbtest.Response {
+		var topic zbc.Topic
+		request.UnmarshalData(&topic)
+		if atomic.AddInt32(&created, 1) > 1 {
+			topic.State = zbc.TopicCreateRejected
+		} else {
+			topic.State = zbc.TopicCreated
+		}
+		return zbtest.CommandResponse(request, 1, &topic)
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if _, err := client.CreateTopic("orders", 0); err == nil {
+		t.Fatal("Expected topic without partitions rejected")
+	}
+
+	topic, err := client.CreateTopic("orders", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic.Name != "orders" || topic.Partitions != 3 {
+		t.Fatalf("Expected topic orders with 3 partitions, received %+v", topic)
+	}
+	if n := atomic.LoadInt32(&requests); n < 3 {
+		t.Fatalf("Expected to wait until all partitions have a leader, topology requested %d times", n)
+	}
+	topology, err := client.Topology()
+	if err != nil || len(topology.Partitions("orders")) != 3 {
+		t.Fatalf("Expected 3 partitions of the topic, received %v, %v", topology, err)
+	}
+
+	for _, request := range broker.Received() {
+		if cmd, ok := (*request.SbeMessage).(*sbe.ExecuteCommandRequest); ok && cmd.EventType == sbe.EventType.TOPIC_EVENT {
+			if string(cmd.TopicName) != zbc.SystemTopic {
+				t.Fatalf("Expected command sent to the system topic, sent to %s", cmd.TopicName)
+			}
+		}
+	}
+
+	if _, err := client.CreateTopic("orders", 3); err != zbc.ErrTopicRejected {
+		t.Fatalf("Expected creation of existing topic rejected, received %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// topicAckInterval is how long handled events wait for the ack batch to fill up before they are acknowledged anyway.
+const topicAckInterval = time.Second
+
+// TopicEventHandler is invoked by TopicWorker for every event of the topic subscription. Event is acknowledged once
+// the handler returns nil, returned error is dealt with by TopicErrorPolicy of the TopicWorker.
+type TopicEventHandler func(event *Message) error
+
+// TopicErrorPolicy decides what TopicWorker does with an event on which its handler failed.
+type TopicErrorPolicy int
+
+const (
+	// TopicErrorStop stops the TopicWorker and closes its subscription. Events handled before are acknowledged, the
+	// failed one is not, so it's delivered again once subscription with the same name is opened. Err returns the error.
+	TopicErrorStop TopicErrorPolicy = iota
+
+	// TopicErrorSkip logs the error and acknowledges the event as if it was handled.
+	TopicErrorSkip
+
+	// TopicErrorRetry invokes the handler on the event again, with backoff of DefaultRetryPolicy, until it succeeds
+	// or the TopicWorker is stopped. Following events wait meanwhile, so their order is kept.
+	TopicErrorRetry
+)
+
+// TopicWorkerOption is used to configure TopicWorker.
+type TopicWorkerOption func(*TopicWorker)
+
+// WithSubscriptionName sets name under which broker remembers acknowledged position of the TopicWorker. Default is zbc.
+func WithSubscriptionName(name string) TopicWorkerOption {
+	return func(w *TopicWorker) {
+		w.subscription.Name = name
+	}
+}
+
+// WithTopicPartition sets partition on which TopicWorker opens subscription. Default is 0.
+func WithTopicPartition(partitionID int32) TopicWorkerOption {
+	return func(w *TopicWorker) {
+		w.subscription.PartitionID = partitionID
+	}
+}
+
+// WithStartPosition sets position at which subscription with a new name starts, TailPosition skips events written
+// before it is opened. Default is 0, the beginning of the topic.
+func WithStartPosition(position int64) TopicWorkerOption {
+	return func(w *TopicWorker) {
+		w.subscription.StartPosition = position
+	}
+}
+
+// WithCheckpoints sets CheckpointStore which saves acknowledged positions of the TopicWorker, see TopicSubscription.
+func WithCheckpoints(store CheckpointStore) TopicWorkerOption {
+	return func(w *TopicWorker) {
+		w.subscription.Checkpoints = store
+	}
+}
+
+// WithAckBatchSize sets number of handled events which are acknowledged by a single request. Events of an incomplete
+// batch are acknowledged a second after the first of them was handled and once TopicWorker stops. Default is 1,
+// every event is acknowledged on its own.
+func WithAckBatchSize(n int) TopicWorkerOption {
+	return func(w *TopicWorker) {
+		if n > 0 {
+			w.ackBatch = n
+		}
+	}
+}
+
+// WithErrorPolicy sets what TopicWorker does when its handler fails. Default is TopicErrorStop.
+func WithErrorPolicy(policy TopicErrorPolicy) TopicWorkerOption {
+	return func(w *TopicWorker) {
+		w.errorPolicy = policy
+	}
+}
+
+// TopicWorker passes events of a topic subscription to TopicEventHandler one by one, in order of their positions,
+// and acknowledges them once they are handled. If connection breaks, subscription is reopened after the acknowledged
+// position, so events handled but not acknowledged yet are handled again.
+type TopicWorker struct {
+	client       *Client
+	handler      TopicEventHandler
+	subscription *TopicSubscription
+	ackBatch     int
+	errorPolicy  TopicErrorPolicy
+
+	sub *Subscription
+
+	errMu sync.Mutex
+	err   error // Error of the handler which stopped the TopicWorker.
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// SubscribeTopic will open topic subscription and pass its events to the handler in the background, until the
+// returned TopicWorker is stopped or its handler fails with TopicErrorStop policy.
+func (c *Client) SubscribeTopic(topic string, handler TopicEventHandler, opts ...TopicWorkerOption) (*TopicWorker, error) {
+	ctx, cancel := c.requestContext()
+	defer cancel()
+	return c.SubscribeTopicCtx(ctx, topic, handler, opts...)
+}
+
+// SubscribeTopicCtx is same as SubscribeTopic, but opening of the subscription is aborted once ctx is done.
+func (c *Client) SubscribeTopicCtx(ctx context.Context, topic string, handler TopicEventHandler, opts ...TopicWorkerOption) (*TopicWorker, error) {
+	w := &TopicWorker{
+		client:  c,
+		handler: handler,
+		subscription: &TopicSubscription{
+			TopicName:        topic,
+			Name:             "zbc",
+			PrefetchCapacity: 32,
+		},
+		ackBatch: 1,
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	sub, err := c.openTopicSubscription(ctx, w.subscription, nil)
+	if err != nil {
+		return nil, err
+	}
+	w.sub = sub
+	c.addWorker(w)
+
+	go w.work()
+	return w, nil
+}
+
+// Done returns channel which is closed once the TopicWorker stops, either by Stop or because its handler failed.
+func (w *TopicWorker) Done() <-chan struct{} {
+	return w.done
+}
+
+// Err returns error of the handler which stopped the TopicWorker, or nil if it's running or was stopped by Stop.
+func (w *TopicWorker) Err() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+	return w.err
+}
+
+// Stop will stop passing events to the handler, wait for the running one to return, acknowledge handled events
+// and close the subscription.
+func (w *TopicWorker) Stop() {
+	w.StopCtx(context.Background())
+}
+
+// StopCtx is same as Stop, but waiting is given up once ctx is done.
+func (w *TopicWorker) StopCtx(ctx context.Context) error {
+	w.stopOnce.Do(func() {
+		close(w.stopCh)
+	})
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *TopicWorker) work() {
+	defer close(w.done)
+	defer w.client.removeWorker(w)
+
+	var last *sbe.SubscribedEvent // Last handled event which is not acknowledged yet.
+	pending := 0
+	ack := func() {
+		if last == nil {
+			return
+		}
+		ctx, cancel := w.client.requestContext()
+		defer cancel()
+		if _, err := w.sub.acknowledge(ctx, last); err != nil {
+			// Positions only grow, so the next acknowledgement covers these events too.
+			w.client.log().Warn("Acknowledging topic events failed", F("subscription", w.sub), F("position", last.Position), F("error", err))
+			return
+		}
+		last, pending = nil, 0
+	}
+	defer func() {
+		ack()
+		ctx, cancel := w.client.requestContext()
+		defer cancel()
+		w.sub.CloseCtx(ctx)
+	}()
+
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-flush:
+			flush = nil
+			ack()
+		case message, ok := <-w.sub.Events():
+			if !ok {
+				return
+			}
+			event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+			if !w.handle(message, event) {
+				return
+			}
+
+			last = event
+			pending++
+			if pending >= w.ackBatch {
+				flush = nil
+				ack()
+			} else if flush == nil {
+				flush = time.After(topicAckInterval)
+			}
+		}
+	}
+}
+
+// handle will pass the event to the handler and apply the error policy. False is returned if the TopicWorker must stop
+// without acknowledging the event.
+func (w *TopicWorker) handle(message *Message, event *sbe.SubscribedEvent) bool {
+	for attempt := 1; ; attempt++ {
+		var err error
+		withLabels(context.Background(), func(context.Context) {
+			err = w.invoke(message)
+		}, "topic", w.subscription.TopicName, "subscription", w.subscription.Name)
+		if err == nil {
+			return true
+		}
+
+		switch w.errorPolicy {
+		case TopicErrorSkip:
+			w.client.log().Warn("Handler failed, event is skipped", F("subscription", w.sub), F("position", event.Position), F("error", err))
+			return true
+
+		case TopicErrorRetry:
+			backoff := DefaultRetryPolicy.Backoff(attempt + 1)
+			w.client.log().Warn("Handler failed, retrying", F("subscription", w.sub), F("position", event.Position), F("backoff", backoff), F("error", err))
+			select {
+			case <-w.stopCh:
+				return false
+			case <-time.After(backoff):
+			}
+
+		default:
+			w.client.log().Error("Handler failed, topic worker is stopped", F("subscription", w.sub), F("position", event.Position), F("error", err))
+			w.errMu.Lock()
+			w.err = err
+			w.errMu.Unlock()
+			return false
+		}
+	}
+}
+
+// invoke will call the handler and turn panic into an error, which is dealt with by the error policy.
+func (w *TopicWorker) invoke(message *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return w.handler(message)
+}
@@ -0,0 +1,111 @@
+package zbc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestTopicWorker_ErrorPolicy(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	cases := []struct {
+		policy   zbc.TopicErrorPolicy
+		handled  []uint64 // Keys of the events passed to the handler.
+		acks     int
+		stopped  bool
+		ackBatch int
+	}{
+		{zbc.TopicErrorStop, []uint64{1, 2}, 1, true, 1},
+		{zbc.TopicErrorSkip, []uint64{1, 2, 3}, 3, false, 1},
+		{zbc.TopicErrorRetry, []uint64{1, 2, 2, 3}, 3, false, 1},
+		{zbc.TopicErrorSkip, []uint64{1, 2, 3}, 1, false, 3},
+	}
+	for _, c := range cases {
+		broker, err := zbtest.NewMockBroker()
+		if err != nil {
+			t.Fatal(err)
+		}
+		acks := make(chan map[string]interface{}, 10)
+		broker.HandleCommand(sbe.EventType.SUBSCRIPTION_EVENT, func(request *zbc.Message) zbtest.Response {
+			acks <- *request.Data
+			return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.SubscriptionAcknowledged})
+		})
+		client, err := zbc.NewClient(broker.Addr())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handled := make(chan uint64, 10)
+		failed := false
+		worker, err := client.SubscribeTopic("default-topic", func(message *zbc.Message) error {
+			key := (*message.SbeMessage).(*sbe.SubscribedEvent).Key
+			handled <- key
+			if key == 2 && !failed {
+				failed = true
+				return errHandler
+			}
+			return nil
+		}, zbc.WithSubscriptionName("audit"), zbc.WithErrorPolicy(c.policy), zbc.WithAckBatchSize(c.ackBatch))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key := uint64(1); key <= 3; key++ {
+			if err := broker.PushTopicEvent("default-topic", key, sbe.EventType.TASK_EVENT, map[string]interface{}{"state": zbc.TaskCreated}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for _, expected := range c.handled {
+			select {
+			case key := <-handled:
+				if key != expected {
+					t.Fatalf("Policy %d: expected event %d, handled %d", c.policy, expected, key)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Policy %d: event %d not handled", c.policy, expected)
+			}
+		}
+
+		if c.stopped {
+			select {
+			case <-worker.Done():
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Policy %d: expected topic worker to stop", c.policy)
+			}
+			if worker.Err() != errHandler {
+				t.Fatalf("Policy %d: expected handler error, received %v", c.policy, worker.Err())
+			}
+		} else {
+			for len(acks) < c.acks {
+				time.Sleep(10 * time.Millisecond)
+			}
+			worker.Stop()
+			if worker.Err() != nil {
+				t.Fatalf("Policy %d: expected no error, received %v", c.policy, worker.Err())
+			}
+		}
+
+		// Events left in the batch are acknowledged when the worker stops.
+		if len(acks) != c.acks {
+			t.Fatalf("Policy %d with batch %d: expected %d acknowledgements, received %d", c.policy, c.ackBatch, c.acks, len(acks))
+		}
+		if ack := <-acks; ack["name"] != "audit" || ack["state"] != zbc.SubscriptionAcknowledge {
+			t.Fatalf("Expected acknowledgement of audit subscription, received %v", ack)
+		}
+		select {
+		case key := <-handled:
+			t.Fatalf("Policy %d: unexpected event %d handled", c.policy, key)
+		default:
+		}
+
+		client.Close(context.Background())
+		broker.Close()
+	}
+}
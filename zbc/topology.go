@@ -0,0 +1,198 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errUnexpectedResponse = errors.New("Received unexpected response")
+
+// BrokerAddress is address of one broker in the cluster.
+type BrokerAddress struct {
+	Host string `msgpack:"host" json:"host"`
+	Port int    `msgpack:"port" json:"port"`
+}
+
+func (b BrokerAddress) String() string {
+	return fmt.Sprintf("%s:%d", b.Host, b.Port)
+}
+
+// TopicLeader is the broker which leads one partition of the topic.
+type TopicLeader struct {
+	BrokerAddress `msgpack:",inline"`
+	TopicName     string `msgpack:"topicName" json:"topicName"`
+	PartitionID   uint16 `msgpack:"partitionId" json:"partitionId"`
+}
+
+// Topology describes brokers in the cluster and leaders of all partitions.
+type Topology struct {
+	TopicLeaders []TopicLeader   `msgpack:"topicLeaders" json:"topicLeaders"`
+	Brokers      []BrokerAddress `msgpack:"brokers" json:"brokers"`
+}
+
+// Leader will return address of the broker leading partition of the topic.
+func (t *Topology) Leader(topic string, partitionID uint16) (string, bool) {
+	for _, leader := range t.TopicLeaders {
+		if leader.TopicName == topic && leader.PartitionID == partitionID {
+			return leader.String(), true
+		}
+	}
+	return "", false
+}
+
+// Partitions will return sorted IDs of all partitions of the topic which have a leader.
+func (t *Topology) Partitions(topic string) []uint16 {
+	var partitions []uint16
+	for _, leader := range t.TopicLeaders {
+		if leader.TopicName == topic {
+			partitions = append(partitions, leader.PartitionID)
+		}
+	}
+	sort.Sort(partitionIDs(partitions))
+	return partitions
+}
+
+type partitionIDs []uint16
+
+func (p partitionIDs) Len() int           { return len(p) }
+func (p partitionIDs) Less(i, j int) bool { return p[i] < p[j] }
+func (p partitionIDs) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+func (t *Topology) hasTopic(topic string) bool {
+	for _, leader := range t.TopicLeaders {
+		if leader.TopicName == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Topology will request topology of the cluster and cache it, so commands can be sent to leaders of their partitions.
+func (c *Client) Topology(opts ...RequestOption) (*Topology, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.TopologyCtx(ctx)
+}
+
+// TopologyCtx is same as Topology, but request is aborted once ctx is done.
+func (c *Client) TopologyCtx(ctx context.Context) (*Topology, error) {
+	var topology *Topology
+	err := c.retry(ctx, false, func() error {
+		var err error
+		topology, err = c.requestTopology(ctx)
+		return err
+	})
+	return topology, err
+}
+
+// requestTopology will request topology once, without retries. Health checks use it directly, so broken connections are noticed.
+func (c *Client) requestTopology(ctx context.Context) (*Topology, error) {
+	response, err := c.sendControl(ctx, NewControlMessageRequest(sbe.ControlMessageType.REQUEST_TOPOLOGY))
+	if err != nil {
+		return nil, err
+	}
+
+	var topology Topology
+	if err := response.UnmarshalData(&topology); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.topology = &topology
+	if len(topology.Brokers) > 0 {
+		c.seeds = c.seeds[:0]
+		for _, broker := range topology.Brokers {
+			c.seeds = append(c.seeds, broker.String())
+		}
+	}
+	c.mu.Unlock()
+	return &topology, nil
+}
+
+// cachedTopology returns topology of the cluster. It is requested on first use.
+func (c *Client) cachedTopology(ctx context.Context) *Topology {
+	c.mu.Lock()
+	topology := c.topology
+	c.mu.Unlock()
+
+	if topology == nil {
+		var err error
+		if topology, err = c.TopologyCtx(ctx); err != nil {
+			// Don't ask again for every command, topology is refreshed once broker tells us it's not the leader.
+			c.log().Warn("Requesting topology failed", F("error", err))
+			topology = &Topology{}
+			c.mu.Lock()
+			c.topology = topology
+			c.mu.Unlock()
+		}
+	}
+	return topology
+}
+
+// leaderClient returns pooled connection to the leader of the partition. Topology is requested on first use.
+// If the leader is not known, this client is used.
+func (c *Client) leaderClient(ctx context.Context, topic string, partitionID uint16) (*Client, error) {
+	addr, ok := c.cachedTopology(ctx).Leader(topic, partitionID)
+	if !ok || c.pool == nil {
+		return c, nil
+	}
+	return c.pool.Client(addr)
+}
+
+// executeCommand will send command to the leader of its partition. If broker is not leading the partition anymore,
+// topology is refreshed and command is sent once more. Failed commands are retried if RetryPolicy allows it. Commands
+// with request key are deduplicated, see SetDeduplicationWindow. Every attempt is subject to RateLimit.
+func (c *Client) executeCommand(ctx context.Context, message *Message) (*Message, error) {
+	if !isRepeatable(ctx, (*message.SbeMessage).(*sbe.ExecuteCommandRequest)) {
+		return c.executeCommandOnce(ctx, message)
+	}
+
+	return c.deduplicate(ctx, func() (*Message, error) {
+		var response *Message
+		err := c.retry(ctx, true, func() error {
+			var err error
+			response, err = c.executeCommandOnce(ctx, message)
+			return err
+		})
+		return response, err
+	})
+}
+
+func (c *Client) executeCommandOnce(ctx context.Context, message *Message) (*Message, error) {
+	if err := c.takeRateToken(ctx); err != nil {
+		return nil, err
+	}
+
+	cmdReq := (*message.SbeMessage).(*sbe.ExecuteCommandRequest)
+	topic, partitionID := string(cmdReq.TopicName), cmdReq.PartitionId
+
+	client, err := c.leaderClient(ctx, topic, partitionID)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.ResponderCtx(ctx, message)
+	if !isNotLeader(err) {
+		return response, err
+	}
+	c.log().Debug("Broker is not leader, retrying with refreshed topology", F("addr", client.addr), F("topic", topic), F("partition", partitionID))
+
+	topology, err := c.TopologyCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if client, err = c.leaderClient(ctx, topic, partitionID); err != nil {
+		return nil, err
+	}
+
+	response, err = client.ResponderCtx(ctx, message)
+	if isNotLeader(err) && topology.hasTopic(topic) {
+		// Topic exists, so it's the partition which is missing.
+		err.(*BrokerError).Kind = ErrPartitionNotFound
+	}
+	return response, err
+}
@@ -0,0 +1,49 @@
+package zbc_test
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestNewClusterClient(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Nothing listens on the first seed, client must bootstrap from the second one.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	down := listener.Addr().String()
+	listener.Close()
+
+	client, err := zbc.NewClusterClient([]string{down, broker.Addr()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	if seeds := client.Seeds(); !reflect.DeepEqual(seeds, []string{down, broker.Addr()}) {
+		t.Fatalf("Expected seeds given to the constructor, received %v", seeds)
+	}
+
+	topology, err := client.Topology()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seeds := client.Seeds(); len(seeds) != 1 || seeds[0] != topology.Brokers[0].String() {
+		t.Fatalf("Expected seeds updated from topology, received %v", seeds)
+	}
+
+	if _, err := zbc.NewClusterClient([]string{down}); err == nil {
+		t.Fatal("Expected error when no seed is reachable")
+	}
+}
@@ -0,0 +1,93 @@
+package zbc
+
+import "context"
+
+// TraceHeader is the key under which trace context is carried in task headers and workflow instance payload.
+const TraceHeader = "zbcTrace"
+
+// Tracer propagates trace context across Zeebe, so traces of the services creating tasks and workflow instances
+// continue in the Workers handling them. Client injects trace context of ctx when creating tasks and workflow
+// instances, Worker extracts it before invoking the handler. Carrier is a map of strings stored under TraceHeader.
+// Inject is called by every request creating tasks or instances and Extract by every handler the Workers run in
+// parallel, so spans of several tasks are open at the same time.
+type Tracer interface {
+	// Inject will write trace context of ctx into carrier.
+	Inject(ctx context.Context, carrier map[string]string)
+
+	// Extract will return ctx continuing trace context from carrier, which is empty if task wasn't traced.
+	// Returned finish is called with the result of the handler once it returns.
+	Extract(ctx context.Context, carrier map[string]string, task *Task) (context.Context, func(err error))
+}
+
+// SetTracer is a setter for Tracer. Trace context is not propagated by default.
+func (c *Client) SetTracer(tracer Tracer) {
+	c.mu.Lock()
+	c.tracer = tracer
+	c.mu.Unlock()
+}
+
+func (c *Client) getTracer() Tracer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tracer
+}
+
+// injectTrace returns copy of values with trace context of ctx under TraceHeader. Values are returned unchanged
+// if there is no Tracer.
+func (c *Client) injectTrace(ctx context.Context, values map[string]interface{}) map[string]interface{} {
+	tracer := c.getTracer()
+	if tracer == nil {
+		return values
+	}
+
+	carrier := make(map[string]string)
+	tracer.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return values
+	}
+
+	traced := make(map[string]interface{}, len(values)+1)
+	for k, v := range values {
+		traced[k] = v
+	}
+	traced[TraceHeader] = carrier
+	return traced
+}
+
+// extractCarrier reads carrier from task headers, or from payload for tasks created by workflow instances.
+// Second return value tells if carrier was found in the payload.
+func extractCarrier(task *Task) (map[string]string, bool) {
+	if carrier := toCarrier(task.Headers[TraceHeader]); carrier != nil {
+		return carrier, false
+	}
+	if carrier := toCarrier(task.PayloadJson[TraceHeader]); carrier != nil {
+		return carrier, true
+	}
+	return map[string]string{}, false
+}
+
+// toCarrier converts carrier decoded from msgpack, which has keys of type interface{}.
+func toCarrier(v interface{}) map[string]string {
+	carrier := make(map[string]string)
+	switch m := v.(type) {
+	case map[string]string:
+		return m
+	case map[string]interface{}:
+		for k, v := range m {
+			if s, ok := v.(string); ok {
+				carrier[k] = s
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			ks, ok := k.(string)
+			vs, ok2 := v.(string)
+			if ok && ok2 {
+				carrier[ks] = vs
+			}
+		}
+	default:
+		return nil
+	}
+	return carrier
+}
@@ -0,0 +1,14 @@
+// Package tracing propagates OpenTracing spans through tasks and workflow instances. OpenTracing library is not
+// vendored, so the package is built only with the opentracing build tag:
+//
+//	go get github.com/opentracing/opentracing-go
+//	go build -tags opentracing
+//
+// Usage:
+//
+//	client.SetTracer(tracing.New(opentracing.GlobalTracer()))
+//
+// Span of ctx passed to CreateTaskCtx or CreateWorkflowInstanceCtx becomes parent of the span Worker starts for
+// the handler, which is available through task.Context(). Other tracing libraries, like OpenTelemetry, can be
+// plugged in by implementing zbc.Tracer with their propagators.
+package tracing
@@ -0,0 +1,53 @@
+//go:build opentracing
+// +build opentracing
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/zeebe-io/zbc-go/zbc"
+)
+
+// Tracer is zbc.Tracer which injects and extracts spans of OpenTracing tracer in text map format.
+type Tracer struct {
+	tracer opentracing.Tracer
+}
+
+// New is constructor for Tracer.
+func New(tracer opentracing.Tracer) *Tracer {
+	return &Tracer{tracer: tracer}
+}
+
+// Inject will write context of the span in ctx into carrier. Nothing is written if ctx has no span.
+func (t *Tracer) Inject(ctx context.Context, carrier map[string]string) {
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	t.tracer.Inject(span.Context(), opentracing.TextMap, opentracing.TextMapCarrier(carrier))
+}
+
+// Extract will start span of the handler, child of the span read from carrier if there is one.
+// Span is finished once handler returns and marked as error if handler failed.
+func (t *Tracer) Extract(ctx context.Context, carrier map[string]string, task *zbc.Task) (context.Context, func(err error)) {
+	opts := []opentracing.StartSpanOption{
+		ext.SpanKindConsumer,
+		opentracing.Tag{Key: "zeebe.task.type", Value: task.Type},
+		opentracing.Tag{Key: "zeebe.task.retries", Value: task.Retries},
+	}
+	if parent, err := t.tracer.Extract(opentracing.TextMap, opentracing.TextMapCarrier(carrier)); err == nil {
+		opts = append(opts, opentracing.ChildOf(parent))
+	}
+
+	span := t.tracer.StartSpan("zeebe task "+task.Type, opts...)
+	return opentracing.ContextWithSpan(ctx, span), func(err error) {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogKV("event", "error", "message", err.Error())
+		}
+		span.Finish()
+	}
+}
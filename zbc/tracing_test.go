@@ -0,0 +1,89 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+type traceKey struct{}
+
+type testTracer struct {
+	finished chan error
+}
+
+func (t *testTracer) Inject(ctx context.Context, carrier map[string]string) {
+	if id, ok := ctx.Value(traceKey{}).(string); ok {
+		carrier["trace-id"] = id
+	}
+}
+
+func (t *testTracer) Extract(ctx context.Context, carrier map[string]string, task *zbc.Task) (context.Context, func(error)) {
+	return context.WithValue(ctx, traceKey{}, carrier["trace-id"]), func(err error) { t.finished <- err }
+}
+
+func TestClient_SetTracer(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	created := make(chan *zbc.Task, 1)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		if err := request.UnmarshalData(&task); err != nil {
+			t.Error(err)
+		}
+		if task.State == "CREATE" {
+			created <- &task
+			return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "CREATED"})
+		}
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "COMPLETED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+	tracer := &testTracer{finished: make(chan error, 1)}
+	client.SetTracer(tracer)
+
+	handled := make(chan string, 1)
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		id, _ := task.Context().Value(traceKey{}).(string)
+		handled <- id
+		return nil, nil
+	})
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "42")
+	if _, err := client.CreateTaskCtx(ctx, "default-topic", &zbc.Task{State: "CREATE", Type: "foo", Retries: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	task := <-created
+	task.State = "LOCKED"
+	if err := broker.PushTask(2, task); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case id := <-handled:
+		if id != "42" {
+			t.Fatalf("Expected trace 42 in handler, received %q", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Task was not handled")
+	}
+	if err := <-tracer.finished; err != nil {
+		t.Fatalf("Expected handler to succeed, received %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package zbc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validator checks commands before Client encodes and sends them, so mistakes are reported with descriptive errors
+// instead of rejections of the broker. Tasks are validated by CreateTask, FailTask and UpdateTaskRetries, workflow
+// instances by CreateWorkflowInstance and payloads by CompleteTask, ResolveIncident and UpdateWorkflowInstancePayload.
+// Methods are called from the goroutines calling the Client, so they must be safe for concurrent use.
+type Validator interface {
+	ValidateTask(task *Task) error
+	ValidateWorkflowInstance(wf *WorkflowInstance) error
+	ValidatePayload(payload map[string]interface{}) error
+}
+
+// ValidationError is returned when Validator rejects a command. Field is name of the attribute which is invalid.
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Invalid %s: %s", e.Field, e.Reason)
+}
+
+// DefaultValidator requires task type and BPMN process ID, retries not less than zero and payloads which hold only
+// values encodable as message pack.
+type DefaultValidator struct{}
+
+// ValidateTask checks type, retries, names of custom headers and payload of the task.
+func (DefaultValidator) ValidateTask(task *Task) error {
+	if len(task.Type) == 0 {
+		return &ValidationError{"type", "task type is empty"}
+	}
+	if task.Retries < 0 {
+		return &ValidationError{"retries", fmt.Sprintf("retries %d are negative", task.Retries)}
+	}
+	if _, ok := task.CustomHeaders[""]; ok {
+		return &ValidationError{"customHeaders", "header name is empty"}
+	}
+	return validatePayload("payload", reflect.ValueOf(task.PayloadJson))
+}
+
+// ValidateWorkflowInstance checks BPMN process ID, version and payload of the workflow instance.
+func (DefaultValidator) ValidateWorkflowInstance(wf *WorkflowInstance) error {
+	if len(wf.BpmnProcessId) == 0 {
+		return &ValidationError{"bpmnProcessId", "BPMN process ID is empty"}
+	}
+	if wf.Version < -1 {
+		return &ValidationError{"version", fmt.Sprintf("version %d is neither latest (-1) nor a deployed one", wf.Version)}
+	}
+	return validatePayload("payload", reflect.ValueOf(wf.PayloadJson))
+}
+
+// ValidatePayload checks that all values of the payload can be encoded.
+func (DefaultValidator) ValidatePayload(payload map[string]interface{}) error {
+	return validatePayload("payload", reflect.ValueOf(payload))
+}
+
+// validatePayload will walk the value and reject functions, channels, complex numbers and unsafe pointers, which
+// have no representation in message pack or JSON. Path of the rejected value is reported.
+func validatePayload(path string, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return validatePayload(path, v.Elem())
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			if err := validatePayload(fmt.Sprintf("%s.%v", path, key.Interface()), v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validatePayload(fmt.Sprintf("%s[%d]", path, i), v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Func, reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		return &ValidationError{path, fmt.Sprintf("%s cannot be encoded", v.Type())}
+	}
+	return nil
+}
+
+// SetValidator is a setter for Validator checking commands before they are sent. Nil, the default, disables validation.
+func (c *Client) SetValidator(validator Validator) {
+	c.mu.Lock()
+	c.validator = validator
+	c.mu.Unlock()
+}
+
+func (c *Client) getValidator() Validator {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.validator
+}
+
+// validate will call check with Validator of the client, if there is one.
+func (c *Client) validate(check func(v Validator) error) error {
+	validator := c.getValidator()
+	if validator == nil {
+		return nil
+	}
+	return check(validator)
+}
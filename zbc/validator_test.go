@@ -0,0 +1,59 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_SetValidator(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.TaskCreated})
+	})
+
+	client, err := zbc.NewClient(broker.Addr(), zbc.WithValidator(zbc.DefaultValidator{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	invalid := []struct {
+		field string
+		task  *zbc.Task
+	}{
+		{"type", &zbc.Task{}},
+		{"retries", &zbc.Task{Type: "foo", Retries: -1}},
+		{"customHeaders", &zbc.Task{Type: "foo", CustomHeaders: map[string]string{"": "eu"}}},
+		{"payload.order[1]", &zbc.Task{Type: "foo", PayloadJson: map[string]interface{}{
+			"order": []interface{}{1, func() {}},
+		}}},
+	}
+	for _, c := range invalid {
+		_, err := client.CreateTask("default-topic", c.task)
+		if validationErr, ok := err.(*zbc.ValidationError); !ok || validationErr.Field != c.field {
+			t.Fatalf("Expected invalid %s, received %v", c.field, err)
+		}
+	}
+
+	if _, err := client.CreateTask("default-topic", &zbc.Task{Type: "foo", Retries: 3}); err != nil {
+		t.Fatal(err)
+	}
+	commands := 0
+	for _, request := range broker.Received() {
+		if _, ok := (*request.SbeMessage).(*sbe.ExecuteCommandRequest); ok {
+			commands++
+		}
+	}
+	if commands != 1 {
+		t.Fatalf("Expected only valid command sent, broker received %d", commands)
+	}
+}
@@ -0,0 +1,53 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// BrokerProtocol describes protocol spoken by the broker, as read from headers of its response. Broker doesn't report
+// its release version, compatibility with the client is decided by the SBE schema. Responses of brokers speaking
+// schema versions which the client doesn't decode are not understood at all, requests to them fail with
+// UnsupportedSchemaError.
+type BrokerProtocol struct {
+	Address       string `json:"address"`
+	ProtocolID    uint16 `json:"protocolId"`
+	SchemaID      uint16 `json:"schemaId"`
+	SchemaVersion uint16 `json:"schemaVersion"`
+}
+
+// Compatible tells if the broker speaks the SBE schema of the client in one of SupportedSchemaVersions.
+func (p *BrokerProtocol) Compatible() bool {
+	return checkSchema(&sbe.MessageHeader{SchemaId: p.SchemaID, Version: p.SchemaVersion}) == nil
+}
+
+// BrokerProtocol will send topology request to the connected broker and return protocol of its response.
+func (c *Client) BrokerProtocol(opts ...RequestOption) (*BrokerProtocol, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.BrokerProtocolCtx(ctx)
+}
+
+// BrokerProtocolCtx is same as BrokerProtocol, but request is aborted once ctx is done. Protocol of broker speaking
+// unsupported schema version is returned too, it's not Compatible then.
+func (c *Client) BrokerProtocolCtx(ctx context.Context) (*BrokerProtocol, error) {
+	response, err := c.ResponderCtx(ctx, NewTopologyRequestMessage())
+	if schemaErr, ok := err.(*UnsupportedSchemaError); ok {
+		return &BrokerProtocol{Address: c.addr, SchemaID: schemaErr.SchemaID, SchemaVersion: schemaErr.Version}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Headers == nil || response.Headers.SbeMessageHeader == nil {
+		return nil, errUnexpectedResponse
+	}
+
+	protocol := &BrokerProtocol{
+		Address:       c.addr,
+		SchemaID:      response.Headers.SbeMessageHeader.SchemaId,
+		SchemaVersion: response.Headers.SbeMessageHeader.Version,
+	}
+	protocol.ProtocolID, _ = response.Headers.ProtocolID()
+	return protocol, nil
+}
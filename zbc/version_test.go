@@ -0,0 +1,71 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_BrokerProtocol(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	protocol, err := client.BrokerProtocol()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !protocol.Compatible() || protocol.Address != broker.Addr() || protocol.SchemaVersion != zbc.SchemaVersion {
+		t.Fatalf("Expected broker speaking schema version %d, received %+v", zbc.SchemaVersion, protocol)
+	}
+
+	protocol.SchemaVersion = 0
+	if protocol.Compatible() {
+		t.Fatal("Expected broker with other schema version incompatible")
+	}
+}
+
+func TestClient_UnsupportedSchemaVersion(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	broker.SetSchemaVersion(zbc.SchemaVersion + 1)
+	protocol, err := client.BrokerProtocol()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol.Compatible() || protocol.SchemaVersion != zbc.SchemaVersion+1 {
+		t.Fatalf("Expected incompatible broker speaking schema version %d, received %+v", zbc.SchemaVersion+1, protocol)
+	}
+
+	// Request fails with the schema of the response instead of timing out.
+	start := time.Now()
+	_, err = client.Topology(zbc.TimeoutOption(5 * time.Second))
+	schemaErr, ok := err.(*zbc.UnsupportedSchemaError)
+	if !ok || zbc.Cause(err) != zbc.ErrUnsupportedSchemaVersion || schemaErr.Version != zbc.SchemaVersion+1 {
+		t.Fatalf("Expected UnsupportedSchemaError, received %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected request to fail at once, failed after %s", elapsed)
+	}
+}
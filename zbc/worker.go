@@ -0,0 +1,347 @@
+package zbc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// TaskHandler is invoked by Worker for every locked task. Returned payload is used to complete the task, returned error will fail it.
+// Trace context extracted by Tracer is available through task.Context().
+type TaskHandler func(task *Task) (map[string]interface{}, error)
+
+// WorkerOption is used to configure Worker.
+type WorkerOption func(*Worker)
+
+// WithTopic sets topic on which Worker opens subscription. Default is default-topic.
+func WithTopic(topic string) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.TopicName = topic
+	}
+}
+
+// WithPartition sets partition on which Worker opens subscription. Default is 0, AllPartitions handles tasks of every
+// partition of the topic.
+func WithPartition(partitionID int32) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.PartitionID = partitionID
+	}
+}
+
+// WithLockOwner sets owner of the locks Worker acquires on tasks. Default is zbc.
+func WithLockOwner(lockOwner string) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.LockOwner = lockOwner
+	}
+}
+
+// WithLockDuration sets how long task stays locked for the Worker. Default is 5 minutes.
+func WithLockDuration(d time.Duration) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.LockDuration = uint64(d / time.Millisecond)
+	}
+}
+
+// WithCredits sets number of tasks broker can push to the Worker before they are handled. Default is 32.
+func WithCredits(credits int32) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.Credits = credits
+	}
+}
+
+// WithBuffer sets number of tasks kept for the Worker until handlers take them and what happens to tasks arriving
+// when the buffer is full. Default is as many as credits, which broker never exceeds.
+func WithBuffer(size int32, overflow OverflowPolicy) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.BufferSize = size
+		w.subscription.Overflow = overflow
+	}
+}
+
+// WithFilters sets filters of tasks handled by the Worker, e.g. CustomHeaderFilter to route tasks of one type to
+// workers by their custom headers. Filtered tasks stay locked by the Worker until the lock expires, see EventFilter.
+func WithFilters(filters ...EventFilter) WorkerOption {
+	return func(w *Worker) {
+		w.subscription.Filters = append(w.subscription.Filters, filters...)
+	}
+}
+
+// WithCodec sets Codec of payloads of the tasks handled by the Worker. Default is Codec of the Client.
+func WithCodec(codec Codec) WorkerOption {
+	return func(w *Worker) {
+		w.codec = codec
+	}
+}
+
+// WithMaxConcurrentJobs sets number of handlers running in parallel. Default is 1. Tasks are dispatched to n goroutines,
+// but a task locked again while its handler runs is never handled twice at the same time. Credits are raised to n
+// if they are lower, so that no goroutine is left idle.
+func WithMaxConcurrentJobs(n int) WorkerOption {
+	return func(w *Worker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// Worker consumes tasks of one type and passes them to TaskHandler. Task is completed when handler succeeds and failed
+// when handler returns an error. Credits are given back to the broker once handlers are done with the tasks, so broker
+// never pushes more tasks than credits while they wait for or run in handlers.
+// Broker has no command to extend a lock, so handler should return before lock duration passes. Otherwise broker
+// locks the task again, possibly for this Worker. Such task is not handled twice, result of the running handler
+// is reported with the new lock.
+type Worker struct {
+	client       *Client
+	handler      TaskHandler
+	subscription *TaskSubscription
+	concurrency  int
+	codec        Codec
+	deadLetter   *DeadLetterPolicy
+
+	inputMappings  []Mapping
+	outputMappings []Mapping
+
+	sub *Subscription
+
+	runningMu sync.Mutex
+	running   map[uint64]*sbe.SubscribedEvent // Latest event of the tasks being handled by key.
+	failures  map[uint64]int                  // Failures of the handler by key of the task, counted for DeadLetterPolicy.
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewWorker is constructor for Worker. Worker will not receive any tasks until it is started.
+func (c *Client) NewWorker(taskType string, handler TaskHandler, opts ...WorkerOption) *Worker {
+	w := &Worker{
+		client:  c,
+		handler: handler,
+		subscription: &TaskSubscription{
+			TopicName:    "default-topic",
+			PartitionID:  0,
+			TaskType:     taskType,
+			LockDuration: 300000,
+			LockOwner:    "zbc",
+			Credits:      32,
+		},
+		concurrency: 1,
+		running:     make(map[uint64]*sbe.SubscribedEvent),
+		failures:    make(map[uint64]int),
+		stopCh:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Start will open task subscription and start handling tasks in the background.
+func (w *Worker) Start() error {
+	ctx, cancel := w.client.requestContext()
+	defer cancel()
+
+	if w.subscription.Credits < int32(w.concurrency) {
+		w.subscription.Credits = int32(w.concurrency)
+	}
+	sub, err := w.client.subscribe(ctx, w.subscription, true)
+	if err != nil {
+		return err
+	}
+	w.sub = sub
+	w.client.addWorker(w)
+
+	for i := 0; i < w.concurrency; i++ {
+		w.wg.Add(1)
+		go w.work()
+	}
+	return nil
+}
+
+// Stop will close the task subscription, stop handling new tasks and wait for running handlers to return.
+func (w *Worker) Stop() {
+	w.StopCtx(context.Background())
+}
+
+// StopCtx is same as Stop, but waiting for running handlers is given up once ctx is done. Tasks which were
+// pushed to the Worker but not handled yet are left to the broker, which unlocks them once lock duration passes.
+func (w *Worker) StopCtx(ctx context.Context) error {
+	w.stopOnce.Do(func() {
+		if w.sub != nil {
+			closeCtx, cancel := context.WithTimeout(ctx, w.client.RequestTimeout())
+			if err := w.sub.CloseCtx(closeCtx); err != nil {
+				w.client.log().Warn("Closing worker subscription failed", F("subscription", w.sub), F("error", err))
+			}
+			cancel()
+		}
+		close(w.stopCh)
+		w.client.removeWorker(w)
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Worker) work() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case message, ok := <-w.sub.ch:
+			if !ok {
+				return
+			}
+			w.handle(message)
+			w.sub.done(message)
+		}
+	}
+}
+
+// begin will mark the task as being handled. False is returned if it is handled already. Event of the latest lock
+// is kept then, as result of the task must be reported with it.
+func (w *Worker) begin(event *sbe.SubscribedEvent) bool {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+
+	running, ok := w.running[event.Key]
+	if !ok || event.Position >= running.Position {
+		w.running[event.Key] = event
+	}
+	return !ok
+}
+
+// end will return the latest event of the task and mark the task as handled.
+func (w *Worker) end(key uint64) *sbe.SubscribedEvent {
+	w.runningMu.Lock()
+	defer w.runningMu.Unlock()
+
+	event := w.running[key]
+	delete(w.running, key)
+	return event
+}
+
+func (w *Worker) handle(message *Message) {
+	event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+	if !w.begin(event) {
+		w.client.log().Warn("Task locked again while its handler is running, lock duration is too short", F("key", event.Key))
+		return
+	}
+
+	codec := w.codec
+	if codec == nil {
+		codec = w.client.payloadCodec(context.Background())
+	}
+	task, err := decodeTask(event, codec)
+	if err != nil {
+		w.end(event.Key)
+		w.client.log().Error("Cannot decode task", F("key", event.Key), F("error", err))
+		return
+	}
+
+	finish := func(error) {}
+	tracedPayload := false
+	if tracer := w.client.getTracer(); tracer != nil {
+		var carrier map[string]string
+		carrier, tracedPayload = extractCarrier(task)
+		task.ctx, finish = tracer.Extract(context.Background(), carrier, task)
+	}
+
+	start := time.Now()
+	var payload map[string]interface{}
+	withLabels(task.Context(), func(ctx context.Context) {
+		task.ctx = ctx
+		payload, err = w.run(task)
+	}, "taskType", w.subscription.TaskType, "topic", w.subscription.TopicName)
+	finish(err)
+	if elapsed := time.Since(start); elapsed > time.Duration(w.subscription.LockDuration)*time.Millisecond {
+		w.client.log().Warn("Handler ran longer than lock duration", F("key", event.Key), F("elapsed", elapsed))
+	}
+	event = w.end(event.Key)
+
+	if tracedPayload && payload != nil {
+		// Returned payload replaces the traced one, trace continues from the handler in following tasks.
+		payload = w.client.injectTrace(task.Context(), payload)
+	}
+
+	var response *Message
+	if err != nil {
+		w.client.log().Warn("Handler failed", F("key", event.Key), F("error", err))
+		response, err = w.fail(event, task, err, codec)
+	} else {
+		w.forget(event.Key)
+		response, err = w.client.CompleteTask(event, payload, CodecOption(codec))
+	}
+
+	if err != nil {
+		w.client.log().Error("Reporting result of task failed", F("key", event.Key), F("error", err))
+		return
+	}
+	var state interface{}
+	if response.Data != nil {
+		state = (*response.Data)["state"]
+	}
+	if state != TaskCompleted && state != TaskFailed {
+		w.client.log().Warn("Broker rejected result of task", F("key", event.Key), F("state", state))
+	}
+}
+
+// fail will report failure of the handler to the broker. Task is diverted first, if DeadLetterPolicy says so.
+func (w *Worker) fail(event *sbe.SubscribedEvent, task *Task, cause error, codec Codec) (*Message, error) {
+	retries := task.Retries - 1
+	if w.failed(event.Key) {
+		if err := w.divert(event.Key, task, cause); err != nil {
+			w.client.log().Error("Diverting task to dead letter failed", F("key", event.Key), F("error", err))
+		} else {
+			w.client.log().Warn("Task diverted to dead letter", F("key", event.Key), F("topic", w.deadLetter.Topic))
+			w.forget(event.Key)
+			if w.deadLetter.Complete {
+				return w.client.CompleteTask(event, nil, CodecOption(codec))
+			}
+			retries = 0
+		}
+	}
+	if retries <= 0 {
+		// Broker raises an incident, task isn't locked again until it's resolved.
+		w.forget(event.Key)
+	}
+	return w.client.FailTask(event, retries, cause.Error(), CodecOption(codec))
+}
+
+// run will map payload of the task for the handler, call the handler and map payload it returns.
+func (w *Worker) run(task *Task) (map[string]interface{}, error) {
+	input, err := w.mapInput(task)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := w.invoke(input)
+	if err != nil {
+		return nil, err
+	}
+	return w.mapOutput(task, payload)
+}
+
+// invoke will call the handler and turn panic into an error, so a single task cannot take down the Worker.
+func (w *Worker) invoke(task *Task) (payload map[string]interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return w.handler(task)
+}
@@ -0,0 +1,289 @@
+package zbc_test
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestWorker_TaskLockedAgain(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	completed := make(chan string, 2)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		completed <- task.Headers["lock"].(string)
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "COMPLETED"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int32
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}, zbc.WithMaxConcurrentJobs(2), zbc.WithLockDuration(10*time.Millisecond))
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop()
+
+	// Lock expires while the handler runs and the broker locks the task for the worker again.
+	broker.PushTask(1, &zbc.Task{State: "LOCKED", Type: "foo", Headers: map[string]interface{}{"lock": "first"}})
+	<-started
+	broker.PushTask(1, &zbc.Task{State: "LOCKED", Type: "foo", Headers: map[string]interface{}{"lock": "second"}})
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case lock := <-completed:
+		if lock != "second" {
+			t.Fatalf("Expected task completed with the latest lock, received %s", lock)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Task was not completed")
+	}
+	select {
+	case <-completed:
+		t.Fatal("Task completed twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("Expected handler called once, called %d times", n)
+	}
+}
+
+func TestWorker_MaxConcurrentJobs(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": "COMPLETED"})
+	})
+	increased := make(chan struct{}, 10)
+	broker.HandleControl(sbe.ControlMessageType.INCREASE_TASK_SUBSCRIPTION_CREDITS, func(request *zbc.Message) zbtest.Response {
+		increased <- struct{}{}
+		return zbtest.ControlResponse(*request.Data)
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return nil, nil
+	}, zbc.WithMaxConcurrentJobs(2), zbc.WithCredits(1))
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop()
+
+	// Credits are raised to the number of jobs, both tasks are handled at once.
+	broker.PushTask(1, &zbc.Task{State: "LOCKED", Type: "foo"})
+	broker.PushTask(2, &zbc.Task{State: "LOCKED", Type: "foo"})
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("Tasks were not handled concurrently")
+		}
+	}
+
+	select {
+	case <-increased:
+		t.Fatal("Credits increased while handlers are running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-increased:
+	case <-time.After(time.Second):
+		t.Fatal("Credits were not increased once handlers returned")
+	}
+}
+
+func TestWorker_DeadLetterPolicy(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	type command struct {
+		topic string
+		task  zbc.Task
+	}
+	commands := make(chan command, 10)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		commands <- command{string((*request.SbeMessage).(*sbe.ExecuteCommandRequest).TopicName), task}
+		state := map[string]string{zbc.TaskCreate: zbc.TaskCreated, zbc.TaskFail: zbc.TaskFailed}[task.State]
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": state})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diverted := make(chan error, 1)
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		return nil, errors.New("poison")
+	}, zbc.WithDeadLetterPolicy(&zbc.DeadLetterPolicy{
+		MaxFailures: 2,
+		Topic:       "dead-letters",
+		Handler: func(task *zbc.Task, err error) error {
+			diverted <- err
+			return nil
+		},
+	}))
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop()
+
+	broker.PushTask(1, &zbc.Task{State: zbc.TaskLocked, Type: "foo", Retries: 5})
+	if c := <-commands; c.task.State != zbc.TaskFail || c.task.Retries != 4 {
+		t.Fatalf("Expected task failed with retries decremented, received %+v", c.task)
+	}
+
+	// Broker locks the task again and the handler fails on it for the second time.
+	broker.PushTask(1, &zbc.Task{State: zbc.TaskLocked, Type: "foo", Retries: 4})
+	if err := <-diverted; err == nil || err.Error() != "poison" {
+		t.Fatalf("Expected task passed to dead letter handler with the error, received %v", err)
+	}
+	c := <-commands
+	if c.topic != "dead-letters" || c.task.State != zbc.TaskCreate || c.task.Type != "foo-dead-letter" || c.task.Headers[zbc.DeadLetterErrorHeader] != "poison" {
+		t.Fatalf("Expected task created on dead letter topic, received %s %+v", c.topic, c.task)
+	}
+	if c := <-commands; c.task.State != zbc.TaskFail || c.task.Retries != 0 {
+		t.Fatalf("Expected task failed without retries, received %+v", c.task)
+	}
+}
+
+func TestWorker_PayloadMappings(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	completed := make(chan map[string]interface{}, 1)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		var payload map[string]interface{}
+		task.UnmarshalPayload(&payload)
+		completed <- payload
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": zbc.TaskCompleted})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := make(chan map[string]interface{}, 1)
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		input <- task.PayloadJson
+		return map[string]interface{}{"transactionId": "t-1"}, nil
+	},
+		zbc.WithInputMappings(zbc.Mapping{Source: "$.order.total", Target: "$.amount"}),
+		zbc.WithOutputMappings(zbc.Mapping{Source: "$.transactionId", Target: "$.order.payment"}))
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop()
+
+	task := &zbc.Task{State: zbc.TaskLocked, Type: "foo", Retries: 3}
+	task.SetPayloadObject(map[string]interface{}{"customer": "c-1", "order": map[string]interface{}{"total": 42}})
+	broker.PushTask(1, task)
+
+	if payload := <-input; len(payload) != 1 || fmt.Sprint(payload["amount"]) != "42" {
+		t.Fatalf("Expected handler to see only the amount, received %v", payload)
+	}
+	payload := <-completed
+	order, _ := payload["order"].(map[interface{}]interface{})
+	if payload["customer"] != "c-1" || fmt.Sprint(order["total"]) != "42" || order["payment"] != "t-1" {
+		t.Fatalf("Expected output merged into payload of the task, received %v", payload)
+	}
+}
+
+func TestWorker_CustomHeaders(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	commands := make(chan zbc.Task, 2)
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) zbtest.Response {
+		var task zbc.Task
+		request.UnmarshalData(&task)
+		commands <- task
+		return zbtest.CommandResponse(request, 1, map[string]interface{}{"state": task.State + "D"})
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headers := map[string]string{"region": "eu"}
+	if _, err := client.CreateTask("default-topic", &zbc.Task{Type: "foo", CustomHeaders: headers}); err != nil {
+		t.Fatal(err)
+	}
+	if created := <-commands; created.CustomHeaders["region"] != "eu" {
+		t.Fatalf("Expected custom headers of the created task, received %v", created.CustomHeaders)
+	}
+
+	received := make(chan map[string]string, 1)
+	worker := client.NewWorker("foo", func(task *zbc.Task) (map[string]interface{}, error) {
+		received <- task.CustomHeaders
+		return nil, nil
+	}, zbc.WithFilters(zbc.CustomHeaderFilter("region", "eu")))
+	if err := worker.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Stop()
+
+	broker.PushTask(1, &zbc.Task{State: "LOCKED", Type: "foo", CustomHeaders: map[string]string{"region": "us"}})
+	broker.PushTask(2, &zbc.Task{State: "LOCKED", Type: "foo", CustomHeaders: headers})
+	select {
+	case h := <-received:
+		if h["region"] != "eu" {
+			t.Fatalf("Expected task of region eu passed to the handler, received %v", h)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Task was not handled")
+	}
+	if completed := <-commands; completed.State != zbc.TaskComplete || completed.CustomHeaders["region"] != "eu" {
+		t.Fatalf("Expected custom headers kept by completion, received %+v", completed)
+	}
+}
@@ -0,0 +1,125 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+var errWorkflowNotFound = errors.New("Workflow not found")
+
+// replayQuietPeriod is time without new event after which replay of the topic is considered finished.
+const replayQuietPeriod = time.Second
+
+// Workflow is one deployed version of BPMN process. Broker creates it for every process of the deployment.
+// Broker doesn't keep resource name or time of the deployment, so they can't be provided.
+type Workflow struct {
+	Key           uint64 `msgpack:"-" json:"key"`
+	BpmnProcessId string `msgpack:"bpmnProcessId" json:"bpmnProcessId"`
+	Version       int    `msgpack:"version" json:"version"`
+	DeploymentKey int64  `msgpack:"deploymentKey" json:"deploymentKey"`
+	BpmnXml       []byte `msgpack:"bpmnXml" json:"-"`
+}
+
+func isWorkflowEvent(event *sbe.SubscribedEvent) bool {
+	return event.EventType == sbe.EventType.WORKFLOW_EVENT
+}
+
+// ListWorkflows will return all workflows deployed on the topic in order of deployment. Broker has no query for
+// workflows, so workflow events of the topic are replayed until no event arrives for a second. If request times out
+// during the replay, workflows read so far are returned together with the error.
+func (c *Client) ListWorkflows(topic string, opts ...RequestOption) ([]*Workflow, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.ListWorkflowsCtx(ctx, topic)
+}
+
+// ListWorkflowsCtx is same as ListWorkflows, but replay is aborted once ctx is done.
+func (c *Client) ListWorkflowsCtx(ctx context.Context, topic string) ([]*Workflow, error) {
+	var workflows []*Workflow
+	err := c.replayTopic(ctx, topic, 0, "zbc-workflows", isWorkflowEvent, func(message *Message) error {
+		if (*message.Data)["state"] != WorkflowCreated {
+			return nil
+		}
+
+		var workflow Workflow
+		if err := message.UnmarshalData(&workflow); err != nil {
+			return err
+		}
+		workflow.Key = (*message.SbeMessage).(*sbe.SubscribedEvent).Key
+		workflows = append(workflows, &workflow)
+		return nil
+	})
+	return workflows, err
+}
+
+// replayTopic will pass events of the partition from its beginning to handle, until no event arrives for
+// replayQuietPeriod. Events not matching the filter are skipped, nil filter passes all of them.
+func (c *Client) replayTopic(ctx context.Context, topic string, partitionID int32, name string, filter func(event *sbe.SubscribedEvent) bool, handle func(message *Message) error) error {
+	sub, err := c.openTopicSubscription(ctx, &TopicSubscription{
+		TopicName:        topic,
+		PartitionID:      partitionID,
+		Name:             name,
+		StartPosition:    0,
+		PrefetchCapacity: 32,
+		ForceStart:       true,
+	}, filter)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case message, ok := <-sub.Events():
+			if !ok {
+				return errSubscriptionNotFound
+			}
+			if err := handle(message); err != nil {
+				return err
+			}
+		case <-time.After(replayQuietPeriod):
+			return nil
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return ErrRequestTimeout
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// GetWorkflow will return workflow with given bpmnProcessId and version deployed on the topic. Version -1 means latest version.
+func (c *Client) GetWorkflow(topic, bpmnProcessId string, version int, opts ...RequestOption) (*Workflow, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.GetWorkflowCtx(ctx, topic, bpmnProcessId, version)
+}
+
+// GetWorkflowCtx is same as GetWorkflow, but replay is aborted once ctx is done.
+func (c *Client) GetWorkflowCtx(ctx context.Context, topic, bpmnProcessId string, version int) (*Workflow, error) {
+	workflows, err := c.ListWorkflowsCtx(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *Workflow
+	for _, workflow := range workflows {
+		if workflow.BpmnProcessId != bpmnProcessId {
+			continue
+		}
+		if workflow.Version == version {
+			return workflow, nil
+		}
+		if version == -1 && (found == nil || workflow.Version > found.Version) {
+			found = workflow
+		}
+	}
+
+	if found == nil {
+		return nil, errWorkflowNotFound
+	}
+	return found, nil
+}
@@ -0,0 +1,53 @@
+package zbc_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+)
+
+func TestClient_GetWorkflow(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+
+	type result struct {
+		workflow *zbc.Workflow
+		err      error
+	}
+	resultCh := make(chan result, 1)
+	go func() {
+		workflow, err := client.GetWorkflow("default-topic", "order-process", -1)
+		resultCh <- result{workflow, err}
+	}()
+
+	events := []map[string]interface{}{
+		{"state": "CREATED", "bpmnProcessId": "order-process", "version": 1, "deploymentKey": 10},
+		{"state": "CREATED", "bpmnProcessId": "other-process", "version": 1, "deploymentKey": 10},
+		{"state": "CREATED", "bpmnProcessId": "order-process", "version": 2, "deploymentKey": 20},
+	}
+	for i, event := range events {
+		for broker.PushTopicEvent("default-topic", uint64(i+1), sbe.EventType.WORKFLOW_EVENT, event) != nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	r := <-resultCh
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.workflow.Version != 2 || r.workflow.Key != 3 || r.workflow.DeploymentKey != 20 {
+		t.Fatalf("Expected latest version of order-process, received %+v", r.workflow)
+	}
+}
@@ -0,0 +1,110 @@
+package zbc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// ErrWorkflowInstanceRejected is returned by CreateWorkflowInstanceWithResult once broker rejects creation of the
+// instance, e.g. because the workflow is not deployed.
+var ErrWorkflowInstanceRejected = errors.New("Workflow instance rejected, workflow not found")
+
+// WorkflowInstanceResult is the final event of workflow instance awaited by CreateWorkflowInstanceWithResult.
+type WorkflowInstanceResult struct {
+	Key     uint64
+	State   string // WorkflowInstanceCompleted or WorkflowInstanceCanceled.
+	Payload map[string]interface{}
+	Event   *Message
+}
+
+// CreateWorkflowInstanceWithResult will create new instance of the workflow same as CreateWorkflowInstance and wait
+// until the instance completes or is canceled. Timeout of the request covers the whole workflow, pass TimeoutOption
+// for workflows taking longer than the request timeout. Topic subscription is opened on the partition of the instance
+// for the time of waiting, so the instance completed before its response arrives is not missed.
+func (c *Client) CreateWorkflowInstanceWithResult(topic, bpmnProcessId string, version int, payload map[string]interface{}, opts ...RequestOption) (*WorkflowInstanceResult, error) {
+	ctx, cancel := c.requestContext(opts...)
+	defer cancel()
+	return c.CreateWorkflowInstanceWithResultCtx(ctx, topic, bpmnProcessId, version, payload)
+}
+
+// CreateWorkflowInstanceWithResultCtx is same as CreateWorkflowInstanceWithResult, but request and waiting are
+// aborted once ctx is done.
+func (c *Client) CreateWorkflowInstanceWithResultCtx(ctx context.Context, topic, bpmnProcessId string, version int, payload map[string]interface{}) (*WorkflowInstanceResult, error) {
+	workflowInstance, err := c.newWorkflowInstance(ctx, bpmnProcessId, version, payload)
+	if err != nil {
+		return nil, err
+	}
+	partitionID := int32(c.selectPartition(ctx, topic))
+
+	sub, err := c.openTopicSubscription(ctx, &TopicSubscription{
+		TopicName:        topic,
+		PartitionID:      partitionID,
+		Name:             fmt.Sprintf("zbc-result-%d", time.Now().UnixNano()),
+		StartPosition:    TailPosition,
+		PrefetchCapacity: 32,
+		ForceStart:       true,
+		Filters: []EventFilter{
+			EventTypeFilter(sbe.EventType.WORKFLOW_INSTANCE_EVENT),
+			StateFilter(WorkflowInstanceCompleted, WorkflowInstanceCanceled),
+		},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	response, err := c.createWorkflowInstance(ctx, topic, partitionID, workflowInstance)
+	if err != nil {
+		return nil, err
+	}
+	var created WorkflowInstance
+	if err := response.UnmarshalData(&created); err != nil {
+		return nil, err
+	}
+	if created.State != WorkflowInstanceCreated {
+		return nil, ErrWorkflowInstanceRejected
+	}
+	commandResponse, ok := (*response.SbeMessage).(*sbe.ExecuteCommandResponse)
+	if !ok {
+		return nil, errUnexpectedResponse
+	}
+	key := commandResponse.Key
+
+	for {
+		select {
+		case message, ok := <-sub.Events():
+			if !ok {
+				return nil, errSubscriptionNotFound
+			}
+			event := (*message.SbeMessage).(*sbe.SubscribedEvent)
+			if event.Key != key {
+				continue
+			}
+			return c.workflowInstanceResult(ctx, key, message)
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil, ErrRequestTimeout
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) workflowInstanceResult(ctx context.Context, key uint64, message *Message) (*WorkflowInstanceResult, error) {
+	var event WorkflowInstance
+	if err := message.UnmarshalData(&event); err != nil {
+		return nil, err
+	}
+
+	result := &WorkflowInstanceResult{Key: key, State: event.State, Event: message}
+	if len(event.Payload) > 0 {
+		if err := c.payloadCodec(ctx).Unmarshal(event.Payload, &result.Payload); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
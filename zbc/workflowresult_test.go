@@ -0,0 +1,66 @@
+package zbc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"github.com/zeebe-io/zbc-go/zbc/zbtest"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+func TestClient_CreateWorkflowInstanceWithResult(t *testing.T) {
+	broker, err := zbtest.NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broker.Close()
+
+	// Instance completes before its response is sent, result must not be missed.
+	broker.HandleCommand(sbe.EventType.WORKFLOW_INSTANCE_EVENT, func(request *zbc.Message) zbtest.Response {
+		var instance zbc.WorkflowInstance
+		request.UnmarshalData(&instance)
+		if instance.BpmnProcessId != "order-process" {
+			instance.State = zbc.WorkflowInstanceRejected
+			return zbtest.CommandResponse(request, 0, &instance)
+		}
+
+		payload, _ := msgpack.Marshal(map[string]interface{}{"approved": true})
+		events := []struct {
+			key   uint64
+			state string
+		}{
+			{41, zbc.WorkflowInstanceCompleted},
+			{42, "ACTIVITY_COMPLETED"},
+			{42, zbc.WorkflowInstanceCompleted},
+		}
+		for _, e := range events {
+			event := map[string]interface{}{"state": e.state, "bpmnProcessId": "order-process", "payload": payload}
+			if err := broker.PushTopicEvent("default-topic", e.key, sbe.EventType.WORKFLOW_INSTANCE_EVENT, event); err != nil {
+				t.Error(err)
+			}
+		}
+
+		instance.State = zbc.WorkflowInstanceCreated
+		return zbtest.CommandResponse(request, 42, &instance)
+	})
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close(context.Background())
+
+	result, err := client.CreateWorkflowInstanceWithResult("default-topic", "order-process", -1, map[string]interface{}{"orderId": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Key != 42 || result.State != zbc.WorkflowInstanceCompleted || result.Payload["approved"] != true {
+		t.Fatalf("Expected instance 42 completed with its payload, received %+v", result)
+	}
+
+	if _, err := client.CreateWorkflowInstanceWithResult("default-topic", "unknown", -1, nil); err != zbc.ErrWorkflowInstanceRejected {
+		t.Fatalf("Expected instance of unknown workflow rejected, received %v", err)
+	}
+}
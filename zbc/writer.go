@@ -2,53 +2,83 @@ package zbc
 
 import (
 	"bytes"
-	"encoding/binary"
 	"log"
+	"sync"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
 )
 
+// bufferPool keeps buffers for encoding of outgoing messages, so they can be reused between requests.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, 512))
+	},
+}
+
+// acquireBuffer returns empty buffer from the pool. It must be given back with releaseBuffer once written to the socket.
+func acquireBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func releaseBuffer(buffer *bytes.Buffer) {
+	buffer.Reset()
+	bufferPool.Put(buffer)
+}
+
 // MessageWriter is builder which will take Message pointer and create valid byte array.
 type MessageWriter struct {
 	message *Message
 }
 
-func (mw *MessageWriter) writeFrameHeader(writer *bytes.Buffer) error {
-	err := mw.message.Headers.FrameHeader.Encode(writer)
-	if err != nil {
-		return err
-	}
-	return nil
+// Headers are encoded by hand, binary.Write would allocate on every call.
+func (mw *MessageWriter) writeFrameHeader(writer *bytes.Buffer, fh *protocol.FrameHeader) error {
+	var b [12]byte
+	protocol.ByteOrder.PutUint32(b[0:], fh.Length)
+	b[4] = fh.Version
+	b[5] = fh.Flags
+	protocol.ByteOrder.PutUint16(b[6:], fh.TypeID)
+	protocol.ByteOrder.PutUint32(b[8:], fh.StreamID)
+	_, err := writer.Write(b[:])
+	return err
 }
 
 func (mw *MessageWriter) writeTransportHeader(writer *bytes.Buffer) error {
-	err := mw.message.Headers.TransportHeader.Encode(writer)
-	if err != nil {
-		return err
-	}
-	return nil
+	var b [2]byte
+	protocol.ByteOrder.PutUint16(b[0:], mw.message.Headers.TransportHeader.ProtocolID)
+	_, err := writer.Write(b[:])
+	return err
 }
 
 func (mw *MessageWriter) writeRequestResponseHeader(writer *bytes.Buffer) error {
 	if mw.message.Headers.IsSingleMessage() {
 		return nil
 	}
-	err := mw.message.Headers.RequestResponseHeader.Encode(writer)
-	if err != nil {
-		return err
-	}
-	return nil
+	var b [8]byte
+	protocol.ByteOrder.PutUint64(b[0:], mw.message.Headers.RequestResponseHeader.RequestID)
+	_, err := writer.Write(b[:])
+	return err
 }
 
 func (mw *MessageWriter) writeSbeMessageHeader(writer *bytes.Buffer) error {
-	if err := mw.message.Headers.SbeMessageHeader.Encode(writer, binary.LittleEndian); err != nil {
-		return err
-	}
-	return nil
+	h := mw.message.Headers.SbeMessageHeader
+	var b [8]byte
+	protocol.ByteOrder.PutUint16(b[0:], h.BlockLength)
+	protocol.ByteOrder.PutUint16(b[2:], h.TemplateId)
+	protocol.ByteOrder.PutUint16(b[4:], h.SchemaId)
+	protocol.ByteOrder.PutUint16(b[6:], h.Version)
+	_, err := writer.Write(b[:])
+	return err
 }
 
 func (mw *MessageWriter) writeHeaders(writer *bytes.Buffer) error {
-	if err := mw.writeFrameHeader(writer); err != nil {
+	if err := mw.writeFrameHeader(writer, mw.message.Headers.FrameHeader); err != nil {
 		return err
 	}
+	return mw.writeMessageHeaders(writer)
+}
+
+// writeMessageHeaders will write headers which follow the frame header.
+func (mw *MessageWriter) writeMessageHeaders(writer *bytes.Buffer) error {
 	if err := mw.writeTransportHeader(writer); err != nil {
 		return err
 	}
@@ -61,20 +91,56 @@ func (mw *MessageWriter) writeHeaders(writer *bytes.Buffer) error {
 	return nil
 }
 
-func (mw *MessageWriter) writeMessage(writer *bytes.Buffer) error {
-	if err := (*mw.message.SbeMessage).Encode(writer, binary.LittleEndian, false); err != nil {
-		return err
+// WriteFragments is same as Write, but message longer than maxFrameLength is split into several frames. First frame
+// has FrameFlagBegin set, last one FrameFlagEnd. Zero maxFrameLength disables fragmentation.
+func (mw *MessageWriter) WriteFragments(writer *bytes.Buffer, maxFrameLength int) {
+	if maxFrameLength <= 0 || int(mw.message.Headers.FrameHeader.Length) <= maxFrameLength {
+		mw.Write(writer)
+		return
+	}
+
+	body := acquireBuffer()
+	defer releaseBuffer(body)
+	if err := mw.writeMessageHeaders(body); err != nil {
+		log.Fatal("failed writing header")
+	}
+	if err := mw.writeMessage(body); err != nil {
+		log.Fatalf("failed writing message")
+	}
+
+	fh := *mw.message.Headers.FrameHeader
+	data := body.Bytes()
+	for offset := 0; offset < len(data); offset += maxFrameLength {
+		end := offset + maxFrameLength
+		if end > len(data) {
+			end = len(data)
+		}
+
+		fh.Length = uint32(end - offset)
+		fh.Flags = 0
+		if offset == 0 {
+			fh.Flags |= protocol.FrameFlagBegin
+		}
+		if end == len(data) {
+			fh.Flags |= protocol.FrameFlagEnd
+		}
+
+		mw.writeFrameHeader(writer, &fh)
+		writer.Write(data[offset:end])
+		mw.align(writer)
 	}
-	return nil
 }
 
+func (mw *MessageWriter) writeMessage(writer *bytes.Buffer) error {
+	return encodeBody(writer, *mw.message.SbeMessage, mw.message.Headers.SbeMessageHeader)
+}
+
+var padding [8]byte
+
 func (mw *MessageWriter) align(writer *bytes.Buffer) {
-	currentSize := len(writer.Bytes())
+	currentSize := writer.Len()
 	expectedSize := (currentSize + 7) & ^7
-	for currentSize < expectedSize {
-		writer.Write([]byte{0x00})
-		currentSize++
-	}
+	writer.Write(padding[:expectedSize-currentSize])
 }
 
 func (mw *MessageWriter) Write(writer *bytes.Buffer) {
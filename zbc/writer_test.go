@@ -0,0 +1,93 @@
+package zbc
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/zeebe-io/zbc-go/zbc/protocol"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+func newTestCommandMessage() *Message {
+	return NewCommandRequestMessage(&sbe.ExecuteCommandRequest{
+		PartitionId: 0,
+		Position:    0,
+		Key:         0,
+		EventType:   sbe.EventType.TASK_EVENT,
+		TopicName:   []uint8("default-topic"),
+	}, &Task{State: "CREATE", Type: "foo", Retries: 3})
+}
+
+func TestMessageWriter_Write(t *testing.T) {
+	msg := newTestCommandMessage()
+
+	// Headers encoded by hand must be same as the ones encoded by the protocol package.
+	expected := &bytes.Buffer{}
+	msg.Headers.FrameHeader.Encode(expected)
+	msg.Headers.TransportHeader.Encode(expected)
+	msg.Headers.RequestResponseHeader.Encode(expected)
+	msg.Headers.SbeMessageHeader.Encode(expected, protocol.ByteOrder)
+	(*msg.SbeMessage).Encode(expected, protocol.ByteOrder, false)
+	for expected.Len()%8 != 0 {
+		expected.WriteByte(0)
+	}
+
+	buffer := acquireBuffer()
+	defer releaseBuffer(buffer)
+	NewMessageWriter(msg).Write(buffer)
+
+	if !bytes.Equal(expected.Bytes(), buffer.Bytes()) {
+		t.Fatalf("Expected %v, got %v", expected.Bytes(), buffer.Bytes())
+	}
+}
+
+func BenchmarkMessageWriter_Write(b *testing.B) {
+	msg := newTestCommandMessage()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buffer := &bytes.Buffer{}
+		NewMessageWriter(msg).Write(buffer)
+	}
+}
+
+func BenchmarkMessageWriter_WritePooled(b *testing.B) {
+	msg := newTestCommandMessage()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buffer := acquireBuffer()
+		NewMessageWriter(msg).Write(buffer)
+		releaseBuffer(buffer)
+	}
+}
+
+func TestMessageWriter_WriteFragments(t *testing.T) {
+	msg := newTestCommandMessage()
+	msg.Headers.RequestResponseHeader.RequestID = 7
+
+	whole := &bytes.Buffer{}
+	NewMessageWriter(msg).Write(whole)
+
+	fragmented := &bytes.Buffer{}
+	NewMessageWriter(msg).WriteFragments(fragmented, 16)
+	if fragmented.Len() <= whole.Len() {
+		t.Fatalf("Message of %d bytes was not fragmented", whole.Len())
+	}
+
+	r := NewMessageReader(bufio.NewReader(fragmented))
+	headers, tail, err := r.ReadHeaders()
+	if err != nil {
+		t.Fatalf("Reading fragmented message failed: %s", err)
+	}
+	if headers.RequestResponseHeader.RequestID != 7 {
+		t.Fatalf("Expected request 7, received %d", headers.RequestResponseHeader.RequestID)
+	}
+	if headers.FrameHeader.Length != msg.Headers.FrameHeader.Length {
+		t.Fatalf("Expected length %d, received %d", msg.Headers.FrameHeader.Length, headers.FrameHeader.Length)
+	}
+
+	expected := whole.Bytes()[FrameHeaderSize+TotalHeaderSizeNoFrame : FrameHeaderSize+int(msg.Headers.FrameHeader.Length)]
+	if !bytes.Equal(expected, *tail) {
+		t.Fatalf("Expected %v, received %v", expected, *tail)
+	}
+}
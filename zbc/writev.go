@@ -0,0 +1,12 @@
+//go:build go1.8
+// +build go1.8
+
+package zbc
+
+import "net"
+
+// writeFrames will write all frames by a single writev syscall where the connection supports it.
+func writeFrames(conn net.Conn, frames [][]byte) (int64, error) {
+	buffers := net.Buffers(frames)
+	return buffers.WriteTo(conn)
+}
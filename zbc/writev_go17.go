@@ -0,0 +1,22 @@
+//go:build !go1.8
+// +build !go1.8
+
+package zbc
+
+import "net"
+
+// writeFrames will copy all frames into one buffer and write it at once, net.Buffers needs Go 1.8.
+func writeFrames(conn net.Conn, frames [][]byte) (int64, error) {
+	if len(frames) == 1 {
+		n, err := conn.Write(frames[0])
+		return int64(n), err
+	}
+
+	buffer := acquireBuffer()
+	defer releaseBuffer(buffer)
+	for _, frame := range frames {
+		buffer.Write(frame)
+	}
+	n, err := conn.Write(buffer.Bytes())
+	return int64(n), err
+}
@@ -0,0 +1,462 @@
+// Package zbtest provides MockBroker, which speaks the client protocol of the broker, so applications built on zbc
+// can be tested without a live broker.
+package zbtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net"
+	"sync"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+	"gopkg.in/vmihailenco/msgpack.v2"
+)
+
+var (
+	errNoConnection   = errors.New("No client is connected to the broker")
+	errNoSubscription = errors.New("No subscription for the event")
+	errBrokerClosed   = errors.New("Broker is closed")
+)
+
+// Response is SBE message which MockBroker can send to the client, e.g. *sbe.ExecuteCommandResponse.
+type Response interface {
+	zbc.Template
+}
+
+// Handler returns response to the request. If it returns nil, no response is sent and the request times out.
+type Handler func(request *zbc.Message) Response
+
+// MockBroker listens on a local port and answers requests of clients with responses of registered handlers.
+// Task subscriptions, topic subscriptions and topology are handled out of the box, every command must be handled
+// by a handler registered with HandleCommand, otherwise it is rejected with MESSAGE_NOT_SUPPORTED.
+// All received requests are recorded, so tests can assert on them.
+type MockBroker struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu                sync.Mutex // Guards fields below.
+	commands          map[sbe.EventTypeEnum]Handler
+	controls          map[sbe.ControlMessageTypeEnum]Handler
+	received          []*zbc.Message
+	conns             map[*mockConn]struct{}
+	taskSubscriptions map[uint64]*zbc.TaskSubscription
+	topicSubscribers  map[uint64]string // Topic of every open topic subscription by subscriber key.
+	nextKey           uint64
+	schemaVersion     uint16 // SBE schema version in headers of responses, zero keeps zbc.SchemaVersion.
+	closed            bool
+}
+
+// mockConn is connection of one client. Responses and pushed events are written to it from different goroutines.
+type mockConn struct {
+	net.Conn
+	mu sync.Mutex
+}
+
+func (c *mockConn) write(b []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.Write(b)
+	return err
+}
+
+// NewMockBroker will start MockBroker listening on a free port of the loopback interface.
+func NewMockBroker() (*MockBroker, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &MockBroker{
+		listener:          listener,
+		commands:          make(map[sbe.EventTypeEnum]Handler),
+		controls:          make(map[sbe.ControlMessageTypeEnum]Handler),
+		conns:             make(map[*mockConn]struct{}),
+		taskSubscriptions: make(map[uint64]*zbc.TaskSubscription),
+		topicSubscribers:  make(map[uint64]string),
+	}
+	b.controls[sbe.ControlMessageType.REQUEST_TOPOLOGY] = b.topology
+	b.controls[sbe.ControlMessageType.ADD_TASK_SUBSCRIPTION] = b.addTaskSubscription
+	b.controls[sbe.ControlMessageType.REMOVE_TASK_SUBSCRIPTION] = b.removeTaskSubscription
+	b.controls[sbe.ControlMessageType.INCREASE_TASK_SUBSCRIPTION_CREDITS] = echo
+	b.controls[sbe.ControlMessageType.REMOVE_TOPIC_SUBSCRIPTION] = b.removeTopicSubscription
+	b.commands[sbe.EventType.SUBSCRIBER_EVENT] = b.addTopicSubscription
+
+	b.wg.Add(1)
+	go b.accept()
+	return b, nil
+}
+
+// Addr returns address the broker listens on, which can be passed to zbc.NewClient.
+func (b *MockBroker) Addr() string {
+	return b.listener.Addr().String()
+}
+
+// HandleCommand will register handler for commands with given event type. It replaces previously registered handler.
+func (b *MockBroker) HandleCommand(eventType sbe.EventTypeEnum, handler Handler) {
+	b.mu.Lock()
+	b.commands[eventType] = handler
+	b.mu.Unlock()
+}
+
+// HandleControl will register handler for control messages with given type. It replaces previously registered handler.
+func (b *MockBroker) HandleControl(messageType sbe.ControlMessageTypeEnum, handler Handler) {
+	b.mu.Lock()
+	b.controls[messageType] = handler
+	b.mu.Unlock()
+}
+
+// SetSchemaVersion will send responses with the SBE schema version in their headers, so clients can be tested
+// against brokers speaking other versions. Bodies are encoded by the schema of zbc in any case.
+func (b *MockBroker) SetSchemaVersion(version uint16) {
+	b.mu.Lock()
+	b.schemaVersion = version
+	b.mu.Unlock()
+}
+
+// Received returns all requests received by the broker in order of their arrival. Keep alive frames are not recorded.
+func (b *MockBroker) Received() []*zbc.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	received := make([]*zbc.Message, len(b.received))
+	copy(received, b.received)
+	return received
+}
+
+// Push will send the event to all connected clients. Client delivers it to its subscription with the SubscriberKey.
+func (b *MockBroker) Push(event *sbe.SubscribedEvent) error {
+	msg, err := zbc.NewEventMessage(event)
+	if err != nil {
+		return err
+	}
+	frame := encode(msg)
+
+	b.mu.Lock()
+	conns := make([]*mockConn, 0, len(b.conns))
+	for c := range b.conns {
+		conns = append(conns, c)
+	}
+	b.mu.Unlock()
+
+	if len(conns) == 0 {
+		return errNoConnection
+	}
+	for _, c := range conns {
+		if err := c.write(frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PushTask will push the task with given key to all open task subscriptions for the type of the task.
+func (b *MockBroker) PushTask(key uint64, task *zbc.Task) error {
+	event, err := msgpack.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	var subscriptions []zbc.TaskSubscription
+	for _, ts := range b.taskSubscriptions {
+		if ts.TaskType == task.Type {
+			subscriptions = append(subscriptions, *ts)
+		}
+	}
+	b.mu.Unlock()
+
+	if len(subscriptions) == 0 {
+		return errNoSubscription
+	}
+	for _, ts := range subscriptions {
+		err := b.Push(&sbe.SubscribedEvent{
+			PartitionId:      uint16(ts.PartitionID),
+			Key:              key,
+			SubscriberKey:    ts.SubscriberKey,
+			SubscriptionType: sbe.SubscriptionType.TASK_SUBSCRIPTION,
+			EventType:        sbe.EventType.TASK_EVENT,
+			TopicName:        []uint8(ts.TopicName),
+			Event:            event,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PushTopicEvent will push the event with given key and type to all open topic subscriptions on the topic.
+func (b *MockBroker) PushTopicEvent(topic string, key uint64, eventType sbe.EventTypeEnum, event interface{}) error {
+	data, err := msgpack.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	var subscriberKeys []uint64
+	for subscriberKey, subscribedTopic := range b.topicSubscribers {
+		if subscribedTopic == topic {
+			subscriberKeys = append(subscriberKeys, subscriberKey)
+		}
+	}
+	b.mu.Unlock()
+
+	if len(subscriberKeys) == 0 {
+		return errNoSubscription
+	}
+	for _, subscriberKey := range subscriberKeys {
+		err := b.Push(&sbe.SubscribedEvent{
+			Key:              key,
+			SubscriberKey:    subscriberKey,
+			SubscriptionType: sbe.SubscriptionType.TOPIC_SUBSCRIPTION,
+			EventType:        eventType,
+			TopicName:        []uint8(topic),
+			Event:            data,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dial returns client end of an in-memory connection to the broker. Pass it to zbc.WithDialer, so the client is
+// tested without a socket.
+func (b *MockBroker) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, errBrokerClosed
+	}
+
+	client, server := net.Pipe()
+	c := &mockConn{Conn: server}
+	b.conns[c] = struct{}{}
+	b.wg.Add(1)
+	go b.serve(c)
+	return client, nil
+}
+
+// Close will stop listening and close connections of all clients.
+func (b *MockBroker) Close() error {
+	err := b.listener.Close()
+
+	b.mu.Lock()
+	b.closed = true
+	for c := range b.conns {
+		c.Close()
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return err
+}
+
+func (b *MockBroker) accept() {
+	defer b.wg.Done()
+
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		c := &mockConn{Conn: conn}
+		b.mu.Lock()
+		b.conns[c] = struct{}{}
+		b.mu.Unlock()
+
+		b.wg.Add(1)
+		go b.serve(c)
+	}
+}
+
+// serve will answer requests received on the connection until it is closed.
+func (b *MockBroker) serve(c *mockConn) {
+	defer b.wg.Done()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		b.mu.Unlock()
+		c.Close()
+	}()
+
+	r := zbc.NewMessageReader(bufio.NewReaderSize(c, 20000))
+	for {
+		headers, tail, err := r.ReadHeaders()
+		if err != nil {
+			return
+		}
+		if headers.IsControlFrame() || headers.IsSingleMessage() {
+			continue
+		}
+
+		request, err := r.ParseMessage(headers, tail)
+		if err != nil {
+			log.Printf("[M] Parsing request failed: %s\n", err)
+			continue
+		}
+
+		b.mu.Lock()
+		b.received = append(b.received, request)
+		handler := b.handler(request)
+		b.mu.Unlock()
+
+		response := handler(request)
+		if response == nil {
+			continue
+		}
+
+		msg, err := zbc.NewResponseMessage(headers.RequestResponseHeader.RequestID, response)
+		if err != nil {
+			log.Printf("[M] Encoding response failed: %s\n", err)
+			continue
+		}
+		b.mu.Lock()
+		if b.schemaVersion != 0 {
+			msg.Headers.SbeMessageHeader.Version = b.schemaVersion
+		}
+		b.mu.Unlock()
+		if err := c.write(encode(msg)); err != nil {
+			return
+		}
+	}
+}
+
+// handler returns handler registered for the request. Caller must hold b.mu.
+func (b *MockBroker) handler(request *zbc.Message) Handler {
+	var handler Handler
+	if request.SbeMessage != nil {
+		switch sbeMessage := (*request.SbeMessage).(type) {
+		case *sbe.ExecuteCommandRequest:
+			handler = b.commands[sbeMessage.EventType]
+		case *sbe.ControlMessageRequest:
+			handler = b.controls[sbeMessage.MessageType]
+		}
+	}
+
+	if handler == nil {
+		return notSupported
+	}
+	return handler
+}
+
+func (b *MockBroker) key() uint64 {
+	b.nextKey++
+	return b.nextKey
+}
+
+// topology tells the client that this broker is the only one in the cluster.
+func (b *MockBroker) topology(request *zbc.Message) Response {
+	host, port, _ := net.SplitHostPort(b.Addr())
+	addr := zbc.BrokerAddress{Host: host}
+	addr.Port, _ = net.LookupPort("tcp", port)
+	return ControlResponse(&zbc.Topology{
+		TopicLeaders: []zbc.TopicLeader{},
+		Brokers:      []zbc.BrokerAddress{addr},
+	})
+}
+
+func (b *MockBroker) addTaskSubscription(request *zbc.Message) Response {
+	var ts zbc.TaskSubscription
+	if err := request.UnmarshalData(&ts); err != nil {
+		return ErrorResponse(sbe.ErrorCode.REQUEST_PROCESSING_FAILURE, err.Error())
+	}
+
+	b.mu.Lock()
+	ts.SubscriberKey = b.key()
+	b.taskSubscriptions[ts.SubscriberKey] = &ts
+	b.mu.Unlock()
+	return ControlResponse(&ts)
+}
+
+func (b *MockBroker) removeTaskSubscription(request *zbc.Message) Response {
+	var ts zbc.TaskSubscription
+	if err := request.UnmarshalData(&ts); err != nil {
+		return ErrorResponse(sbe.ErrorCode.REQUEST_PROCESSING_FAILURE, err.Error())
+	}
+
+	b.mu.Lock()
+	delete(b.taskSubscriptions, ts.SubscriberKey)
+	b.mu.Unlock()
+	return ControlResponse(&ts)
+}
+
+func (b *MockBroker) addTopicSubscription(request *zbc.Message) Response {
+	cmdReq := (*request.SbeMessage).(*sbe.ExecuteCommandRequest)
+	b.mu.Lock()
+	key := b.key()
+	b.topicSubscribers[key] = string(cmdReq.TopicName)
+	b.mu.Unlock()
+
+	subscriber := *request.Data
+	subscriber["state"] = "SUBSCRIBED"
+	return CommandResponse(request, key, subscriber)
+}
+
+func (b *MockBroker) removeTopicSubscription(request *zbc.Message) Response {
+	if subscriberKey, ok := (*request.Data)["subscriberKey"].(uint64); ok {
+		b.mu.Lock()
+		delete(b.topicSubscribers, subscriberKey)
+		b.mu.Unlock()
+	}
+	return echo(request)
+}
+
+// echo will respond to control message with its own data.
+func echo(request *zbc.Message) Response {
+	return &sbe.ControlMessageResponse{Data: (*request.SbeMessage).(*sbe.ControlMessageRequest).Data}
+}
+
+func notSupported(request *zbc.Message) Response {
+	return ErrorResponse(sbe.ErrorCode.MESSAGE_NOT_SUPPORTED, "Message not supported by mock broker")
+}
+
+// CommandResponse is a constructor for response to the command. Topic and partition are taken from the request.
+// Event is encoded into message pack, response is nil if encoding fails.
+func CommandResponse(request *zbc.Message, key uint64, event interface{}) Response {
+	b, err := msgpack.Marshal(event)
+	if err != nil {
+		log.Printf("[M] Encoding event failed: %s\n", err)
+		return nil
+	}
+
+	cmdReq := (*request.SbeMessage).(*sbe.ExecuteCommandRequest)
+	return &sbe.ExecuteCommandResponse{
+		PartitionId: cmdReq.PartitionId,
+		Key:         key,
+		TopicName:   cmdReq.TopicName,
+		Event:       b,
+	}
+}
+
+// ControlResponse is a constructor for response to the control message. Data is encoded into message pack,
+// response is nil if encoding fails.
+func ControlResponse(data interface{}) Response {
+	b, err := msgpack.Marshal(data)
+	if err != nil {
+		log.Printf("[M] Encoding data failed: %s\n", err)
+		return nil
+	}
+	return &sbe.ControlMessageResponse{Data: b}
+}
+
+// ErrorResponse is a constructor for response which rejects the request.
+func ErrorResponse(code sbe.ErrorCodeEnum, message string) Response {
+	return &sbe.ErrorResponse{
+		ErrorCode:     code,
+		ErrorData:     []uint8(message),
+		FailedRequest: []uint8{},
+	}
+}
+
+// encode will encode the message into frame written to the connection.
+func encode(msg *zbc.Message) []byte {
+	var frame bytes.Buffer
+	zbc.NewMessageWriter(msg).Write(&frame)
+	return frame.Bytes()
+}
@@ -0,0 +1,98 @@
+package zbtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zeebe-io/zbc-go/zbc"
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+func newTestClient(t *testing.T) (*MockBroker, *zbc.Client) {
+	broker, err := NewMockBroker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := zbc.NewClient(broker.Addr())
+	if err != nil {
+		broker.Close()
+		t.Fatal(err)
+	}
+	client.SetReconnectPolicy(nil)
+	client.SetRequestTimeout(time.Second)
+	return broker, client
+}
+
+func TestMockBroker_CreateTask(t *testing.T) {
+	broker, client := newTestClient(t)
+	defer broker.Close()
+
+	broker.HandleCommand(sbe.EventType.TASK_EVENT, func(request *zbc.Message) Response {
+		var task zbc.Task
+		if err := request.UnmarshalData(&task); err != nil {
+			t.Error(err)
+		}
+		task.State = "CREATED"
+		return CommandResponse(request, 42, &task)
+	})
+
+	response, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo", Retries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := (*response.Data)["state"]; state != "CREATED" {
+		t.Fatalf("Expected state CREATED, received %v", state)
+	}
+	if key := (*response.SbeMessage).(*sbe.ExecuteCommandResponse).Key; key != 42 {
+		t.Fatalf("Expected key 42, received %d", key)
+	}
+
+	var commands []*sbe.ExecuteCommandRequest
+	for _, msg := range broker.Received() {
+		if cmdReq, ok := (*msg.SbeMessage).(*sbe.ExecuteCommandRequest); ok {
+			commands = append(commands, cmdReq)
+		}
+	}
+	if len(commands) != 1 || string(commands[0].TopicName) != "default-topic" {
+		t.Fatalf("Expected one command on default-topic, received %+v", commands)
+	}
+}
+
+func TestMockBroker_RejectsUnhandledCommand(t *testing.T) {
+	broker, client := newTestClient(t)
+	defer broker.Close()
+
+	_, err := client.CreateTask("default-topic", &zbc.Task{State: "CREATE", Type: "foo"})
+	if zbc.Cause(err) != zbc.ErrMessageNotSupported {
+		t.Fatalf("Expected ErrMessageNotSupported, received %v", err)
+	}
+}
+
+func TestMockBroker_PushTask(t *testing.T) {
+	broker, client := newTestClient(t)
+	defer broker.Close()
+
+	sub, err := client.OpenTaskSubscription(&zbc.TaskSubscription{
+		TopicName: "default-topic",
+		TaskType:  "foo",
+		LockOwner: "test",
+		Credits:   10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := broker.PushTask(7, &zbc.Task{State: "LOCKED", Type: "foo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-sub.Events():
+		if key := (*msg.SbeMessage).(*sbe.SubscribedEvent).Key; key != 7 {
+			t.Fatalf("Expected task with key 7, received %d", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Task was not delivered")
+	}
+}
@@ -0,0 +1,89 @@
+package zbc
+
+import (
+	"context"
+
+	"github.com/zeebe-io/zbc-go/zbc/sbe"
+)
+
+// ZeebeClient is the set of operations of Client, so code built on zbc can take the interface and be unit tested with
+// a stub, e.g. Client of package zbc/mock. Settings of the connection stay on Client, they are not part of it.
+type ZeebeClient interface {
+	Responder(message *Message, opts ...RequestOption) (*Message, error)
+	ResponderCtx(ctx context.Context, message *Message) (*Message, error)
+	SendAsync(message *Message, opts ...RequestOption) (<-chan *Response, error)
+	SendAsyncCtx(ctx context.Context, message *Message) (<-chan *Response, error)
+	SendBatch(messages []*Message, opts ...RequestOption) ([]*Message, error)
+	SendBatchCtx(ctx context.Context, messages []*Message) ([]*Message, error)
+	SendRawCommand(topic string, eventType sbe.EventTypeEnum, partitionID int32, key int64, body []byte, opts ...RequestOption) (map[string]interface{}, error)
+	SendRawCommandCtx(ctx context.Context, topic string, eventType sbe.EventTypeEnum, partitionID int32, key int64, body []byte) (map[string]interface{}, error)
+	SendControlMessage(messageType sbe.ControlMessageTypeEnum, data map[string]interface{}, opts ...RequestOption) (*Message, error)
+	SendControlMessageCtx(ctx context.Context, messageType sbe.ControlMessageTypeEnum, data map[string]interface{}) (*Message, error)
+
+	CreateTask(topic string, task *Task, opts ...RequestOption) (*Message, error)
+	CreateTaskCtx(ctx context.Context, topic string, task *Task) (*Message, error)
+	CreateTasks(topic string, tasks []*Task, opts ...RequestOption) ([]*Message, error)
+	CreateTasksCtx(ctx context.Context, topic string, tasks []*Task) ([]*Message, error)
+	CompleteTask(task *sbe.SubscribedEvent, payload map[string]interface{}, opts ...RequestOption) (*Message, error)
+	CompleteTaskCtx(ctx context.Context, task *sbe.SubscribedEvent, payload map[string]interface{}) (*Message, error)
+	FailTask(task *sbe.SubscribedEvent, retries int, errorMessage string, opts ...RequestOption) (*Message, error)
+	FailTaskCtx(ctx context.Context, task *sbe.SubscribedEvent, retries int, errorMessage string) (*Message, error)
+	UpdateTaskRetries(topic string, partitionID int32, key int64, retries int, opts ...RequestOption) (*Message, error)
+	UpdateTaskRetriesCtx(ctx context.Context, topic string, partitionID int32, key int64, retries int) (*Message, error)
+	FlushTaskQueue() error
+	FlushTaskQueueCtx(ctx context.Context) error
+
+	DeployWorkflow(topic string, bpmnBytes []byte, opts ...RequestOption) (*Message, error)
+	DeployWorkflowCtx(ctx context.Context, topic string, bpmnBytes []byte) (*Message, error)
+	DeployWorkflowResource(topic, resourceType string, resource []byte, opts ...RequestOption) (*Message, error)
+	DeployWorkflowResourceCtx(ctx context.Context, topic, resourceType string, resource []byte) (*Message, error)
+	ListWorkflows(topic string, opts ...RequestOption) ([]*Workflow, error)
+	ListWorkflowsCtx(ctx context.Context, topic string) ([]*Workflow, error)
+	GetWorkflow(topic, bpmnProcessId string, version int, opts ...RequestOption) (*Workflow, error)
+	GetWorkflowCtx(ctx context.Context, topic, bpmnProcessId string, version int) (*Workflow, error)
+	CreateWorkflowInstance(topic, bpmnProcessId string, version int, payload map[string]interface{}, opts ...RequestOption) (*Message, error)
+	CreateWorkflowInstanceCtx(ctx context.Context, topic, bpmnProcessId string, version int, payload map[string]interface{}) (*Message, error)
+	CreateWorkflowInstanceWithResult(topic, bpmnProcessId string, version int, payload map[string]interface{}, opts ...RequestOption) (*WorkflowInstanceResult, error)
+	CreateWorkflowInstanceWithResultCtx(ctx context.Context, topic, bpmnProcessId string, version int, payload map[string]interface{}) (*WorkflowInstanceResult, error)
+	CancelWorkflowInstance(topic string, partitionID int32, key int64, opts ...RequestOption) (*Message, error)
+	CancelWorkflowInstanceCtx(ctx context.Context, topic string, partitionID int32, key int64) (*Message, error)
+	UpdateWorkflowInstancePayload(topic string, partitionID int32, activityInstanceKey, workflowInstanceKey int64, payload map[string]interface{}, opts ...RequestOption) (*Message, error)
+	UpdateWorkflowInstancePayloadCtx(ctx context.Context, topic string, partitionID int32, activityInstanceKey, workflowInstanceKey int64, payload map[string]interface{}) (*Message, error)
+	ResolveIncident(incident *sbe.SubscribedEvent, payload map[string]interface{}, opts ...RequestOption) (*Message, error)
+	ResolveIncidentCtx(ctx context.Context, incident *sbe.SubscribedEvent, payload map[string]interface{}) (*Message, error)
+
+	OpenTaskSubscription(ts *TaskSubscription, opts ...RequestOption) (*Subscription, error)
+	OpenTaskSubscriptionCtx(ctx context.Context, ts *TaskSubscription) (*Subscription, error)
+	TaskConsumer(ts *TaskSubscription, opts ...RequestOption) (chan *Message, error)
+	TaskConsumerCtx(ctx context.Context, ts *TaskSubscription) (chan *Message, error)
+	OpenTopicSubscription(ts *TopicSubscription, opts ...RequestOption) (*Subscription, error)
+	OpenTopicSubscriptionCtx(ctx context.Context, ts *TopicSubscription) (*Subscription, error)
+	TopicConsumer(ts *TopicSubscription, opts ...RequestOption) (chan *Message, error)
+	TopicConsumerCtx(ctx context.Context, ts *TopicSubscription) (chan *Message, error)
+	OpenIncidentSubscription(ts *TopicSubscription, opts ...RequestOption) (*Subscription, error)
+	OpenIncidentSubscriptionCtx(ctx context.Context, ts *TopicSubscription) (*Subscription, error)
+	AcknowledgeTopicEvent(event *sbe.SubscribedEvent, opts ...RequestOption) (*Message, error)
+	AcknowledgeTopicEventCtx(ctx context.Context, event *sbe.SubscribedEvent) (*Message, error)
+	NewWorker(taskType string, handler TaskHandler, opts ...WorkerOption) *Worker
+	SubscribeTopic(topic string, handler TopicEventHandler, opts ...TopicWorkerOption) (*TopicWorker, error)
+	SubscribeTopicCtx(ctx context.Context, topic string, handler TopicEventHandler, opts ...TopicWorkerOption) (*TopicWorker, error)
+
+	CreateTopic(name string, partitions int, opts ...RequestOption) (*Topic, error)
+	CreateTopicCtx(ctx context.Context, name string, partitions int) (*Topic, error)
+	TopicStats(topic string, opts ...RequestOption) ([]*PartitionStats, error)
+	TopicStatsCtx(ctx context.Context, topic string) ([]*PartitionStats, error)
+	ListWorkflowInstances(topic string, opts ...RequestOption) ([]*WorkflowInstanceSnapshot, error)
+	ListWorkflowInstancesCtx(ctx context.Context, topic string) ([]*WorkflowInstanceSnapshot, error)
+	ListTasks(topic string, opts ...RequestOption) ([]*TaskSnapshot, error)
+	ListTasksCtx(ctx context.Context, topic string) ([]*TaskSnapshot, error)
+	Topology(opts ...RequestOption) (*Topology, error)
+	TopologyCtx(ctx context.Context) (*Topology, error)
+	HealthCheck(opts ...RequestOption) (*Health, error)
+	HealthCheckCtx(ctx context.Context) (*Health, error)
+	BrokerProtocol(opts ...RequestOption) (*BrokerProtocol, error)
+	BrokerProtocolCtx(ctx context.Context) (*BrokerProtocol, error)
+
+	Close(ctx context.Context) error
+}
+
+var _ ZeebeClient = (*Client)(nil)